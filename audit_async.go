@@ -0,0 +1,162 @@
+package toolfs
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultAuditBufferSize is the AsyncAuditLogger buffer size used when
+// NewAsyncAuditLogger is given zero or less
+const DefaultAuditBufferSize = 1024
+
+// ErrAuditLoggerClosed is returned by AsyncAuditLogger.Log after Close
+var ErrAuditLoggerClosed = errors.New("audit logger closed")
+
+// AuditOverflowPolicy controls what AsyncAuditLogger.Log does when the
+// buffer is full
+type AuditOverflowPolicy int
+
+const (
+	AuditOverflowBlock      AuditOverflowPolicy = iota // Wait for space in the buffer (default)
+	AuditOverflowDropOldest                            // Discard the oldest buffered entry
+)
+
+// AsyncAuditLogger buffers audit entries and writes them to another logger on
+// a background goroutine, so Log does not wait for the wrapped logger. Errors
+// from the wrapped logger are reported by Flush and Close. It is safe for
+// concurrent use.
+type AsyncAuditLogger struct {
+	inner   AuditLogger
+	entries chan AuditLogEntry
+	done    chan struct{} // Closed when the background goroutine exits
+
+	sendMu sync.RWMutex // Held for reading while sending, for writing to close entries
+	closed bool
+	policy AuditOverflowPolicy
+
+	mu      sync.Mutex
+	idle    *sync.Cond // Signaled when pending drops to zero
+	pending int        // Entries accepted but not yet written or dropped
+	dropped int64
+	err     error // First error from inner since the last Flush
+}
+
+// NewAsyncAuditLogger starts a logger that buffers up to bufferSize entries
+// for inner
+func NewAsyncAuditLogger(inner AuditLogger, bufferSize int) *AsyncAuditLogger {
+	if bufferSize <= 0 {
+		bufferSize = DefaultAuditBufferSize
+	}
+	l := &AsyncAuditLogger{
+		inner:   inner,
+		entries: make(chan AuditLogEntry, bufferSize),
+		done:    make(chan struct{}),
+	}
+	l.idle = sync.NewCond(&l.mu)
+	go l.drain()
+	return l
+}
+
+// SetOverflowPolicy sets what Log does when the buffer is full
+func (l *AsyncAuditLogger) SetOverflowPolicy(policy AuditOverflowPolicy) {
+	l.sendMu.Lock()
+	l.policy = policy
+	l.sendMu.Unlock()
+}
+
+// Log queues entry for the wrapped logger
+func (l *AsyncAuditLogger) Log(entry AuditLogEntry) error {
+	l.sendMu.RLock()
+	defer l.sendMu.RUnlock()
+	if l.closed {
+		return ErrAuditLoggerClosed
+	}
+
+	l.addPending(1)
+	if l.policy != AuditOverflowDropOldest {
+		l.entries <- entry
+		return nil
+	}
+	for {
+		select {
+		case l.entries <- entry:
+			return nil
+		default:
+		}
+		select {
+		case <-l.entries:
+			l.mu.Lock()
+			l.dropped++
+			l.mu.Unlock()
+			l.addPending(-1)
+		default:
+		}
+	}
+}
+
+// Dropped returns the number of entries discarded because the buffer was full
+func (l *AsyncAuditLogger) Dropped() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dropped
+}
+
+// Flush waits until every entry logged so far has been written (or dropped)
+// and returns the first error the wrapped logger reported since the last Flush
+func (l *AsyncAuditLogger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.pending > 0 {
+		l.idle.Wait()
+	}
+	err := l.err
+	l.err = nil
+	return err
+}
+
+// Close writes the buffered entries, stops the background goroutine and
+// closes the wrapped logger if it has a Close method. Later calls to Log
+// return ErrAuditLoggerClosed.
+func (l *AsyncAuditLogger) Close() error {
+	l.sendMu.Lock()
+	if l.closed {
+		l.sendMu.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.entries)
+	l.sendMu.Unlock()
+
+	<-l.done
+	err := l.Flush()
+	if closer, ok := l.inner.(interface{ Close() error }); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// drain writes queued entries to the wrapped logger until entries is closed
+func (l *AsyncAuditLogger) drain() {
+	defer close(l.done)
+	for entry := range l.entries {
+		err := l.inner.Log(entry)
+		l.mu.Lock()
+		if err != nil && l.err == nil {
+			l.err = err
+		}
+		l.mu.Unlock()
+		l.addPending(-1)
+	}
+}
+
+// addPending adjusts the count of unwritten entries
+func (l *AsyncAuditLogger) addPending(n int) {
+	l.mu.Lock()
+	l.pending += n
+	if l.pending == 0 {
+		l.idle.Broadcast()
+	}
+	l.mu.Unlock()
+}
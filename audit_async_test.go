@@ -0,0 +1,143 @@
+package toolfs
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedAuditLogger records entries, waiting on gate before each one
+type gatedAuditLogger struct {
+	gate chan struct{}
+	err  error
+
+	mu      sync.Mutex
+	entries []AuditLogEntry
+}
+
+func (l *gatedAuditLogger) Log(entry AuditLogEntry) error {
+	if l.gate != nil {
+		<-l.gate
+	}
+	l.mu.Lock()
+	l.entries = append(l.entries, entry)
+	l.mu.Unlock()
+	return l.err
+}
+
+func (l *gatedAuditLogger) paths() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var paths []string
+	for _, entry := range l.entries {
+		paths = append(paths, entry.Path)
+	}
+	return paths
+}
+
+func TestAsyncAuditLoggerFlush(t *testing.T) {
+	inner := &gatedAuditLogger{}
+	logger := NewAsyncAuditLogger(inner, 4)
+	defer logger.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := logger.Log(AuditLogEntry{Path: "/toolfs/x"}); err != nil {
+					t.Errorf("Log failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got := len(inner.paths()); got != 100 {
+		t.Errorf("Expected 100 entries after Flush, got %d", got)
+	}
+	if logger.Dropped() != 0 {
+		t.Errorf("Expected no drops with the blocking policy, got %d", logger.Dropped())
+	}
+}
+
+func TestAsyncAuditLoggerDropOldest(t *testing.T) {
+	inner := &gatedAuditLogger{gate: make(chan struct{})}
+	logger := NewAsyncAuditLogger(inner, 2)
+	logger.SetOverflowPolicy(AuditOverflowDropOldest)
+
+	// "a" is taken by the background goroutine, which then waits on the gate
+	logger.Log(AuditLogEntry{Path: "a"})
+	deadline := time.Now().Add(time.Second)
+	for len(logger.entries) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The buffer holds two entries, so "b" and "c" are dropped for "d" and "e"
+	for _, path := range []string{"b", "c", "d", "e"} {
+		done := make(chan struct{})
+		go func() {
+			logger.Log(AuditLogEntry{Path: path})
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("Log blocked with the drop-oldest policy")
+		}
+	}
+	close(inner.gate)
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := inner.paths(); len(got) != 3 || got[0] != "a" || got[1] != "d" || got[2] != "e" {
+		t.Errorf("Expected [a d e], got %v", got)
+	}
+	if logger.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped entries, got %d", logger.Dropped())
+	}
+}
+
+func TestAsyncAuditLoggerClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	fileLogger, err := NewFileAuditLogger(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+	logger := NewAsyncAuditLogger(fileLogger, 0)
+
+	session := NewSession("async", nil)
+	session.SetAuditLogger(logger)
+	for i := 0; i < 50; i++ {
+		session.logAudit("ReadFile", "/toolfs/data/file.txt", true, nil, 1, 0)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := len(readAuditFile(t, path)); got != 50 {
+		t.Errorf("Expected 50 entries written on Close, got %d", got)
+	}
+	if err := logger.Log(AuditLogEntry{}); !errors.Is(err, ErrAuditLoggerClosed) {
+		t.Errorf("Expected ErrAuditLoggerClosed, got %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("Expected second Close to succeed, got %v", err)
+	}
+
+	// Errors from the wrapped logger surface on Flush
+	failing := NewAsyncAuditLogger(&gatedAuditLogger{err: errors.New("disk full")}, 1)
+	defer failing.Close()
+	failing.Log(AuditLogEntry{})
+	if err := failing.Flush(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected inner error from Flush, got %v", err)
+	}
+	if err := failing.Flush(); err != nil {
+		t.Errorf("Expected Flush to clear the error, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time. Tests substitute a fake clock to control
+// time-dependent behavior such as audit file rotation.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock backed by time.Now
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// DailyRotatingAuditLogger appends audit entries as JSON lines to one file
+// per local calendar day, named dir/audit-YYYY-MM-DD.jsonl. Files are created
+// with owner-only permissions and every entry is written to the file as soon
+// as it is logged. It is safe for concurrent use.
+type DailyRotatingAuditLogger struct {
+	dir   string
+	clock Clock
+
+	mu   sync.Mutex
+	date string   // Date of the open file
+	file *os.File // Open file for date (nil until the first entry)
+}
+
+// NewDailyRotatingAuditLogger creates a logger writing to dir, creating the
+// directory if needed
+func NewDailyRotatingAuditLogger(dir string) (*DailyRotatingAuditLogger, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	return &DailyRotatingAuditLogger{dir: dir, clock: systemClock{}}, nil
+}
+
+// SetClock replaces the clock used to choose the file for each entry
+func (l *DailyRotatingAuditLogger) SetClock(clock Clock) {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	l.mu.Lock()
+	l.clock = clock
+	l.mu.Unlock()
+}
+
+// Log appends entry to the file for the current local date, switching files
+// when the date has changed since the previous entry
+func (l *DailyRotatingAuditLogger) Log(entry AuditLogEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	date := l.clock.Now().Local().Format("2006-01-02")
+	if l.file == nil || date != l.date {
+		if err := l.openLocked(date); err != nil {
+			return err
+		}
+	}
+	_, err = l.file.Write(data)
+	return err
+}
+
+// Path returns the file entries logged now would be written to
+func (l *DailyRotatingAuditLogger) Path() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pathFor(l.clock.Now().Local().Format("2006-01-02"))
+}
+
+// Close closes the open audit file. A later Log reopens it.
+func (l *DailyRotatingAuditLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// pathFor returns the audit file path for date
+func (l *DailyRotatingAuditLogger) pathFor(date string) string {
+	return filepath.Join(l.dir, "audit-"+date+".jsonl")
+}
+
+// openLocked closes the current file and opens the file for date.
+// l.mu must be held.
+func (l *DailyRotatingAuditLogger) openLocked(date string) error {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	file, err := os.OpenFile(l.pathFor(date), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	l.file = file
+	l.date = date
+	return nil
+}
+
+// FileAuditLogger appends audit entries as JSON lines to a single file. When
+// an entry would take the file past its size limit, the file is rotated:
+// path becomes path.1, path.1 becomes path.2, and so on, and a new path is
+// started. It is safe for concurrent use.
+type FileAuditLogger struct {
+	path    string
+	maxSize int64 // Rotation threshold in bytes (0 = never rotate)
+
+	mu   sync.Mutex
+	file *os.File
+	size int64 // Current size of file
+}
+
+// NewFileAuditLogger opens (or creates) the audit file at path for appending.
+// maxSizeBytes of zero or less disables rotation.
+func NewFileAuditLogger(path string, maxSizeBytes int64) (*FileAuditLogger, error) {
+	if maxSizeBytes < 0 {
+		maxSizeBytes = 0
+	}
+	l := &FileAuditLogger{path: path, maxSize: maxSizeBytes}
+	if err := l.openLocked(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Log appends entry to the file, rotating it first if the entry would exceed
+// the size limit. An entry larger than the limit is written to a fresh file.
+func (l *FileAuditLogger) Log(entry AuditLogEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		if err := l.openLocked(); err != nil {
+			return err
+		}
+	}
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(data)) > l.maxSize {
+		if err := l.rotateLocked(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	return err
+}
+
+// Path returns the path of the active audit file
+func (l *FileAuditLogger) Path() string {
+	return l.path
+}
+
+// Close flushes and closes the audit file. A later Log reopens it.
+func (l *FileAuditLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	syncErr := l.file.Sync()
+	err := l.file.Close()
+	l.file = nil
+	if err == nil {
+		err = syncErr
+	}
+	return err
+}
+
+// openLocked opens the audit file for appending. l.mu must be held (or l not
+// yet shared).
+func (l *FileAuditLogger) openLocked() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit file: %w", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// rotateLocked closes the audit file, shifts it and its backups up by one
+// and opens a new file. l.mu must be held.
+func (l *FileAuditLogger) rotateLocked() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit file: %w", err)
+	}
+	l.file = nil
+
+	last := 1
+	for {
+		if _, err := os.Stat(l.backupPath(last)); err != nil {
+			break
+		}
+		last++
+	}
+	for i := last; i > 1; i-- {
+		if err := os.Rename(l.backupPath(i-1), l.backupPath(i)); err != nil {
+			return fmt.Errorf("failed to rotate audit file: %w", err)
+		}
+	}
+	if err := os.Rename(l.path, l.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate audit file: %w", err)
+	}
+	return l.openLocked()
+}
+
+// backupPath returns the path of the nth rotated file
+func (l *FileAuditLogger) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
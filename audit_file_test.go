@@ -0,0 +1,215 @@
+package toolfs
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time is set by the test
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+}
+
+// readAuditFile returns the entries in a JSONL audit file
+func readAuditFile(t *testing.T, path string) []AuditLogEntry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var entries []AuditLogEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Invalid JSON line in %s: %v", path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestDailyRotatingAuditLogger(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "audit")
+	logger, err := NewDailyRotatingAuditLogger(dir)
+	if err != nil {
+		t.Fatalf("NewDailyRotatingAuditLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	clock := &fakeClock{now: time.Date(2024, 3, 9, 23, 59, 58, 0, time.Local)}
+	logger.SetClock(clock)
+
+	logger.Log(AuditLogEntry{SessionID: "s", Operation: "ReadFile", Path: "/toolfs/a"})
+	logger.Log(AuditLogEntry{SessionID: "s", Operation: "WriteFile", Path: "/toolfs/b"})
+	clock.Set(time.Date(2024, 3, 10, 0, 0, 1, 0, time.Local))
+	logger.Log(AuditLogEntry{SessionID: "s", Operation: "Stat", Path: "/toolfs/c"})
+
+	before := readAuditFile(t, filepath.Join(dir, "audit-2024-03-09.jsonl"))
+	after := readAuditFile(t, filepath.Join(dir, "audit-2024-03-10.jsonl"))
+	if len(before) != 2 || before[0].Path != "/toolfs/a" || before[1].Path != "/toolfs/b" {
+		t.Errorf("Unexpected entries before midnight: %+v", before)
+	}
+	if len(after) != 1 || after[0].Path != "/toolfs/c" {
+		t.Errorf("Unexpected entries after midnight: %+v", after)
+	}
+	if logger.Path() != filepath.Join(dir, "audit-2024-03-10.jsonl") {
+		t.Errorf("Unexpected current path: %s", logger.Path())
+	}
+
+	// Going back to an earlier date appends to that day's file
+	clock.Set(time.Date(2024, 3, 9, 12, 0, 0, 0, time.Local))
+	logger.Log(AuditLogEntry{SessionID: "s", Operation: "ReadFile", Path: "/toolfs/d"})
+	if entries := readAuditFile(t, filepath.Join(dir, "audit-2024-03-09.jsonl")); len(entries) != 3 {
+		t.Errorf("Expected 3 entries after reopening, got %d", len(entries))
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "audit-2024-03-10.jsonl"))
+		if err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected 0600 audit file, got %v, %v", info, err)
+		}
+	}
+}
+
+func TestDailyRotatingAuditLoggerConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewDailyRotatingAuditLogger(dir)
+	if err != nil {
+		t.Fatalf("NewDailyRotatingAuditLogger failed: %v", err)
+	}
+	defer logger.Close()
+	clock := &fakeClock{now: time.Date(2024, 3, 9, 23, 0, 0, 0, time.Local)}
+	logger.SetClock(clock)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if i == 0 && j == 25 {
+					clock.Set(time.Date(2024, 3, 10, 1, 0, 0, 0, time.Local))
+				}
+				logger.Log(AuditLogEntry{SessionID: "s", Operation: "ReadFile"})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	total := len(readAuditFile(t, filepath.Join(dir, "audit-2024-03-09.jsonl"))) +
+		len(readAuditFile(t, filepath.Join(dir, "audit-2024-03-10.jsonl")))
+	if total != 400 {
+		t.Errorf("Expected 400 entries across both files, got %d", total)
+	}
+}
+
+func TestFileAuditLogger(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	logger, err := NewFileAuditLogger(path, 300)
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+
+	session := NewSession("file-logger", nil)
+	session.SetAuditLogger(logger)
+	for i := 0; i < 10; i++ {
+		session.logAudit("ReadFile", "/toolfs/data/file.txt", true, nil, int64(i), 0)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Entries are spread over path, path.1, path.2, ... oldest last
+	var all []AuditLogEntry
+	files := []string{path}
+	for n := 1; ; n++ {
+		backup := path + "." + strconv.Itoa(n)
+		if _, err := os.Stat(backup); err != nil {
+			break
+		}
+		files = append(files, backup)
+	}
+	if len(files) < 3 {
+		t.Fatalf("Expected at least two rotations, got files %v", files)
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		info, err := os.Stat(files[i])
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+		if info.Size() > 300 {
+			t.Errorf("%s exceeds the size limit: %d bytes", files[i], info.Size())
+		}
+		all = append(all, readAuditFile(t, files[i])...)
+	}
+	if len(all) != 10 {
+		t.Fatalf("Expected 10 entries across files, got %d", len(all))
+	}
+	for i, entry := range all {
+		if entry.BytesRead != int64(i) {
+			t.Errorf("Entry %d out of order: bytes_read %d", i, entry.BytesRead)
+		}
+	}
+
+	// Logging after Close reopens and appends to the file
+	before := len(readAuditFile(t, path))
+	if err := logger.Log(AuditLogEntry{Operation: "Stat"}); err != nil {
+		t.Fatalf("Log after Close failed: %v", err)
+	}
+	logger.Close()
+	if got := len(readAuditFile(t, path)); got != before+1 {
+		t.Errorf("Expected %d entries after reopening, got %d", before+1, got)
+	}
+}
+
+func TestFileAuditLoggerConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := NewFileAuditLogger(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				logger.Log(AuditLogEntry{Operation: "ReadFile", Path: "/toolfs/data/x"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(readAuditFile(t, path)); got != 200 {
+		t.Errorf("Expected 200 entries, got %d", got)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Error("Expected no rotation without a size limit")
+	}
+}
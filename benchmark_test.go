@@ -62,6 +62,28 @@ func BenchmarkReadFile(b *testing.B) {
 	})
 }
 
+// BenchmarkReadFileRepeated benchmarks repeated reads of the same small file,
+// which hit the single-entry resolution cache
+func BenchmarkReadFileRepeated(b *testing.B) {
+	fs := NewToolFS("/toolfs")
+	tmpDir := setupBenchmarkDir(b)
+	defer os.RemoveAll(tmpDir)
+
+	err := fs.MountLocal("/data", tmpDir, false)
+	if err != nil {
+		b.Fatalf("MountLocal failed: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := fs.ReadFile("/toolfs/data/test0.txt")
+		if err != nil {
+			b.Fatalf("ReadFile failed: %v", err)
+		}
+	}
+}
+
 // BenchmarkReadFileLarge benchmarks reading large files
 func BenchmarkReadFileLarge(b *testing.B) {
 	fs := NewToolFS("/toolfs")
@@ -288,6 +310,35 @@ func BenchmarkPathResolution(b *testing.B) {
 	}
 }
 
+// BenchmarkResolveRepeated benchmarks resolving the same path repeatedly,
+// serially and from parallel readers, through the single-entry cache
+func BenchmarkResolveRepeated(b *testing.B) {
+	fs := NewToolFS("/toolfs")
+	tmpDir := setupBenchmarkDir(b)
+	defer os.RemoveAll(tmpDir)
+
+	fs.MountLocal("/data", tmpDir, false)
+
+	b.Run("Serial", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := fs.resolveMapped("/toolfs/data/test0.txt"); err != nil {
+				b.Fatalf("resolveMapped failed: %v", err)
+			}
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, _, err := fs.resolveMapped("/toolfs/data/test0.txt"); err != nil {
+					b.Fatalf("resolveMapped failed: %v", err)
+				}
+			}
+		})
+	})
+}
+
 // BenchmarkSkillExecute benchmarks skill execution performance
 func BenchmarkSkillExecute(b *testing.B) {
 	fs := NewToolFS("/toolfs")
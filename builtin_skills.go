@@ -64,12 +64,11 @@ func (p *BuiltinMemorySkill) Execute(input []byte) ([]byte, error) {
 		})
 
 	case "write_file", "write":
+		// Writes without an entry ID are stored under a generated ID,
+		// which is returned in the response
 		entryID := p.extractEntryID(request.Path, request.Data)
 		if entryID == "" {
-			return json.Marshal(SkillResponse{
-				Success: false,
-				Error:   "memory entry ID is required",
-			})
+			entryID = generateMemoryID()
 		}
 
 		var content string
@@ -141,12 +140,22 @@ func (p *BuiltinMemorySkill) Execute(input []byte) ([]byte, error) {
 }
 
 func (p *BuiltinMemorySkill) extractEntryID(path string, data map[string]interface{}) string {
-	// Try to extract from path first
-	if path != "" {
+	if relPath, ok := data["relative_path"].(string); ok {
+		// Mounted: the entry ID is the first segment below the mount point
+		if relPath = strings.Trim(relPath, "/"); relPath != "" {
+			return strings.SplitN(relPath, "/", 2)[0]
+		}
+	} else if path != "" {
+		// Try to extract from path
 		parts := strings.Split(strings.Trim(path, "/"), "/")
 		for i, part := range parts {
-			if part == "memory" && i+1 < len(parts) {
-				return parts[i+1]
+			if part == "memory" {
+				if i+1 < len(parts) {
+					return parts[i+1]
+				}
+				// Path is the memory directory itself, fall back to data
+				parts = nil
+				break
 			}
 		}
 		// If path doesn't contain "memory", use the last part
@@ -187,6 +196,10 @@ Persistent key-value storage for session data, conversation context, and agent s
 
 ### Read Memory Entry
 GET /toolfs/memory/<entry_id>
+Returns the entry content as plain text.
+
+GET /toolfs/memory/<entry_id>?format=json
+Returns the full entry (id, content, created_at, updated_at, metadata) as JSON.
 
 ### Write Memory Entry  
 PUT /toolfs/memory/<entry_id>
@@ -246,6 +259,12 @@ func (p *BuiltinRAGSkill) Execute(input []byte) ([]byte, error) {
 			})
 		}
 
+		if highlight, pre, post := p.extractHighlight(request.Path, request.Data); highlight {
+			for i := range results {
+				results[i] = HighlightRAGResult(results[i], query, pre, post)
+			}
+		}
+
 		return json.Marshal(SkillResponse{
 			Success: true,
 			Result: RAGSearchResults{
@@ -307,6 +326,26 @@ func (p *BuiltinRAGSkill) extractQuery(path string, data map[string]interface{})
 	return query, topK
 }
 
+// extractHighlight reads the highlight options from the path query string
+// (highlight, highlight_pre, highlight_post) or from the request data
+func (p *BuiltinRAGSkill) extractHighlight(path string, data map[string]interface{}) (bool, string, string) {
+	if parts := strings.SplitN(path, "?", 2); len(parts) == 2 {
+		if queryURL, err := url.ParseQuery(parts[1]); err == nil {
+			if highlight, _ := strconv.ParseBool(queryURL.Get("highlight")); highlight {
+				return true, queryURL.Get("highlight_pre"), queryURL.Get("highlight_post")
+			}
+		}
+	}
+
+	if highlight, ok := data["highlight"].(bool); ok && highlight {
+		pre, _ := data["highlight_pre"].(string)
+		post, _ := data["highlight_post"].(string)
+		return true, pre, post
+	}
+
+	return false, "", ""
+}
+
 // GetSkillDocument implements SkillDocumentProvider
 func (p *BuiltinRAGSkill) GetSkillDocument() string {
 	return `---
@@ -326,6 +365,12 @@ Semantic search over vector databases for document retrieval.
 
 ### Semantic Search
 GET /toolfs/rag/query?text=<query_text>&top_k=<number>
+
+### Highlighting
+GET /toolfs/rag/query?text=<query_text>&highlight=true
+
+Matched terms are wrapped in ** markers (override with highlight_pre and highlight_post).
+Each result keeps the original text in raw_content and lists match offsets in highlights.
 `
 }
 
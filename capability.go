@@ -0,0 +1,129 @@
+package toolfs
+
+import "errors"
+
+// Capability is an opaque, immutable grant of filesystem access derived from
+// a session. It is bound to its ToolFS and session when created and exposes no
+// way to reach either, so code holding a capability can only act within the
+// session's scope. The scope follows the session's current access rules, which
+// only the holder of the session can change.
+type Capability struct {
+	fs      *ToolFS
+	session *Session
+}
+
+// NewCapability derives a capability from session. A nil session grants
+// unrestricted access. A nil capability grants nothing: every operation
+// fails because no ToolFS instance is available.
+func NewCapability(fs *ToolFS, session *Session) *Capability {
+	return &Capability{fs: fs, session: session}
+}
+
+// SessionID returns the ID of the session the capability was derived from
+func (c *Capability) SessionID() string {
+	if c == nil || c.session == nil {
+		return ""
+	}
+	return c.session.ID
+}
+
+// AllowedPaths returns the session's legacy AllowedPaths list. An empty
+// result means access is unrestricted unless ReadPaths or WritePaths are set;
+// use ReadPaths and WritePaths for the prefixes that actually apply, and
+// CheckAccess to include DeniedPaths and deny rules.
+func (c *Capability) AllowedPaths() []string {
+	if c == nil || c.session == nil {
+		return nil
+	}
+	return copyPaths(c.session.AllowedPaths)
+}
+
+// ReadPaths returns the path prefixes the capability may read: the session's
+// ReadPaths, or its AllowedPaths when none are set. An empty result means
+// reads are unrestricted.
+func (c *Capability) ReadPaths() []string {
+	if c == nil || c.session == nil {
+		return nil
+	}
+	return copyPaths(c.session.readPaths())
+}
+
+// WritePaths returns the path prefixes the capability may write: the
+// session's WritePaths, or its AllowedPaths when none are set. An empty
+// result means writes are unrestricted.
+func (c *Capability) WritePaths() []string {
+	if c == nil || c.session == nil {
+		return nil
+	}
+	return copyPaths(c.session.writePaths())
+}
+
+// copyPaths returns a copy of paths, or nil if it is empty
+func copyPaths(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	return append([]string(nil), paths...)
+}
+
+// CheckAccess returns an error if the capability does not grant read, or with
+// write set write, access to path. Deny rules, the session's DeniedPaths and
+// its read or write paths apply as they do for session-scoped operations.
+func (c *Capability) CheckAccess(path string, write bool) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	if err := c.fs.checkDenied(path); err != nil {
+		return err
+	}
+	if c.session == nil {
+		return nil
+	}
+	return c.session.checkAccess(path, write)
+}
+
+// Allows reports whether the capability may read path
+func (c *Capability) Allows(path string) bool {
+	return c.CheckAccess(path, false) == nil
+}
+
+// check reports whether the capability can be used. Path restrictions are
+// enforced (and audited) by the session-scoped operations themselves.
+func (c *Capability) check() error {
+	if c == nil || c.fs == nil {
+		return errors.New("ToolFS instance not available")
+	}
+	return nil
+}
+
+// ReadFile reads a file within the capability's scope
+func (c *Capability) ReadFile(path string) ([]byte, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	return c.fs.ReadFileWithSession(path, c.session)
+}
+
+// WriteFile writes a file within the capability's scope
+func (c *Capability) WriteFile(path string, data []byte) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.fs.WriteFileWithSession(path, data, c.session)
+}
+
+// ListDir lists a directory within the capability's scope
+func (c *Capability) ListDir(path string) ([]string, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	return c.fs.ListDirWithSession(path, c.session)
+}
+
+// Stat gets file metadata within the capability's scope
+func (c *Capability) Stat(path string) (*FileInfo, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	return c.fs.StatWithSession(path, c.session)
+}
@@ -0,0 +1,90 @@
+package toolfs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCapabilityLimitsSkillToSessionScope(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	session, _ := fs.NewSession("bound", []string{"/toolfs/data/subdir"})
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+
+	ctx := NewSkillContext(fs, session)
+	if ctx.Capability().SessionID() != "bound" {
+		t.Errorf("Expected capability bound to 'bound', got %q", ctx.Capability().SessionID())
+	}
+
+	// Within scope
+	if data, err := ctx.ReadFile("/toolfs/data/subdir/subfile.txt"); err != nil || string(data) != "Subdirectory file" {
+		t.Errorf("Expected in-scope read to succeed, got %q, %v", data, err)
+	}
+	if err := ctx.WriteFile("/toolfs/data/subdir/new.txt", []byte("x")); err != nil {
+		t.Errorf("Expected in-scope write to succeed, got %v", err)
+	}
+
+	// Outside scope, through both the context and its capability
+	if _, err := ctx.ReadFile("/toolfs/data/test.txt"); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if _, err := ctx.Capability().ListDir("/toolfs/data"); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied from capability, got %v", err)
+	}
+	if err := ctx.Capability().WriteFile("/toolfs/memory/x", []byte("x")); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied for memory write, got %v", err)
+	}
+
+	// Operations are audited as the bound session
+	if len(logger.Entries) == 0 || logger.Entries[len(logger.Entries)-1].SessionID != "bound" {
+		t.Errorf("Expected audit entries for the bound session, got %+v", logger.Entries)
+	}
+
+	// A context sharing the capability has the same scope
+	shared := NewSkillContextWithCapability(ctx.Capability())
+	if _, err := shared.ReadFile("/toolfs/data/test.txt"); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected shared capability to be denied, got %v", err)
+	}
+
+	// A zero context has no access at all
+	if _, err := (&SkillContext{}).ReadFile("/toolfs/data/subdir/subfile.txt"); err == nil {
+		t.Error("Expected zero context to fail")
+	}
+}
+
+// TestCapabilityHasNoEscalationAPI checks that neither SkillContext nor
+// Capability exposes the ToolFS instance, a session, or a way to change them
+func TestCapabilityHasNoEscalationAPI(t *testing.T) {
+	forbidden := map[reflect.Type]bool{
+		reflect.TypeOf(&ToolFS{}):   true,
+		reflect.TypeOf(&Session{}):  true,
+		reflect.TypeOf(&ScopedFS{}): true,
+	}
+
+	for _, typ := range []reflect.Type{reflect.TypeOf(&SkillContext{}), reflect.TypeOf(&Capability{})} {
+		elem := typ.Elem()
+		for i := 0; i < elem.NumField(); i++ {
+			if elem.Field(i).IsExported() {
+				t.Errorf("%s has exported field %s", elem.Name(), elem.Field(i).Name)
+			}
+		}
+		for i := 0; i < typ.NumMethod(); i++ {
+			method := typ.Method(i)
+			for j := 0; j < method.Type.NumIn(); j++ {
+				if j > 0 && forbidden[method.Type.In(j)] {
+					t.Errorf("%s.%s accepts %s", elem.Name(), method.Name, method.Type.In(j))
+				}
+			}
+			for j := 0; j < method.Type.NumOut(); j++ {
+				if forbidden[method.Type.Out(j)] {
+					t.Errorf("%s.%s returns %s", elem.Name(), method.Name, method.Type.Out(j))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ChainOperationsTx executes operations like ChainOperations, but all or
+// nothing: the content of every file a step is about to write or delete is
+// captured first, and when a step fails the files changed so far are restored
+// (files the chain created are removed) before the step's *ChainStepError is
+// returned along with the results up to the failed step. Local files are
+// restored the same way RollbackSnapshot restores them and memory is restored
+// like a snapshot that includes memory; other writable backends (object
+// stores) get the captured content written back. Changes made by commands
+// and skills are not rolled back. Step conditions are evaluated as in
+// ChainOperations, but since a failed step ends the chain, "on_failure" steps
+// only run after steps that found nothing.
+func ChainOperationsTx(fs *ToolFS, operations []Operation, session *Session) ([]*Result, error) {
+	tx := &chainTx{fs: fs, captured: make(map[string]bool)}
+	var results []*Result
+
+	for i, op := range operations {
+		if result := checkCondition(op, results, i); result != nil {
+			results = append(results, result)
+			if !result.Skipped {
+				return results, tx.fail(i, op, result.Error)
+			}
+			continue
+		}
+
+		if path := mutatedPath(op); path != "" {
+			if err := tx.capture(path); err != nil {
+				result := &Result{Type: "file", Source: path, Success: false, Error: err.Error()}
+				results = append(results, result)
+				return results, tx.fail(i, op, result.Error)
+			}
+		}
+
+		result, err := runOperation(fs, op, session)
+		results = append(results, result)
+		if result != nil && !result.Success {
+			return results, tx.fail(i, op, result.Error)
+		}
+		if result == nil && err != nil {
+			return results, tx.fail(i, op, err.Error())
+		}
+	}
+
+	return results, nil
+}
+
+// mutatedPath returns the path a chain step writes or deletes, or "" for
+// steps that do not change files
+func mutatedPath(op Operation) string {
+	switch op.Type {
+	case "write_file", "delete_file":
+		return op.Path
+	}
+	return ""
+}
+
+// chainTx records the pre-images of the files written by a transactional chain
+type chainTx struct {
+	fs       *ToolFS
+	captured map[string]bool // Normalized paths whose pre-image was recorded
+
+	// Local files, restored with applyRestore
+	restores  []restoreItem
+	deletions []restoreItem
+
+	// Memory store content, restored with restoreMemory
+	memory         []MemoryEntry
+	memoryCaptured bool
+
+	// Files on other backends, restored through ToolFS
+	others []chainPreImage
+}
+
+// chainPreImage is the content of a non-local file before the chain wrote it
+type chainPreImage struct {
+	path    string
+	content []byte
+	existed bool
+}
+
+// capture records the current content of path, once per path
+func (tx *chainTx) capture(path string) error {
+	path = normalizeVirtualPath(path)
+	if tx.captured[path] {
+		return nil
+	}
+
+	localPath, mount, err := tx.fs.resolvePath(path)
+	if err != nil {
+		// The step fails on the same error without writing anything
+		return nil
+	}
+	tx.captured[path] = true
+
+	switch mountKind(mount) {
+	case "local":
+		return tx.captureLocal(path, localPath)
+	case "memory":
+		if !tx.memoryCaptured {
+			memory, err := tx.fs.snapshotMemory()
+			if err != nil {
+				return fmt.Errorf("failed to capture memory before writing: %w", err)
+			}
+			tx.memory, tx.memoryCaptured = memory, true
+		}
+	default:
+		content, readErr := tx.fs.readFileWithSession(path, nil)
+		tx.others = append(tx.others, chainPreImage{path: path, content: content, existed: readErr == nil})
+	}
+	return nil
+}
+
+// captureLocal records a local file as a restore item, or as a deletion if
+// it does not exist yet
+func (tx *chainTx) captureLocal(path, localPath string) error {
+	info, err := os.Stat(localPath)
+	switch {
+	case os.IsNotExist(err):
+		tx.deletions = append(tx.deletions, restoreItem{virtualPath: path, localPath: localPath})
+	case err != nil:
+		return fmt.Errorf("failed to capture %s before writing: %w", path, err)
+	case !info.IsDir():
+		content, err := os.ReadFile(localPath)
+		if err != nil {
+			return fmt.Errorf("failed to capture %s before writing: %w", path, err)
+		}
+		tx.restores = append(tx.restores, restoreItem{
+			virtualPath: path,
+			localPath:   localPath,
+			snap:        &FileSnapshot{Path: path, Content: content, Size: info.Size(), ModTime: info.ModTime(), Mode: info.Mode().Perm()},
+		})
+	}
+	return nil
+}
+
+// fail rolls back the chain and returns the error for its failed step
+func (tx *chainTx) fail(step int, op Operation, message string) error {
+	stepErr := &ChainStepError{Step: step, Operation: op.Type, Message: message}
+	if err := tx.rollback(); err != nil {
+		return errors.Join(stepErr, fmt.Errorf("rollback failed: %w", err))
+	}
+	return stepErr
+}
+
+// rollback restores every captured file
+func (tx *chainTx) rollback() error {
+	var errs []error
+	if err := tx.fs.applyRestore(tx.restores, tx.deletions); err != nil {
+		errs = append(errs, err)
+	}
+	if tx.memoryCaptured {
+		if err := tx.fs.restoreMemory(tx.memory); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, pre := range tx.others {
+		var err error
+		if pre.existed {
+			err = tx.fs.WriteFile(pre.path, pre.content)
+		} else if _, statErr := tx.fs.Stat(pre.path); statErr == nil {
+			err = tx.fs.DeleteFile(pre.path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", pre.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
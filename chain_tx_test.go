@@ -0,0 +1,92 @@
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChainOperationsTx(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	store := NewInMemoryObjectStore()
+	store.PutObject("kept.txt", []byte("original object"))
+	fs.MountObjectStore("/bucket", store)
+	fs.WriteFile("/toolfs/memory/existing", []byte("original memory"))
+
+	ops := []Operation{
+		{Type: "write_file", Path: "/toolfs/data/test.txt", Content: "changed"},
+		{Type: "write_file", Path: "/toolfs/data/test.txt", Content: "changed twice"},
+		{Type: "write_file", Path: "/toolfs/data/new/created.txt", Content: "created"},
+		{Type: "write_file", Path: "/toolfs/memory/existing", Content: "changed memory"},
+		{Type: "write_file", Path: "/toolfs/memory/", Content: "new memory"},
+		{Type: "write_file", Path: "/toolfs/bucket/kept.txt", Content: "changed object"},
+		{Type: "read_file", Path: "/toolfs/data/missing.txt"},
+		{Type: "write_file", Path: "/toolfs/data/never.txt", Content: "never"},
+	}
+	results, err := ChainOperationsTx(fs, ops, nil)
+
+	var stepErr *ChainStepError
+	if !errors.As(err, &stepErr) || stepErr.Step != 6 || stepErr.Operation != "read_file" {
+		t.Fatalf("Expected step 6 to fail, got %v", err)
+	}
+	if len(results) != 7 {
+		t.Errorf("Expected results up to the failed step, got %d", len(results))
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "test.txt")); string(data) != "Hello, ToolFS!" {
+		t.Errorf("Expected test.txt to be restored, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "new", "created.txt")); !os.IsNotExist(err) {
+		t.Error("Expected created file to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "never.txt")); !os.IsNotExist(err) {
+		t.Error("Expected steps after the failure not to run")
+	}
+	if ids, _ := fs.memoryStore.List(); len(ids) != 1 {
+		t.Errorf("Expected only the original memory entry, got %v", ids)
+	}
+	if entry, err := fs.memoryStore.Get("existing"); err != nil || entry.Content != "original memory" {
+		t.Errorf("Expected memory entry to be restored, got %+v, %v", entry, err)
+	}
+	if data, _ := store.GetObject("kept.txt"); string(data) != "original object" {
+		t.Errorf("Expected object to be restored, got %q", data)
+	}
+
+	// Chains without failures are applied in full
+	results, err = ChainOperationsTx(fs, ops[:3], nil)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("Expected chain to succeed, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "new", "created.txt")); string(data) != "created" {
+		t.Errorf("Expected created file to be kept, got %q", data)
+	}
+}
+
+func TestChainOperationsTxSession(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	fs.MountLocal("/other", t.TempDir(), false)
+	session, _ := fs.NewSession("tx", []string{"/toolfs/data"})
+	session.SetAuditLogger(nil)
+
+	_, err := ChainOperationsTx(fs, []Operation{
+		{Type: "write_file", Path: "/toolfs/data/test.txt", Content: "changed"},
+		{Type: "write_file", Path: "/toolfs/other/denied.txt", Content: "denied"},
+	}, session)
+
+	var stepErr *ChainStepError
+	if !errors.As(err, &stepErr) || stepErr.Step != 1 {
+		t.Fatalf("Expected the denied write to fail the chain, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "test.txt")); string(data) != "Hello, ToolFS!" {
+		t.Errorf("Expected test.txt to be restored, got %q", data)
+	}
+}
@@ -0,0 +1,213 @@
+package toolfs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CLIOptions configures ExecuteCLIWithOptions
+type CLIOptions struct {
+	// RestrictToSessionMounts runs the command against a filesystem view that
+	// contains only the local mounts the session may read, laid out at their
+	// ToolFS paths (e.g. /toolfs/data). Sessions without read restrictions
+	// see every local mount. In chroot views, mounts the session may not
+	// write are read-only. Glob read paths are only honored in the form
+	// "<dir>/**"; other globs add nothing to the view. Paths matched by the
+	// session's DeniedPaths are left out only when they name a whole mount
+	// or read path, not when they lie inside one.
+	//
+	// On Linux, when the process may create bind mounts (typically as root),
+	// the command is chrooted into the view. The view also contains read-only
+	// copies of the system binary and library directories (/bin, /sbin,
+	// /usr, /lib, /lib32, /lib64) so commands can run; /dev, /proc and /etc
+	// are not available.
+	//
+	// Elsewhere, or when bind mounts fail, the view is a directory of
+	// symlinks used as the working directory, and absolute path arguments are
+	// translated into it: "/" names the view root and paths outside the view
+	// are rejected. This only constrains paths passed as arguments; the
+	// command itself can still reach the host filesystem.
+	RestrictToSessionMounts bool
+}
+
+// CLI view modes reported in Result.Metadata["view"]
+const (
+	CLIViewChroot = "chroot"
+	CLIViewPaths  = "paths"
+)
+
+// cliSystemDirs are bind-mounted read-only into chroot views
+var cliSystemDirs = []string{"/bin", "/sbin", "/usr", "/lib", "/lib32", "/lib64"}
+
+// cliViewEntry is a local file or directory exposed in a CLI view
+type cliViewEntry struct {
+	virtual  string // ToolFS path, e.g. /toolfs/data
+	local    string // Backing local path
+	readOnly bool
+}
+
+// cliView is a temporary directory tree presenting a session's mounts
+type cliView struct {
+	root    string
+	mode    string
+	entries []cliViewEntry
+	mounts  []string // Bind mount targets in mount order (chroot mode)
+}
+
+// newCLIView materializes the mounts visible to session in a temporary tree,
+// using bind mounts where possible and symlinks otherwise
+func (fs *ToolFS) newCLIView(session *Session) (*cliView, error) {
+	root, err := os.MkdirTemp("", "toolfs-cli-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CLI view: %w", err)
+	}
+	view := &cliView{root: root, entries: fs.cliViewEntries(session)}
+
+	if bindErr := view.bind(); bindErr == nil {
+		view.mode = CLIViewChroot
+		return view, nil
+	}
+	// Undo a partial bind before falling back to symlinks
+	if err := view.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Mkdir(root, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create CLI view: %w", err)
+	}
+
+	view.mode = CLIViewPaths
+	for _, entry := range view.entries {
+		target := view.hostPath(entry.virtual)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			view.Close()
+			return nil, fmt.Errorf("failed to create CLI view: %w", err)
+		}
+		if err := os.Symlink(entry.local, target); err != nil {
+			view.Close()
+			return nil, fmt.Errorf("failed to create CLI view: %w", err)
+		}
+	}
+	return view, nil
+}
+
+// cliViewEntries lists the local mounts, or parts of them, that session may
+// read, skipping entries nested inside another entry. Entries the session may
+// not write are read-only.
+func (fs *ToolFS) cliViewEntries(session *Session) []cliViewEntry {
+	var entries []cliViewEntry
+	for mountPoint, mount := range fs.mounts {
+		if session == nil || len(session.readPaths()) == 0 {
+			entries = append(entries, cliViewEntry{virtual: mountPoint, local: mount.LocalPath, readOnly: mount.ReadOnly || !canWrite(session, mountPoint)})
+			continue
+		}
+		for _, allowed := range session.readPaths() {
+			allowed = strings.TrimSuffix(normalizeVirtualPath(allowed), "/**")
+			if isGlobPattern(allowed) {
+				continue
+			}
+			allowed = cleanVirtualPath(allowed)
+			switch {
+			case isSubPath(mountPoint, allowed):
+				entries = append(entries, cliViewEntry{virtual: mountPoint, local: mount.LocalPath, readOnly: mount.ReadOnly || !canWrite(session, mountPoint)})
+			case isSubPath(allowed, mountPoint):
+				rel := strings.TrimPrefix(allowed, mountPoint)
+				local := filepath.Join(mount.LocalPath, filepath.FromSlash(rel))
+				if _, err := os.Stat(local); err == nil {
+					entries = append(entries, cliViewEntry{virtual: allowed, local: local, readOnly: mount.ReadOnly || !canWrite(session, allowed)})
+				}
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].virtual < entries[j].virtual })
+	kept := entries[:0]
+	for _, entry := range entries {
+		if len(kept) > 0 && isSubPath(entry.virtual, kept[len(kept)-1].virtual) {
+			continue
+		}
+		if session != nil && session.deniedRule(entry.virtual) != "" {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept
+}
+
+// apply configures cmd to run inside the view
+func (v *cliView) apply(cmd *exec.Cmd) error {
+	if v.mode == CLIViewChroot {
+		chrootCmd(cmd, v.root)
+		cmd.Dir = "/"
+		return nil
+	}
+
+	for i := 1; i < len(cmd.Args); i++ {
+		arg := cmd.Args[i]
+		if !strings.HasPrefix(arg, "/") && !strings.Contains(arg, "..") {
+			continue
+		}
+		mapped, err := v.mapArg(arg)
+		if err != nil {
+			return err
+		}
+		cmd.Args[i] = mapped
+	}
+	cmd.Dir = v.root
+	return nil
+}
+
+// mapArg translates an absolute ToolFS path argument into the view
+func (v *cliView) mapArg(arg string) (string, error) {
+	for _, segment := range strings.Split(arg, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: path traversal in argument '%s'", ErrAccessDenied, arg)
+		}
+	}
+	if !strings.HasPrefix(arg, "/") {
+		return arg, nil
+	}
+
+	clean := cleanVirtualPath(arg)
+	for _, entry := range v.entries {
+		// Ancestors of an entry (including "/") and paths inside it are visible
+		if isSubPath(entry.virtual, clean) || isSubPath(clean, entry.virtual) {
+			return v.hostPath(clean), nil
+		}
+	}
+	return "", fmt.Errorf("%w: '%s' is outside the session's mounts", ErrAccessDenied, arg)
+}
+
+// hostPath returns the location of a virtual path within the view
+func (v *cliView) hostPath(virtual string) string {
+	return filepath.Join(v.root, filepath.FromSlash(virtual))
+}
+
+// Close unmounts the view and removes it. The tree is only removed once every
+// bind mount is gone, so host files are never deleted through the view.
+func (v *cliView) Close() error {
+	if err := v.unbind(); err != nil {
+		return fmt.Errorf("failed to unmount CLI view at %s: %w", v.root, err)
+	}
+	return os.RemoveAll(v.root)
+}
+
+// canWrite reports whether session may write path; nil sessions may write
+// anything
+func canWrite(session *Session, path string) bool {
+	return session == nil || session.IsWriteAllowed(path)
+}
+
+// isSubPath reports whether p equals dir or lies below it
+func isSubPath(p, dir string) bool {
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
+
+// cleanVirtualPath cleans a slash-separated virtual path
+func cleanVirtualPath(p string) string {
+	return path.Clean("/" + strings.TrimPrefix(p, "/"))
+}
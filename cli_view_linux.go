@@ -0,0 +1,80 @@
+//go:build linux
+// +build linux
+
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// bind populates the view with bind mounts of its entries and the system
+// directories. It fails without CAP_SYS_ADMIN.
+func (v *cliView) bind() error {
+	if os.Geteuid() != 0 {
+		return errors.New("bind mounts require root")
+	}
+
+	for _, dir := range cliSystemDirs {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := v.bindOne(dir, v.hostPath(dir), true); err != nil {
+			return err
+		}
+	}
+	for _, entry := range v.entries {
+		if err := v.bindOne(entry.local, v.hostPath(entry.virtual), entry.readOnly); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindOne bind-mounts source at target, creating the mount point
+func (v *cliView) bindOne(source, target string, readOnly bool) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		err = os.MkdirAll(target, 0o755)
+	} else if err = os.MkdirAll(filepath.Dir(target), 0o755); err == nil {
+		err = os.WriteFile(target, nil, 0o600)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := syscall.Mount(source, target, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return err
+	}
+	v.mounts = append(v.mounts, target)
+	if readOnly {
+		return syscall.Mount("", target, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY, "")
+	}
+	return nil
+}
+
+// unbind detaches the view's bind mounts in reverse order
+func (v *cliView) unbind() error {
+	for len(v.mounts) > 0 {
+		target := v.mounts[len(v.mounts)-1]
+		if err := syscall.Unmount(target, syscall.MNT_DETACH); err != nil {
+			return err
+		}
+		v.mounts = v.mounts[:len(v.mounts)-1]
+	}
+	return nil
+}
+
+// chrootCmd makes cmd run with root as its filesystem root
+func chrootCmd(cmd *exec.Cmd, root string) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Chroot = root
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+// +build !linux
+
+package toolfs
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// errCLIBindUnsupported is returned by bind on platforms without bind mounts
+var errCLIBindUnsupported = errors.New("bind mounts are not supported on this platform")
+
+// bind is unsupported outside Linux, so views always use symlinks
+func (v *cliView) bind() error {
+	return errCLIBindUnsupported
+}
+
+func (v *cliView) unbind() error {
+	return nil
+}
+
+func chrootCmd(cmd *exec.Cmd, root string) {}
@@ -0,0 +1,97 @@
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExecuteCLIRestrictedView(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("CLI views are only tested on Linux")
+	}
+
+	dataDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	secretDir := t.TempDir()
+	os.WriteFile(filepath.Join(secretDir, "secret.txt"), []byte("secret"), 0o644)
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", dataDir, true)
+	fs.MountLocal("/secret", secretDir, true)
+	session, _ := fs.NewSession("cli-view", []string{"/toolfs/data"})
+	opts := CLIOptions{RestrictToSessionMounts: true}
+
+	result, err := ExecuteCLIWithOptions("ls", []string{"/"}, session, fs, opts)
+	if err != nil {
+		t.Fatalf("ExecuteCLIWithOptions failed: %v", err)
+	}
+	mode := result.Metadata.(map[string]interface{})["view"]
+	t.Logf("CLI view mode: %v", mode)
+	for _, host := range []string{"etc", "root", "home", "tmp"} {
+		for _, entry := range strings.Fields(result.Content) {
+			if entry == host {
+				t.Errorf("Expected host directory %q to be hidden, got %q", host, result.Content)
+			}
+		}
+	}
+
+	result, err = ExecuteCLIWithOptions("ls", []string{"/toolfs"}, session, fs, opts)
+	if err != nil || strings.TrimSpace(result.Content) != "data" {
+		t.Errorf("Expected only the session's mount, got %q, %v", result.Content, err)
+	}
+
+	result, err = ExecuteCLIWithOptions("ls", []string{"/toolfs/data"}, session, fs, opts)
+	if err != nil || !strings.Contains(result.Content, "test.txt") {
+		t.Errorf("Expected mount contents, got %q, %v", result.Content, err)
+	}
+
+	// Paths outside the session's mounts are hidden or rejected
+	result, err = ExecuteCLIWithOptions("cat", []string{"/toolfs/secret/secret.txt"}, session, fs, opts)
+	if err == nil && result.Success {
+		t.Errorf("Expected reading another mount to fail, got %q", result.Content)
+	}
+	if mode == CLIViewPaths && !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied in paths mode, got %v", err)
+	}
+
+	// Without the option the command sees the host
+	result, err = ExecuteCLI("ls", []string{dataDir}, session, fs)
+	if err != nil || !strings.Contains(result.Content, "test.txt") {
+		t.Errorf("Expected unrestricted listing, got %q, %v", result.Content, err)
+	}
+}
+
+func TestCLIViewPathMapping(t *testing.T) {
+	dataDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", dataDir, true)
+	fs.MountLocal("/other", t.TempDir(), true)
+	session, _ := fs.NewSession("cli-map", []string{"/toolfs/data/subdir"})
+
+	entries := fs.cliViewEntries(session)
+	if len(entries) != 1 || entries[0].virtual != "/toolfs/data/subdir" || entries[0].local != filepath.Join(dataDir, "subdir") {
+		t.Fatalf("Expected only the allowed subdirectory, got %+v", entries)
+	}
+
+	view := &cliView{root: "/view", entries: entries}
+	for arg, want := range map[string]string{
+		"/":                               "/view",
+		"/toolfs":                         "/view/toolfs",
+		"/toolfs/data/subdir/subfile.txt": "/view/toolfs/data/subdir/subfile.txt",
+	} {
+		if got, err := view.mapArg(arg); err != nil || got != filepath.FromSlash(want) {
+			t.Errorf("mapArg(%q) = %q, %v; want %q", arg, got, err, want)
+		}
+	}
+	for _, arg := range []string{"/etc/passwd", "/toolfs/other", "/toolfs/data/test.txt", "../escape"} {
+		if _, err := view.mapArg(arg); !errors.Is(err, ErrAccessDenied) {
+			t.Errorf("Expected mapArg(%q) to be denied, got %v", arg, err)
+		}
+	}
+}
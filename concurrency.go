@@ -0,0 +1,206 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrConcurrencyLimit is wrapped by errors returned when a skill execution is
+// rejected because a concurrency limit was reached, either immediately
+// (ConcurrencyReject) or after the queue wait timed out (ConcurrencyQueue).
+var ErrConcurrencyLimit = errors.New("skill concurrency limit reached")
+
+// ConcurrencyPolicy controls what happens to an execution that would exceed a
+// concurrency limit
+type ConcurrencyPolicy int
+
+const (
+	ConcurrencyQueue  ConcurrencyPolicy = iota // Wait for a free slot (default)
+	ConcurrencyReject                          // Fail immediately
+)
+
+// ConcurrencyStats reports the state of a concurrency limit
+type ConcurrencyStats struct {
+	Limit    int    `json:"limit"`     // Maximum simultaneous executions (0 = unlimited)
+	InFlight int    `json:"in_flight"` // Executions currently running
+	Waiting  int    `json:"waiting"`   // Executions queued for a slot
+	Rejected uint64 `json:"rejected"`  // Executions rejected or timed out so far
+}
+
+// execLimiter bounds simultaneous executions and tracks in-flight counts.
+// A limit of 0 tracks executions without bounding them.
+type execLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inflight int
+	waiting  int
+	rejected uint64
+	changed  chan struct{} // Closed (and replaced) when a slot frees or the limit changes
+}
+
+func newExecLimiter(limit int) *execLimiter {
+	return &execLimiter{limit: limit, changed: make(chan struct{})}
+}
+
+// setLimit changes the limit, waking queued executions
+func (l *execLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	l.limit = limit
+	l.broadcastLocked()
+	l.mu.Unlock()
+}
+
+// acquire takes a slot according to policy. With ConcurrencyQueue it waits up
+// to timeout (forever if timeout <= 0).
+func (l *execLimiter) acquire(name string, policy ConcurrencyPolicy, timeout time.Duration) error {
+	var expired <-chan time.Time
+	l.mu.Lock()
+	for l.limit > 0 && l.inflight >= l.limit {
+		if policy == ConcurrencyReject {
+			l.rejected++
+			l.mu.Unlock()
+			return fmt.Errorf("%w: %d executions of %s in flight", ErrConcurrencyLimit, l.limit, name)
+		}
+		if expired == nil && timeout > 0 {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			expired = timer.C
+		}
+
+		changed := l.changed
+		l.waiting++
+		l.mu.Unlock()
+		select {
+		case <-changed:
+			l.mu.Lock()
+			l.waiting--
+		case <-expired:
+			l.mu.Lock()
+			l.waiting--
+			l.rejected++
+			l.mu.Unlock()
+			return fmt.Errorf("%w: timed out after %v waiting for %s", ErrConcurrencyLimit, timeout, name)
+		}
+	}
+	l.inflight++
+	l.mu.Unlock()
+	return nil
+}
+
+// release frees a slot taken by acquire
+func (l *execLimiter) release() {
+	l.mu.Lock()
+	l.inflight--
+	l.broadcastLocked()
+	l.mu.Unlock()
+}
+
+// broadcastLocked wakes all waiters. l.mu must be held.
+func (l *execLimiter) broadcastLocked() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// stats returns a snapshot of the limiter's state
+func (l *execLimiter) stats() ConcurrencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ConcurrencyStats{Limit: l.limit, InFlight: l.inflight, Waiting: l.waiting, Rejected: l.rejected}
+}
+
+// SetMaxConcurrentSkillExecs bounds the number of skill executions running at
+// once through this ToolFS (ExecuteSkill, pipelines and skill mounts) across
+// all skills. 0 removes the limit. Executions over the limit are queued or
+// rejected according to SetSkillConcurrencyPolicy.
+func (fs *ToolFS) SetMaxConcurrentSkillExecs(n int) {
+	if n < 0 {
+		n = 0
+	}
+	fs.skillExecLimiter.setLimit(n)
+}
+
+// SetSkillConcurrencyPolicy sets how this ToolFS handles executions over the
+// global limit or a per-skill limit of its SkillExecutorManager. Queued
+// executions fail with ErrConcurrencyLimit after queueTimeout (0 waits forever).
+func (fs *ToolFS) SetSkillConcurrencyPolicy(policy ConcurrencyPolicy, queueTimeout time.Duration) {
+	fs.skillExecPolicy = policy
+	fs.skillExecQueueTimeout = queueTimeout
+}
+
+// SkillExecStats returns the global skill execution counts of this ToolFS.
+// Per-skill counts are available from SkillExecutorManager.ConcurrencyStats.
+func (fs *ToolFS) SkillExecStats() ConcurrencyStats {
+	return fs.skillExecLimiter.stats()
+}
+
+// acquireSkillExec takes a global slot and, when the skill is managed by the
+// executor manager, a per-skill slot. The returned function releases both.
+func (fs *ToolFS) acquireSkillExec(name string) (func(), error) {
+	policy, timeout := fs.skillExecPolicy, fs.skillExecQueueTimeout
+	if err := fs.skillExecLimiter.acquire("all skills", policy, timeout); err != nil {
+		return nil, err
+	}
+	if fs.executorManager == nil {
+		return fs.skillExecLimiter.release, nil
+	}
+	perSkill := fs.executorManager.limiter(name)
+	if err := perSkill.acquire("skill '"+name+"'", policy, timeout); err != nil {
+		fs.skillExecLimiter.release()
+		return nil, err
+	}
+	return func() {
+		perSkill.release()
+		fs.skillExecLimiter.release()
+	}, nil
+}
+
+// SetSkillConcurrencyLimit bounds the number of simultaneous executions of
+// the named executor, both through the manager and through ToolFS. 0 removes
+// the limit.
+func (pm *SkillExecutorManager) SetSkillConcurrencyLimit(name string, n int) error {
+	if _, exists := pm.lookup(name); !exists {
+		return fmt.Errorf("executor '%s' not found", name)
+	}
+	if n < 0 {
+		return errors.New("concurrency limit cannot be negative")
+	}
+	pm.limiter(name).setLimit(n)
+	return nil
+}
+
+// SetConcurrencyPolicy sets how ExecuteSkill handles executions over a
+// per-skill limit. Queued executions fail with ErrConcurrencyLimit after
+// queueTimeout (0 waits forever).
+func (pm *SkillExecutorManager) SetConcurrencyPolicy(policy ConcurrencyPolicy, queueTimeout time.Duration) {
+	pm.policy = policy
+	pm.queueTimeout = queueTimeout
+}
+
+// ConcurrencyStats returns the execution counts of each executor that has run
+// or has a limit
+func (pm *SkillExecutorManager) ConcurrencyStats() map[string]ConcurrencyStats {
+	pm.limitersMu.Lock()
+	defer pm.limitersMu.Unlock()
+	stats := make(map[string]ConcurrencyStats, len(pm.limiters))
+	for name, l := range pm.limiters {
+		stats[name] = l.stats()
+	}
+	return stats
+}
+
+// limiter returns the per-skill limiter for name, creating an unlimited one
+func (pm *SkillExecutorManager) limiter(name string) *execLimiter {
+	pm.limitersMu.Lock()
+	defer pm.limitersMu.Unlock()
+	if pm.limiters == nil {
+		pm.limiters = make(map[string]*execLimiter)
+	}
+	l, ok := pm.limiters[name]
+	if !ok {
+		l = newExecLimiter(0)
+		pm.limiters[name] = l
+	}
+	return l
+}
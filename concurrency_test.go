@@ -0,0 +1,179 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// GatedSkill blocks each execution until gate is closed (or for delay when
+// gate is nil) and records the highest number of simultaneous executions
+type GatedSkill struct {
+	name    string
+	gate    chan struct{}
+	delay   time.Duration
+	started chan struct{}
+	current int32
+	peak    int32
+}
+
+func (s *GatedSkill) Name() string                             { return s.name }
+func (s *GatedSkill) Version() string                          { return "1.0.0" }
+func (s *GatedSkill) Init(config map[string]interface{}) error { return nil }
+
+func (s *GatedSkill) Execute(input []byte) ([]byte, error) {
+	n := atomic.AddInt32(&s.current, 1)
+	defer atomic.AddInt32(&s.current, -1)
+	for {
+		peak := atomic.LoadInt32(&s.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&s.peak, peak, n) {
+			break
+		}
+	}
+	if s.started != nil {
+		s.started <- struct{}{}
+	}
+	if s.gate != nil {
+		<-s.gate
+	} else {
+		time.Sleep(s.delay)
+	}
+	return json.Marshal(SkillResponse{Success: true, Result: "ok"})
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSkillConcurrencyLimitReject(t *testing.T) {
+	pm := NewSkillExecutorManager()
+	skill := &GatedSkill{name: "gated", gate: make(chan struct{}), started: make(chan struct{}, 3)}
+	pm.InjectSkill(skill, nil, nil)
+	if err := pm.SetSkillConcurrencyLimit("gated", 2); err != nil {
+		t.Fatalf("SetSkillConcurrencyLimit failed: %v", err)
+	}
+	pm.SetConcurrencyPolicy(ConcurrencyReject, 0)
+	if err := pm.SetSkillConcurrencyLimit("missing", 1); err == nil {
+		t.Error("Expected error for unknown executor")
+	}
+
+	input, _ := json.Marshal(SkillRequest{Operation: "run"})
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pm.ExecuteSkill("gated", input); err != nil {
+				t.Errorf("ExecuteSkill failed: %v", err)
+			}
+		}()
+	}
+	<-skill.started
+	<-skill.started
+
+	// The third execution is rejected without running
+	if _, err := pm.ExecuteSkill("gated", input); !errors.Is(err, ErrConcurrencyLimit) {
+		t.Errorf("Expected ErrConcurrencyLimit, got %v", err)
+	}
+	stats := pm.ConcurrencyStats()["gated"]
+	if stats.Limit != 2 || stats.InFlight != 2 || stats.Rejected != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	close(skill.gate)
+	wg.Wait()
+	waitFor(t, func() bool { return pm.ConcurrencyStats()["gated"].InFlight == 0 })
+}
+
+func TestSkillConcurrencyLimitQueue(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	skill := &GatedSkill{name: "gated", gate: make(chan struct{}), started: make(chan struct{}, 3)}
+	if _, err := fs.RegisterCodeSkill(skill, "/toolfs/skills/gated"); err != nil {
+		t.Fatalf("RegisterCodeSkill failed: %v", err)
+	}
+	fs.SetMaxConcurrentSkillExecs(1)
+	fs.SetSkillConcurrencyPolicy(ConcurrencyQueue, 20*time.Millisecond)
+
+	input, _ := json.Marshal(SkillRequest{Operation: "run"})
+	first := make(chan error, 1)
+	go func() {
+		_, err := fs.ExecuteSkill("gated", input, nil)
+		first <- err
+	}()
+	<-skill.started
+
+	// A queued execution times out while the slot is held
+	if _, err := fs.ExecuteSkill("gated", input, nil); !errors.Is(err, ErrConcurrencyLimit) {
+		t.Errorf("Expected queue timeout, got %v", err)
+	}
+
+	// With a longer wait the queued execution runs once the slot frees
+	fs.SetSkillConcurrencyPolicy(ConcurrencyQueue, 5*time.Second)
+	second := make(chan error, 1)
+	go func() {
+		_, err := fs.ExecuteSkill("gated", input, nil)
+		second <- err
+	}()
+	waitFor(t, func() bool { return fs.SkillExecStats().Waiting == 1 })
+	if stats := fs.SkillExecStats(); stats.InFlight != 1 || stats.Rejected != 1 {
+		t.Errorf("Unexpected stats while queued: %+v", stats)
+	}
+
+	close(skill.gate)
+	if err := <-first; err != nil {
+		t.Errorf("First execution failed: %v", err)
+	}
+	if err := <-second; err != nil {
+		t.Errorf("Queued execution failed: %v", err)
+	}
+	if stats := fs.SkillExecStats(); stats.InFlight != 0 || stats.Waiting != 0 {
+		t.Errorf("Expected no executions in flight, got %+v", stats)
+	}
+}
+
+func TestSkillConcurrencyLimitBurst(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	skill := &GatedSkill{name: "burst", delay: 2 * time.Millisecond}
+	pm.InjectSkill(skill, NewSkillContext(fs, nil), nil)
+	if err := fs.MountSkillExecutor("/burst", "burst"); err != nil {
+		t.Fatalf("MountSkillExecutor failed: %v", err)
+	}
+	fs.SetMaxConcurrentSkillExecs(4)
+	pm.SetSkillConcurrencyLimit("burst", 3)
+
+	var wg sync.WaitGroup
+	var failures int32
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := fs.ReadFile("/toolfs/burst/item"); err != nil {
+				atomic.AddInt32(&failures, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failures != 0 {
+		t.Errorf("Expected all queued executions to succeed, %d failed", failures)
+	}
+	if peak := atomic.LoadInt32(&skill.peak); peak > 3 || peak == 0 {
+		t.Errorf("Expected at most 3 simultaneous executions, got %d", peak)
+	}
+	if stats := pm.ConcurrencyStats()["burst"]; stats.InFlight != 0 || stats.Limit != 3 {
+		t.Errorf("Unexpected per-skill stats: %+v", stats)
+	}
+}
@@ -0,0 +1,78 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Copy copies the file at src to dst. Both paths may be served by any
+// backend: local mounts, memory entries and writable skill mounts. Copying a
+// memory entry to another memory entry keeps its metadata; copying it
+// elsewhere writes its content. Copies to read-only mounts, including the
+// RAG store, fail with ErrReadOnly. An existing file at dst is replaced.
+func (fs *ToolFS) Copy(src, dst string) error {
+	return fs.CopyWithSession(src, dst, nil)
+}
+
+// CopyWithSession copies src to dst with session-based access control. The
+// session must be allowed to read src and write dst. Two audit entries are
+// recorded, "Copy" for src and "CopyTo" for dst, but the copy counts as one
+// operation towards the session's quota.
+func (fs *ToolFS) CopyWithSession(src, dst string, session *Session) error {
+	end := fs.startSpan("Copy", src, session, "dst", dst)
+	err := fs.runAudited(session, "Copy", src, func() (int64, int64, error) {
+		var written int64
+		err := fs.runAuditedTarget(session, "CopyTo", dst, func() (int64, int64, error) {
+			n, err := fs.copyFile(src, dst, session)
+			written = n
+			return 0, n, err
+		})
+		return written, 0, err
+	})
+	end(err)
+	return err
+}
+
+// copyFile implements CopyWithSession without tracing or auditing and
+// returns the number of bytes copied
+func (fs *ToolFS) copyFile(src, dst string, session *Session) (int64, error) {
+	_, srcMount, err := fs.resolvePath(src)
+	if err != nil {
+		return 0, err
+	}
+	_, dstMount, err := fs.resolvePath(dst)
+	if err != nil {
+		return 0, err
+	}
+	if dstMount.ReadOnly {
+		return 0, fmt.Errorf("%w: cannot copy '%s' to '%s'", ErrReadOnly, src, dst)
+	}
+	if mountKind(srcMount) == "rag" {
+		return 0, fmt.Errorf("%w: cannot copy '%s' to '%s'", ErrCrossDevice, src, dst)
+	}
+
+	data, err := fs.readFileWithSession(src, session)
+	if err != nil {
+		return 0, err
+	}
+	if mountKind(srcMount) == "memory" && mountKind(dstMount) == "memory" {
+		// Memory reads return plain content; copy the whole entry so the
+		// destination keeps the metadata
+		id, err := fs.memoryEntryID(src)
+		if err != nil {
+			return 0, err
+		}
+		entry, err := fs.memoryStore.Get(id)
+		if err != nil {
+			return 0, err
+		}
+		if data, err = json.Marshal(entry); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := fs.writeFileWithSession(dst, data, session); err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
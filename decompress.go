@@ -0,0 +1,132 @@
+package toolfs
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrDecompressedTooLarge is wrapped by ReadFileAuto errors when a file
+// decompresses to more than the configured maximum
+var ErrDecompressedTooLarge = errors.New("decompressed content exceeds limit")
+
+// ErrUnsupportedCompression is wrapped by ReadFileAuto errors for files with a
+// known compression extension but no registered decompressor (e.g. ".zst")
+var ErrUnsupportedCompression = errors.New("unsupported compression format")
+
+// DefaultMaxDecompressedSize is the default output limit of ReadFileAuto
+const DefaultMaxDecompressedSize = 64 << 20
+
+// Decompressor wraps a compressed stream in a reader of its plaintext
+type Decompressor func(r io.Reader) (io.Reader, error)
+
+// builtinDecompressors are available on every ToolFS
+var builtinDecompressors = map[string]Decompressor{
+	".gz": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	".bz2": func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	},
+}
+
+// knownCompressionExts are recognized even without a decompressor, so that
+// reading them fails instead of returning compressed bytes
+var knownCompressionExts = []string{".gz", ".bz2", ".zst", ".xz", ".lz4"}
+
+// RegisterDecompressor adds or replaces the decompressor used by ReadFileAuto
+// for files ending in ext (e.g. ".zst"). gzip (".gz") and bzip2 (".bz2") are
+// built in.
+func (fs *ToolFS) RegisterDecompressor(ext string, decompressor Decompressor) {
+	if fs.decompressors == nil {
+		fs.decompressors = make(map[string]Decompressor)
+	}
+	fs.decompressors[strings.ToLower(ext)] = decompressor
+}
+
+// SetMaxDecompressedSize limits how many bytes ReadFileAuto returns for a
+// compressed file, guarding against decompression bombs. Zero or less
+// restores DefaultMaxDecompressedSize.
+func (fs *ToolFS) SetMaxDecompressedSize(n int64) {
+	fs.maxDecompressedSize = n
+}
+
+// ReadFileAuto reads a file like ReadFileWithSession, decompressing files with
+// a known compression extension. Other files are returned unchanged, and
+// ReadFile always returns the raw bytes. Output is limited by
+// SetMaxDecompressedSize; larger content fails with ErrDecompressedTooLarge.
+func (fs *ToolFS) ReadFileAuto(path string, session *Session) ([]byte, error) {
+	end := fs.startSpan("ReadFileAuto", path, session)
+	var data []byte
+	err := fs.runAudited(session, "ReadFileAuto", path, func() (int64, int64, error) {
+		var err error
+		data, err = fs.readFileAuto(path, session)
+		return int64(len(data)), 0, err
+	})
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readFileAuto implements ReadFileAuto without tracing or auditing
+func (fs *ToolFS) readFileAuto(path string, session *Session) ([]byte, error) {
+	raw, err := fs.readFileWithSession(path, session)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := fs.compressionExt(path)
+	if ext == "" {
+		return raw, nil
+	}
+	decompressor, ok := fs.decompressors[ext]
+	if !ok {
+		decompressor, ok = builtinDecompressors[ext]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: no decompressor registered for %s", ErrUnsupportedCompression, ext)
+	}
+
+	limit := fs.maxDecompressedSize
+	if limit <= 0 {
+		limit = DefaultMaxDecompressedSize
+	}
+
+	reader, err := decompressor(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	// Read one byte past the limit to detect oversized output without
+	// decompressing the rest
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if closer, ok := reader.(io.Closer); ok {
+		closer.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: %s decompresses to more than %d bytes", ErrDecompressedTooLarge, path, limit)
+	}
+	return data, nil
+}
+
+// compressionExt returns the lower-case compression extension of path, or ""
+func (fs *ToolFS) compressionExt(path string) string {
+	lower := strings.ToLower(path)
+	for ext := range fs.decompressors {
+		if strings.HasSuffix(lower, ext) {
+			return ext
+		}
+	}
+	for _, ext := range knownCompressionExts {
+		if strings.HasSuffix(lower, ext) {
+			return ext
+		}
+	}
+	return ""
+}
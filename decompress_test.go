@@ -0,0 +1,94 @@
+package toolfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gzipBytes compresses data with gzip
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestReadFileAuto(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/logs", tmpDir, true)
+
+	original := []byte(strings.Repeat("2024-03-09 INFO request served\n", 100))
+	compressed := gzipBytes(t, original)
+	os.WriteFile(filepath.Join(tmpDir, "app.log.gz"), compressed, 0o644)
+
+	data, err := fs.ReadFileAuto("/toolfs/logs/app.log.gz", nil)
+	if err != nil {
+		t.Fatalf("ReadFileAuto failed: %v", err)
+	}
+	if !bytes.Equal(data, original) {
+		t.Errorf("Expected decompressed content, got %d bytes", len(data))
+	}
+
+	// ReadFile stays byte-exact and uncompressed files pass through
+	if raw, _ := fs.ReadFile("/toolfs/logs/app.log.gz"); !bytes.Equal(raw, compressed) {
+		t.Error("ReadFile should return the compressed bytes")
+	}
+	if plain, _ := fs.ReadFileAuto("/toolfs/logs/test.txt", nil); string(plain) != "Hello, ToolFS!" {
+		t.Errorf("Expected plain file unchanged, got %q", plain)
+	}
+
+	// Corrupt input and formats without a decompressor are errors
+	os.WriteFile(filepath.Join(tmpDir, "bad.gz"), []byte("not gzip"), 0o644)
+	if _, err := fs.ReadFileAuto("/toolfs/logs/bad.gz", nil); err == nil {
+		t.Error("Expected error for corrupt gzip")
+	}
+	os.WriteFile(filepath.Join(tmpDir, "app.log.zst"), []byte("zstd frame"), 0o644)
+	if _, err := fs.ReadFileAuto("/toolfs/logs/app.log.zst", nil); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Errorf("Expected ErrUnsupportedCompression, got %v", err)
+	}
+
+	// Registered decompressors handle further formats
+	fs.RegisterDecompressor(".zst", func(r io.Reader) (io.Reader, error) {
+		data, err := io.ReadAll(r)
+		return strings.NewReader(strings.ToUpper(string(data))), err
+	})
+	if data, err := fs.ReadFileAuto("/toolfs/logs/app.log.zst", nil); err != nil || string(data) != "ZSTD FRAME" {
+		t.Errorf("Expected registered decompressor output, got %q, %v", data, err)
+	}
+}
+
+func TestReadFileAutoBomb(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/logs", tmpDir, true)
+
+	// 16 MiB of zeros compresses to a few KiB
+	bomb := gzipBytes(t, make([]byte, 16<<20))
+	os.WriteFile(filepath.Join(tmpDir, "bomb.gz"), bomb, 0o644)
+
+	fs.SetMaxDecompressedSize(1 << 20)
+	if _, err := fs.ReadFileAuto("/toolfs/logs/bomb.gz", nil); !errors.Is(err, ErrDecompressedTooLarge) {
+		t.Errorf("Expected ErrDecompressedTooLarge, got %v", err)
+	}
+
+	// Content exactly at the limit is allowed
+	os.WriteFile(filepath.Join(tmpDir, "exact.gz"), gzipBytes(t, make([]byte, 1<<20)), 0o644)
+	if data, err := fs.ReadFileAuto("/toolfs/logs/exact.gz", nil); err != nil || len(data) != 1<<20 {
+		t.Errorf("Expected content at the limit to be returned, got %d bytes, %v", len(data), err)
+	}
+}
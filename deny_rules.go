@@ -0,0 +1,106 @@
+package toolfs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// DenyPathsEnvSuffix is appended to the prefix given to LoadDenyRulesFromEnv
+// to form the environment variable name, e.g. TOOLFS_DENY_PATHS.
+const DenyPathsEnvSuffix = "_DENY_PATHS"
+
+// SetDenyRules installs a global deny list consulted by every session-scoped
+// operation, with or without a session. Deny rules take precedence over a
+// session's allowed paths. A rule is either a path prefix ("/toolfs/data/secrets"
+// denies that path and everything below it) or a glob using path.Match syntax
+// per segment plus "**" for any number of segments ("/toolfs/*/.env",
+// "/toolfs/**/*.key"), which denies matching paths and everything below them.
+// Passing no rules removes the deny list.
+func (fs *ToolFS) SetDenyRules(rules []string) error {
+	var normalized []string
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		rule = normalizeVirtualPath(rule)
+		if _, err := path.Match(rule, ""); err != nil {
+			return fmt.Errorf("invalid deny rule '%s': %w", rule, err)
+		}
+		normalized = append(normalized, rule)
+	}
+	fs.denyRules = normalized
+	return nil
+}
+
+// DenyRules returns the installed global deny rules
+func (fs *ToolFS) DenyRules() []string {
+	rules := make([]string, len(fs.denyRules))
+	copy(rules, fs.denyRules)
+	return rules
+}
+
+// LoadDenyRulesFromEnv installs the deny rules listed in the environment
+// variable prefix + DenyPathsEnvSuffix (e.g. TOOLFS_DENY_PATHS for prefix
+// "TOOLFS"). Rules are separated by newlines or commas. An unset or empty
+// variable removes the deny list.
+func (fs *ToolFS) LoadDenyRulesFromEnv(prefix string) error {
+	name := strings.TrimSuffix(prefix, "_") + DenyPathsEnvSuffix
+	return fs.SetDenyRules(splitDenyRules(os.Getenv(name)))
+}
+
+// LoadDenyRulesFromFile installs the deny rules listed in a file, one per line
+// or comma-separated. Blank lines and lines starting with '#' are ignored.
+func (fs *ToolFS) LoadDenyRulesFromFile(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open deny rules file: %w", err)
+	}
+	defer file.Close()
+
+	var rules []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rules = append(rules, splitDenyRules(line)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read deny rules file: %w", err)
+	}
+	return fs.SetDenyRules(rules)
+}
+
+// splitDenyRules splits a newline- or comma-separated rule list
+func splitDenyRules(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+}
+
+// checkDenied returns a *PathDeniedError if path matches a global deny rule
+func (fs *ToolFS) checkDenied(p string) error {
+	if len(fs.denyRules) == 0 {
+		return nil
+	}
+	p = normalizeVirtualPath(p)
+	for _, rule := range fs.denyRules {
+		if denyRuleMatches(rule, p) {
+			return &PathDeniedError{Path: p, Rule: rule}
+		}
+	}
+	return nil
+}
+
+// denyRuleMatches reports whether rule matches p or one of its ancestors
+func denyRuleMatches(rule, p string) bool {
+	if !isGlobPattern(rule) {
+		return p == rule || strings.HasPrefix(p, strings.TrimSuffix(rule, "/")+"/")
+	}
+	return globMatchesPathOrAncestor(rule, p)
+}
@@ -0,0 +1,91 @@
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDenyRulesFromEnv(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	session, _ := fs.NewSession("allowed", []string{"/toolfs/data"})
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+
+	t.Setenv("TOOLFS_DENY_PATHS", "/toolfs/data/subdir,\n/toolfs/*/*.txt")
+	if err := fs.LoadDenyRulesFromEnv("TOOLFS"); err != nil {
+		t.Fatalf("LoadDenyRulesFromEnv failed: %v", err)
+	}
+	if rules := fs.DenyRules(); len(rules) != 2 {
+		t.Fatalf("Expected 2 deny rules, got %v", rules)
+	}
+
+	// Denied regardless of session, even though the session allows /toolfs/data
+	denied := []func() error{
+		func() error { _, err := fs.ReadFileWithSession("/toolfs/data/test.txt", session); return err },
+		func() error { _, err := fs.ReadFile("/toolfs/data/subdir/subfile.txt"); return err },
+		func() error { return fs.WriteFileWithSession("/toolfs/data/new.txt", []byte("x"), session) },
+		func() error { return fs.WriteFile("/toolfs/data/subdir/new", []byte("x")) },
+		func() error { _, err := fs.ListDirWithSession("/toolfs/data/subdir", session); return err },
+		func() error { return fs.Move("/toolfs/data/subdir", "/toolfs/data/elsewhere") },
+	}
+	for i, op := range denied {
+		if err := op(); !errors.Is(err, ErrAccessDenied) {
+			t.Errorf("Operation %d: expected ErrAccessDenied, got %v", i, err)
+		}
+	}
+	if len(logger.Entries) == 0 || !logger.Entries[len(logger.Entries)-1].AccessDenied {
+		t.Errorf("Expected denied operations to be audited, got %+v", logger.Entries)
+	}
+
+	// Paths outside the rules are unaffected
+	if err := fs.WriteFileWithSession("/toolfs/data/notes.md", []byte("ok"), session); err != nil {
+		t.Errorf("Expected write outside deny rules to succeed, got %v", err)
+	}
+
+	// Clearing the variable restores access
+	os.Unsetenv("TOOLFS_DENY_PATHS")
+	if err := fs.LoadDenyRulesFromEnv("TOOLFS_"); err != nil {
+		t.Fatalf("LoadDenyRulesFromEnv failed: %v", err)
+	}
+	if _, err := fs.ReadFileWithSession("/toolfs/data/test.txt", session); err != nil {
+		t.Errorf("Expected access after clearing deny rules, got %v", err)
+	}
+}
+
+func TestLoadDenyRulesFromFile(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+
+	rulesFile := filepath.Join(t.TempDir(), "deny.conf")
+	os.WriteFile(rulesFile, []byte("# secrets\n/toolfs/data/subdir\n\n/toolfs/memory/private*\n"), 0o644)
+	if err := fs.LoadDenyRulesFromFile(rulesFile); err != nil {
+		t.Fatalf("LoadDenyRulesFromFile failed: %v", err)
+	}
+
+	if _, err := fs.StatWithSession("/toolfs/data/subdir/subfile.txt", nil); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if err := fs.WriteFile("/toolfs/memory/private-notes", []byte("x")); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied for memory glob, got %v", err)
+	}
+	// Prefix rules match whole path components
+	if err := fs.WriteFile("/toolfs/data/subdirectory.txt", []byte("x")); err != nil {
+		t.Errorf("Expected sibling path to be allowed, got %v", err)
+	}
+
+	if err := fs.LoadDenyRulesFromFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if err := fs.SetDenyRules([]string{"/toolfs/[bad"}); err == nil {
+		t.Error("Expected error for invalid glob")
+	}
+}
@@ -95,7 +95,14 @@ func (p *FileProcessorSkill) Execute(input []byte) ([]byte, error) {
 			return nil, errors.New("path is required for read_and_process")
 		}
 
-		// Check allowed paths if configured
+		// Apply the session's access rules first
+		if p.context != nil {
+			if err := p.context.CheckAccess(request.Path, "read"); err != nil {
+				return nil, err
+			}
+		}
+
+		// Config may narrow access further
 		if allowedPaths, ok := p.config["allowed_paths"].([]interface{}); ok {
 			allowed := false
 			for _, ap := range allowedPaths {
@@ -384,6 +391,108 @@ func TestSkillContext(t *testing.T) {
 	}
 }
 
+func TestSkillContextCheckAccess(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	fs.MountLocal("/data", tmpDir, false)
+
+	session, _ := fs.NewSession("check-access", []string{"/toolfs/data/subdir"})
+	session.SetAuditLogger(&TestAuditLogger{})
+	ctx := NewSkillContext(fs, session)
+
+	if paths := ctx.AllowedPaths(); len(paths) != 1 || paths[0] != "/toolfs/data/subdir" {
+		t.Errorf("Unexpected allowed paths: %v", paths)
+	}
+
+	// CheckAccess agrees with the session for every path
+	for _, path := range []string{"/toolfs/data/subdir/subfile.txt", "/toolfs/data/test.txt", "/toolfs/memory/x"} {
+		_, readErr := fs.ReadFileWithSession(path, session)
+		sessionDenied := readErr != nil && strings.Contains(readErr.Error(), "access denied")
+		ctxDenied := ctx.CheckAccess(path, "read") != nil
+		if sessionDenied != ctxDenied {
+			t.Errorf("CheckAccess(%s) denied=%v, session denied=%v", path, ctxDenied, sessionDenied)
+		}
+	}
+
+	// A skill using CheckAccess is denied what the session denies
+	skill := &FileProcessorSkill{context: ctx}
+	skill.Init(nil)
+	input, _ := json.Marshal(SkillRequest{Operation: "read_and_process", Path: "/toolfs/data/test.txt"})
+	if _, err := skill.Execute(input); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Errorf("Expected skill to be denied by session rules, got: %v", err)
+	}
+
+	// Changing the session's paths is reflected immediately
+	session.AllowedPaths = []string{"/toolfs/data"}
+	if err := ctx.CheckAccess("/toolfs/data/test.txt", "read"); err != nil {
+		t.Errorf("Expected access after session paths changed, got: %v", err)
+	}
+	if _, err := skill.Execute(input); err != nil {
+		t.Errorf("Expected skill to follow updated session paths, got: %v", err)
+	}
+
+	// A context without a session is unrestricted
+	if err := NewSkillContext(fs, nil).CheckAccess("/anything", "write"); err != nil {
+		t.Errorf("Expected nil session to allow access, got: %v", err)
+	}
+}
+
+func TestSkillContextCheckAccessRules(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	fs.MountLocal("/data", tmpDir, false)
+
+	session, _ := fs.NewSession("check-rules", nil)
+	session.SetAuditLogger(&TestAuditLogger{})
+	session.SetReadPaths([]string{"/toolfs/data"})
+	session.SetWritePaths([]string{"/toolfs/data/subdir"})
+	session.SetDeniedPaths([]string{"/toolfs/data/subdir/secret"})
+	fs.SetDenyRules([]string{"/toolfs/data/blocked"})
+	ctx := NewSkillContext(fs, session)
+
+	if paths := ctx.ReadPaths(); len(paths) != 1 || paths[0] != "/toolfs/data" {
+		t.Errorf("Unexpected read paths: %v", paths)
+	}
+	if paths := ctx.WritePaths(); len(paths) != 1 || paths[0] != "/toolfs/data/subdir" {
+		t.Errorf("Unexpected write paths: %v", paths)
+	}
+
+	// CheckAccess denies exactly what the session-scoped operations deny
+	tests := []struct {
+		path, op string
+		denied   bool
+	}{
+		{"/toolfs/data/test.txt", "read", false},
+		{"/toolfs/data/test.txt", "write", true},
+		{"/toolfs/data/subdir/new.txt", "write", false},
+		{"/toolfs/data/subdir/secret", "read", true},
+		{"/toolfs/data/blocked", "list", true},
+	}
+	for _, tt := range tests {
+		var opErr error
+		if tt.op == "write" {
+			opErr = fs.WriteFileWithSession(tt.path, []byte("x"), session)
+		} else {
+			_, opErr = fs.StatWithSession(tt.path, session)
+		}
+		sessionDenied := errors.Is(opErr, ErrAccessDenied)
+		err := ctx.CheckAccess(tt.path, tt.op)
+		if (err != nil) != tt.denied || sessionDenied != tt.denied {
+			t.Errorf("%s %s: CheckAccess=%v, session=%v, want denied=%v", tt.op, tt.path, err, opErr, tt.denied)
+		}
+	}
+
+	// Deny rules also apply without a session
+	if err := NewSkillContext(fs, nil).CheckAccess("/toolfs/data/blocked", "read"); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected deny rule to apply without a session, got %v", err)
+	}
+	if err := ctx.CheckAccess("/toolfs/data/test.txt", "execute"); err == nil {
+		t.Error("Expected unknown operation to be rejected")
+	}
+}
+
 func TestFileProcessorSkill(t *testing.T) {
 	fs := NewToolFS("/toolfs")
 	tmpDir, cleanup := setupTestDir(t)
@@ -578,7 +687,7 @@ func TestSkillExecutorRegistryInitSkill(t *testing.T) {
 }
 
 func TestSkillContextWithNilToolFS(t *testing.T) {
-	ctx := &SkillContext{fs: nil, session: nil}
+	ctx := &SkillContext{}
 
 	// Test ReadFile with nil ToolFS
 	_, err := ctx.ReadFile("/test/path")
@@ -946,13 +1055,16 @@ func TestSkillExecutorManagerLoadSkill(t *testing.T) {
 		t.Error("Expected error for duplicate skill loading")
 	}
 
-	// Test loading native skill (not yet implemented)
+	// Test loading a missing native skill
 	err = pm.LoadSkill("test.so", ctx, nil)
 	if err == nil {
-		t.Error("Expected error for native skill (not implemented)")
+		t.Error("Expected error for missing native skill")
 	}
-	if !strings.Contains(err.Error(), "not yet implemented") {
-		t.Errorf("Expected 'not yet implemented' error, got: %v", err)
+
+	// Test loading an unsupported file type
+	err = pm.LoadSkill("test.py", ctx, nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported executor file") {
+		t.Errorf("Expected 'unsupported executor file' error, got: %v", err)
 	}
 }
 
@@ -5,6 +5,8 @@ package toolfs
 
 import (
 	"context"
+	"errors"
+	"os"
 	"strings"
 	"syscall"
 
@@ -65,6 +67,21 @@ func (r *ToolFSRoot) OnAdd(ctx context.Context) {
 		}
 	}
 
+	// Add remote mounts
+	for mountPoint := range r.toolfs.remoteMounts {
+		relPath := r.toolfs.normalizeMountPoint(mountPoint)
+		if relPath != "" {
+			remoteNode := &ToolFSDir{
+				toolfs: r.toolfs,
+				path:   mountPoint,
+			}
+			remoteInode := r.NewPersistentInode(ctx, remoteNode, fs.StableAttr{
+				Mode: syscall.S_IFDIR | 0o755,
+			})
+			r.AddChild(relPath, remoteInode, false)
+		}
+	}
+
 	// Add skill mounts
 	for mountPoint := range r.toolfs.skillMounts {
 		relPath := r.toolfs.normalizeMountPoint(mountPoint)
@@ -118,11 +135,14 @@ type ToolFSDir struct {
 var (
 	_ fs.NodeReaddirer = (*ToolFSDir)(nil)
 	_ fs.NodeLookuper  = (*ToolFSDir)(nil)
+	_ fs.NodeCreater   = (*ToolFSDir)(nil)
+	_ fs.NodeMkdirer   = (*ToolFSDir)(nil)
+	_ fs.NodeRenamer   = (*ToolFSDir)(nil)
 )
 
 // Readdir implements NodeReaddirer interface
 func (d *ToolFSDir) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
-	entries, err := d.toolfs.ListDir(d.path)
+	entries, err := d.toolfs.ListDirContext(ctx, d.path, nil)
 	if err != nil {
 		return nil, syscall.EIO
 	}
@@ -155,7 +175,7 @@ func (d *ToolFSDir) Lookup(ctx context.Context, name string, out *fuse.EntryOut)
 	childPath := d.path + "/" + name
 
 	// Check if it's a file or directory
-	info, err := d.toolfs.Stat(childPath)
+	info, err := d.toolfs.StatContext(ctx, childPath, nil)
 	if err != nil {
 		return nil, syscall.ENOENT
 	}
@@ -181,6 +201,124 @@ func (d *ToolFSDir) Lookup(ctx context.Context, name string, out *fuse.EntryOut)
 	}
 }
 
+// Create implements NodeCreater interface. Editors that save atomically
+// create a temporary file next to the original and rename it over the original.
+func (d *ToolFSDir) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+	childPath := d.path + "/" + name
+	if err := d.toolfs.WriteFile(childPath, []byte{}); err != nil {
+		return nil, nil, 0, toolfsErrno(err)
+	}
+
+	childNode := &ToolFSFile{
+		toolfs: d.toolfs,
+		path:   childPath,
+	}
+	childInode := d.NewPersistentInode(ctx, childNode, fs.StableAttr{
+		Mode: syscall.S_IFREG | 0o644,
+	})
+	out.Mode = syscall.S_IFREG | 0o644
+
+	return childInode, &ToolFSFileHandle{
+		toolfs: d.toolfs,
+		path:   childPath,
+	}, 0, 0
+}
+
+// Mkdir implements NodeMkdirer interface by creating the directory, and any
+// missing parents, in the backing local mount
+func (d *ToolFSDir) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	childPath := d.path + "/" + name
+	if err := d.toolfs.Mkdir(childPath); err != nil {
+		return nil, toolfsErrno(err)
+	}
+
+	childNode := &ToolFSDir{
+		toolfs: d.toolfs,
+		path:   childPath,
+	}
+	out.Mode = syscall.S_IFDIR | 0o755
+	return d.NewPersistentInode(ctx, childNode, fs.StableAttr{
+		Mode: syscall.S_IFDIR | 0o755,
+	}), 0
+}
+
+// Rename implements NodeRenamer interface by moving the entry with ToolFS.Move.
+// Renames into read-only mounts fail with EROFS and renames between backends
+// (or through RAG, remote and skill mounts) with EXDEV, so tools like mv fall back to
+// copying rather than ToolFS.Move copying non-atomically. Rename flags (RENAME_NOREPLACE, RENAME_EXCHANGE) are not supported.
+func (d *ToolFSDir) Rename(ctx context.Context, name string, newParent fs.InodeEmbedder, newName string, flags uint32) syscall.Errno {
+	if flags != 0 {
+		return syscall.EINVAL
+	}
+
+	var parentPath string
+	switch p := newParent.EmbeddedInode().Operations().(type) {
+	case *ToolFSDir:
+		parentPath = p.path
+	case *ToolFSRoot:
+		parentPath = p.toolfs.rootPath
+	default:
+		return syscall.EXDEV
+	}
+
+	src := d.path + "/" + name
+	dst := parentPath + "/" + newName
+	if !d.toolfs.sameRenameBackend(src, dst) {
+		return syscall.EXDEV
+	}
+	if err := d.toolfs.Move(src, dst); err != nil {
+		return toolfsErrno(err)
+	}
+
+	// Nodes keep their ToolFS path, so the moved subtree must be retargeted
+	if child := d.GetChild(name); child != nil {
+		retargetNode(child, dst)
+	}
+	return 0
+}
+
+// sameRenameBackend reports whether Move would rename src to dst in place
+// rather than copying between backends. Unresolvable paths are left for Move
+// to report.
+func (fs *ToolFS) sameRenameBackend(src, dst string) bool {
+	_, srcMount, srcErr := fs.resolvePath(src)
+	_, dstMount, dstErr := fs.resolvePath(dst)
+	if srcErr != nil || dstErr != nil {
+		return true
+	}
+	kind := mountKind(srcMount)
+	return kind == mountKind(dstMount) && kind != "skill" && kind != "remote"
+}
+
+// retargetNode updates the ToolFS paths of a node and its known children
+func retargetNode(inode *fs.Inode, path string) {
+	switch n := inode.Operations().(type) {
+	case *ToolFSDir:
+		n.path = path
+		for name, child := range inode.Children() {
+			retargetNode(child, path+"/"+name)
+		}
+	case *ToolFSFile:
+		n.path = path
+	}
+}
+
+// toolfsErrno maps ToolFS errors to errno values
+func toolfsErrno(err error) syscall.Errno {
+	switch {
+	case errors.Is(err, ErrReadOnly):
+		return syscall.EROFS
+	case errors.Is(err, ErrCrossDevice):
+		return syscall.EXDEV
+	case errors.Is(err, ErrAccessDenied):
+		return syscall.EACCES
+	case errors.Is(err, os.ErrNotExist):
+		return syscall.ENOENT
+	default:
+		return syscall.EIO
+	}
+}
+
 // ToolFSFile represents a file in the ToolFS FUSE filesystem
 type ToolFSFile struct {
 	fs.Inode
@@ -192,19 +330,36 @@ type ToolFSFile struct {
 var (
 	_ fs.NodeOpener    = (*ToolFSFile)(nil)
 	_ fs.NodeGetattrer = (*ToolFSFile)(nil)
+	_ fs.NodeSetattrer = (*ToolFSFile)(nil)
 )
 
-// Open implements NodeOpener interface
+// Open implements NodeOpener interface. Opening for writing fails with EROFS
+// on read-only mounts; O_TRUNC empties the file and O_APPEND makes every
+// write go to the end of the file.
 func (f *ToolFSFile) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
-	return &ToolFSFileHandle{
+	handle := &ToolFSFileHandle{
 		toolfs: f.toolfs,
 		path:   f.path,
-	}, fuse.FOPEN_KEEP_CACHE, 0
+	}
+	if flags&syscall.O_ACCMODE == syscall.O_RDONLY {
+		return handle, fuse.FOPEN_KEEP_CACHE, 0
+	}
+
+	if !f.toolfs.isWritablePath(f.path) {
+		return nil, 0, syscall.EROFS
+	}
+	if flags&syscall.O_TRUNC != 0 {
+		if err := f.toolfs.WriteFile(f.path, []byte{}); err != nil {
+			return nil, 0, toolfsErrno(err)
+		}
+	}
+	handle.append = flags&syscall.O_APPEND != 0
+	return handle, 0, 0
 }
 
 // Getattr implements NodeGetattrer interface
 func (f *ToolFSFile) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
-	info, err := f.toolfs.Stat(f.path)
+	info, err := f.toolfs.StatContext(ctx, f.path, nil)
 	if err != nil {
 		return syscall.ENOENT
 	}
@@ -216,10 +371,43 @@ func (f *ToolFSFile) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.At
 	return 0
 }
 
+// Setattr implements NodeSetattrer interface. Only size changes (truncate)
+// are applied; other attributes are ignored.
+func (f *ToolFSFile) Setattr(ctx context.Context, fh fs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) syscall.Errno {
+	if size, ok := in.GetSize(); ok {
+		data, err := f.toolfs.ReadFile(f.path)
+		if err != nil {
+			return toolfsErrno(err)
+		}
+		if uint64(len(data)) != size {
+			resized := make([]byte, size)
+			copy(resized, data)
+			if err := f.toolfs.WriteFile(f.path, resized); err != nil {
+				return toolfsErrno(err)
+			}
+		}
+	}
+	return f.Getattr(ctx, fh, out)
+}
+
+// isWritablePath reports whether path is served by a backend that accepts
+// writes. Unresolvable paths are left for the write itself to report.
+func (fs *ToolFS) isWritablePath(path string) bool {
+	_, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return true
+	}
+	if skillMount, _ := fs.isSkillMount(path); skillMount != nil && skillMount.ReadOnly {
+		return false
+	}
+	return !mount.ReadOnly && mountKind(mount) != "rag"
+}
+
 // ToolFSFileHandle is a file handle for ToolFS files
 type ToolFSFileHandle struct {
 	toolfs *ToolFS
 	path   string
+	append bool // Opened with O_APPEND
 }
 
 // Ensure ToolFSFileHandle implements the required interfaces
@@ -230,7 +418,7 @@ var (
 
 // Read implements FileReader interface
 func (fh *ToolFSFileHandle) Read(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
-	data, err := fh.toolfs.ReadFile(fh.path)
+	data, err := fh.toolfs.ReadFileContext(ctx, fh.path, nil)
 	if err != nil {
 		return nil, syscall.EIO
 	}
@@ -263,6 +451,9 @@ func (fh *ToolFSFileHandle) Write(ctx context.Context, data []byte, off int64) (
 	if err != nil {
 		existing = []byte{}
 	}
+	if fh.append {
+		off = int64(len(existing))
+	}
 
 	// Resize if needed
 	newSize := int(off) + len(data)
@@ -277,7 +468,7 @@ func (fh *ToolFSFileHandle) Write(ctx context.Context, data []byte, off int64) (
 
 	// Write back
 	if err := fh.toolfs.WriteFile(fh.path, existing); err != nil {
-		return 0, syscall.EIO
+		return 0, toolfsErrno(err)
 	}
 
 	return uint32(len(data)), 0
@@ -4,9 +4,15 @@
 package toolfs
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
+	"syscall"
 	"testing"
+
+	gofusefs "github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
 )
 
 // TestFUSEAdapterCompilation tests that the FUSE adapter compiles correctly
@@ -142,3 +148,156 @@ func TestFUSEMemoryAccess(t *testing.T) {
 }
 
 
+
+// TestFUSERenameOverOriginal mounts ToolFS and saves a file the way editors do:
+// write a temporary file, then rename it over the original
+func TestFUSERenameOverOriginal(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("FUSE integration test runs on Linux only")
+	}
+
+	dataDir := t.TempDir()
+	mountDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "doc.txt"), []byte("original"), 0o644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tfs := NewToolFS("/toolfs")
+	if err := tfs.MountLocal("/data", dataDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+	server, err := gofusefs.Mount(mountDir, NewToolFSRoot(tfs), &gofusefs.Options{
+		MountOptions: fuse.MountOptions{DirectMountStrict: true},
+	})
+	if err != nil {
+		t.Skipf("FUSE mount unavailable: %v", err)
+	}
+	defer server.Unmount()
+
+	target := filepath.Join(mountDir, "data", "doc.txt")
+	tmp := target + ".swp"
+	if err := os.WriteFile(tmp, []byte("edited"), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		t.Fatalf("Rename over original failed: %v", err)
+	}
+
+	if content, err := os.ReadFile(target); err != nil || string(content) != "edited" {
+		t.Errorf("Expected edited content through mount, got %q, %v", content, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dataDir, "doc.txt")); string(content) != "edited" {
+		t.Errorf("Expected edited content on disk, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "doc.txt.swp")); !os.IsNotExist(err) {
+		t.Errorf("Expected temp file to be gone, got %v", err)
+	}
+
+	// Renames into another backend are rejected with EXDEV
+	err = os.Rename(target, filepath.Join(mountDir, "memory", "doc"))
+	if !errors.Is(err, syscall.EXDEV) {
+		t.Errorf("Expected EXDEV for rename into memory, got %v", err)
+	}
+}
+
+// TestFUSEWrite mounts ToolFS and writes through the mount with create,
+// truncate and append semantics, reading the results back through ToolFS
+func TestFUSEWrite(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("FUSE integration test runs on Linux only")
+	}
+
+	dataDir := t.TempDir()
+	roDir := t.TempDir()
+	mountDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(roDir, "fixed.txt"), []byte("fixed"), 0o644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tfs := NewToolFS("/toolfs")
+	if err := tfs.MountLocal("/data", dataDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+	if err := tfs.MountLocal("/ro", roDir, true); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+	server, err := gofusefs.Mount(mountDir, NewToolFSRoot(tfs), &gofusefs.Options{
+		MountOptions: fuse.MountOptions{DirectMountStrict: true},
+	})
+	if err != nil {
+		t.Skipf("FUSE mount unavailable: %v", err)
+	}
+	defer server.Unmount()
+
+	readBack := func(path string) string {
+		t.Helper()
+		content, err := tfs.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) failed: %v", path, err)
+		}
+		return string(content)
+	}
+
+	// Create
+	target := filepath.Join(mountDir, "data", "file.txt")
+	if err := os.WriteFile(target, []byte("data\n"), 0o644); err != nil {
+		t.Fatalf("Create through mount failed: %v", err)
+	}
+	if got := readBack("/toolfs/data/file.txt"); got != "data\n" {
+		t.Errorf("Expected created content, got %q", got)
+	}
+
+	// Truncate on open
+	if err := os.WriteFile(target, []byte("new"), 0o644); err != nil {
+		t.Fatalf("Overwrite through mount failed: %v", err)
+	}
+	if got := readBack("/toolfs/data/file.txt"); got != "new" {
+		t.Errorf("Expected truncated content, got %q", got)
+	}
+
+	// Append
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Open for append failed: %v", err)
+	}
+	if _, err := f.Write([]byte(" more")); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	f.Close()
+	if got := readBack("/toolfs/data/file.txt"); got != "new more" {
+		t.Errorf("Expected appended content, got %q", got)
+	}
+
+	// Truncate via setattr
+	if err := os.Truncate(target, 3); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	if got := readBack("/toolfs/data/file.txt"); got != "new" {
+		t.Errorf("Expected content truncated to 3 bytes, got %q", got)
+	}
+
+	// Directories
+	if err := os.MkdirAll(filepath.Join(mountDir, "data", "a", "b"), 0o755); err != nil {
+		t.Fatalf("Mkdir through mount failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mountDir, "data", "a", "b", "nested.txt"), []byte("nested"), 0o644); err != nil {
+		t.Fatalf("Write into new directory failed: %v", err)
+	}
+	if got := readBack("/toolfs/data/a/b/nested.txt"); got != "nested" {
+		t.Errorf("Expected nested content, got %q", got)
+	}
+
+	// Read-only mounts reject writes with EROFS
+	if err := os.WriteFile(filepath.Join(mountDir, "ro", "new.txt"), []byte("x"), 0o644); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("Expected EROFS creating in read-only mount, got %v", err)
+	}
+	if _, err := os.OpenFile(filepath.Join(mountDir, "ro", "fixed.txt"), os.O_WRONLY, 0); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("Expected EROFS opening read-only file for writing, got %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(mountDir, "ro", "dir"), 0o755); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("Expected EROFS for mkdir in read-only mount, got %v", err)
+	}
+	if got := readBack("/toolfs/ro/fixed.txt"); got != "fixed" {
+		t.Errorf("Expected read-only file unchanged, got %q", got)
+	}
+}
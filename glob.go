@@ -0,0 +1,160 @@
+package toolfs
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Glob returns the virtual paths of files and directories on local mounts
+// matching pattern, sorted. The pattern is an absolute virtual path whose
+// segments are matched with path.Match, except "**", which matches any number
+// of segments: "/toolfs/src/**/*.go" finds Go files at any depth below
+// /toolfs/src. Paths hidden by deny rules or not readable by the session are
+// left out, and directories the session cannot read are not descended into
+// unless it may read something below them. Memory, RAG, skill and remote
+// mounts are not searched. No match yields an empty slice.
+func (fs *ToolFS) Glob(pattern string, session *Session) ([]string, error) {
+	end := fs.startSpan("Glob", pattern, session)
+	start := time.Now()
+
+	// Access is checked per path while walking, so only the session itself
+	// is authorized up front
+	var matches []string
+	var err error
+	if session != nil {
+		if err = session.checkExpired(); err == nil {
+			session.touch()
+			err = session.checkQuota("Glob")
+		}
+	}
+	if err == nil {
+		matches, err = fs.glob(pattern, session)
+	}
+	if session != nil {
+		fs.auditOp(session, "Glob", pattern, err, 0, 0, start, nil, nil)
+	}
+	end(err)
+	return matches, err
+}
+
+// glob implements Glob without tracing or auditing
+func (fs *ToolFS) glob(pattern string, session *Session) ([]string, error) {
+	pattern = strings.TrimSuffix(normalizeVirtualPath(pattern), "/")
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("glob pattern '%s' must be an absolute virtual path", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+	}
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+
+	// Walking starts below the segments without metacharacters
+	base := ""
+	for _, segment := range segments {
+		if isGlobPattern(segment) {
+			break
+		}
+		base += "/" + segment
+	}
+	if base == "" {
+		base = "/"
+	}
+
+	w := &globWalk{fs: fs, pattern: pattern, segments: segments, session: session, mounts: fs.mounts, seen: make(map[string]bool)}
+	if mountPoint, mount := fs.localMountFor(base); mount != nil {
+		localPath := filepath.Join(mount.LocalPath, filepath.FromSlash(strings.TrimPrefix(base, mountPoint)))
+		if err := w.walk(base, localPath, mount); err != nil {
+			return nil, err
+		}
+	}
+	for mountPoint, mount := range fs.mounts {
+		if mountPoint != base && isSubPath(mountPoint, base) {
+			if err := w.walk(mountPoint, mount.LocalPath, mount); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	matches := make([]string, 0, len(w.seen))
+	for match := range w.seen {
+		matches = append(matches, match)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// globWalk collects the paths matching a glob pattern from local mounts
+type globWalk struct {
+	fs       *ToolFS
+	pattern  string
+	segments []string // Pattern segments
+	session  *Session
+	mounts   map[string]*Mount // Mount point -> local mount
+	seen     map[string]bool   // Matching virtual paths
+}
+
+// walk walks the local directory backing virtual path root of mount
+func (w *globWalk) walk(root, localRoot string, mount *Mount) error {
+	return filepath.WalkDir(localRoot, func(localPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Missing roots and unreadable entries are skipped
+			if entry != nil && entry.IsDir() && localPath != localRoot {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		virtualPath := root
+		if localPath != localRoot {
+			rel, err := filepath.Rel(localRoot, localPath)
+			if err != nil {
+				return nil
+			}
+			virtualPath = strings.TrimSuffix(root, "/") + "/" + filepath.ToSlash(rel)
+			if mount.lazy != nil && filepath.Dir(localPath) == mount.LocalPath && entry.Name() == lazyManifestName {
+				return nil
+			}
+			if _, nested := w.mounts[virtualPath]; nested && entry.IsDir() {
+				return filepath.SkipDir // Walked as a mount of its own
+			}
+		}
+
+		readable, descend := w.visible(virtualPath)
+		if readable && matchPathPattern(w.pattern, virtualPath) {
+			w.seen[virtualPath] = true
+		}
+		if entry.IsDir() && (!descend || !matchPatternPrefix(w.segments, strings.Split(strings.Trim(virtualPath, "/"), "/"))) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// visible reports whether the session may see path and whether anything it
+// may see can lie below it
+func (w *globWalk) visible(virtualPath string) (readable, descend bool) {
+	if w.fs.checkDenied(virtualPath) != nil {
+		return false, false
+	}
+	if w.session == nil {
+		return true, true
+	}
+	if w.session.deniedRule(virtualPath) != "" {
+		return false, false
+	}
+	if w.session.checkAccess(virtualPath, false) == nil {
+		return true, true
+	}
+	for _, allowed := range w.session.readPaths() {
+		allowed = normalizeVirtualPath(allowed)
+		if isGlobPattern(allowed) || strings.HasPrefix(allowed, strings.TrimSuffix(virtualPath, "/")+"/") {
+			return false, true
+		}
+	}
+	return false, false
+}
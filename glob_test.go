@@ -0,0 +1,85 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGlob(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	for _, name := range []string{"main.go", "pkg/util.go", "pkg/util_test.go", "pkg/deep/inner.go", "secret/key.go", "docs/readme.md"} {
+		localPath := filepath.Join(tmpDir, "src", filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(localPath), 0o755)
+		os.WriteFile(localPath, []byte("package x"), 0o644)
+	}
+	vendor := t.TempDir()
+	os.WriteFile(filepath.Join(vendor, "lib.go"), []byte("package lib"), 0o644)
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	fs.MountLocal("/data/src/vendor", vendor, true)
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/toolfs/data/src/**/*.go", []string{
+			"/toolfs/data/src/main.go", "/toolfs/data/src/pkg/deep/inner.go", "/toolfs/data/src/pkg/util.go",
+			"/toolfs/data/src/pkg/util_test.go", "/toolfs/data/src/secret/key.go", "/toolfs/data/src/vendor/lib.go",
+		}},
+		{"/toolfs/data/src/*.go", []string{"/toolfs/data/src/main.go"}},
+		{"/toolfs/data/src/pkg/*_test.go", []string{"/toolfs/data/src/pkg/util_test.go"}},
+		{"/toolfs/data/*", []string{"/toolfs/data/src", "/toolfs/data/subdir", "/toolfs/data/test.txt"}},
+		{"/toolfs/*/test.txt", []string{"/toolfs/data/test.txt"}},
+		{"/toolfs/data/src/**/deep", []string{"/toolfs/data/src/pkg/deep"}},
+		{"/toolfs/data/test.txt", []string{"/toolfs/data/test.txt"}},
+		{"/toolfs/data/**/*.rs", []string{}},
+		{"/toolfs/missing/**", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := fs.Glob(tt.pattern, nil)
+		if err != nil {
+			t.Errorf("Glob(%q) failed: %v", tt.pattern, err)
+			continue
+		}
+		if got == nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("Glob(%q) = %#v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+
+	// Deny rules and sessions hide paths
+	fs.SetDenyRules([]string{"/toolfs/data/src/secret"})
+	session, _ := fs.NewSession("pkg-only", []string{"/toolfs/data/src/pkg"})
+	session.DeniedPaths = []string{"/toolfs/data/src/pkg/deep"}
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+	got, err := fs.Glob("/toolfs/**/*.go", session)
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if want := []string{"/toolfs/data/src/pkg/util.go", "/toolfs/data/src/pkg/util_test.go"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if len(logger.Entries) != 1 || logger.Entries[0].Operation != "Glob" || !logger.Entries[0].Success {
+		t.Errorf("Expected one audit entry, got %+v", logger.Entries)
+	}
+
+	if _, err := fs.Glob("data/*.go", nil); err == nil {
+		t.Error("Expected an error for a relative pattern")
+	}
+	if _, err := fs.Glob("/toolfs/data/[", nil); err == nil {
+		t.Error("Expected an error for a malformed pattern")
+	}
+
+	session.SetTTL(time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, err := fs.Glob("/toolfs/**", session); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("Expected ErrSessionExpired, got %v", err)
+	}
+}
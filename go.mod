@@ -2,6 +2,10 @@ module github.com/IceWhaleTech/toolfs
 
 go 1.21
 
-require github.com/hanwen/go-fuse/v2 v2.9.0
+require (
+	github.com/fsnotify/fsnotify v1.8.0
+	github.com/hanwen/go-fuse/v2 v2.9.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require golang.org/x/sys v0.28.0 // indirect
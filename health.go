@@ -0,0 +1,164 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// HealthFileName is the virtual file under the ToolFS root that serves the
+// JSON health report (e.g. /toolfs/.health)
+const HealthFileName = ".health"
+
+// HealthChecker is an optional interface for skills, stores and audit
+// loggers that can report whether they are working. A nil error means healthy.
+type HealthChecker interface {
+	HealthCheck() error
+}
+
+// ComponentHealth is the status of one mount, store or skill
+type ComponentHealth struct {
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthReport summarizes the health of a ToolFS instance. Healthy is true
+// only when every component is healthy.
+type HealthReport struct {
+	Healthy   bool              `json:"healthy"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Mounts    []ComponentHealth `json:"mounts"`
+	Stores    []ComponentHealth `json:"stores"`
+	Skills    []ComponentHealth `json:"skills"`
+}
+
+// Health checks every mount, store and registered skill. Local mounts are
+// checked with a stat of their local directory, stores and skills through
+// HealthChecker when they implement it, and skill mounts report the health of
+// their skill.
+func (fs *ToolFS) Health() *HealthReport {
+	report := &HealthReport{
+		Healthy:   true,
+		CheckedAt: time.Now(),
+		Mounts:    []ComponentHealth{},
+		Stores:    []ComponentHealth{},
+		Skills:    []ComponentHealth{},
+	}
+
+	// Skills first, so skill mounts can reuse their results
+	skillErrs := make(map[string]error)
+	if registry := fs.GetSkillExecutorRegistry(); registry != nil {
+		names := registry.List()
+		sort.Strings(names)
+		for _, name := range names {
+			skill, err := registry.Get(name)
+			if err == nil {
+				err = checkHealth(skill)
+			}
+			skillErrs[name] = err
+			report.Skills = append(report.Skills, componentHealth(name, MountTypeSkill, err))
+		}
+	}
+
+	for _, info := range fs.ListMounts() {
+		var err error
+		switch info.Type {
+		case MountTypeLocal, MountTypeLazy:
+			err = checkLocalDir(info.LocalPath)
+		case MountTypeSkill:
+			var checked bool
+			if err, checked = skillErrs[info.SkillName]; !checked {
+				err = fmt.Errorf("skill '%s' is not registered", info.SkillName)
+			}
+		}
+		report.Mounts = append(report.Mounts, componentHealth(info.MountPoint, info.Type, err))
+	}
+
+	report.Stores = append(report.Stores,
+		componentHealth("memory", MountTypeMemory, checkHealth(fs.memoryStore)),
+		componentHealth("rag", MountTypeRAG, checkHealth(fs.ragStore)),
+	)
+	if fs.auditLogger != nil {
+		report.Stores = append(report.Stores, componentHealth("audit", "audit", checkHealth(fs.auditLogger)))
+	}
+	if fs.skillStateRoot != "" {
+		report.Stores = append(report.Stores, componentHealth("skill_state", "skill_state", checkWritableDir(fs.skillStateRoot)))
+	}
+
+	for _, group := range [][]ComponentHealth{report.Mounts, report.Stores, report.Skills} {
+		for _, component := range group {
+			if !component.Healthy {
+				report.Healthy = false
+			}
+		}
+	}
+	return report
+}
+
+// healthPath returns the virtual path of the health report file
+func (fs *ToolFS) healthPath() string {
+	return normalizeVirtualPath(fs.rootPath + "/" + HealthFileName)
+}
+
+// readHealth serves the health report file
+func (fs *ToolFS) readHealth() ([]byte, error) {
+	return json.MarshalIndent(fs.Health(), "", "  ")
+}
+
+// HealthCheck reports whether the audit directory is still writable
+func (l *DailyRotatingAuditLogger) HealthCheck() error {
+	return checkWritableDir(l.dir)
+}
+
+// HealthCheck reports whether the directory of the audit file is writable
+func (l *FileAuditLogger) HealthCheck() error {
+	return checkWritableDir(filepath.Dir(l.path))
+}
+
+func componentHealth(name, componentType string, err error) ComponentHealth {
+	health := ComponentHealth{Name: name, Type: componentType, Healthy: err == nil}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// checkHealth calls HealthCheck on component if it implements HealthChecker.
+// Other components are assumed healthy.
+func checkHealth(component interface{}) error {
+	if checker, ok := component.(HealthChecker); ok {
+		return checker.HealthCheck()
+	}
+	return nil
+}
+
+// checkLocalDir verifies that a mount's local directory still exists
+func checkLocalDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.New("local path is not a directory")
+	}
+	return nil
+}
+
+// checkWritableDir verifies that files can be created in dir
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".health-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
@@ -0,0 +1,119 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+// FlakySkill is a skill whose health check returns err
+type FlakySkill struct {
+	MockSkill
+	err error
+}
+
+func (s *FlakySkill) HealthCheck() error { return s.err }
+
+func TestHealthHealthy(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	fs.SetSkillStateRoot(t.TempDir())
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	pm.InjectSkill(&FlakySkill{MockSkill: MockSkill{name: "flaky", version: "1.0.0"}}, NewSkillContext(fs, nil), nil)
+	fs.MountSkillExecutor("/flaky", "flaky")
+
+	report := fs.Health()
+	if !report.Healthy {
+		t.Fatalf("Expected healthy report, got %+v", report)
+	}
+	if len(report.Mounts) != 5 {
+		t.Errorf("Expected memory, rag, skill document, local and skill mounts, got %+v", report.Mounts)
+	}
+	hasSkill := false
+	for _, skill := range report.Skills {
+		hasSkill = hasSkill || skill.Name == "flaky"
+	}
+	if !hasSkill {
+		t.Errorf("Expected flaky skill in report, got %+v", report.Skills)
+	}
+
+	// The report is also served as a virtual file
+	data, err := fs.ReadFile("/toolfs/.health")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var decoded HealthReport
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Healthy {
+		t.Errorf("Expected healthy JSON report, got %s (%v)", data, err)
+	}
+}
+
+func TestHealthUnhealthy(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	broken := t.TempDir()
+	fs.MountLocal("/data", tmpDir, false)
+	fs.MountLocal("/broken", broken, false)
+	os.RemoveAll(broken)
+
+	report := fs.Health()
+	if report.Healthy {
+		t.Fatal("Expected unhealthy report after removing a mount's directory")
+	}
+	for _, mount := range report.Mounts {
+		if wantHealthy := mount.Name != "/toolfs/broken"; mount.Healthy != wantHealthy {
+			t.Errorf("Mount %s: expected healthy=%v, got %+v", mount.Name, wantHealthy, mount)
+		}
+	}
+
+	// A failing skill health check marks the skill and its mount unhealthy
+	fs = NewToolFS("/toolfs")
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	pm.InjectSkill(&FlakySkill{MockSkill: MockSkill{name: "flaky", version: "1.0.0"}, err: errors.New("backend down")}, NewSkillContext(fs, nil), nil)
+	fs.MountSkillExecutor("/flaky", "flaky")
+
+	report = fs.Health()
+	if report.Healthy {
+		t.Error("Expected unhealthy report for a failing skill")
+	}
+	for _, skill := range report.Skills {
+		if skill.Name == "flaky" && skill.Error != "backend down" {
+			t.Errorf("Expected skill error, got %+v", skill)
+		}
+	}
+	for _, mount := range report.Mounts {
+		if mount.Type == MountTypeSkill && mount.Healthy {
+			t.Errorf("Expected skill mount to be unhealthy, got %+v", mount)
+		}
+	}
+}
+
+func TestSkillExecutorManagerCheckHealth(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	pm := NewSkillExecutorManager()
+	ctx := NewSkillContext(fs, nil)
+	pm.InjectSkill(&FlakySkill{MockSkill: MockSkill{name: "ok", version: "1.0.0"}}, ctx, nil)
+	pm.InjectSkill(&FlakySkill{MockSkill: MockSkill{name: "down", version: "1.0.0"}, err: errors.New("db unreachable")}, ctx, nil)
+	pm.InjectSkill(&MockSkill{name: "plain", version: "1.0.0"}, ctx, nil)
+
+	if err := pm.CheckHealth("ok"); err != nil {
+		t.Errorf("Expected healthy skill, got %v", err)
+	}
+	if err := pm.CheckHealth("plain"); err != nil {
+		t.Errorf("Expected skill without HealthCheck to be healthy, got %v", err)
+	}
+	if err := pm.CheckHealth("down"); err == nil || err.Error() != "db unreachable" {
+		t.Errorf("Expected health check error, got %v", err)
+	}
+	if err := pm.CheckHealth("missing"); !errors.Is(err, ErrSkillNotFound) {
+		t.Errorf("Expected ErrSkillNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package toolfs
+
+import "fmt"
+
+// OperationHook observes and adjusts filesystem operations. Hooks are invoked
+// by ReadFile, WriteFile, ListDir, Stat and DeleteFile and their session and
+// context variants, after access control and before the operation runs.
+type OperationHook interface {
+	// Before is called before op runs on path and returns the path the
+	// operation should use instead, or path itself (or "") to keep it. A
+	// redirected path is checked against the deny rules and the session's
+	// allowed paths again, and later hooks see it; the audit entry records
+	// it with the requested path as "requested_path" metadata. Returning an
+	// error aborts the operation; the caller gets the error wrapped.
+	Before(op, path string, session *Session) (string, error)
+	// After is called once op has run with its result ([]byte for ReadFile,
+	// *WriteResult for WriteFile, []string for ListDir, *FileInfo for Stat
+	// and nil for DeleteFile) and error, and returns the result and error to
+	// use instead; returning them unchanged keeps them. A replacement result
+	// must have the operation's result type, and WriteFile and Stat need a
+	// result unless an error is returned.
+	After(op, path string, result interface{}, err error) (interface{}, error)
+}
+
+// AddHook registers hook for every subsequent operation. Hooks run in
+// registration order and each sees the path and result left by the previous
+// ones; when a Before hook aborts an operation, only the hooks whose Before
+// already returned are given After.
+func (fs *ToolFS) AddHook(hook OperationHook) {
+	if hook == nil {
+		return
+	}
+	fs.hooksMu.Lock()
+	defer fs.hooksMu.Unlock()
+	// Copy on write so running operations keep the hooks they started with
+	hooks := make([]OperationHook, len(fs.hooks), len(fs.hooks)+1)
+	copy(hooks, fs.hooks)
+	fs.hooks = append(hooks, hook)
+}
+
+// runHooked runs fn, which performs op on the path it is given, between the
+// Before and After calls of the registered hooks and returns the result and
+// error left by the hooks along with the path the hooks redirected op to
+func (fs *ToolFS) runHooked(op, path string, session *Session, fn func(path string) (interface{}, error)) (interface{}, string, error) {
+	fs.hooksMu.RLock()
+	hooks := fs.hooks
+	fs.hooksMu.RUnlock()
+	if len(hooks) == 0 {
+		result, err := fn(path)
+		return result, path, err
+	}
+
+	var result interface{}
+	var err error
+	ran := 0
+	for _, hook := range hooks {
+		rewritten, beforeErr := hook.Before(op, path, session)
+		if beforeErr != nil {
+			err = fmt.Errorf("%s '%s' aborted by hook: %w", op, path, beforeErr)
+			break
+		}
+		ran++
+		if rewritten != "" && rewritten != path {
+			if err = fs.authorizeRedirect(session, op, rewritten); err != nil {
+				err = fmt.Errorf("%s '%s' redirected by hook: %w", op, path, err)
+				break
+			}
+			path = rewritten
+		}
+	}
+
+	if err == nil {
+		result, err = fn(path)
+	}
+	if err != nil {
+		result = nil
+	}
+	for _, hook := range hooks[:ran] {
+		result, err = hook.After(op, path, result, err)
+	}
+	return result, path, err
+}
+
+// authorizeRedirect checks a path a hook redirected op to. Expiry and quota
+// were already checked for the original path.
+func (fs *ToolFS) authorizeRedirect(session *Session, op, path string) error {
+	if err := fs.checkDenied(path); err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+	return session.checkAccess(path, isWriteOp(op))
+}
+
+// hookResultError reports a hook replacing op's result with a value of the
+// wrong type
+func hookResultError(op string, result interface{}) error {
+	return fmt.Errorf("hook returned %T as the %s result", result, op)
+}
@@ -0,0 +1,259 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// recordingHook records the hook calls it receives and can abort operations
+type recordingHook struct {
+	name  string
+	mu    sync.Mutex
+	calls *[]string
+	abort func(op, path string) error
+	last  interface{}
+}
+
+func (h *recordingHook) Before(op, path string, session *Session) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.calls = append(*h.calls, fmt.Sprintf("%s.Before %s", h.name, op))
+	if h.abort != nil {
+		return path, h.abort(op, path)
+	}
+	return path, nil
+}
+
+func (h *recordingHook) After(op, path string, result interface{}, err error) (interface{}, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.calls = append(*h.calls, fmt.Sprintf("%s.After %s %v", h.name, op, err != nil))
+	h.last = result
+	return result, err
+}
+
+// funcHook adjusts operations with optional functions
+type funcHook struct {
+	before func(op, path string) (string, error)
+	after  func(op, path string, result interface{}, err error) (interface{}, error)
+}
+
+func (h *funcHook) Before(op, path string, session *Session) (string, error) {
+	if h.before == nil {
+		return path, nil
+	}
+	return h.before(op, path)
+}
+
+func (h *funcHook) After(op, path string, result interface{}, err error) (interface{}, error) {
+	if h.after == nil {
+		return result, err
+	}
+	return h.after(op, path, result, err)
+}
+
+func TestOperationHooks(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+
+	var calls []string
+	first := &recordingHook{name: "first", calls: &calls}
+	second := &recordingHook{name: "second", calls: &calls}
+	fs.AddHook(first)
+	fs.AddHook(second)
+
+	data, err := fs.ReadFile("/toolfs/data/test.txt")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	want := []string{"first.Before ReadFile", "second.Before ReadFile", "first.After ReadFile false", "second.After ReadFile false"}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+	if got, ok := second.last.([]byte); !ok || string(got) != string(data) {
+		t.Errorf("Expected After to get the content, got %#v", second.last)
+	}
+
+	// Every facade operation is hooked, failures included
+	calls = nil
+	fs.WriteFile("/toolfs/data/new.txt", []byte("new"))
+	if _, ok := first.last.(*WriteResult); !ok {
+		t.Errorf("Expected a *WriteResult, got %#v", first.last)
+	}
+	fs.ListDir("/toolfs/data")
+	fs.Stat("/toolfs/data/new.txt")
+	if _, ok := first.last.(*FileInfo); !ok {
+		t.Errorf("Expected a *FileInfo, got %#v", first.last)
+	}
+	fs.DeleteFile("/toolfs/data/new.txt")
+	fs.Stat("/toolfs/data/new.txt")
+	if first.last != nil {
+		t.Errorf("Expected no result for a failed operation, got %#v", first.last)
+	}
+	if len(calls) != 20 || calls[19] != "second.After Stat true" {
+		t.Errorf("Unexpected calls %v", calls)
+	}
+
+	// A Before hook aborts the operation; earlier hooks still get After
+	calls = nil
+	denied := errors.New("blocked")
+	first.abort = func(op, path string) error { return nil }
+	second.abort = func(op, path string) error {
+		if op == "WriteFile" {
+			return denied
+		}
+		return nil
+	}
+	third := &recordingHook{name: "third", calls: &calls}
+	fs.AddHook(third)
+
+	err = fs.WriteFile("/toolfs/data/blocked.txt", []byte("x"))
+	if !errors.Is(err, denied) {
+		t.Fatalf("Expected the hook error, got %v", err)
+	}
+	want = []string{"first.Before WriteFile", "second.Before WriteFile", "first.After WriteFile true"}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+	if _, err := fs.Stat("/toolfs/data/blocked.txt"); err == nil {
+		t.Error("Expected the aborted write not to create the file")
+	}
+
+	// Sessions are passed to hooks and aborted operations are audited
+	logger := &TestAuditLogger{}
+	session, _ := fs.NewSession("hooked", []string{"/toolfs/data"})
+	session.SetAuditLogger(logger)
+	if err := fs.WriteFileWithSession("/toolfs/data/blocked.txt", []byte("x"), session); !errors.Is(err, denied) {
+		t.Errorf("Expected the hook error, got %v", err)
+	}
+	if len(logger.Entries) != 1 || logger.Entries[0].Success {
+		t.Errorf("Expected a failed audit entry, got %+v", logger.Entries)
+	}
+
+	// Operations denied by access control never reach the hooks
+	calls = nil
+	fs.ReadFileWithSession("/toolfs/memory/x", session)
+	if len(calls) != 0 {
+		t.Errorf("Expected no hook calls for a denied operation, got %v", calls)
+	}
+}
+
+func TestOperationHookRedirect(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+
+	var seen []string
+	fs.AddHook(&funcHook{before: func(op, path string) (string, error) {
+		if path == "/toolfs/data/alias.txt" {
+			return "/toolfs/data/subdir/subfile.txt", nil
+		}
+		if path == "/toolfs/data/secret.txt" {
+			return "/toolfs/memory/secret", nil
+		}
+		return path, nil
+	}})
+	fs.AddHook(&funcHook{before: func(op, path string) (string, error) {
+		seen = append(seen, path)
+		return "", nil
+	}})
+
+	// The operation and later hooks use the redirected path
+	data, err := fs.ReadFile("/toolfs/data/alias.txt")
+	if err != nil || string(data) != "Subdirectory file" {
+		t.Errorf("Expected redirected content, got %q (%v)", data, err)
+	}
+	if len(seen) != 1 || seen[0] != "/toolfs/data/subdir/subfile.txt" {
+		t.Errorf("Expected later hooks to see the redirected path, got %v", seen)
+	}
+
+	// The audit entry names the path that was read and the one requested
+	session, _ := fs.NewSession("redirected", []string{"/toolfs/data"})
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+	if _, err := fs.ReadFileWithSession("/toolfs/data/alias.txt", session); err != nil {
+		t.Fatalf("ReadFileWithSession failed: %v", err)
+	}
+	entry := logger.Entries[len(logger.Entries)-1]
+	if entry.Path != "/toolfs/data/subdir/subfile.txt" || entry.Metadata["requested_path"] != "/toolfs/data/alias.txt" {
+		t.Errorf("Expected audit of the redirected path, got %+v", entry)
+	}
+
+	// Redirected paths are authorized again
+	if _, err := fs.ReadFileWithSession("/toolfs/data/secret.txt", session); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected redirect outside the session to be denied, got %v", err)
+	}
+	if err := fs.SetDenyRules([]string{"/toolfs/data/subdir"}); err != nil {
+		t.Fatalf("SetDenyRules failed: %v", err)
+	}
+	if _, err := fs.ReadFile("/toolfs/data/alias.txt"); err == nil {
+		t.Error("Expected redirect to a denied path to fail")
+	}
+}
+
+func TestOperationHookReplacesResult(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+
+	// A cache-style hook answers reads of missing files
+	cache := map[string][]byte{"/toolfs/data/cached.txt": []byte("from cache")}
+	var fallback *funcHook
+	fallback = &funcHook{after: func(op, path string, result interface{}, err error) (interface{}, error) {
+		if op == "ReadFile" && errors.Is(err, ErrNotFound) {
+			if data, ok := cache[path]; ok {
+				return data, nil
+			}
+		}
+		if op == "Stat" && path == "/toolfs/data/wrong-type" {
+			return "not a FileInfo", nil
+		}
+		if path == "/toolfs/data/no-result" {
+			return nil, nil
+		}
+		return result, err
+	}}
+	fs.AddHook(fallback)
+
+	data, err := fs.ReadFile("/toolfs/data/cached.txt")
+	if err != nil || string(data) != "from cache" {
+		t.Errorf("Expected cached content, got %q (%v)", data, err)
+	}
+	if _, err := fs.ReadFile("/toolfs/data/uncached.txt"); err == nil {
+		t.Error("Expected the original error for uncached paths")
+	}
+
+	// Hooks can also turn a result into an error
+	fs.AddHook(&funcHook{after: func(op, path string, result interface{}, err error) (interface{}, error) {
+		if op == "ListDir" {
+			return nil, errors.New("listing disabled")
+		}
+		return result, err
+	}})
+	if entries, err := fs.ListDir("/toolfs/data"); err == nil || entries != nil {
+		t.Errorf("Expected replaced error, got %v (%v)", entries, err)
+	}
+
+	// Replacements of the wrong type are rejected
+	if _, err := fs.Stat("/toolfs/data/wrong-type"); err == nil {
+		t.Error("Expected a result of the wrong type to fail")
+	}
+	if info, err := fs.Stat("/toolfs/data/no-result"); err == nil {
+		t.Errorf("Expected a nil Stat result without an error to fail, got %+v", info)
+	}
+	if result, err := fs.WriteFileResult("/toolfs/data/no-result", []byte("x"), nil); err == nil {
+		t.Errorf("Expected a nil WriteFile result without an error to fail, got %+v", result)
+	}
+}
@@ -0,0 +1,178 @@
+package toolfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultHTTPMountTimeout limits each request made for an HTTP mount
+const DefaultHTTPMountTimeout = 30 * time.Second
+
+// MountTypeHTTP is the MountInfo.Type of HTTP mounts
+const MountTypeHTTP = "http"
+
+// HTTPStatusError reports a non-200 response from an HTTP mount. A 404
+// response matches os.ErrNotExist and 401 or 403 match ErrAccessDenied.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+// Error implements the error interface
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.URL, e.Status)
+}
+
+// Is matches os.ErrNotExist and ErrAccessDenied by status code
+func (e *HTTPStatusError) Is(target error) bool {
+	switch target {
+	case os.ErrNotExist:
+		return e.StatusCode == http.StatusNotFound
+	case ErrAccessDenied:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// httpSource serves a read-only mount from an HTTP server
+type httpSource struct {
+	client  *http.Client
+	baseURL string // Without a trailing slash
+	headers map[string]string
+}
+
+// MountHTTP mounts the HTTP server at baseURL as a read-only directory:
+// reading mountPoint/path issues a GET for baseURL/path with the given
+// headers and returns the response body. Responses other than 200 OK are
+// returned as *HTTPStatusError. Listing a directory requests its URL with a
+// trailing slash and expects a JSON index: an array of names, or an object
+// with an "entries" array, where subdirectory names end with "/".
+func (fs *ToolFS) MountHTTP(mountPoint, baseURL string, headers map[string]string) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid base URL '%s': must be an absolute http or https URL", baseURL)
+	}
+
+	source := &httpSource{
+		client:  &http.Client{Timeout: DefaultHTTPMountTimeout},
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		headers: make(map[string]string, len(headers)),
+	}
+	for name, value := range headers {
+		source.headers[name] = value
+	}
+	return fs.addRemoteMount(mountPoint, source, true)
+}
+
+func (s *httpSource) mountType() string {
+	return MountTypeHTTP
+}
+
+// url returns the URL for key, escaping each path segment
+func (s *httpSource) url(key string) string {
+	if key == "" {
+		return s.baseURL
+	}
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.baseURL + "/" + strings.Join(segments, "/")
+}
+
+// do sends a request with the mount's headers and fails on non-200 responses
+func (s *httpSource) do(ctx context.Context, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &HTTPStatusError{URL: target, StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return resp, nil
+}
+
+func (s *httpSource) get(ctx context.Context, key string) ([]byte, error) {
+	if key == "" {
+		return nil, fmt.Errorf("%w: %s", ErrIsDirectory, s.baseURL)
+	}
+	resp, err := s.do(ctx, http.MethodGet, s.url(key))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (s *httpSource) put(ctx context.Context, key string, data []byte) error {
+	return fmt.Errorf("%w: HTTP mounts are read-only", ErrReadOnly)
+}
+
+func (s *httpSource) list(ctx context.Context, key string) ([]string, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.url(key)+"/")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal(body, &names); err != nil {
+		var index struct {
+			Entries []string `json:"entries"`
+		}
+		if err := json.Unmarshal(body, &index); err != nil || index.Entries == nil {
+			return nil, fmt.Errorf("no JSON directory index at %s/", s.url(key))
+		}
+		names = index.Entries
+	}
+	return names, nil
+}
+
+// stat issues a HEAD request for key; keys without a file are directories if
+// the server provides an index for them
+func (s *httpSource) stat(ctx context.Context, key string) (*FileInfo, error) {
+	if key == "" {
+		return &FileInfo{ModTime: time.Now(), IsDir: true}, nil
+	}
+
+	resp, headErr := s.do(ctx, http.MethodHead, s.url(key))
+	if headErr != nil {
+		if _, err := s.list(ctx, key); err == nil {
+			return &FileInfo{ModTime: time.Now(), IsDir: true}, nil
+		}
+		return nil, headErr
+	}
+	resp.Body.Close()
+
+	info := &FileInfo{Size: resp.ContentLength, ModTime: time.Now()}
+	if info.Size < 0 {
+		info.Size = 0
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modified
+	}
+	return info, nil
+}
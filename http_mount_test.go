@@ -0,0 +1,174 @@
+package toolfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newHTTPMountServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	files := map[string]string{
+		"/readme.txt":       "hello from http",
+		"/docs/guide.md":    "# Guide",
+		"/docs/with space":  "spaced",
+		"/private/data.txt": "secret",
+	}
+	indexes := map[string]interface{}{
+		"/":      []string{"readme.txt", "docs/"},
+		"/docs/": map[string]interface{}{"entries": []string{"guide.md", "with space"}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api")
+		if strings.HasPrefix(path, "/private/") && r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if index, ok := indexes[path]; ok {
+			json.NewEncoder(w).Encode(index)
+			return
+		}
+		if content, ok := files[path]; ok {
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			w.Write([]byte(content))
+			return
+		}
+		if path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMountHTTP(t *testing.T) {
+	server := newHTTPMountServer(t)
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountHTTP("/web", server.URL+"/api/", map[string]string{"Authorization": "Bearer token"}); err != nil {
+		t.Fatalf("MountHTTP failed: %v", err)
+	}
+
+	data, err := fs.ReadFile("/toolfs/web/readme.txt")
+	if err != nil || string(data) != "hello from http" {
+		t.Errorf("Expected file content, got %q, %v", data, err)
+	}
+	if data, err := fs.ReadFile("/toolfs/web/docs/with space"); err != nil || string(data) != "spaced" {
+		t.Errorf("Expected escaped path to be fetched, got %q, %v", data, err)
+	}
+	if data, err := fs.ReadFile("/toolfs/web/private/data.txt"); err != nil || string(data) != "secret" {
+		t.Errorf("Expected headers to be sent, got %q, %v", data, err)
+	}
+
+	// Non-200 responses are errors
+	_, err = fs.ReadFile("/toolfs/web/missing.txt")
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected 404 HTTPStatusError, got %v", err)
+	}
+	if _, err := fs.ReadFile("/toolfs/web/broken"); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected 500 HTTPStatusError, got %v", err)
+	}
+
+	// Directory listings come from the JSON index
+	entries, err := fs.ListDir("/toolfs/web")
+	if err != nil || strings.Join(entries, ",") != "readme.txt,docs/" {
+		t.Errorf("Unexpected root listing %v, %v", entries, err)
+	}
+	entries, err = fs.ListDir("/toolfs/web/docs")
+	if err != nil || strings.Join(entries, ",") != "guide.md,with space" {
+		t.Errorf("Unexpected docs listing %v, %v", entries, err)
+	}
+	if _, err := fs.ListDir("/toolfs/web/private"); err == nil {
+		t.Error("Expected listing without an index to fail")
+	}
+	recursive, err := fs.ListDirRecursive("/toolfs/web", 0)
+	if err != nil || len(recursive) != 4 {
+		t.Errorf("Expected 4 recursive entries, got %+v, %v", recursive, err)
+	}
+
+	info, err := fs.Stat("/toolfs/web/readme.txt")
+	if err != nil || info.IsDir || info.Size != int64(len("hello from http")) || info.ModTime.Year() != 2006 {
+		t.Errorf("Unexpected file stat %+v, %v", info, err)
+	}
+	if info.BackedBy == nil || info.BackedBy.Type != MountTypeHTTP || !info.BackedBy.ReadOnly {
+		t.Errorf("Expected read-only http mount info, got %+v", info.BackedBy)
+	}
+	if info, err := fs.Stat("/toolfs/web/docs"); err != nil || !info.IsDir {
+		t.Errorf("Expected directory stat, got %+v, %v", info, err)
+	}
+
+	// Writes are rejected
+	if err := fs.WriteFile("/toolfs/web/new.txt", []byte("x")); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly for write, got %v", err)
+	}
+	if err := fs.DeleteFile("/toolfs/web/readme.txt"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly for delete, got %v", err)
+	}
+
+	// Files can be copied out of the mount
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	fs.MountLocal("/data", tmpDir, false)
+	if err := fs.Copy("/toolfs/web/readme.txt", "/toolfs/data/readme.txt"); err != nil {
+		t.Errorf("Copy from HTTP mount failed: %v", err)
+	}
+
+	found := false
+	for _, mount := range fs.ListMounts() {
+		if mount.MountPoint == "/toolfs/web" && mount.Type == MountTypeHTTP {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected HTTP mount in ListMounts")
+	}
+}
+
+func TestMountHTTPInvalidURL(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	for _, baseURL := range []string{"", "ftp://example.com", "/relative", "http://"} {
+		if err := fs.MountHTTP("/web", baseURL, nil); err == nil {
+			t.Errorf("Expected MountHTTP(%q) to fail", baseURL)
+		}
+	}
+}
+
+func TestMountHTTPContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hang until the client gives up
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountHTTP("/slow", server.URL, nil); err != nil {
+		t.Fatalf("MountHTTP failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := fs.ReadFileContext(ctx, "/toolfs/slow/file.txt", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the request to be aborted promptly, took %v", elapsed)
+	}
+
+	if _, err := fs.ListDirContext(ctx, "/toolfs/slow", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded for ListDirContext, got %v", err)
+	}
+	if _, err := fs.StatContext(ctx, "/toolfs/slow/file.txt", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded for StatContext, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Fetcher retrieves files for a lazy mount (e.g. an HTTP server or archive).
+// Paths are relative to the mount point and use forward slashes.
+type Fetcher interface {
+	// Fetch returns the content of the file at relPath along with its version
+	// (an ETag, revision or any string that changes when the content changes).
+	Fetch(relPath string) ([]byte, string, error)
+
+	// Version returns the current version of the file at relPath without
+	// fetching its content.
+	Version(relPath string) (string, error)
+}
+
+// FetcherLister is an optional interface for fetchers that can list directories.
+// Without it, ListDir on a lazy mount returns the files materialized so far.
+type FetcherLister interface {
+	List(relPath string) ([]string, error)
+}
+
+// lazyManifestName is the file in the cache directory recording the version
+// of every materialized file
+const lazyManifestName = ".toolfs-lazy.json"
+
+// lazySource materializes files from a Fetcher into a local cache directory
+type lazySource struct {
+	mu       sync.Mutex
+	fetcher  Fetcher
+	cacheDir string
+	versions map[string]string // relPath -> version of the cached copy
+}
+
+// MountLazy mounts a lazily fetched source at mountPoint. Files are fetched on
+// first access and materialized into cacheDir, so later reads are served from
+// the local copy. A cached file is refetched when the fetcher reports a new
+// version. The cache directory persists across restarts. Lazy mounts are read-only.
+func (fs *ToolFS) MountLazy(mountPoint string, fetcher Fetcher, cacheDir string) error {
+	if fetcher == nil {
+		return errors.New("fetcher cannot be nil")
+	}
+	if cacheDir == "" {
+		return errors.New("cache directory cannot be empty")
+	}
+
+	cacheDir, err := filepath.Abs(cacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve cache directory: %w", err)
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	source := &lazySource{
+		fetcher:  fetcher,
+		cacheDir: cacheDir,
+		versions: make(map[string]string),
+	}
+	if err := source.loadManifest(); err != nil {
+		return err
+	}
+
+	if err := fs.MountLocal(mountPoint, cacheDir, true); err != nil {
+		return err
+	}
+	fs.mounts[fs.rootedMountPoint(mountPoint)].lazy = source
+
+	return nil
+}
+
+// relPath returns the fetcher-relative path for a local path in the cache
+func (s *lazySource) relPath(localPath string) (string, error) {
+	rel, err := filepath.Rel(s.cacheDir, localPath)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		return "", nil
+	}
+	if strings.HasPrefix(rel, "../") || rel == ".." {
+		return "", fmt.Errorf("path escapes lazy mount: %s", localPath)
+	}
+	return rel, nil
+}
+
+// materialize makes sure the cached copy of localPath is present and current
+func (s *lazySource) materialize(localPath string) error {
+	rel, err := s.relPath(localPath)
+	if err != nil {
+		return err
+	}
+	if rel == "" || rel == lazyManifestName {
+		return nil // Mount root and manifest are never fetched
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version, err := s.fetcher.Version(rel)
+	if err != nil {
+		return err
+	}
+
+	if cached, ok := s.versions[rel]; ok && cached == version {
+		if _, err := os.Stat(localPath); err == nil {
+			return nil
+		}
+	}
+
+	data, fetchedVersion, err := s.fetcher.Fetch(rel)
+	if err != nil {
+		return err
+	}
+	if fetchedVersion == "" {
+		fetchedVersion = version
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(localPath, data, 0o644); err != nil {
+		return err
+	}
+
+	s.versions[rel] = fetchedVersion
+	return s.saveManifest()
+}
+
+// list returns directory entries from the fetcher, if it supports listing
+func (s *lazySource) list(localPath string) ([]string, bool, error) {
+	if s == nil {
+		return nil, false, nil
+	}
+	lister, ok := s.fetcher.(FetcherLister)
+	if !ok {
+		return nil, false, nil
+	}
+	rel, err := s.relPath(localPath)
+	if err != nil {
+		return nil, true, err
+	}
+	entries, err := lister.List(rel)
+	return entries, true, err
+}
+
+func (s *lazySource) loadManifest() error {
+	data, err := os.ReadFile(filepath.Join(s.cacheDir, lazyManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, &s.versions); err != nil {
+		return fmt.Errorf("failed to parse lazy mount manifest: %w", err)
+	}
+	return nil
+}
+
+func (s *lazySource) saveManifest() error {
+	data, err := json.Marshal(s.versions)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.cacheDir, lazyManifestName), data, 0o644)
+}
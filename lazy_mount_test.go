@@ -0,0 +1,125 @@
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// countingFetcher serves files from memory and counts fetches
+type countingFetcher struct {
+	files    map[string]string
+	versions map[string]string
+	fetches  map[string]int
+}
+
+func newCountingFetcher() *countingFetcher {
+	return &countingFetcher{
+		files:    map[string]string{"docs/readme.txt": "remote readme"},
+		versions: map[string]string{"docs/readme.txt": "v1"},
+		fetches:  make(map[string]int),
+	}
+}
+
+func (f *countingFetcher) Fetch(relPath string) ([]byte, string, error) {
+	content, ok := f.files[relPath]
+	if !ok {
+		return nil, "", errors.New("not found")
+	}
+	f.fetches[relPath]++
+	return []byte(content), f.versions[relPath], nil
+}
+
+func (f *countingFetcher) Version(relPath string) (string, error) {
+	version, ok := f.versions[relPath]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return version, nil
+}
+
+func TestMountLazyMaterializesOnRead(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	cacheDir := t.TempDir()
+	fetcher := newCountingFetcher()
+
+	if err := fs.MountLazy("/remote", fetcher, cacheDir); err != nil {
+		t.Fatalf("MountLazy failed: %v", err)
+	}
+
+	data, err := fs.ReadFile("/toolfs/remote/docs/readme.txt")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(data) != "remote readme" {
+		t.Errorf("Expected fetched content, got '%s'", string(data))
+	}
+
+	// The file is materialized into the cache directory
+	cached, err := os.ReadFile(filepath.Join(cacheDir, "docs", "readme.txt"))
+	if err != nil || string(cached) != "remote readme" {
+		t.Fatalf("Expected materialized copy in cache dir, got '%s' (%v)", string(cached), err)
+	}
+
+	// Second read is served from the cache without refetching
+	if _, err := fs.ReadFile("/toolfs/remote/docs/readme.txt"); err != nil {
+		t.Fatalf("Second ReadFile failed: %v", err)
+	}
+	if fetcher.fetches["docs/readme.txt"] != 1 {
+		t.Errorf("Expected 1 fetch, got %d", fetcher.fetches["docs/readme.txt"])
+	}
+
+	// Changing the version triggers a refetch
+	fetcher.files["docs/readme.txt"] = "updated readme"
+	fetcher.versions["docs/readme.txt"] = "v2"
+	data, err = fs.ReadFile("/toolfs/remote/docs/readme.txt")
+	if err != nil {
+		t.Fatalf("ReadFile after version change failed: %v", err)
+	}
+	if string(data) != "updated readme" {
+		t.Errorf("Expected refetched content, got '%s'", string(data))
+	}
+	if fetcher.fetches["docs/readme.txt"] != 2 {
+		t.Errorf("Expected 2 fetches after version change, got %d", fetcher.fetches["docs/readme.txt"])
+	}
+
+	// Lazy mounts are read-only
+	if err := fs.WriteFile("/toolfs/remote/docs/readme.txt", []byte("x")); err == nil {
+		t.Error("Expected write to lazy mount to fail")
+	}
+}
+
+func TestMountLazyCachePersistsAcrossMounts(t *testing.T) {
+	cacheDir := t.TempDir()
+	fetcher := newCountingFetcher()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLazy("/remote", fetcher, cacheDir)
+	if _, err := fs.ReadFile("/toolfs/remote/docs/readme.txt"); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	// A new instance using the same cache dir does not refetch
+	restarted := NewToolFS("/toolfs")
+	if err := restarted.MountLazy("/remote", fetcher, cacheDir); err != nil {
+		t.Fatalf("MountLazy failed: %v", err)
+	}
+	if _, err := restarted.ReadFile("/toolfs/remote/docs/readme.txt"); err != nil {
+		t.Fatalf("ReadFile after restart failed: %v", err)
+	}
+	if fetcher.fetches["docs/readme.txt"] != 1 {
+		t.Errorf("Expected cached copy to survive restart, got %d fetches", fetcher.fetches["docs/readme.txt"])
+	}
+
+	// The manifest is hidden from listings
+	entries, err := restarted.ListDir("/toolfs/remote")
+	if err != nil {
+		t.Fatalf("ListDir failed: %v", err)
+	}
+	for _, e := range entries {
+		if e == lazyManifestName {
+			t.Error("Manifest should not be listed")
+		}
+	}
+}
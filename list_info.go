@@ -0,0 +1,178 @@
+package toolfs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ListDirInfo lists a directory like ListDirWithSession, returning the name,
+// size, modification time and type of every entry in one call. Memory entries
+// report their content length and last update. Skill mounts receive a
+// "list_dir_info" request; skills that do not answer it with a listing of
+// {"name", "size", "mod_time", "is_dir"} objects fall back to "list_dir",
+// whose entries only carry their names.
+func (fs *ToolFS) ListDirInfo(path string, session *Session) ([]FileInfo, error) {
+	end := fs.startSpan("ListDirInfo", path, session)
+	var result []FileInfo
+	err := fs.runAudited(session, "ListDirInfo", path, func() (int64, int64, error) {
+		var err error
+		result, err = fs.listDirInfo(context.Background(), path, session)
+		return 0, 0, err
+	})
+	end(err)
+	return result, err
+}
+
+// listDirInfo implements ListDirInfo without tracing or auditing
+func (fs *ToolFS) listDirInfo(ctx context.Context, path string, session *Session) ([]FileInfo, error) {
+	localPath, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	backedBy := fs.mountInfoForPath(path, mount)
+
+	var infos []FileInfo
+	switch mountKind(mount) {
+	case "skill":
+		infos, err = fs.listSkillInfo(ctx, path, localPath, session)
+	case "remote":
+		var entries []FileInfoEntry
+		if entries, err = walkRemote(ctx, mount.remote, localPath, 1); err == nil {
+			for _, entry := range entries {
+				infos = append(infos, FileInfo{Name: entry.RelPath, Size: entry.Size, ModTime: entry.ModTime, IsDir: entry.IsDir})
+			}
+		}
+	case "memory", "rag":
+		var names []string
+		if names, err = fs.listDirContext(ctx, path, session); err == nil {
+			dir := strings.TrimSuffix(normalizeVirtualPath(path), "/")
+			for _, name := range names {
+				entry := fs.virtualEntry(dir, name)
+				infos = append(infos, FileInfo{Name: name, Size: entry.Size, ModTime: entry.ModTime})
+			}
+		}
+	case "skills":
+		var names []string
+		if names, err = fs.listSkillDocs(path); err == nil {
+			dir := strings.TrimSuffix(normalizeVirtualPath(path), "/")
+			for _, name := range names {
+				entry := fs.skillDocEntry(dir, name)
+				infos = append(infos, FileInfo{Name: name, Size: entry.Size, ModTime: entry.ModTime, IsDir: entry.IsDir})
+			}
+		}
+	default:
+		infos, err = listLocalInfo(localPath, mount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range infos {
+		infos[i].BackedBy = backedBy
+	}
+	return infos, nil
+}
+
+// listLocalInfo lists a directory of a local mount. Lazy mounts list the
+// fetcher's entries when it can list them; files not yet materialized only
+// carry their names.
+func listLocalInfo(localPath string, mount *Mount) ([]FileInfo, error) {
+	if names, ok, err := mount.lazy.list(localPath); ok {
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]FileInfo, 0, len(names))
+		for _, name := range names {
+			info := FileInfo{Name: name}
+			if stat, err := os.Stat(filepath.Join(localPath, name)); err == nil {
+				info.Size, info.ModTime, info.IsDir = stat.Size(), stat.ModTime(), stat.IsDir()
+			}
+			infos = append(infos, info)
+		}
+		return infos, nil
+	}
+
+	dirEntries, err := os.ReadDir(localPath)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]FileInfo, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		if mount.lazy != nil && entry.Name() == lazyManifestName {
+			continue
+		}
+		stat, err := entry.Info()
+		if err != nil {
+			continue // Removed since the directory was read
+		}
+		infos = append(infos, FileInfo{Name: entry.Name(), Size: stat.Size(), ModTime: stat.ModTime(), IsDir: stat.IsDir()})
+	}
+	return infos, nil
+}
+
+// skillFileInfo is an entry of a skill's "list_dir_info" response
+type skillFileInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	IsDir   bool      `json:"is_dir"`
+}
+
+// listSkillInfo forwards a "list_dir_info" request to a skill mount, falling
+// back to the names returned for "list_dir" when the skill cannot answer it.
+// The response is a list of skillFileInfo, either as the result or in
+// result["entries"].
+func (fs *ToolFS) listSkillInfo(ctx context.Context, path, relPath string, session *Session) ([]FileInfo, error) {
+	skillMount, _ := fs.isSkillMount(path)
+	if skillMount == nil {
+		return nil, notFoundf("skill mount not found for path: %s", path)
+	}
+
+	if data, err := fs.executeSkillMount(ctx, skillMount, path, relPath, "list_dir_info", nil, session); err == nil {
+		if entries, ok := decodeSkillFileInfos(data); ok {
+			infos := make([]FileInfo, 0, len(entries))
+			for _, entry := range entries {
+				infos = append(infos, FileInfo{Name: entry.Name, Size: entry.Size, ModTime: entry.ModTime, IsDir: entry.IsDir})
+			}
+			return infos, nil
+		}
+	} else if ctx.Err() != nil {
+		return nil, err
+	}
+
+	names, err := fs.listDirContext(ctx, path, session)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]FileInfo, 0, len(names))
+	for _, name := range names {
+		infos = append(infos, FileInfo{Name: name})
+	}
+	return infos, nil
+}
+
+// decodeSkillFileInfos decodes a "list_dir_info" response. Responses without
+// named entries, such as a skill answering every operation the same way, are
+// rejected.
+func decodeSkillFileInfos(data []byte) ([]skillFileInfo, bool) {
+	var entries []skillFileInfo
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var wrapped struct {
+			Entries *[]skillFileInfo `json:"entries"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil || wrapped.Entries == nil {
+			return nil, false
+		}
+		entries = *wrapped.Entries
+	}
+	for _, entry := range entries {
+		if entry.Name == "" {
+			return nil, false
+		}
+	}
+	return entries, true
+}
@@ -0,0 +1,101 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// InfoSkill lists a fixed directory, answering "list_dir_info" only when
+// detailed is set
+type InfoSkill struct {
+	MockSkill
+	detailed bool
+}
+
+func (s *InfoSkill) Execute(input []byte) ([]byte, error) {
+	var request SkillRequest
+	json.Unmarshal(input, &request)
+	switch {
+	case request.Operation == "list_dir_info" && s.detailed:
+		return json.Marshal(SkillResponse{Success: true, Result: map[string]interface{}{
+			"entries": []map[string]interface{}{
+				{"name": "reports", "is_dir": true},
+				{"name": "q1.csv", "size": 42, "mod_time": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			},
+		}})
+	case request.Operation == "list_dir":
+		return json.Marshal(SkillResponse{Success: true, Result: map[string]interface{}{
+			"entries": []string{"reports", "q1.csv"},
+		}})
+	}
+	return json.Marshal(SkillResponse{Success: false, Error: "unsupported operation: " + request.Operation})
+}
+
+func TestListDirInfo(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+
+	infos, err := fs.ListDirInfo("/toolfs/data", nil)
+	if err != nil {
+		t.Fatalf("ListDirInfo failed: %v", err)
+	}
+	if len(infos) != 2 || infos[0].Name != "subdir" || !infos[0].IsDir || infos[1].Name != "test.txt" || infos[1].Size != 14 || infos[1].IsDir {
+		t.Fatalf("Unexpected local entries %+v", infos)
+	}
+	if infos[1].ModTime.IsZero() || infos[1].BackedBy == nil || infos[1].BackedBy.MountPoint != "/toolfs/data" {
+		t.Errorf("Expected modification time and mount info, got %+v", infos[1])
+	}
+
+	// Memory entries report their content length and last update
+	fs.WriteFile("/toolfs/memory/note", []byte("remember"))
+	entry, _ := fs.memoryStore.Get("note")
+	infos, err = fs.ListDirInfo("/toolfs/memory", nil)
+	if err != nil || len(infos) != 1 || infos[0].Name != "note" || infos[0].Size != 8 || !infos[0].ModTime.Equal(entry.UpdatedAt) {
+		t.Errorf("Unexpected memory entries %+v, %v", infos, err)
+	}
+
+	// Sessions are enforced
+	session, _ := fs.NewSession("limited", []string{"/toolfs/memory"})
+	if _, err := fs.ListDirInfo("/toolfs/data", session); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if _, err := fs.ListDirInfo("/toolfs/data/missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListDirInfoSkillMount(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	detailed := &InfoSkill{MockSkill: MockSkill{name: "detailed", version: "1.0.0"}, detailed: true}
+	basic := &InfoSkill{MockSkill: MockSkill{name: "basic", version: "1.0.0"}}
+	pm.InjectSkill(detailed, NewSkillContext(fs, nil), nil)
+	pm.InjectSkill(basic, NewSkillContext(fs, nil), nil)
+	fs.MountSkillExecutor("/detailed", "detailed")
+	fs.MountSkillExecutor("/basic", "basic")
+
+	infos, err := fs.ListDirInfo("/toolfs/detailed", nil)
+	if err != nil {
+		t.Fatalf("ListDirInfo failed: %v", err)
+	}
+	if len(infos) != 2 || !infos[0].IsDir || infos[1].Size != 42 || infos[1].ModTime.Year() != 2024 {
+		t.Errorf("Unexpected skill entries %+v", infos)
+	}
+
+	// Skills without "list_dir_info" fall back to names only
+	infos, err = fs.ListDirInfo("/toolfs/basic", nil)
+	if err != nil {
+		t.Fatalf("ListDirInfo failed: %v", err)
+	}
+	if len(infos) != 2 || infos[0].Name != "reports" || infos[1].Name != "q1.csv" || infos[1].Size != 0 {
+		t.Errorf("Expected names from list_dir, got %+v", infos)
+	}
+}
@@ -0,0 +1,56 @@
+package toolfs
+
+import (
+	"errors"
+	"sort"
+)
+
+// ListDirPaged lists a page of a directory's entries, sorted by name, along
+// with the total number of entries. A limit of 0 returns every entry from
+// offset on; an offset past the end returns an empty page.
+func (fs *ToolFS) ListDirPaged(path string, offset, limit int) ([]string, int, error) {
+	return fs.ListDirPagedWithSession(path, offset, limit, nil)
+}
+
+// ListDirPagedWithSession lists a page of a directory's entries like
+// ListDirPaged with session-based access control
+func (fs *ToolFS) ListDirPagedWithSession(path string, offset, limit int, session *Session) ([]string, int, error) {
+	if err := validatePage(offset, limit); err != nil {
+		return nil, 0, err
+	}
+
+	entries, err := fs.ListDirWithSession(path, session)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Memory IDs and skill listings come in no particular order
+	sort.Strings(entries)
+	start, end := pageBounds(len(entries), offset, limit)
+	return entries[start:end], len(entries), nil
+}
+
+// validatePage checks paging parameters
+func validatePage(offset, limit int) error {
+	if offset < 0 {
+		return errors.New("offset cannot be negative")
+	}
+	if limit < 0 {
+		return errors.New("limit cannot be negative")
+	}
+	return nil
+}
+
+// pageBounds returns the slice bounds of a page of total items. A limit of 0
+// extends the page to the end.
+func pageBounds(total, offset, limit int) (int, int) {
+	start := offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if limit > 0 && limit < end-start {
+		end = start + limit
+	}
+	return start, end
+}
@@ -0,0 +1,122 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestListDirPaged(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	for i := 0; i < 25; i++ {
+		fs.memoryStore.Set(fmt.Sprintf("entry-%02d", i), "content", nil)
+	}
+
+	var seen []string
+	for offset := 0; ; offset += 10 {
+		page, total, err := fs.ListDirPaged("/toolfs/memory", offset, 10)
+		if err != nil {
+			t.Fatalf("ListDirPaged failed: %v", err)
+		}
+		if total != 25 {
+			t.Fatalf("Expected total 25, got %d", total)
+		}
+		if len(page) == 0 {
+			break
+		}
+		seen = append(seen, page...)
+	}
+	if len(seen) != 25 {
+		t.Fatalf("Expected 25 entries across pages, got %d", len(seen))
+	}
+	for i, id := range seen {
+		if want := fmt.Sprintf("entry-%02d", i); id != want {
+			t.Errorf("Entry %d: expected %s, got %s", i, want, id)
+		}
+	}
+
+	// A limit of 0 returns the rest
+	page, _, err := fs.ListDirPaged("/toolfs/memory", 20, 0)
+	if err != nil || len(page) != 5 || page[0] != "entry-20" {
+		t.Errorf("Expected last 5 entries, got %v, %v", page, err)
+	}
+
+	if _, _, err := fs.ListDirPaged("/toolfs/memory", -1, 10); err == nil {
+		t.Error("Expected error for negative offset")
+	}
+	if _, _, err := fs.ListDirPaged("/toolfs/memory", 0, -1); err == nil {
+		t.Error("Expected error for negative limit")
+	}
+
+	// Session access control applies
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	fs.MountLocal("/data", tmpDir, true)
+	session, _ := fs.NewSession("paged", []string{"/toolfs/memory"})
+	session.SetAuditLogger(nil)
+	if _, _, err := fs.ListDirPagedWithSession("/toolfs/data", 0, 1, session); err == nil {
+		t.Error("Expected access denied outside the session's paths")
+	}
+	page, total, err := fs.ListDirPagedWithSession("/toolfs/data", 1, 1, nil)
+	if err != nil || total != 2 || len(page) != 1 || page[0] != "test.txt" {
+		t.Errorf("Expected second local entry, got %v (%d), %v", page, total, err)
+	}
+}
+
+func TestRAGQueryPaging(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+
+	query := func(path string) RAGSearchResults {
+		t.Helper()
+		data, err := fs.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) failed: %v", path, err)
+		}
+		var results RAGSearchResults
+		if err := json.Unmarshal(data, &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		return results
+	}
+
+	all := query("/toolfs/rag/query?text=AI+agents+memory&top_k=10&offset=0")
+	if all.Total != 4 || len(all.Results) != 4 {
+		t.Fatalf("Expected 4 results, got %+v", all)
+	}
+	for i := 1; i < len(all.Results); i++ {
+		if all.Results[i].Score > all.Results[i-1].Score {
+			t.Errorf("Expected results sorted by score, got %+v", all.Results)
+		}
+	}
+
+	page := query("/toolfs/rag/query?text=AI+agents+memory&top_k=10&offset=1&limit=2")
+	if page.Total != 4 || page.Offset != 1 || page.Limit != 2 || len(page.Results) != 2 {
+		t.Fatalf("Unexpected page %+v", page)
+	}
+	if page.Results[0].ID != all.Results[1].ID || page.Results[1].ID != all.Results[2].ID {
+		t.Errorf("Expected results 1 and 2, got %+v", page.Results)
+	}
+
+	// Without top_k the search covers the requested page
+	page = query("/toolfs/rag/query?text=AI+agents+memory&offset=3&limit=10")
+	if page.TopK != 13 || len(page.Results) != 1 || page.Results[0].ID != all.Results[3].ID {
+		t.Errorf("Expected last result, got %+v", page)
+	}
+
+	// Unpaged queries leave the paging fields out
+	data, _ := fs.ReadFile("/toolfs/rag/query?text=AI")
+	if strings.Contains(string(data), `"total"`) {
+		t.Errorf("Expected no paging fields, got %s", data)
+	}
+
+	for _, path := range []string{
+		"/toolfs/rag/query?text=AI&offset=-1",
+		"/toolfs/rag/query?text=AI&limit=-5",
+		"/toolfs/rag/query?text=AI&limit=ten",
+	} {
+		if _, err := fs.ReadFile(path); err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("ReadFile(%s): expected invalid parameter error, got %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package toolfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileInfoEntry describes one entry found by ListDirRecursive
+type FileInfoEntry struct {
+	Path    string    `json:"path"`     // Full virtual path, e.g. /toolfs/data/dir/file.txt
+	RelPath string    `json:"rel_path"` // Path relative to the listed directory, "/"-separated
+	IsDir   bool      `json:"is_dir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ListDirRecursive lists everything below path, up to maxDepth levels deep
+// (1 lists immediate children only; 0 or less means no limit). Entries are
+// sorted by path. Entries that cannot be read, or that match a deny rule or
+// the session's DeniedPaths, are skipped rather than failing the whole listing.
+func (fs *ToolFS) ListDirRecursive(path string, maxDepth int) ([]FileInfoEntry, error) {
+	return fs.ListDirRecursiveWithSession(path, maxDepth, nil)
+}
+
+// ListDirRecursiveWithSession lists everything below path like
+// ListDirRecursive with session-based access control. Skill mounts receive a
+// "list_dir_recursive" request with the depth limit in Data["input"].
+func (fs *ToolFS) ListDirRecursiveWithSession(path string, maxDepth int, session *Session) ([]FileInfoEntry, error) {
+	end := fs.startSpan("ListDirRecursive", path, session)
+	var result []FileInfoEntry
+	err := fs.runAudited(session, "ListDirRecursive", path, func() (int64, int64, error) {
+		var err error
+		result, err = fs.listDirRecursive(path, maxDepth, session)
+		return 0, 0, err
+	})
+	end(err)
+	return result, err
+}
+
+// listDirRecursive implements ListDirRecursiveWithSession without tracing or auditing
+func (fs *ToolFS) listDirRecursive(path string, maxDepth int, session *Session) ([]FileInfoEntry, error) {
+	path = strings.TrimSuffix(normalizeVirtualPath(path), "/")
+	localPath, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []FileInfoEntry
+	switch mountKind(mount) {
+	case "skill":
+		entries, err = fs.listSkillRecursive(path, localPath, maxDepth, session)
+	case "memory", "rag":
+		// Virtual directories are flat, so their children are the whole tree
+		var names []string
+		names, err = fs.listDirWithSession(path, session)
+		for _, name := range names {
+			entries = append(entries, fs.virtualEntry(path, name))
+		}
+	case "skills":
+		entries, err = fs.listSkillDocsRecursive(path)
+	case "remote":
+		entries, err = walkRemote(context.Background(), mount.remote, localPath, maxDepth)
+	default:
+		entries, err = walkLocal(localPath, maxDepth, mount.lazy != nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]FileInfoEntry, 0, len(entries))
+	for _, entry := range entries {
+		entry.RelPath = strings.Trim(normalizeVirtualPath(entry.RelPath), "/")
+		if entry.RelPath == "" || maxDepth > 0 && strings.Count(entry.RelPath, "/") >= maxDepth {
+			continue
+		}
+		entry.Path = path + "/" + entry.RelPath
+		if fs.checkDenied(entry.Path) == nil && (session == nil || session.deniedRule(entry.Path) == "") {
+			visible = append(visible, entry)
+		}
+	}
+	sort.Slice(visible, func(i, j int) bool { return visible[i].Path < visible[j].Path })
+	return visible, nil
+}
+
+// walkLocal walks a local directory up to maxDepth levels, skipping entries
+// that cannot be read
+func walkLocal(root string, maxDepth int, lazy bool) ([]FileInfoEntry, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	var entries []FileInfoEntry
+	err := filepath.Walk(root, func(localPath string, info os.FileInfo, walkErr error) error {
+		if localPath == root {
+			return walkErr
+		}
+		if walkErr != nil {
+			// Unreadable entries are skipped rather than failing the listing
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, localPath)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if lazy && rel == lazyManifestName {
+			return nil
+		}
+
+		entries = append(entries, FileInfoEntry{
+			RelPath: rel,
+			IsDir:   info.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		if info.IsDir() && maxDepth > 0 && strings.Count(rel, "/")+1 >= maxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// virtualEntry describes a child of the memory or RAG directory
+func (fs *ToolFS) virtualEntry(dir, name string) FileInfoEntry {
+	entry := FileInfoEntry{RelPath: name, ModTime: time.Now()}
+	if dir == fs.memoryPath {
+		if memEntry, err := fs.memoryStore.Get(name); err == nil {
+			entry.Size = int64(len(memEntry.Content))
+			entry.ModTime = memEntry.UpdatedAt
+		}
+	}
+	return entry
+}
+
+// listSkillRecursive forwards a recursive listing to a skill mount. The skill
+// returns FileInfoEntry objects, either as the result or in result["entries"].
+func (fs *ToolFS) listSkillRecursive(path, relPath string, maxDepth int, session *Session) ([]FileInfoEntry, error) {
+	skillMount, _ := fs.isSkillMount(path)
+	if skillMount == nil {
+		return nil, notFoundf("skill mount not found for path: %s", path)
+	}
+
+	data, err := fs.executeSkillMount(context.Background(), skillMount, path, relPath, "list_dir_recursive", []byte(strconv.Itoa(maxDepth)), session)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []FileInfoEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var wrapped struct {
+			Entries []FileInfoEntry `json:"entries"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("invalid recursive listing from skill '%s': %w", skillMount.SkillName, err)
+		}
+		entries = wrapped.Entries
+	}
+	for i := range entries {
+		if entries[i].RelPath == "" {
+			entries[i].RelPath = strings.TrimPrefix(entries[i].Path, path+"/")
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,115 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TreeSkill answers recursive listings with a fixed tree
+type TreeSkill struct {
+	MockSkill
+	lastDepth string
+}
+
+func (s *TreeSkill) Execute(input []byte) ([]byte, error) {
+	var request SkillRequest
+	json.Unmarshal(input, &request)
+	s.lastDepth, _ = request.Data["input"].(string)
+	return json.Marshal(SkillResponse{Success: true, Result: map[string]interface{}{
+		"entries": []FileInfoEntry{
+			{RelPath: "reports", IsDir: true},
+			{RelPath: "reports/q1.csv", Size: 42},
+		},
+	}})
+}
+
+// entryPaths returns the relative paths of entries
+func entryPaths(entries []FileInfoEntry) []string {
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.RelPath
+	}
+	return paths
+}
+
+func TestListDirRecursive(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	os.MkdirAll(filepath.Join(tmpDir, "subdir", "deep"), 0o755)
+	os.WriteFile(filepath.Join(tmpDir, "subdir", "deep", "leaf.txt"), []byte("leaf"), 0o644)
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, true)
+
+	entries, err := fs.ListDirRecursive("/toolfs/data", 0)
+	if err != nil {
+		t.Fatalf("ListDirRecursive failed: %v", err)
+	}
+	want := []string{"subdir", "subdir/deep", "subdir/deep/leaf.txt", "subdir/subfile.txt", "test.txt"}
+	if got := entryPaths(entries); len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i, entry := range entries {
+		if entry.RelPath != want[i] || entry.Path != "/toolfs/data/"+want[i] {
+			t.Errorf("Entry %d: expected %s, got %+v", i, want[i], entry)
+		}
+	}
+	if entries[2].IsDir || entries[2].Size != 4 || !entries[1].IsDir {
+		t.Errorf("Unexpected entry details: %+v", entries)
+	}
+
+	// Depth 1 lists immediate children only
+	entries, _ = fs.ListDirRecursive("/toolfs/data/", 1)
+	if got := entryPaths(entries); len(got) != 2 || got[0] != "subdir" || got[1] != "test.txt" {
+		t.Errorf("Expected immediate children, got %v", got)
+	}
+	entries, _ = fs.ListDirRecursive("/toolfs/data", 2)
+	if len(entries) != 4 {
+		t.Errorf("Expected 4 entries at depth 2, got %v", entryPaths(entries))
+	}
+
+	// Deny rules hide entries and sessions are enforced
+	fs.SetDenyRules([]string{"/toolfs/data/subdir/deep"})
+	entries, _ = fs.ListDirRecursive("/toolfs/data", 0)
+	if len(entries) != 3 {
+		t.Errorf("Expected denied subtree to be hidden, got %v", entryPaths(entries))
+	}
+	session, _ := fs.NewSession("limited", []string{"/toolfs/memory"})
+	if _, err := fs.ListDirRecursiveWithSession("/toolfs/data", 0, session); err == nil {
+		t.Error("Expected session restriction to apply")
+	}
+
+	// Memory entries are listed flat
+	fs.WriteFile("/toolfs/memory/note", []byte("remember"))
+	entries, err = fs.ListDirRecursiveWithSession("/toolfs/memory", 0, session)
+	if err != nil || len(entries) != 1 || entries[0].Path != "/toolfs/memory/note" || entries[0].Size != 8 {
+		t.Errorf("Expected memory entry, got %+v, %v", entries, err)
+	}
+}
+
+func TestListDirRecursiveSkillMount(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	skill := &TreeSkill{MockSkill: MockSkill{name: "tree", version: "1.0.0"}}
+	pm.InjectSkill(skill, NewSkillContext(fs, nil), nil)
+	fs.MountSkillExecutor("/tree", "tree")
+
+	entries, err := fs.ListDirRecursive("/toolfs/tree", 3)
+	if err != nil {
+		t.Fatalf("ListDirRecursive failed: %v", err)
+	}
+	if skill.lastDepth != "3" {
+		t.Errorf("Expected depth to be forwarded, got %q", skill.lastDepth)
+	}
+	if len(entries) != 2 || entries[1].Path != "/toolfs/tree/reports/q1.csv" || entries[1].Size != 42 {
+		t.Errorf("Unexpected skill entries: %+v", entries)
+	}
+
+	// The depth limit also applies to skill results
+	if entries, _ := fs.ListDirRecursive("/toolfs/tree", 1); len(entries) != 1 {
+		t.Errorf("Expected depth-limited skill entries, got %+v", entries)
+	}
+}
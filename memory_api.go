@@ -0,0 +1,136 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SetMemory stores content and metadata under id, creating or replacing the
+// entry. A nil metadata map keeps the metadata of an existing entry.
+func (fs *ToolFS) SetMemory(id, content string, metadata map[string]interface{}) error {
+	return fs.SetMemoryWithSession(id, content, metadata, nil)
+}
+
+// SetMemoryWithSession stores a memory entry like SetMemory. The session
+// must be allowed to write /toolfs/memory/<id>.
+func (fs *ToolFS) SetMemoryWithSession(id, content string, metadata map[string]interface{}, session *Session) error {
+	path, err := fs.memoryEntryPath(id)
+	if err != nil {
+		return err
+	}
+	end := fs.startSpan("SetMemory", path, session)
+	err = fs.runAuditedContent(session, "SetMemory", path, func() ([]byte, int64, int64, error) {
+		if fs.memoryStore == nil {
+			return nil, 0, 0, errors.New("memory store not available")
+		}
+		return []byte(content), 0, int64(len(content)), fs.memoryStore.Set(id, content, metadata)
+	})
+	end(err)
+	return err
+}
+
+// GetMemory returns a copy of the memory entry with the given ID. Unknown IDs
+// are an error wrapping ErrNotFound.
+func (fs *ToolFS) GetMemory(id string) (*MemoryEntry, error) {
+	return fs.GetMemoryWithSession(id, nil)
+}
+
+// GetMemoryWithSession returns a memory entry like GetMemory. The session
+// must be allowed to read /toolfs/memory/<id>.
+func (fs *ToolFS) GetMemoryWithSession(id string, session *Session) (*MemoryEntry, error) {
+	path, err := fs.memoryEntryPath(id)
+	if err != nil {
+		return nil, err
+	}
+	end := fs.startSpan("GetMemory", path, session)
+	var result *MemoryEntry
+	err = fs.runAuditedContent(session, "GetMemory", path, func() ([]byte, int64, int64, error) {
+		if fs.memoryStore == nil {
+			return nil, 0, 0, errors.New("memory store not available")
+		}
+		entry, err := fs.memoryStore.Get(id)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		copied := *entry
+		result = &copied
+		return []byte(copied.Content), int64(len(copied.Content)), 0, nil
+	})
+	end(err)
+	return result, err
+}
+
+// ListMemory returns copies of all memory entries, oldest first
+func (fs *ToolFS) ListMemory() ([]*MemoryEntry, error) {
+	return fs.ListMemoryWithSession(nil)
+}
+
+// ListMemoryWithSession lists memory entries like ListMemory. The session
+// must be allowed to read /toolfs/memory.
+func (fs *ToolFS) ListMemoryWithSession(session *Session) ([]*MemoryEntry, error) {
+	end := fs.startSpan("ListMemory", fs.memoryPath, session)
+	var result []*MemoryEntry
+	err := fs.runAudited(session, "ListMemory", fs.memoryPath, func() (int64, int64, error) {
+		if fs.memoryStore == nil {
+			return 0, 0, errors.New("memory store not available")
+		}
+		ids, err := fs.memoryStore.List()
+		if err != nil {
+			return 0, 0, err
+		}
+		entries := make([]*MemoryEntry, 0, len(ids))
+		for _, id := range ids {
+			entry, err := fs.memoryStore.Get(id)
+			if err != nil {
+				continue // Deleted since List
+			}
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+				return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+			}
+			return entries[i].ID < entries[j].ID
+		})
+		result = entries
+		return 0, 0, nil
+	})
+	end(err)
+	return result, err
+}
+
+// DeleteMemory removes the memory entry with the given ID. Unknown IDs are an
+// error wrapping ErrNotFound.
+func (fs *ToolFS) DeleteMemory(id string) error {
+	return fs.DeleteMemoryWithSession(id, nil)
+}
+
+// DeleteMemoryWithSession removes a memory entry like DeleteMemory. The
+// session must be allowed to write /toolfs/memory/<id>.
+func (fs *ToolFS) DeleteMemoryWithSession(id string, session *Session) error {
+	path, err := fs.memoryEntryPath(id)
+	if err != nil {
+		return err
+	}
+	end := fs.startSpan("DeleteMemory", path, session)
+	err = fs.runAudited(session, "DeleteMemory", path, func() (int64, int64, error) {
+		if fs.memoryStore == nil {
+			return 0, 0, errors.New("memory store not available")
+		}
+		return 0, 0, fs.memoryStore.Delete(id)
+	})
+	end(err)
+	return err
+}
+
+// memoryEntryPath returns the virtual path of memory entry id, which access
+// control and auditing apply to
+func (fs *ToolFS) memoryEntryPath(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, "/\\?") {
+		return "", fmt.Errorf("invalid memory ID '%s'", id)
+	}
+	return fs.memoryPath + "/" + id, nil
+}
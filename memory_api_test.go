@@ -0,0 +1,115 @@
+package toolfs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryAPI(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+
+	if err := fs.SetMemory("first", "first fact", map[string]interface{}{"source": "test"}); err != nil {
+		t.Fatalf("SetMemory failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := fs.SetMemory("second", "second fact", nil); err != nil {
+		t.Fatalf("SetMemory failed: %v", err)
+	}
+
+	entry, err := fs.GetMemory("first")
+	if err != nil || entry.Content != "first fact" || entry.Metadata["source"] != "test" || entry.CreatedAt.IsZero() {
+		t.Fatalf("Unexpected entry %+v, %v", entry, err)
+	}
+
+	// Entries are copies
+	entry.Content = "changed"
+	if again, _ := fs.GetMemory("first"); again.Content != "first fact" {
+		t.Error("Expected GetMemory to return a copy")
+	}
+
+	// Updates without metadata keep the existing metadata
+	fs.SetMemory("first", "updated fact", nil)
+	if entry, _ := fs.GetMemory("first"); entry.Content != "updated fact" || entry.Metadata["source"] != "test" {
+		t.Errorf("Unexpected updated entry %+v", entry)
+	}
+
+	// The filesystem view is the same store
+	if data, err := fs.ReadFile("/toolfs/memory/second"); err != nil || string(data) != "second fact" {
+		t.Errorf("Expected entry through the filesystem, got %q, %v", data, err)
+	}
+
+	entries, err := fs.ListMemory()
+	if err != nil || len(entries) != 2 || entries[0].ID != "first" || entries[1].ID != "second" {
+		t.Fatalf("Expected entries oldest first, got %+v, %v", entries, err)
+	}
+
+	if err := fs.DeleteMemory("first"); err != nil {
+		t.Fatalf("DeleteMemory failed: %v", err)
+	}
+	if _, err := fs.GetMemory("first"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := fs.DeleteMemory("first"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	for _, id := range []string{"", "a/b", "a?format=json"} {
+		if err := fs.SetMemory(id, "x", nil); err == nil {
+			t.Errorf("Expected error for memory ID %q", id)
+		}
+	}
+}
+
+func TestMemoryAPIWithSession(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	fs.SetMemory("shared", "visible", nil)
+
+	logger := &TestAuditLogger{}
+	reader, _ := fs.NewSession("reader", []string{"/toolfs/data"})
+	reader.SetAuditLogger(logger)
+
+	if _, err := fs.GetMemoryWithSession("shared", reader); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if _, err := fs.ListMemoryWithSession(reader); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if err := fs.SetMemoryWithSession("new", "x", nil, reader); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if err := fs.DeleteMemoryWithSession("shared", reader); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if _, err := fs.GetMemory("shared"); err != nil {
+		t.Error("Expected denied delete to leave the entry")
+	}
+
+	agent, _ := fs.NewSession("agent", []string{"/toolfs/memory"})
+	agent.SetAuditLogger(logger)
+	if err := fs.SetMemoryWithSession("note", "agent note", nil, agent); err != nil {
+		t.Fatalf("SetMemoryWithSession failed: %v", err)
+	}
+	if entry, err := fs.GetMemoryWithSession("note", agent); err != nil || entry.Content != "agent note" {
+		t.Errorf("Unexpected entry %+v, %v", entry, err)
+	}
+	if entries, err := fs.ListMemoryWithSession(agent); err != nil || len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %+v, %v", entries, err)
+	}
+
+	var denied, set int
+	for _, entry := range logger.Entries {
+		if entry.AccessDenied {
+			denied++
+		}
+		if entry.Operation == "SetMemory" && entry.Success {
+			set++
+			if entry.Path != "/toolfs/memory/note" || entry.BytesWritten != int64(len("agent note")) {
+				t.Errorf("Unexpected SetMemory audit entry %+v", entry)
+			}
+		}
+	}
+	if denied != 4 || set != 1 {
+		t.Errorf("Expected 4 denied and 1 successful set in the audit log, got %d and %d", denied, set)
+	}
+}
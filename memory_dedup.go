@@ -0,0 +1,90 @@
+package toolfs
+
+import "crypto/sha256"
+
+// memoryDedup indexes memory entry contents by SHA-256 so entries with
+// identical content share a single string
+type memoryDedup struct {
+	blobs   map[[sha256.Size]byte]*memoryBlob
+	entries map[string][sha256.Size]byte // Entry ID -> content hash
+}
+
+// memoryBlob is a stored content shared by refs entries
+type memoryBlob struct {
+	content string
+	refs    int
+}
+
+// EnableDedup turns content-addressable deduplication on or off. While it is
+// on, Set stores content already held by another entry as a reference to that
+// entry's copy; entries stored before dedup was enabled are deduplicated
+// when it is turned on. Reads are unaffected. Turning it off stops sharing
+// new content and resets the statistics reported by Stats.
+func (s *InMemoryStore) EnableDedup(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !enabled {
+		s.dedup = nil
+		return
+	}
+	if s.dedup != nil {
+		return
+	}
+	s.dedup = &memoryDedup{
+		blobs:   make(map[[sha256.Size]byte]*memoryBlob),
+		entries: make(map[string][sha256.Size]byte),
+	}
+	for id, entry := range s.entries {
+		entry.Content = s.dedup.intern(id, entry.Content)
+	}
+}
+
+// Stats reports the number of entries, the number of distinct contents they
+// hold and the bytes saved by sharing them. Without dedup every entry counts
+// as its own blob and nothing is saved.
+func (s *InMemoryStore) Stats() (entries, uniqueBlobs int, bytesSaved int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries = len(s.entries)
+	if s.dedup == nil {
+		return entries, entries, 0
+	}
+	for _, blob := range s.dedup.blobs {
+		bytesSaved += int64(blob.refs-1) * int64(len(blob.content))
+	}
+	return entries, len(s.dedup.blobs), bytesSaved
+}
+
+// intern records content as the content of entry id and returns the shared
+// copy to store in the entry
+func (d *memoryDedup) intern(id, content string) string {
+	d.release(id)
+
+	hash := sha256.Sum256([]byte(content))
+	blob, exists := d.blobs[hash]
+	if !exists {
+		blob = &memoryBlob{content: content}
+		d.blobs[hash] = blob
+	}
+	blob.refs++
+	d.entries[id] = hash
+	return blob.content
+}
+
+// release drops entry id's reference to its content, removing the blob when
+// no entry refers to it anymore
+func (d *memoryDedup) release(id string) {
+	hash, exists := d.entries[id]
+	if !exists {
+		return
+	}
+	delete(d.entries, id)
+	if blob := d.blobs[hash]; blob != nil {
+		blob.refs--
+		if blob.refs <= 0 {
+			delete(d.blobs, hash)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package toolfs
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestMemoryDedup(t *testing.T) {
+	store := NewInMemoryStore()
+	content := strings.Repeat("shared observation ", 10)
+
+	// Default mode stores every entry on its own
+	store.Set("before", content, nil)
+	if entries, blobs, saved := store.Stats(); entries != 1 || blobs != 1 || saved != 0 {
+		t.Errorf("Unexpected stats without dedup: %d %d %d", entries, blobs, saved)
+	}
+
+	store.EnableDedup(true)
+	store.Set("a", strings.Clone(content), nil)
+	store.Set("b", strings.Clone(content), map[string]interface{}{"tag": "b"})
+	store.Set("other", "different", nil)
+
+	entries, blobs, saved := store.Stats()
+	if entries != 4 || blobs != 2 || saved != int64(2*len(content)) {
+		t.Errorf("Expected 4 entries, 2 blobs and %d bytes saved, got %d %d %d", 2*len(content), entries, blobs, saved)
+	}
+
+	// Reads resolve to the shared content
+	a, _ := store.Get("a")
+	b, _ := store.Get("b")
+	before, _ := store.Get("before")
+	if a.Content != content || b.Content != content || b.Metadata["tag"] != "b" {
+		t.Errorf("Unexpected entries %+v %+v", a, b)
+	}
+	if unsafe.StringData(a.Content) != unsafe.StringData(b.Content) || unsafe.StringData(a.Content) != unsafe.StringData(before.Content) {
+		t.Error("Expected identical contents to share storage")
+	}
+
+	// Updating and deleting entries releases their references
+	store.Set("a", "different", nil)
+	store.Delete("b")
+	if entries, blobs, saved := store.Stats(); entries != 3 || blobs != 2 || saved != int64(len("different")) {
+		t.Errorf("Unexpected stats after update and delete: %d %d %d", entries, blobs, saved)
+	}
+	store.Delete("before")
+	store.Delete("other")
+	if entries, blobs, saved := store.Stats(); entries != 1 || blobs != 1 || saved != 0 {
+		t.Errorf("Expected unshared blobs to be released, got %d %d %d", entries, blobs, saved)
+	}
+
+	store.EnableDedup(false)
+	store.Set("c", "different", nil)
+	if entries, blobs, saved := store.Stats(); entries != 2 || blobs != 2 || saved != 0 {
+		t.Errorf("Unexpected stats after disabling dedup: %d %d %d", entries, blobs, saved)
+	}
+	if entry, err := store.Get("c"); err != nil || entry.Content != "different" {
+		t.Errorf("Expected entry to be readable, got %+v, %v", entry, err)
+	}
+}
+
+func TestMemoryDedupThroughFS(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	fs.memoryStore.(*InMemoryStore).EnableDedup(true)
+
+	for _, id := range []string{"one", "two", "three"} {
+		if err := fs.WriteFile("/toolfs/memory/"+id, []byte("same result")); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	data, err := fs.ReadFile("/toolfs/memory/two")
+	if err != nil || !strings.Contains(string(data), "same result") {
+		t.Errorf("Expected entry content, got %s, %v", data, err)
+	}
+	if _, blobs, saved := fs.memoryStore.(*InMemoryStore).Stats(); blobs != 1 || saved != int64(2*len("same result")) {
+		t.Errorf("Expected writes to share content, got %d blobs and %d bytes saved", blobs, saved)
+	}
+}
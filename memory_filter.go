@@ -0,0 +1,67 @@
+package toolfs
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// MemoryFilter selects memory entries for InMemoryStore.ListFiltered. Zero
+// fields do not filter; all set fields must match.
+type MemoryFilter struct {
+	Contains      string            // Case-insensitive substring of Content
+	Metadata      map[string]string // Metadata key -> required value, compared in string form
+	CreatedAfter  time.Time         // Inclusive lower bound on CreatedAt
+	CreatedBefore time.Time         // Exclusive upper bound on CreatedAt
+	UpdatedAfter  time.Time         // Inclusive lower bound on UpdatedAt
+	UpdatedBefore time.Time         // Exclusive upper bound on UpdatedAt
+}
+
+// ListFiltered returns copies of the entries matching filter, oldest first.
+// List is unaffected.
+func (s *InMemoryStore) ListFiltered(filter MemoryFilter) ([]*MemoryEntry, error) {
+	contains := strings.ToLower(filter.Contains)
+
+	s.mu.RLock()
+	matches := make([]*MemoryEntry, 0)
+	for _, entry := range s.entries {
+		if filter.matches(entry, contains) {
+			copied := *entry
+			matches = append(matches, &copied)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.Before(matches[j].CreatedAt)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches, nil
+}
+
+// matches reports whether entry satisfies the filter; contains is the
+// lower-cased Contains
+func (f *MemoryFilter) matches(entry *MemoryEntry, contains string) bool {
+	if contains != "" && !strings.Contains(strings.ToLower(entry.Content), contains) {
+		return false
+	}
+	if !ragMetadataMatches(entry.Metadata, f.Metadata) {
+		return false
+	}
+	return inTimeRange(entry.CreatedAt, f.CreatedAfter, f.CreatedBefore) &&
+		inTimeRange(entry.UpdatedAt, f.UpdatedAfter, f.UpdatedBefore)
+}
+
+// inTimeRange reports whether t lies in [after, before), treating zero bounds
+// as open
+func inTimeRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+	return true
+}
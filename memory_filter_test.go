@@ -0,0 +1,60 @@
+package toolfs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryListFiltered(t *testing.T) {
+	store := NewInMemoryStore()
+	store.Set("a1", "Alpha kickoff notes", map[string]interface{}{"project": "alpha", "priority": 1})
+	store.Set("b1", "Beta retro", map[string]interface{}{"project": "beta"})
+	store.Set("a2", "alpha deploy checklist", map[string]interface{}{"project": "alpha"})
+
+	// Age one entry so time ranges can tell them apart
+	old := time.Now().Add(-48 * time.Hour)
+	store.entries["a1"].CreatedAt = old
+	store.entries["a1"].UpdatedAt = old
+
+	ids := func(entries []*MemoryEntry) []string {
+		result := make([]string, len(entries))
+		for i, entry := range entries {
+			result[i] = entry.ID
+		}
+		return result
+	}
+
+	entries, _ := store.ListFiltered(MemoryFilter{Metadata: map[string]string{"project": "alpha"}})
+	if got := ids(entries); len(got) != 2 || got[0] != "a1" || got[1] != "a2" {
+		t.Errorf("Expected alpha entries oldest first, got %v", got)
+	}
+
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	entries, _ = store.ListFiltered(MemoryFilter{Metadata: map[string]string{"project": "alpha"}, CreatedAfter: dayAgo})
+	if got := ids(entries); len(got) != 1 || got[0] != "a2" {
+		t.Errorf("Expected recent alpha entry, got %v", got)
+	}
+	entries, _ = store.ListFiltered(MemoryFilter{UpdatedBefore: dayAgo})
+	if got := ids(entries); len(got) != 1 || got[0] != "a1" {
+		t.Errorf("Expected old entry, got %v", got)
+	}
+
+	entries, _ = store.ListFiltered(MemoryFilter{Contains: "ALPHA"})
+	if len(entries) != 2 {
+		t.Errorf("Expected case-insensitive content match, got %v", ids(entries))
+	}
+	entries, _ = store.ListFiltered(MemoryFilter{Metadata: map[string]string{"priority": "1"}})
+	if got := ids(entries); len(got) != 1 || got[0] != "a1" {
+		t.Errorf("Expected non-string metadata match, got %v", got)
+	}
+
+	// Results are copies and List is unchanged
+	entries, _ = store.ListFiltered(MemoryFilter{})
+	entries[0].Content = "changed"
+	if entry, _ := store.Get(entries[0].ID); entry.Content == "changed" {
+		t.Error("Expected ListFiltered to return copies")
+	}
+	if all, _ := store.List(); len(all) != 3 {
+		t.Errorf("Expected List to return every ID, got %v", all)
+	}
+}
@@ -0,0 +1,40 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Mkdir creates the directory at path, along with any missing parents, in a
+// local mount. Creating an existing directory succeeds. Read-only mounts fail
+// with ErrReadOnly; memory, RAG and skill mounts have no directories to create.
+func (fs *ToolFS) Mkdir(path string) error {
+	return fs.MkdirWithSession(path, nil)
+}
+
+// MkdirWithSession creates a directory like Mkdir with session-based access
+// control. The session must be allowed to write path.
+func (fs *ToolFS) MkdirWithSession(path string, session *Session) error {
+	end := fs.startSpan("Mkdir", path, session)
+	err := fs.runAudited(session, "Mkdir", path, func() (int64, int64, error) {
+		return 0, 0, fs.mkdir(path)
+	})
+	end(err)
+	return err
+}
+
+// mkdir implements MkdirWithSession without tracing or auditing
+func (fs *ToolFS) mkdir(path string) error {
+	localPath, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if mount.ReadOnly || mountKind(mount) == "rag" {
+		return fmt.Errorf("%w: cannot create directory '%s'", ErrReadOnly, path)
+	}
+	if mountKind(mount) != "local" {
+		return errors.New("directories can only be created in local mounts")
+	}
+	return os.MkdirAll(localPath, 0o755)
+}
@@ -0,0 +1,55 @@
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMkdir(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	roDir, cleanupRO := setupTestDir(t)
+	defer cleanupRO()
+	fs.MountLocal("/data", tmpDir, false)
+	fs.MountLocal("/ro", roDir, true)
+
+	if err := fs.Mkdir("/toolfs/data/a/b"); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(tmpDir, "a", "b")); err != nil || !info.IsDir() {
+		t.Errorf("Expected directory on disk, got %v", err)
+	}
+	if err := fs.Mkdir("/toolfs/data/a/b"); err != nil {
+		t.Errorf("Expected Mkdir of existing directory to succeed, got %v", err)
+	}
+
+	if err := fs.Mkdir("/toolfs/ro/new"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	if err := fs.Mkdir("/toolfs/memory/dir"); err == nil {
+		t.Error("Expected Mkdir in memory to fail")
+	}
+	if err := fs.WriteFile("/toolfs/ro/test.txt", []byte("x")); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected WriteFile to read-only mount to wrap ErrReadOnly, got %v", err)
+	}
+
+	// Sessions need write access
+	logger := &TestAuditLogger{}
+	session, _ := fs.NewSession("mkdir", []string{})
+	session.SetReadPaths([]string{"/toolfs/data"})
+	session.SetWritePaths([]string{"/toolfs/data/a"})
+	session.AuditLogger = logger
+	if err := fs.MkdirWithSession("/toolfs/data/c", session); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected access denied outside write paths, got %v", err)
+	}
+	if err := fs.MkdirWithSession("/toolfs/data/a/c", session); err != nil {
+		t.Errorf("MkdirWithSession failed: %v", err)
+	}
+	last := logger.Entries[len(logger.Entries)-1]
+	if last.Operation != "Mkdir" || last.Path != "/toolfs/data/a/c" || !last.Success {
+		t.Errorf("Unexpected audit entry: %+v", last)
+	}
+}
@@ -0,0 +1,139 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MissingMountPolicy controls what happens when an operation fails because
+// the local directory backing a mount no longer exists
+type MissingMountPolicy int
+
+const (
+	MissingMountDegrade  MissingMountPolicy = iota // Keep the mount and report it degraded in ListMounts (default)
+	MissingMountUnmount                            // Remove the mount
+	MissingMountRecreate                           // Recreate the directory (empty); degrade if that fails
+)
+
+// SetMissingMountPolicy sets how mounts whose local directory disappears are
+// handled. The condition is detected when an operation on the mount fails.
+func (fs *ToolFS) SetMissingMountPolicy(policy MissingMountPolicy) {
+	fs.mountErrMu.Lock()
+	fs.missingMountPolicy = policy
+	fs.mountErrMu.Unlock()
+}
+
+// OnMountError registers a callback invoked when a mount's local directory is
+// found missing, after the missing mount policy has been applied. A degraded
+// mount is reported once; the state is cleared when the mount is mounted again
+// with MountLocal, or when an operation on it succeeds after its directory
+// has come back.
+func (fs *ToolFS) OnMountError(callback func(mountPoint string, err error)) {
+	fs.mountErrMu.Lock()
+	fs.onMountError = callback
+	fs.mountErrMu.Unlock()
+}
+
+// checkMountError inspects the outcome of an operation on path. If it failed
+// because the backing directory of a local mount disappeared, the missing
+// mount policy is applied and the OnMountError callback is notified. If it
+// succeeded on a degraded mount whose directory is back, the mount recovers.
+func (fs *ToolFS) checkMountError(path string, opErr error) {
+	if opErr == nil {
+		if fs.degradedMounts.Load() > 0 {
+			fs.recoverMount(normalizeVirtualPath(path))
+		}
+		return
+	}
+	if !errors.Is(opErr, os.ErrNotExist) {
+		return
+	}
+
+	path = normalizeVirtualPath(path)
+	fs.mountErrMu.Lock()
+	mountPoint, mount := fs.localMountFor(path)
+	if mount == nil || mount.lazy != nil || mount.missing != nil {
+		fs.mountErrMu.Unlock()
+		return
+	}
+	if _, statErr := os.Stat(mount.LocalPath); !errors.Is(statErr, os.ErrNotExist) {
+		fs.mountErrMu.Unlock()
+		return
+	}
+
+	err := fmt.Errorf("local directory '%s' of mount '%s' is missing", mount.LocalPath, mountPoint)
+	switch fs.missingMountPolicy {
+	case MissingMountUnmount:
+		fs.removeLocalMount(mountPoint)
+	case MissingMountRecreate:
+		if mkErr := os.MkdirAll(mount.LocalPath, 0o755); mkErr != nil {
+			err = fmt.Errorf("%v; recreate failed: %w", err, mkErr)
+			fs.setMountError(mount, err)
+		}
+	default:
+		fs.setMountError(mount, err)
+	}
+	callback := fs.onMountError
+	fs.mountErrMu.Unlock()
+
+	if callback != nil {
+		callback(mountPoint, err)
+	}
+}
+
+// setMountError marks mount degraded; mountErrMu must be held
+func (fs *ToolFS) setMountError(mount *Mount, err error) {
+	if mount.missing == nil {
+		fs.degradedMounts.Add(1)
+	}
+	mount.missing = err
+}
+
+// clearMountError clears the degraded state of mount
+func (fs *ToolFS) clearMountError(mount *Mount) {
+	fs.mountErrMu.Lock()
+	defer fs.mountErrMu.Unlock()
+	if mount.missing != nil {
+		mount.missing = nil
+		fs.degradedMounts.Add(-1)
+	}
+}
+
+// recoverMount clears the degraded state of the local mount containing path
+// once its directory exists again
+func (fs *ToolFS) recoverMount(path string) {
+	_, mount := fs.localMountFor(path)
+	if mount == nil {
+		return
+	}
+	if info, err := os.Stat(mount.LocalPath); err == nil && info.IsDir() {
+		fs.clearMountError(mount)
+	}
+}
+
+// localMountFor returns the longest local mount containing path
+func (fs *ToolFS) localMountFor(path string) (string, *Mount) {
+	var bestMountPoint string
+	var bestMount *Mount
+	for mountPoint, mount := range fs.mounts {
+		if (path == mountPoint || strings.HasPrefix(path, mountPoint+"/")) && len(mountPoint) > len(bestMountPoint) {
+			bestMountPoint, bestMount = mountPoint, mount
+		}
+	}
+	return bestMountPoint, bestMount
+}
+
+// removeLocalMount removes a local mount and its cached path resolutions
+func (fs *ToolFS) removeLocalMount(mountPoint string) {
+	delete(fs.mounts, mountPoint)
+
+	fs.invalidateLastResolved()
+	fs.pathResolveCache.Range(func(key, value interface{}) bool {
+		if strings.HasPrefix(key.(string), mountPoint) {
+			fs.pathResolveCache.Delete(key)
+		}
+		return true
+	})
+}
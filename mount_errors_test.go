@@ -0,0 +1,128 @@
+package toolfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mountInfoFor returns the ListMounts entry for mountPoint
+func mountInfoFor(fs *ToolFS, mountPoint string) *MountInfo {
+	for _, info := range fs.ListMounts() {
+		if info.MountPoint == mountPoint {
+			return &info
+		}
+	}
+	return nil
+}
+
+func TestMissingMountDegrade(t *testing.T) {
+	backing := t.TempDir()
+	os.WriteFile(filepath.Join(backing, "file.txt"), []byte("data"), 0o644)
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", backing, false)
+
+	var calls []string
+	fs.OnMountError(func(mountPoint string, err error) {
+		calls = append(calls, mountPoint)
+	})
+
+	os.RemoveAll(backing)
+	if _, err := fs.ReadFile("/toolfs/data/file.txt"); err == nil {
+		t.Fatal("Expected read from removed directory to fail")
+	}
+	if len(calls) != 1 || calls[0] != "/toolfs/data" {
+		t.Fatalf("Expected one callback for /toolfs/data, got %v", calls)
+	}
+
+	info := mountInfoFor(fs, "/toolfs/data")
+	if info == nil || !info.Degraded || info.Error == "" {
+		t.Fatalf("Expected degraded mount in ListMounts, got %+v", info)
+	}
+
+	// A degraded mount is reported once
+	fs.ReadFile("/toolfs/data/file.txt")
+	if len(calls) != 1 {
+		t.Errorf("Expected no further callbacks, got %v", calls)
+	}
+
+	// A missing file in a healthy mount is not a mount error
+	healthy := t.TempDir()
+	fs.MountLocal("/other", healthy, false)
+	fs.ReadFile("/toolfs/other/missing.txt")
+	if len(calls) != 1 {
+		t.Errorf("Expected missing file not to trigger callback, got %v", calls)
+	}
+
+	// Mounting again clears the degraded state
+	os.MkdirAll(backing, 0o755)
+	fs.MountLocal("/data", backing, false)
+	if info := mountInfoFor(fs, "/toolfs/data"); info.Degraded {
+		t.Errorf("Expected remount to clear degraded state, got %+v", info)
+	}
+}
+
+func TestMissingMountRecovers(t *testing.T) {
+	backing := t.TempDir()
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", backing, false)
+
+	calls := 0
+	fs.OnMountError(func(mountPoint string, err error) { calls++ })
+
+	os.RemoveAll(backing)
+	fs.ListDir("/toolfs/data")
+	if info := mountInfoFor(fs, "/toolfs/data"); calls != 1 || !info.Degraded {
+		t.Fatalf("Expected degraded mount after one callback, got %d calls, %+v", calls, info)
+	}
+
+	// A successful operation once the directory is back clears the state
+	os.MkdirAll(backing, 0o755)
+	if _, err := fs.ListDir("/toolfs/data"); err != nil {
+		t.Fatalf("ListDir on restored directory failed: %v", err)
+	}
+	if info := mountInfoFor(fs, "/toolfs/data"); info.Degraded || info.Error != "" {
+		t.Errorf("Expected recovered mount, got %+v", info)
+	}
+
+	// Losing the directory again is reported again
+	os.RemoveAll(backing)
+	fs.ListDir("/toolfs/data")
+	if calls != 2 {
+		t.Errorf("Expected a second callback, got %d", calls)
+	}
+}
+
+func TestMissingMountPolicies(t *testing.T) {
+	backing := t.TempDir()
+
+	fs := NewToolFS("/toolfs")
+	fs.SetMissingMountPolicy(MissingMountUnmount)
+	fs.MountLocal("/data", backing, false)
+	called := false
+	fs.OnMountError(func(mountPoint string, err error) { called = true })
+
+	os.RemoveAll(backing)
+	fs.ListDir("/toolfs/data")
+	if !called || mountInfoFor(fs, "/toolfs/data") != nil {
+		t.Error("Expected the mount to be removed")
+	}
+
+	// Recreate restores an empty, usable directory
+	backing = t.TempDir()
+	fs = NewToolFS("/toolfs")
+	fs.SetMissingMountPolicy(MissingMountRecreate)
+	fs.MountLocal("/data", backing, false)
+
+	os.RemoveAll(backing)
+	if _, err := fs.ListDir("/toolfs/data"); err == nil {
+		t.Fatal("Expected the first listing after removal to fail")
+	}
+	if info := mountInfoFor(fs, "/toolfs/data"); info == nil || info.Degraded {
+		t.Fatalf("Expected recreated mount to be healthy, got %+v", info)
+	}
+	if err := fs.WriteFile("/toolfs/data/new.txt", []byte("x")); err != nil {
+		t.Errorf("Expected write to recreated directory to succeed: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package toolfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MountTypeObject is the MountInfo.Type of object store mounts
+const MountTypeObject = "object"
+
+// ObjectStore is a flat key/value object store such as S3 or MinIO. Keys are
+// slash-separated paths relative to the mount point; directories are implied
+// by the keys below them. GetObject should return an error wrapping
+// os.ErrNotExist for missing keys.
+type ObjectStore interface {
+	GetObject(key string) ([]byte, error)
+	PutObject(key string, data []byte) error
+	ListObjects(prefix string) ([]string, error) // Keys starting with prefix
+}
+
+// MountObjectStore mounts backend at mountPoint. Reading mountPoint/a/b.txt
+// fetches the object "a/b.txt", writing it stores the object and listing
+// mountPoint/a returns the objects and implied subdirectories directly
+// below the "a/" prefix. Deleting files is not supported.
+func (fs *ToolFS) MountObjectStore(mountPoint string, backend ObjectStore) error {
+	if backend == nil {
+		return errors.New("object store backend is nil")
+	}
+	return fs.addRemoteMount(mountPoint, &objectSource{store: backend}, false)
+}
+
+// objectSource adapts an ObjectStore to a remoteSource. ObjectStore calls
+// cannot be interrupted, so contexts are only checked before calling the store.
+type objectSource struct {
+	store ObjectStore
+}
+
+func (s *objectSource) mountType() string {
+	return MountTypeObject
+}
+
+func (s *objectSource) get(ctx context.Context, key string) ([]byte, error) {
+	if key == "" {
+		return nil, fmt.Errorf("%w: object store root", ErrIsDirectory)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.store.GetObject(key)
+}
+
+func (s *objectSource) put(ctx context.Context, key string, data []byte) error {
+	if key == "" {
+		return fmt.Errorf("%w: object store root", ErrIsDirectory)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.store.PutObject(key, data)
+}
+
+// list returns the objects and implied subdirectories directly below key.
+// Only the root may be empty.
+func (s *objectSource) list(ctx context.Context, key string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+	keys, err := s.store.ListObjects(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(keys))
+	for _, objectKey := range keys {
+		rest := strings.TrimPrefix(objectKey, prefix)
+		if rest == "" || !strings.HasPrefix(objectKey, prefix) {
+			continue
+		}
+		name := rest
+		if i := strings.Index(rest, "/"); i >= 0 {
+			name = rest[:i+1]
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 && key != "" {
+		return nil, fmt.Errorf("%w: %s", os.ErrNotExist, key)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// stat reports key as a directory if objects exist below it, otherwise it
+// fetches the object to find its size
+func (s *objectSource) stat(ctx context.Context, key string) (*FileInfo, error) {
+	if key == "" {
+		return &FileInfo{ModTime: time.Now(), IsDir: true}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if keys, err := s.store.ListObjects(key + "/"); err == nil && len(keys) > 0 {
+		return &FileInfo{ModTime: time.Now(), IsDir: true}, nil
+	}
+	data, err := s.store.GetObject(key)
+	if err != nil {
+		return nil, err
+	}
+	return &FileInfo{Size: int64(len(data)), ModTime: time.Now()}, nil
+}
+
+// InMemoryObjectStore is a simple in-memory implementation of ObjectStore,
+// useful for tests
+type InMemoryObjectStore struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+// NewInMemoryObjectStore creates an empty in-memory object store
+func NewInMemoryObjectStore() *InMemoryObjectStore {
+	return &InMemoryObjectStore{objects: make(map[string][]byte)}
+}
+
+// GetObject returns a copy of the object stored at key
+func (s *InMemoryObjectStore) GetObject(key string) ([]byte, error) {
+	s.mu.RLock()
+	data, exists := s.objects[key]
+	s.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: object '%s'", os.ErrNotExist, key)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// PutObject stores a copy of data at key
+func (s *InMemoryObjectStore) PutObject(key string, data []byte) error {
+	s.mu.Lock()
+	s.objects[key] = append([]byte(nil), data...)
+	s.mu.Unlock()
+	return nil
+}
+
+// ListObjects returns the sorted keys starting with prefix
+func (s *InMemoryObjectStore) ListObjects(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0)
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,78 @@
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMountObjectStore(t *testing.T) {
+	store := NewInMemoryObjectStore()
+	store.PutObject("readme.txt", []byte("hello"))
+	store.PutObject("logs/2024/app.log", []byte("started"))
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountObjectStore("/bucket", store); err != nil {
+		t.Fatalf("MountObjectStore failed: %v", err)
+	}
+
+	if data, err := fs.ReadFile("/toolfs/bucket/readme.txt"); err != nil || string(data) != "hello" {
+		t.Errorf("Expected object content, got %q, %v", data, err)
+	}
+	if _, err := fs.ReadFile("/toolfs/bucket/missing.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+
+	// Writes go to the backend, creating implied directories
+	if err := fs.WriteFile("/toolfs/bucket/logs/2024/new.log", []byte("written")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if data, err := store.GetObject("logs/2024/new.log"); err != nil || string(data) != "written" {
+		t.Errorf("Expected object to be stored, got %q, %v", data, err)
+	}
+
+	entries, err := fs.ListDir("/toolfs/bucket")
+	if err != nil || strings.Join(entries, ",") != "logs/,readme.txt" {
+		t.Errorf("Unexpected root listing %v, %v", entries, err)
+	}
+	entries, err = fs.ListDir("/toolfs/bucket/logs/2024")
+	if err != nil || strings.Join(entries, ",") != "app.log,new.log" {
+		t.Errorf("Unexpected listing %v, %v", entries, err)
+	}
+	if _, err := fs.ListDir("/toolfs/bucket/nothing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist for empty prefix, got %v", err)
+	}
+	recursive, err := fs.ListDirRecursive("/toolfs/bucket", 0)
+	if err != nil || len(recursive) != 5 {
+		t.Errorf("Expected 5 recursive entries, got %+v, %v", recursive, err)
+	}
+
+	if info, err := fs.Stat("/toolfs/bucket/logs"); err != nil || !info.IsDir {
+		t.Errorf("Expected implied directory, got %+v, %v", info, err)
+	}
+	info, err := fs.Stat("/toolfs/bucket/readme.txt")
+	if err != nil || info.IsDir || info.Size != 5 {
+		t.Errorf("Unexpected object stat %+v, %v", info, err)
+	}
+	if info.BackedBy == nil || info.BackedBy.Type != MountTypeObject || info.BackedBy.ReadOnly {
+		t.Errorf("Expected writable object mount info, got %+v", info.BackedBy)
+	}
+
+	if err := fs.DeleteFile("/toolfs/bucket/readme.txt"); err == nil {
+		t.Error("Expected delete to be unsupported")
+	}
+
+	// Session access control applies as for other mounts
+	session, _ := fs.NewSession("object-session", []string{"/toolfs/bucket/logs"})
+	if _, err := fs.ReadFileWithSession("/toolfs/bucket/readme.txt", session); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if _, err := fs.ReadFileWithSession("/toolfs/bucket/logs/2024/app.log", session); err != nil {
+		t.Errorf("Expected allowed read, got %v", err)
+	}
+
+	if err := fs.MountObjectStore("/other", nil); err == nil {
+		t.Error("Expected nil backend to be rejected")
+	}
+}
@@ -0,0 +1,565 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrPatchMismatch is wrapped by ApplyPatch errors when a hunk's context or
+// removed lines do not match the file
+var ErrPatchMismatch = errors.New("patch does not apply")
+
+// PatchOptions controls how ApplyPatchWithOptions locates hunks
+type PatchOptions struct {
+	// Fuzz is the number of lines a hunk may be displaced from the position
+	// stated in its header. 0 (the default) requires hunks to match exactly
+	// where they say they apply.
+	Fuzz int
+}
+
+// noEOL marks a line that is not followed by a newline (the last line of a
+// file without a trailing newline), so that the difference is visible to
+// line comparison
+const noEOL = "\x00no-eol"
+
+// noEOLMarker is the unified diff line that follows a line without newline
+const noEOLMarker = `\ No newline at end of file`
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffHunk is one hunk of a unified diff
+type diffHunk struct {
+	oldStart, oldCount int
+	newStart, newCount int
+	lines              []diffLine
+}
+
+// diffLine is a line of a hunk: ' ' (context), '-' (removed) or '+' (added)
+type diffLine struct {
+	kind byte
+	text string // Line content, ending in noEOL if it has no newline
+}
+
+// header returns the hunk's "@@ -l,s +l,s @@" line
+func (h *diffHunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.oldStart, h.oldCount), hunkRange(h.newStart, h.newCount))
+}
+
+// hunkRange formats a hunk range, omitting a count of 1 like diff -u
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// ApplyPatch applies a unified diff to a text file (on a local mount or a
+// memory entry) and writes the result atomically. Every hunk must match the
+// file exactly at the position stated in its header; otherwise nothing is
+// written and the error, wrapping ErrPatchMismatch, names the hunk and line
+// that differ.
+func (fs *ToolFS) ApplyPatch(path string, unifiedDiff string, session *Session) error {
+	return fs.ApplyPatchWithOptions(path, unifiedDiff, PatchOptions{}, session)
+}
+
+// ApplyPatchWithOptions applies a unified diff like ApplyPatch, with options
+// that allow displaced hunks
+func (fs *ToolFS) ApplyPatchWithOptions(path string, unifiedDiff string, options PatchOptions, session *Session) error {
+	end := fs.startSpan("ApplyPatch", path, session)
+	err := fs.runAudited(session, "ApplyPatch", path, func() (int64, int64, error) {
+		written, err := fs.applyPatch(path, unifiedDiff, options, session)
+		return 0, written, err
+	})
+	end(err)
+	return err
+}
+
+// applyPatch implements ApplyPatchWithOptions without tracing or auditing
+func (fs *ToolFS) applyPatch(path string, unifiedDiff string, options PatchOptions, session *Session) (int64, error) {
+	hunks, err := parseUnifiedDiff(unifiedDiff)
+	if err != nil {
+		return 0, err
+	}
+
+	localPath, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return 0, err
+	}
+	if mount.ReadOnly {
+		return 0, fmt.Errorf("%w: cannot write to read-only mount", ErrReadOnly)
+	}
+	if strings.HasPrefix(mount.LocalPath, "__SKILL_MOUNT__:") {
+		return 0, errors.New("cannot apply patches to skill mounts")
+	}
+
+	original, err := fs.readText(path, session)
+	if err != nil {
+		return 0, err
+	}
+	patched, err := applyHunks(splitDiffLines(string(original)), hunks, options.Fuzz)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	data := []byte(joinDiffLines(patched))
+
+	if mount.LocalPath == "__VIRTUAL_MEMORY__" {
+		entryID, err := fs.memoryEntryID(path)
+		if err != nil {
+			return 0, err
+		}
+		// Metadata is kept; memory entries are replaced atomically
+		err = fs.memoryStore.Set(entryID, string(data), nil)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		err := writeFileAtomic(localPath, data)
+		fs.invalidateReadCache(localPath)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	sessionID := ""
+	if session != nil {
+		sessionID = session.ID
+	}
+	fs.TrackChange(path, "write", sessionID)
+	return int64(len(data)), nil
+}
+
+// Diff returns a unified diff that turns the text of pathA into the text of
+// pathB, or "" if they are identical. Paths may be files on local mounts or
+// memory entries.
+func (fs *ToolFS) Diff(pathA, pathB string) (string, error) {
+	return fs.DiffWithSession(pathA, pathB, nil)
+}
+
+// DiffWithSession returns a unified diff like Diff with session-based access
+// control. The session must be allowed to read both paths.
+func (fs *ToolFS) DiffWithSession(pathA, pathB string, session *Session) (string, error) {
+	end := fs.startSpan("Diff", pathA, session, "path_b", pathB)
+	var diff string
+	err := fs.runAudited(session, "Diff", pathA, func() (int64, int64, error) {
+		if err := fs.checkDenied(pathB); err != nil {
+			return 0, 0, err
+		}
+		if session != nil {
+			if err := session.checkAccess(pathB, false); err != nil {
+				return 0, 0, err
+			}
+		}
+		a, err := fs.readText(pathA, session)
+		if err != nil {
+			return 0, 0, err
+		}
+		b, err := fs.readText(pathB, session)
+		if err != nil {
+			return 0, 0, err
+		}
+		diff = unifiedDiff(pathA, pathB, splitDiffLines(string(a)), splitDiffLines(string(b)))
+		return int64(len(a) + len(b)), 0, nil
+	})
+	end(err)
+	return diff, err
+}
+
+// readText returns the text of a file, or the content of a memory entry
+func (fs *ToolFS) readText(path string, session *Session) ([]byte, error) {
+	_, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if mount.LocalPath == "__VIRTUAL_MEMORY__" {
+		entryID, err := fs.memoryEntryID(path)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := fs.memoryStore.Get(entryID)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(entry.Content), nil
+	}
+	return fs.readFileWithSession(path, session)
+}
+
+// writeFileAtomic replaces a local file by writing a temporary file in the
+// same directory and renaming it, keeping the original permissions
+func writeFileAtomic(localPath string, data []byte) error {
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(localPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), localPath); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// splitDiffLines splits text into lines without their newlines. A last line
+// without a trailing newline is marked with noEOL.
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += noEOL
+	}
+	return lines
+}
+
+// joinDiffLines reverses splitDiffLines
+func joinDiffLines(lines []string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if strings.HasSuffix(line, noEOL) {
+			b.WriteString(strings.TrimSuffix(line, noEOL))
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parseUnifiedDiff parses the hunks of a single-file unified diff. File
+// headers ("---", "+++", "diff", "index") are ignored.
+func parseUnifiedDiff(diff string) ([]diffHunk, error) {
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var hunks []diffHunk
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if !strings.HasPrefix(line, "@@") {
+			if strings.HasPrefix(line, "--- ") && len(hunks) > 0 {
+				return nil, errors.New("patch contains more than one file")
+			}
+			i++
+			continue
+		}
+
+		match := hunkHeaderPattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("malformed hunk header: %q", line)
+		}
+		h := diffHunk{
+			oldStart: atoiDefault(match[1], 0),
+			oldCount: atoiDefault(match[2], 1),
+			newStart: atoiDefault(match[3], 0),
+			newCount: atoiDefault(match[4], 1),
+		}
+		i++
+
+		oldSeen, newSeen := 0, 0
+		for i < len(lines) && (oldSeen < h.oldCount || newSeen < h.newCount) {
+			body := lines[i]
+			kind, text := byte(' '), ""
+			if body != "" { // Some tools strip the space of empty context lines
+				kind, text = body[0], body[1:]
+			}
+			switch kind {
+			case ' ':
+				oldSeen++
+				newSeen++
+			case '-':
+				oldSeen++
+			case '+':
+				newSeen++
+			case '\\':
+				if len(h.lines) > 0 {
+					h.lines[len(h.lines)-1].text += noEOL
+				}
+				i++
+				continue
+			default:
+				return nil, fmt.Errorf("malformed line in hunk %s: %q", h.header(), body)
+			}
+			h.lines = append(h.lines, diffLine{kind: kind, text: text})
+			i++
+		}
+		if oldSeen != h.oldCount || newSeen != h.newCount {
+			return nil, fmt.Errorf("hunk %s is truncated: found %d old and %d new lines", h.header(), oldSeen, newSeen)
+		}
+		// A final line without newline is followed by the marker
+		if i < len(lines) && strings.HasPrefix(lines[i], `\`) && len(h.lines) > 0 {
+			h.lines[len(h.lines)-1].text += noEOL
+			i++
+		}
+		hunks = append(hunks, h)
+	}
+
+	if len(hunks) == 0 {
+		return nil, errors.New("patch contains no hunks")
+	}
+	return hunks, nil
+}
+
+// atoiDefault parses s, returning def if s is empty
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// applyHunks applies hunks to lines. Each hunk must match at the position in
+// its header, or within fuzz lines of it.
+func applyHunks(lines []string, hunks []diffHunk, fuzz int) ([]string, error) {
+	result := make([]string, 0, len(lines))
+	pos := 0 // Next unconsumed line of the original
+
+	for n, h := range hunks {
+		var oldLines, newLines []string
+		for _, line := range h.lines {
+			if line.kind != '+' {
+				oldLines = append(oldLines, line.text)
+			}
+			if line.kind != '-' {
+				newLines = append(newLines, line.text)
+			}
+		}
+
+		// A hunk that removes nothing inserts after line oldStart
+		want := h.oldStart - 1
+		if h.oldCount == 0 {
+			want = h.oldStart
+		}
+
+		at, mismatch := -1, -1
+		for offset := 0; offset <= fuzz && at < 0; offset++ {
+			for _, candidate := range []int{want - offset, want + offset} {
+				if candidate < pos || (offset == 0 && candidate != want) {
+					continue
+				}
+				bad := mismatchAt(lines, candidate, oldLines)
+				if bad < 0 {
+					at = candidate
+					break
+				}
+				if offset == 0 {
+					mismatch = bad
+				}
+			}
+		}
+		if at < 0 {
+			if want < pos {
+				return nil, fmt.Errorf("%w: hunk %d (%s) overlaps the previous hunk", ErrPatchMismatch, n+1, h.header())
+			}
+			if mismatch < 0 {
+				mismatch = 0
+			}
+			found := "end of file"
+			if want+mismatch < len(lines) {
+				found = strconv.Quote(displayDiffLine(lines[want+mismatch]))
+			}
+			return nil, fmt.Errorf("%w: hunk %d (%s) expected %q at line %d, found %s",
+				ErrPatchMismatch, n+1, h.header(), displayDiffLine(oldLines[mismatch]), want+mismatch+1, found)
+		}
+
+		result = append(result, lines[pos:at]...)
+		result = append(result, newLines...)
+		pos = at + len(oldLines)
+	}
+
+	return append(result, lines[pos:]...), nil
+}
+
+// mismatchAt returns the index of the first line of want that differs from
+// lines starting at at, or -1 if all match
+func mismatchAt(lines []string, at int, want []string) int {
+	for i, line := range want {
+		if at+i >= len(lines) || lines[at+i] != line {
+			return i
+		}
+	}
+	return -1
+}
+
+// displayDiffLine returns a line for error messages
+func displayDiffLine(line string) string {
+	if strings.HasSuffix(line, noEOL) {
+		return strings.TrimSuffix(line, noEOL) + " (no newline at end of file)"
+	}
+	return line
+}
+
+// unifiedDiff formats the difference between a and b as a unified diff
+func unifiedDiff(nameA, nameB string, a, b []string) string {
+	ops := diffLines(a, b)
+
+	// Find the ops to show: each change plus diffContext lines around it
+	show := make([]bool, len(ops))
+	changed := false
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		changed = true
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(ops) {
+				show[j] = true
+			}
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+
+	oldLine, newLine := 0, 0 // Lines consumed before ops[i]
+	for i := 0; i < len(ops); {
+		if !show[i] {
+			oldLine, newLine = advance(ops[i], oldLine, newLine)
+			i++
+			continue
+		}
+
+		h := diffHunk{oldStart: oldLine, newStart: newLine}
+		for ; i < len(ops) && show[i]; i++ {
+			h.lines = append(h.lines, ops[i])
+			oldLine, newLine = advance(ops[i], oldLine, newLine)
+			if ops[i].kind != '+' {
+				h.oldCount++
+			}
+			if ops[i].kind != '-' {
+				h.newCount++
+			}
+		}
+		// Ranges start at 1 unless they are empty
+		if h.oldCount > 0 {
+			h.oldStart++
+		}
+		if h.newCount > 0 {
+			h.newStart++
+		}
+
+		out.WriteString(h.header() + "\n")
+		for _, line := range h.lines {
+			out.WriteByte(line.kind)
+			if strings.HasSuffix(line.text, noEOL) {
+				out.WriteString(strings.TrimSuffix(line.text, noEOL) + "\n" + noEOLMarker + "\n")
+			} else {
+				out.WriteString(line.text + "\n")
+			}
+		}
+	}
+	return out.String()
+}
+
+// advance returns the line counters after op
+func advance(op diffLine, oldLine, newLine int) (int, int) {
+	if op.kind != '+' {
+		oldLine++
+	}
+	if op.kind != '-' {
+		newLine++
+	}
+	return oldLine, newLine
+}
+
+// diffLines computes a shortest edit script from a to b with Myers' algorithm
+func diffLines(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	total := n + m
+	if total == 0 {
+		return nil
+	}
+
+	offset := total + 1
+	v := make([]int, 2*total+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= total; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Insertion
+			} else {
+				x = v[offset+k-1] + 1 // Deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back through the trace to recover the edits
+	var ops []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffLine{kind: ' ', text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffLine{kind: '+', text: b[y-1]})
+			} else {
+				ops = append(ops, diffLine{kind: '-', text: a[x-1]})
+			}
+			x, y = prevX, prevY
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,143 @@
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	original := "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"
+	fs.WriteFile("/toolfs/data/main.go", []byte(original))
+	os.Chmod(filepath.Join(tmpDir, "main.go"), 0o600)
+
+	patch := `--- a/main.go
++++ b/main.go
+@@ -2,4 +2,5 @@
+ 
+ func main() {
+-	println("hello")
++	println("hello, world")
++	println("bye")
+ }
+`
+	if err := fs.ApplyPatch("/toolfs/data/main.go", patch, nil); err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	data, _ := fs.ReadFile("/toolfs/data/main.go")
+	want := "package main\n\nfunc main() {\n\tprintln(\"hello, world\")\n\tprintln(\"bye\")\n}\n"
+	if string(data) != want {
+		t.Errorf("Unexpected patched content:\n%s", data)
+	}
+	if info, _ := os.Stat(filepath.Join(tmpDir, "main.go")); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected permissions to be kept, got %v", info.Mode().Perm())
+	}
+
+	// Applying the same patch again no longer matches, and nothing is written
+	err := fs.ApplyPatch("/toolfs/data/main.go", patch, nil)
+	if !errors.Is(err, ErrPatchMismatch) || !strings.Contains(err.Error(), "hunk 1") || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("Expected descriptive mismatch error, got %v", err)
+	}
+	if after, _ := fs.ReadFile("/toolfs/data/main.go"); string(after) != want {
+		t.Error("File changed after a rejected patch")
+	}
+
+	// Displaced hunks are rejected by default and accepted with fuzz
+	displaced := strings.Replace(patch, "@@ -2,4 +2,5 @@", "@@ -1,4 +1,5 @@", 1)
+	fs.WriteFile("/toolfs/data/main.go", []byte(original))
+	if err := fs.ApplyPatch("/toolfs/data/main.go", displaced, nil); !errors.Is(err, ErrPatchMismatch) {
+		t.Errorf("Expected displaced hunk to be rejected, got %v", err)
+	}
+	if err := fs.ApplyPatchWithOptions("/toolfs/data/main.go", displaced, PatchOptions{Fuzz: 1}, nil); err != nil {
+		t.Errorf("Expected displaced hunk to apply with fuzz, got %v", err)
+	}
+
+	// Memory entries can be patched; malformed patches and read-only targets are rejected
+	fs.WriteFile("/toolfs/memory/note", []byte("one\ntwo\n"))
+	if err := fs.ApplyPatch("/toolfs/memory/note", "@@ -2 +2 @@\n-two\n+three\n", nil); err != nil {
+		t.Fatalf("ApplyPatch on memory failed: %v", err)
+	}
+	if entry, _ := fs.memoryStore.Get("note"); entry.Content != "one\nthree\n" {
+		t.Errorf("Unexpected memory content %q", entry.Content)
+	}
+	if err := fs.ApplyPatch("/toolfs/memory/note", "no hunks here", nil); err == nil {
+		t.Error("Expected error for patch without hunks")
+	}
+	fs.MountLocal("/ro", tmpDir, true)
+	if err := fs.ApplyPatch("/toolfs/ro/test.txt", "@@ -1 +1 @@\n-Hello, ToolFS!\n+Hi\n", nil); err == nil {
+		t.Error("Expected error for read-only mount")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	fs.WriteFile("/toolfs/data/a.txt", []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"))
+	fs.WriteFile("/toolfs/data/b.txt", []byte("a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"))
+
+	diff, err := fs.Diff("/toolfs/data/a.txt", "/toolfs/data/b.txt")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	want := `--- /toolfs/data/a.txt
++++ /toolfs/data/b.txt
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+	if diff != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", diff, want)
+	}
+
+	if diff, _ := fs.Diff("/toolfs/data/a.txt", "/toolfs/data/a.txt"); diff != "" {
+		t.Errorf("Expected empty diff for identical files, got:\n%s", diff)
+	}
+
+	// Diffs round-trip through ApplyPatch, including missing final newlines
+	cases := [][2]string{
+		{"", "new\nfile\n"},
+		{"x\ny\n", ""},
+		{"same\nlast", "same\nlast\n"},
+		{"one\ntwo\n", "one\ntwo"},
+		{"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n", "0\n1\n2\n3\nfour\n5\n6\n7\n8\n9\nten\n11\n"},
+	}
+	for i, c := range cases {
+		fs.WriteFile("/toolfs/data/from.txt", []byte(c[0]))
+		fs.WriteFile("/toolfs/data/to.txt", []byte(c[1]))
+		diff, err := fs.Diff("/toolfs/data/from.txt", "/toolfs/data/to.txt")
+		if err != nil {
+			t.Fatalf("Case %d: Diff failed: %v", i, err)
+		}
+		if err := fs.ApplyPatch("/toolfs/data/from.txt", diff, nil); err != nil {
+			t.Fatalf("Case %d: ApplyPatch failed: %v\n%s", i, err, diff)
+		}
+		if data, _ := fs.ReadFile("/toolfs/data/from.txt"); string(data) != c[1] {
+			t.Errorf("Case %d: expected %q after round trip, got %q\n%s", i, c[1], data, diff)
+		}
+	}
+
+	// Sessions need access to both paths
+	session, _ := fs.NewSession("differ", []string{"/toolfs/data/a.txt"})
+	if _, err := fs.DiffWithSession("/toolfs/data/a.txt", "/toolfs/data/b.txt", session); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package toolfs
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PathDeniedError is returned, wrapping ErrAccessDenied, when a path matches a
+// global deny rule or one of a session's DeniedPaths. The rule is recorded in
+// the audit entry's DeniedBy field.
+type PathDeniedError struct {
+	Path      string
+	Rule      string // The deny rule or pattern that matched
+	SessionID string // Set for session DeniedPaths; empty for global deny rules
+}
+
+func (e *PathDeniedError) Error() string {
+	if e.SessionID != "" {
+		return fmt.Sprintf("%v: path '%s' is denied for session '%s' by rule '%s'", ErrAccessDenied, e.Path, e.SessionID, e.Rule)
+	}
+	return fmt.Sprintf("%v: path '%s' is denied by rule '%s'", ErrAccessDenied, e.Path, e.Rule)
+}
+
+func (e *PathDeniedError) Unwrap() error {
+	return ErrAccessDenied
+}
+
+// PathEscapeError is returned, wrapping ErrAccessDenied and ErrPathEscape,
+// for paths that lead outside their mount through ".." segments or, for
+// local mounts that do not follow symlinks, through a symlink
+type PathEscapeError struct {
+	Path    string
+	Symlink bool // The path escapes through a symlink
+}
+
+func (e *PathEscapeError) Error() string {
+	what := "path"
+	if e.Symlink {
+		what = "symlink"
+	}
+	if e.Path == "" {
+		return fmt.Sprintf("%v: %s escapes mount", ErrAccessDenied, what)
+	}
+	return fmt.Sprintf("%v: %s escapes mount: %s", ErrAccessDenied, what, e.Path)
+}
+
+func (e *PathEscapeError) Unwrap() []error {
+	return []error{ErrAccessDenied, ErrPathEscape}
+}
+
+// isGlobPattern reports whether rule contains glob metacharacters
+func isGlobPattern(rule string) bool {
+	return strings.ContainsAny(rule, "*?[")
+}
+
+// matchPathPattern matches p against a slash-separated glob pattern. Each
+// segment is matched with path.Match, except "**", which matches zero or more
+// whole segments.
+func matchPathPattern(pattern, p string) bool {
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(strings.Trim(p, "/"), "/"))
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(rest, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// matchPatternPrefix reports whether segments can be extended into a path
+// matched by the pattern segments
+func matchPatternPrefix(pattern, segments []string) bool {
+	for len(segments) > 0 {
+		if len(pattern) == 0 {
+			return false
+		}
+		if pattern[0] == "**" {
+			return true
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return true
+}
+
+// globMatchesPathOrAncestor reports whether pattern matches p or one of its
+// ancestors, so a pattern naming a directory covers everything below it
+func globMatchesPathOrAncestor(pattern, p string) bool {
+	for candidate := p; ; candidate = path.Dir(candidate) {
+		if matchPathPattern(pattern, candidate) {
+			return true
+		}
+		if candidate == "/" || candidate == "." {
+			return false
+		}
+	}
+}
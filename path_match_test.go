@@ -0,0 +1,97 @@
+package toolfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/toolfs/data/**", "/toolfs/data", true},
+		{"/toolfs/data/**", "/toolfs/data/a/b/c.txt", true},
+		{"/toolfs/data/**", "/toolfs/other/a", false},
+		{"/toolfs/**/*.key", "/toolfs/id.key", true},
+		{"/toolfs/**/*.key", "/toolfs/data/keys/id.key", true},
+		{"/toolfs/**/*.key", "/toolfs/data/keys/id.pub", false},
+		{"/toolfs/*/secret", "/toolfs/data/secret", true},
+		{"/toolfs/*/secret", "/toolfs/data/nested/secret", false},
+		{"/toolfs/data/file?.txt", "/toolfs/data/file1.txt", true},
+		{"/toolfs/data/[ab].txt", "/toolfs/data/c.txt", false},
+	}
+	for _, tt := range tests {
+		if got := matchPathPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSessionDeniedPaths(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	logger := &TestAuditLogger{}
+	fs.SetAuditLogger(logger)
+
+	session, _ := fs.NewSession("glob", []string{"/toolfs/data/**"})
+	session.SetDeniedPaths([]string{"/toolfs/data/subdir/**"})
+
+	if !session.IsReadAllowed("/toolfs/data/test.txt") {
+		t.Error("Expected glob allow to match /toolfs/data/test.txt")
+	}
+	if session.IsReadAllowed("/toolfs/data/subdir/subfile.txt") || session.IsWriteAllowed("/toolfs/data/subdir/subfile.txt") {
+		t.Error("Expected DeniedPaths to override the allow list")
+	}
+
+	if _, err := fs.ReadFileWithSession("/toolfs/data/test.txt", session); err != nil {
+		t.Fatalf("Expected allowed read to work: %v", err)
+	}
+	if _, err := fs.ListDirWithSession("/toolfs/data", session); err != nil {
+		t.Fatalf("Expected listing the allowed root to work: %v", err)
+	}
+
+	logger.Entries = nil
+	_, err := fs.ReadFileWithSession("/toolfs/data/subdir/subfile.txt", session)
+	var deniedErr *PathDeniedError
+	if !errors.Is(err, ErrAccessDenied) || !errors.As(err, &deniedErr) {
+		t.Fatalf("Expected PathDeniedError wrapping ErrAccessDenied, got %v", err)
+	}
+	if deniedErr.Rule != "/toolfs/data/subdir/**" || deniedErr.SessionID != "glob" {
+		t.Errorf("Unexpected denial details: %+v", deniedErr)
+	}
+	if len(logger.Entries) != 1 || !logger.Entries[0].AccessDenied || logger.Entries[0].DeniedBy != "/toolfs/data/subdir/**" {
+		t.Errorf("Expected audit entry to record the deny rule, got %+v", logger.Entries)
+	}
+
+	if err := fs.WriteFileWithSession("/toolfs/data/subdir/new.txt", []byte("x"), session); !errors.As(err, &deniedErr) {
+		t.Errorf("Expected denied write, got %v", err)
+	}
+	if err := fs.MoveWithSession("/toolfs/data/test.txt", "/toolfs/data/subdir/moved.txt", session); !errors.As(err, &deniedErr) {
+		t.Errorf("Expected move into a denied path to fail, got %v", err)
+	}
+
+	// Recursive listings leave out denied entries
+	entries, err := fs.ListDirRecursiveWithSession("/toolfs/data", 0, session)
+	if err != nil {
+		t.Fatalf("ListDirRecursiveWithSession failed: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.RelPath == "subdir" || entry.RelPath == "subdir/subfile.txt" {
+			t.Errorf("Expected denied entry %s to be hidden", entry.RelPath)
+		}
+	}
+
+	// Global deny rules report their rule too
+	fs.SetDenyRules([]string{"/toolfs/**/test.txt"})
+	logger.Entries = nil
+	if _, err := fs.ReadFileWithSession("/toolfs/data/test.txt", session); !errors.As(err, &deniedErr) || deniedErr.SessionID != "" {
+		t.Errorf("Expected global PathDeniedError, got %v", err)
+	}
+	if len(logger.Entries) != 1 || logger.Entries[0].DeniedBy != "/toolfs/**/test.txt" {
+		t.Errorf("Expected audit entry to record the global rule, got %+v", logger.Entries)
+	}
+}
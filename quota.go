@@ -0,0 +1,75 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrQuotaExceeded is wrapped by errors returned for operations on a session
+// that has used up its quota
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quota limits the resources a session may use (see Session.SetQuota). Zero
+// fields are unlimited. Session.Usage reports consumption in the same form.
+type Quota struct {
+	MaxBytesWritten int64 `json:"max_bytes_written,omitempty"`
+	MaxBytesRead    int64 `json:"max_bytes_read,omitempty"`
+	MaxOperations   int64 `json:"max_operations,omitempty"`
+}
+
+// SetQuota limits the session's operations. The operation that reaches a
+// limit completes; later operations fail with ErrQuotaExceeded: every
+// operation once MaxOperations is reached, writes once MaxBytesWritten is
+// reached and other operations once MaxBytesRead is reached. Usage so far
+// counts towards the new quota.
+func (s *Session) SetQuota(quota Quota) {
+	s.quotaMu.Lock()
+	s.quota = quota
+	s.quotaMu.Unlock()
+}
+
+// Usage returns the bytes read and written and the number of operations
+// performed by the session so far
+func (s *Session) Usage() Quota {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	return s.usage
+}
+
+// checkQuota fails if the session may not perform op within its quota
+func (s *Session) checkQuota(op string) error {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	switch {
+	case s.quota.MaxOperations > 0 && s.usage.MaxOperations >= s.quota.MaxOperations:
+		return fmt.Errorf("%w: session '%s' reached its limit of %d operations", ErrQuotaExceeded, s.ID, s.quota.MaxOperations)
+	case isWriteOp(op) && s.quota.MaxBytesWritten > 0 && s.usage.MaxBytesWritten >= s.quota.MaxBytesWritten:
+		return fmt.Errorf("%w: session '%s' reached its limit of %d bytes written", ErrQuotaExceeded, s.ID, s.quota.MaxBytesWritten)
+	case !isWriteOp(op) && s.quota.MaxBytesRead > 0 && s.usage.MaxBytesRead >= s.quota.MaxBytesRead:
+		return fmt.Errorf("%w: session '%s' reached its limit of %d bytes read", ErrQuotaExceeded, s.ID, s.quota.MaxBytesRead)
+	}
+	return nil
+}
+
+// addUsage counts an operation and the bytes it transferred. Operations
+// rejected by the quota are not counted.
+func (s *Session) addUsage(err error, bytesRead, bytesWritten int64) {
+	if errors.Is(err, ErrQuotaExceeded) {
+		return
+	}
+	s.quotaMu.Lock()
+	s.usage.MaxOperations++
+	s.usage.MaxBytesRead += bytesRead
+	s.usage.MaxBytesWritten += bytesWritten
+	s.quotaMu.Unlock()
+}
+
+// addBytes counts bytes transferred on behalf of an operation that is
+// counted by addUsage elsewhere
+func (s *Session) addBytes(bytesRead, bytesWritten int64) {
+	s.quotaMu.Lock()
+	s.usage.MaxBytesRead += bytesRead
+	s.usage.MaxBytesWritten += bytesWritten
+	s.quotaMu.Unlock()
+}
@@ -0,0 +1,123 @@
+package toolfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSessionQuota(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	session, _ := fs.NewSession("quota-session", []string{"/toolfs/data"})
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+	session.SetQuota(Quota{MaxBytesRead: 20, MaxBytesWritten: 10})
+
+	// "Hello, ToolFS!" is 14 bytes; the read crossing the limit succeeds
+	for i := 0; i < 2; i++ {
+		if _, err := fs.ReadFileWithSession("/toolfs/data/test.txt", session); err != nil {
+			t.Fatalf("Read %d failed: %v", i, err)
+		}
+	}
+	_, err := fs.ReadFileWithSession("/toolfs/data/test.txt", session)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	last := logger.Entries[len(logger.Entries)-1]
+	if last.Success || !last.QuotaExceeded {
+		t.Errorf("Expected failed audit entry marked quota_exceeded, got %+v", last)
+	}
+	if _, err := fs.StatWithSession("/toolfs/data/test.txt", session); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected reads to be blocked, got %v", err)
+	}
+
+	// Writes have their own limit
+	if err := fs.WriteFileWithSession("/toolfs/data/a.txt", []byte("12345678"), session); err != nil {
+		t.Errorf("Write failed: %v", err)
+	}
+	if err := fs.WriteFileWithSession("/toolfs/data/b.txt", []byte("123"), session); err != nil {
+		t.Errorf("Write crossing the limit failed: %v", err)
+	}
+	if err := fs.WriteFileWithSession("/toolfs/data/c.txt", []byte("1"), session); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected write quota to be exceeded, got %v", err)
+	}
+
+	usage := session.Usage()
+	if usage.MaxBytesRead != 28 || usage.MaxBytesWritten != 11 || usage.MaxOperations != 4 {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+
+	// Raising the quota allows further operations
+	session.SetQuota(Quota{MaxOperations: 5})
+	if _, err := fs.ReadFileWithSession("/toolfs/data/test.txt", session); err != nil {
+		t.Errorf("Expected read after raising the quota, got %v", err)
+	}
+	if _, err := fs.ListDirWithSession("/toolfs/data", session); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected operation quota to be exceeded, got %v", err)
+	}
+	if err := fs.WriteFileWithSession("/toolfs/data/d.txt", []byte("1"), session); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected operation quota to apply to writes, got %v", err)
+	}
+}
+
+func TestSessionQuotaCommands(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	session, _ := fs.NewSession("command-quota", []string{"/toolfs/data"})
+	session.SetAuditLogger(nil)
+	session.SetQuota(Quota{MaxOperations: 1})
+
+	if _, err := fs.ExecuteCommandWithSession("/toolfs/data", "ls", nil, session); err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	if _, err := fs.ExecuteCommandWithSession("/toolfs/data", "ls", nil, session); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if usage := session.Usage(); usage.MaxOperations != 1 || usage.MaxBytesRead == 0 {
+		t.Errorf("Expected command to be counted without an audit logger, got %+v", usage)
+	}
+}
+
+func TestSessionQuotaTwoPathOperations(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	session, _ := fs.NewSession("two-path-quota", []string{"/toolfs/data"})
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+	session.SetQuota(Quota{MaxOperations: 2})
+
+	// Copy and Move log both paths but count once each
+	if err := fs.CopyWithSession("/toolfs/data/test.txt", "/toolfs/data/copy.txt", session); err != nil {
+		t.Fatalf("CopyWithSession failed: %v", err)
+	}
+	if usage := session.Usage(); usage.MaxOperations != 1 || usage.MaxBytesRead != 14 || usage.MaxBytesWritten != 14 {
+		t.Errorf("Expected the copy to count once with its bytes, got %+v", usage)
+	}
+	if err := fs.MoveWithSession("/toolfs/data/copy.txt", "/toolfs/data/moved.txt", session); err != nil {
+		t.Fatalf("MoveWithSession failed: %v", err)
+	}
+	if usage := session.Usage(); usage.MaxOperations != 2 {
+		t.Errorf("Expected the move to count once, got %+v", usage)
+	}
+
+	var ops []string
+	for _, entry := range logger.Entries {
+		ops = append(ops, entry.Operation)
+	}
+	if len(ops) != 4 || ops[0] != "CopyTo" || ops[1] != "Copy" || ops[2] != "MoveTo" || ops[3] != "Move" {
+		t.Errorf("Expected entries for both sides of each operation, got %v", ops)
+	}
+
+	if err := fs.CopyWithSession("/toolfs/data/test.txt", "/toolfs/data/again.txt", session); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+}
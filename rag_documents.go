@@ -0,0 +1,165 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Defaults for paging through RAG documents
+const (
+	DefaultRAGPageLimit     = 50
+	MaxRAGPageLimit         = 1000
+	DefaultRAGSnippetLength = 100
+)
+
+// RAGListOptions selects a page of RAG documents. Metadata filters match
+// documents whose metadata value, formatted as a string, equals the filter
+// value; all filters must match.
+type RAGListOptions struct {
+	Offset        int
+	Limit         int               // 0 = DefaultRAGPageLimit
+	Metadata      map[string]string // Metadata key -> required value
+	SnippetLength int               // Characters of content per summary (0 = DefaultRAGSnippetLength)
+}
+
+// RAGDocumentSummary describes a document in a listing without its full content
+type RAGDocumentSummary struct {
+	ID       string                 `json:"id"`
+	Snippet  string                 `json:"snippet"`
+	Length   int                    `json:"length"` // Full content length in characters
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// RAGDocumentPage is one page of a RAG document listing. Total counts every
+// document matching the filters, not just those on the page.
+type RAGDocumentPage struct {
+	Offset    int                  `json:"offset"`
+	Limit     int                  `json:"limit"`
+	Total     int                  `json:"total"`
+	HasMore   bool                 `json:"has_more"`
+	Documents []RAGDocumentSummary `json:"documents"`
+}
+
+// RAGDocumentLister is implemented by RAG stores whose documents can be
+// browsed. Listing /toolfs/rag/documents requires it.
+type RAGDocumentLister interface {
+	ListDocumentsPage(opts RAGListOptions) (*RAGDocumentPage, error)
+}
+
+// ListDocumentsPage returns a page of documents in insertion order
+func (s *InMemoryRAGStore) ListDocumentsPage(opts RAGListOptions) (*RAGDocumentPage, error) {
+	opts, err := normalizeRAGListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	page := &RAGDocumentPage{
+		Offset:    opts.Offset,
+		Limit:     opts.Limit,
+		Documents: []RAGDocumentSummary{},
+	}
+	for _, doc := range s.documents {
+		if !ragMetadataMatches(doc.Metadata, opts.Metadata) {
+			continue
+		}
+		if page.Total >= opts.Offset && len(page.Documents) < opts.Limit {
+			page.Documents = append(page.Documents, summarizeRAGDocument(doc, opts.SnippetLength))
+		}
+		page.Total++
+	}
+	page.HasMore = opts.Offset+len(page.Documents) < page.Total
+	return page, nil
+}
+
+// normalizeRAGListOptions validates opts and fills in defaults
+func normalizeRAGListOptions(opts RAGListOptions) (RAGListOptions, error) {
+	if opts.Offset < 0 {
+		return opts, errors.New("offset cannot be negative")
+	}
+	if opts.Limit < 0 {
+		return opts, errors.New("limit cannot be negative")
+	}
+	if opts.Limit == 0 {
+		opts.Limit = DefaultRAGPageLimit
+	}
+	if opts.Limit > MaxRAGPageLimit {
+		opts.Limit = MaxRAGPageLimit
+	}
+	if opts.SnippetLength <= 0 {
+		opts.SnippetLength = DefaultRAGSnippetLength
+	}
+	return opts, nil
+}
+
+// ragMetadataMatches reports whether metadata satisfies every filter
+func ragMetadataMatches(metadata map[string]interface{}, filters map[string]string) bool {
+	for key, want := range filters {
+		value, ok := metadata[key]
+		if !ok || fmt.Sprint(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// summarizeRAGDocument builds a listing entry with the first snippetLength
+// characters of the document content
+func summarizeRAGDocument(doc RAGDocument, snippetLength int) RAGDocumentSummary {
+	content := []rune(doc.Content)
+	snippet := doc.Content
+	if len(content) > snippetLength {
+		snippet = string(content[:snippetLength])
+	}
+	return RAGDocumentSummary{
+		ID:       doc.ID,
+		Snippet:  snippet,
+		Length:   len(content),
+		Metadata: doc.Metadata,
+	}
+}
+
+// listRAGDocuments serves /toolfs/rag/documents?offset=&limit=&snippet=&<key>=<value>.
+// Parameters other than offset, limit and snippet filter on metadata.
+func (fs *ToolFS) listRAGDocuments(rawQuery string) (*RAGDocumentPage, error) {
+	lister, ok := fs.ragStore.(RAGDocumentLister)
+	if !ok {
+		return nil, errors.New("RAG store does not support listing documents")
+	}
+
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, errors.New("invalid RAG documents query format")
+	}
+
+	var opts RAGListOptions
+	for key, values := range params {
+		value := values[0]
+		switch key {
+		case "offset", "limit", "snippet":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s parameter", key)
+			}
+			switch key {
+			case "offset":
+				opts.Offset = n
+			case "limit":
+				opts.Limit = n
+			default:
+				opts.SnippetLength = n
+			}
+		default:
+			if opts.Metadata == nil {
+				opts.Metadata = make(map[string]string)
+			}
+			opts.Metadata[key] = value
+		}
+	}
+
+	return lister.ListDocumentsPage(opts)
+}
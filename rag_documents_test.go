@@ -0,0 +1,112 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newSyntheticRAGStore builds a store of n documents alternating between the
+// "AI" and "Search" topics
+func newSyntheticRAGStore(n int) *InMemoryRAGStore {
+	store := &InMemoryRAGStore{}
+	for i := 0; i < n; i++ {
+		topic := "AI"
+		if i%2 == 1 {
+			topic = "Search"
+		}
+		store.documents = append(store.documents, RAGDocument{
+			ID:       fmt.Sprintf("doc%03d", i),
+			Content:  fmt.Sprintf("Document %d about %s. %s", i, topic, strings.Repeat("filler ", 40)),
+			Metadata: map[string]interface{}{"topic": topic, "index": i},
+		})
+	}
+	return store
+}
+
+// readRAGPage reads and decodes a document listing page
+func readRAGPage(t *testing.T, fs *ToolFS, path string) RAGDocumentPage {
+	t.Helper()
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) failed: %v", path, err)
+	}
+	var page RAGDocumentPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		t.Fatalf("Failed to decode page: %v", err)
+	}
+	return page
+}
+
+func TestRAGDocumentsPagination(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	fs.SetRAGStore(newSyntheticRAGStore(200))
+
+	// Walk the whole corpus in pages of 50
+	seen := make(map[string]bool)
+	offset := 0
+	for pages := 0; ; pages++ {
+		page := readRAGPage(t, fs, fmt.Sprintf("/toolfs/rag/documents?offset=%d&limit=50", offset))
+		if page.Total != 200 {
+			t.Fatalf("Expected total 200, got %d", page.Total)
+		}
+		for _, doc := range page.Documents {
+			seen[doc.ID] = true
+			if len([]rune(doc.Snippet)) > DefaultRAGSnippetLength || doc.Length <= len(doc.Snippet) {
+				t.Errorf("Expected truncated snippet for %s, got %d of %d chars", doc.ID, len(doc.Snippet), doc.Length)
+			}
+		}
+		offset += len(page.Documents)
+		if !page.HasMore {
+			if pages != 3 {
+				t.Errorf("Expected 4 pages, got %d", pages+1)
+			}
+			break
+		}
+	}
+	if len(seen) != 200 {
+		t.Errorf("Expected 200 distinct documents, got %d", len(seen))
+	}
+
+	// Defaults and the end of the corpus
+	if page := readRAGPage(t, fs, "/toolfs/rag/documents"); len(page.Documents) != DefaultRAGPageLimit || !page.HasMore {
+		t.Errorf("Expected default page of %d with more, got %d", DefaultRAGPageLimit, len(page.Documents))
+	}
+	if page := readRAGPage(t, fs, "/toolfs/rag/documents?offset=500"); len(page.Documents) != 0 || page.HasMore {
+		t.Errorf("Expected empty final page, got %+v", page)
+	}
+
+	if _, err := fs.ReadFile("/toolfs/rag/documents?limit=-1"); err == nil {
+		t.Error("Expected error for negative limit")
+	}
+}
+
+func TestRAGDocumentsFilter(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	fs.SetRAGStore(newSyntheticRAGStore(200))
+
+	page := readRAGPage(t, fs, "/toolfs/rag/documents?offset=90&limit=20&topic=AI&snippet=12")
+	if page.Total != 100 || len(page.Documents) != 10 || page.HasMore {
+		t.Fatalf("Expected last 10 of 100 AI documents, got total %d, %d docs, more %v", page.Total, len(page.Documents), page.HasMore)
+	}
+	for _, doc := range page.Documents {
+		if doc.Metadata["topic"] != "AI" {
+			t.Errorf("Expected only AI documents, got %v", doc.Metadata)
+		}
+		if len(doc.Snippet) != 12 {
+			t.Errorf("Expected 12-char snippet, got %q", doc.Snippet)
+		}
+	}
+
+	// Non-string metadata is compared in its formatted form
+	page = readRAGPage(t, fs, "/toolfs/rag/documents?index=7")
+	if page.Total != 1 || page.Documents[0].ID != "doc007" {
+		t.Errorf("Expected doc007, got %+v", page)
+	}
+
+	entries, _ := fs.ListDir("/toolfs/rag")
+	if strings.Join(entries, ",") != "query,documents" {
+		t.Errorf("Expected query and documents entries, got %v", entries)
+	}
+}
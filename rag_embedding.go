@@ -0,0 +1,179 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// EmbeddingFunc converts text into an embedding vector for semantic search
+type EmbeddingFunc func(text string) ([]float32, error)
+
+// SetEmbeddingFunc makes Search rank documents by the cosine similarity of
+// their embeddings to the query's embedding. Embeddings of existing documents
+// are computed now and those of later documents when they are added. A nil
+// function restores keyword scoring.
+func (s *InMemoryRAGStore) SetEmbeddingFunc(embed EmbeddingFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var embeddings [][]float32
+	if embed != nil {
+		embeddings = make([][]float32, len(s.documents))
+		for i, doc := range s.documents {
+			vector, err := embed(doc.Content)
+			if err != nil {
+				return fmt.Errorf("failed to embed document '%s': %w", doc.ID, err)
+			}
+			embeddings[i] = vector
+		}
+	}
+	s.embed = embed
+	s.embeddings = embeddings
+	return nil
+}
+
+// AddDocument adds a document to the store, computing its embedding when an
+// embedding function is set
+func (s *InMemoryRAGStore) AddDocument(doc RAGDocument) error {
+	if doc.ID == "" {
+		return errors.New("document ID cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.documents {
+		if existing.ID == doc.ID {
+			return fmt.Errorf("document '%s' already exists", doc.ID)
+		}
+	}
+
+	if s.embed != nil {
+		vector, err := s.embed(doc.Content)
+		if err != nil {
+			return fmt.Errorf("failed to embed document '%s': %w", doc.ID, err)
+		}
+		s.embeddings = append(s.embeddings, vector)
+	}
+	s.documents = append(s.documents, doc)
+	return nil
+}
+
+// searchEmbeddings returns the topK documents most similar to the query.
+// The caller holds s.mu.
+func (s *InMemoryRAGStore) searchEmbeddings(query string, topK int) ([]RAGResult, error) {
+	queryVector, err := s.embed(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	results := make([]RAGResult, 0, len(s.documents))
+	for i, doc := range s.documents {
+		results = append(results, RAGResult{
+			ID:       doc.ID,
+			Content:  doc.Content,
+			Score:    cosineSimilarity(queryVector, s.embeddings[i]),
+			Metadata: doc.Metadata,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// Metadata keys holding the sub-scores of hybrid search results
+const (
+	RAGKeywordScoreKey = "keyword_score"
+	RAGVectorScoreKey  = "vector_score"
+)
+
+// SetHybridWeight makes Search combine keyword and embedding scores as
+// weight*cosine + (1-weight)*keyword, so 0 ranks by keywords alone and 1 by
+// embeddings alone. Results carry both sub-scores in their metadata under
+// RAGKeywordScoreKey and RAGVectorScoreKey. Hybrid scoring needs an
+// embedding function; without one Search keeps scoring keywords. Until a
+// weight is set, a store with an embedding function ranks by embeddings.
+func (s *InMemoryRAGStore) SetHybridWeight(weight float64) error {
+	if math.IsNaN(weight) || weight < 0 || weight > 1 {
+		return fmt.Errorf("hybrid weight must be between 0 and 1, got %v", weight)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hybrid = true
+	s.hybridWeight = weight
+	return nil
+}
+
+// HybridWeight returns the weight set with SetHybridWeight and whether one
+// is set
+func (s *InMemoryRAGStore) HybridWeight() (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hybridWeight, s.hybrid
+}
+
+// searchHybrid returns the topK documents with the highest weighted sum of
+// keyword and embedding scores, leaving out documents scoring 0 or less.
+// The caller holds s.mu.
+func (s *InMemoryRAGStore) searchHybrid(query string, topK int) ([]RAGResult, error) {
+	queryVector, err := s.embed(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	queryWords := strings.Fields(strings.ToLower(query))
+
+	results := make([]RAGResult, 0, len(s.documents))
+	for i, doc := range s.documents {
+		keyword := keywordScore(queryWords, doc.Content)
+		vector := cosineSimilarity(queryVector, s.embeddings[i])
+		score := s.hybridWeight*vector + (1-s.hybridWeight)*keyword
+		if score <= 0 {
+			continue
+		}
+
+		// Copy the metadata so the sub-scores do not leak into the document
+		metadata := make(map[string]interface{}, len(doc.Metadata)+2)
+		for key, value := range doc.Metadata {
+			metadata[key] = value
+		}
+		metadata[RAGKeywordScoreKey] = keyword
+		metadata[RAGVectorScoreKey] = vector
+
+		results = append(results, RAGResult{
+			ID:       doc.ID,
+			Content:  doc.Content,
+			Score:    score,
+			Metadata: metadata,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// their lengths differ or either is a zero vector
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
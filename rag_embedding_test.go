@@ -0,0 +1,180 @@
+package toolfs
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// keywordEmbedder embeds text as counts of a fixed vocabulary and records
+// every text it embeds
+type keywordEmbedder struct {
+	vocabulary []string
+	calls      []string
+}
+
+func (e *keywordEmbedder) Embed(text string) ([]float32, error) {
+	e.calls = append(e.calls, text)
+	text = strings.ToLower(text)
+	vector := make([]float32, len(e.vocabulary))
+	for i, word := range e.vocabulary {
+		vector[i] = float32(strings.Count(text, word))
+	}
+	return vector, nil
+}
+
+func TestRAGEmbeddingSearch(t *testing.T) {
+	store := &InMemoryRAGStore{}
+	embedder := &keywordEmbedder{vocabulary: []string{"cat", "dog", "fish"}}
+	if err := store.AddDocument(RAGDocument{ID: "cats", Content: "cat cat cat"}); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	if err := store.SetEmbeddingFunc(embedder.Embed); err != nil {
+		t.Fatalf("SetEmbeddingFunc failed: %v", err)
+	}
+	if err := store.AddDocument(RAGDocument{ID: "dogs", Content: "dog dog and a cat"}); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	if err := store.AddDocument(RAGDocument{ID: "fish", Content: "fish"}); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	if len(embedder.calls) != 3 {
+		t.Fatalf("Expected 3 document embeddings, got %d", len(embedder.calls))
+	}
+
+	results, err := store.Search("dogs", 2)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "dogs" || results[1].ID != "cats" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("Expected descending scores, got %+v", results)
+	}
+
+	// Only the query is embedded; document embeddings are cached
+	if len(embedder.calls) != 4 || embedder.calls[3] != "dogs" {
+		t.Errorf("Expected only the query to be embedded, got %v", embedder.calls)
+	}
+
+	// Removing the function restores keyword scoring
+	if err := store.SetEmbeddingFunc(nil); err != nil {
+		t.Fatalf("SetEmbeddingFunc(nil) failed: %v", err)
+	}
+	results, err = store.Search("fish", 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "fish" {
+		t.Errorf("Expected keyword match on 'fish', got %+v", results)
+	}
+}
+
+func TestRAGEmbeddingErrors(t *testing.T) {
+	failing := func(text string) ([]float32, error) {
+		return nil, errors.New("embedding service down")
+	}
+
+	store := &InMemoryRAGStore{}
+	if err := store.AddDocument(RAGDocument{ID: "a", Content: "alpha"}); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	if err := store.AddDocument(RAGDocument{ID: "a", Content: "again"}); err == nil {
+		t.Error("Expected error for duplicate document ID")
+	}
+	if err := store.SetEmbeddingFunc(failing); err == nil {
+		t.Fatal("Expected SetEmbeddingFunc to fail")
+	}
+
+	// A failed SetEmbeddingFunc leaves keyword scoring in place
+	results, err := store.Search("alpha", 5)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Expected keyword search to still work, got %+v, %v", results, err)
+	}
+}
+
+func TestRAGHybridSearch(t *testing.T) {
+	store := &InMemoryRAGStore{}
+	embedder := &keywordEmbedder{vocabulary: []string{"cat", "dog", "fish"}}
+	store.AddDocument(RAGDocument{ID: "cats", Content: "cat cat cat", Metadata: map[string]interface{}{"topic": "cats"}})
+	store.AddDocument(RAGDocument{ID: "dogs", Content: "dog dog and a cat"})
+	store.AddDocument(RAGDocument{ID: "fish", Content: "fish"})
+
+	// Without an embedding function the weight has no effect
+	if err := store.SetHybridWeight(0.5); err != nil {
+		t.Fatalf("SetHybridWeight failed: %v", err)
+	}
+	results, _ := store.Search("cat", 5)
+	if len(results) != 2 || results[0].Metadata[RAGKeywordScoreKey] != nil {
+		t.Fatalf("Expected plain keyword results, got %+v", results)
+	}
+
+	if err := store.SetEmbeddingFunc(embedder.Embed); err != nil {
+		t.Fatalf("SetEmbeddingFunc failed: %v", err)
+	}
+
+	// Both documents fully match the keyword "cat", but "dogs" is mostly
+	// about dogs, so its vector score ranks it lower
+	results, err := store.Search("cat", 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "cats" || results[1].ID != "dogs" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	dogs := results[1]
+	keyword, _ := dogs.Metadata[RAGKeywordScoreKey].(float64)
+	vector, _ := dogs.Metadata[RAGVectorScoreKey].(float64)
+	if keyword != 1 || vector <= 0 || vector >= 1 {
+		t.Errorf("Unexpected sub-scores %+v", dogs.Metadata)
+	}
+	if want := 0.5*vector + 0.5*keyword; dogs.Score != want {
+		t.Errorf("Expected score %v, got %v", want, dogs.Score)
+	}
+	if results[0].Metadata["topic"] != "cats" {
+		t.Errorf("Expected document metadata to be kept, got %+v", results[0].Metadata)
+	}
+	if doc := store.documents[0]; len(doc.Metadata) != 1 {
+		t.Errorf("Sub-scores leaked into the document metadata: %+v", doc.Metadata)
+	}
+
+	// Weight 0 ranks by keywords only
+	store.SetHybridWeight(0)
+	results, _ = store.Search("cat", 5)
+	if len(results) != 2 || results[0].Score != 1 || results[1].Score != 1 {
+		t.Errorf("Expected keyword scores, got %+v", results)
+	}
+
+	// Weight 1 ranks by embeddings only
+	store.SetHybridWeight(1)
+	results, _ = store.Search("fish", 5)
+	if len(results) != 1 || results[0].ID != "fish" || results[0].Score < 0.999 {
+		t.Errorf("Expected vector scores, got %+v", results)
+	}
+
+	for _, weight := range []float64{-0.1, 1.5, math.NaN()} {
+		if err := store.SetHybridWeight(weight); err == nil {
+			t.Errorf("Expected error for weight %v", weight)
+		}
+	}
+	if weight, ok := store.HybridWeight(); !ok || weight != 1 {
+		t.Errorf("Expected invalid weights to be ignored, got %v %v", weight, ok)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := cosineSimilarity([]float32{1, 0}, []float32{2, 0}); got < 0.999 {
+		t.Errorf("Expected parallel vectors to score 1, got %f", got)
+	}
+	if got := cosineSimilarity([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("Expected orthogonal vectors to score 0, got %f", got)
+	}
+	if got := cosineSimilarity([]float32{1}, []float32{1, 1}); got != 0 {
+		t.Errorf("Expected mismatched lengths to score 0, got %f", got)
+	}
+	if got := cosineSimilarity([]float32{0, 0}, []float32{1, 1}); got != 0 {
+		t.Errorf("Expected zero vector to score 0, got %f", got)
+	}
+}
@@ -0,0 +1,130 @@
+package toolfs
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RAGQueryFilter narrows RAG query results. Metadata filters match like
+// RAGListOptions.Metadata: the metadata value, formatted as a string, must
+// equal the filter value.
+type RAGQueryFilter struct {
+	MinScore float64           // Results scoring below this are dropped (0 = no threshold)
+	Metadata map[string]string // Metadata key -> required value
+}
+
+// active reports whether the filter drops anything
+func (f RAGQueryFilter) active() bool {
+	return f.MinScore != 0 || len(f.Metadata) > 0
+}
+
+// Apply returns the results that pass the filter, keeping their order
+func (f RAGQueryFilter) Apply(results []RAGResult) []RAGResult {
+	if !f.active() {
+		return results
+	}
+	kept := make([]RAGResult, 0, len(results))
+	for _, result := range results {
+		if result.Score < f.MinScore || !ragMetadataMatches(result.Metadata, f.Metadata) {
+			continue
+		}
+		kept = append(kept, result)
+	}
+	return kept
+}
+
+// parseRAGQueryFilter reads the min_score and filter.<key>=<value> parameters
+// of a RAG query
+func parseRAGQueryFilter(params url.Values) (RAGQueryFilter, error) {
+	var filter RAGQueryFilter
+	if minScore := params.Get("min_score"); minScore != "" {
+		score, err := strconv.ParseFloat(minScore, 64)
+		if err != nil || math.IsNaN(score) || math.IsInf(score, 0) {
+			return filter, fmt.Errorf("invalid min_score parameter: %s", minScore)
+		}
+		filter.MinScore = score
+	}
+
+	for param, values := range params {
+		key, ok := strings.CutPrefix(param, "filter.")
+		if !ok {
+			continue
+		}
+		if key == "" {
+			return filter, fmt.Errorf("invalid filter parameter '%s': missing metadata key", param)
+		}
+		if len(values) > 1 {
+			return filter, fmt.Errorf("invalid filter parameter '%s': multiple values", param)
+		}
+		if values[0] == "" {
+			return filter, fmt.Errorf("invalid filter parameter '%s': missing value", param)
+		}
+		if filter.Metadata == nil {
+			filter.Metadata = make(map[string]string)
+		}
+		filter.Metadata[key] = values[0]
+	}
+	return filter, nil
+}
+
+// ragSearchLimit returns how many results to request from the store so that
+// topK results survive metadata filtering. Stores that can list their
+// documents are searched in full; others are asked for topK.
+func (fs *ToolFS) ragSearchLimit(topK int, filter RAGQueryFilter) int {
+	if len(filter.Metadata) == 0 {
+		return topK
+	}
+	lister, ok := fs.ragStore.(RAGDocumentLister)
+	if !ok {
+		return topK
+	}
+	page, err := lister.ListDocumentsPage(RAGListOptions{Limit: 1})
+	if err != nil || page.Total <= topK {
+		return topK
+	}
+	return page.Total
+}
+
+// topRAGResults returns the topK highest scoring results. Stores only order
+// the results they truncate, so results are sorted before cutting them down.
+func topRAGResults(results []RAGResult, topK int) []RAGResult {
+	if len(results) <= topK {
+		return results
+	}
+	sortRAGResults(results)
+	return results[:topK]
+}
+
+// sortRAGResults sorts results by descending score, keeping the store's
+// order for equal scores
+func sortRAGResults(results []RAGResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+}
+
+// parseRAGPage reads the offset and limit parameters of a RAG query and
+// reports whether either is present
+func parseRAGPage(params url.Values) (offset, limit int, paged bool, err error) {
+	for _, key := range []string{"offset", "limit"} {
+		value := params.Get(key)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, false, fmt.Errorf("invalid %s parameter", key)
+		}
+		if key == "offset" {
+			offset = n
+		} else {
+			limit = n
+		}
+		paged = true
+	}
+	return offset, limit, paged, nil
+}
@@ -0,0 +1,63 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRAGQueryFilters(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+
+	query := func(path string) []RAGResult {
+		t.Helper()
+		data, err := fs.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) failed: %v", path, err)
+		}
+		var results RAGSearchResults
+		if err := json.Unmarshal(data, &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		return results.Results
+	}
+
+	results := query("/toolfs/rag/query?text=AI&min_score=0.5&filter.topic=RAG")
+	if len(results) != 1 || results[0].ID != "doc3" {
+		t.Errorf("Expected only doc3, got %+v", results)
+	}
+
+	// "AI agents" fully matches doc1, doc2 and doc4 but only half of doc3
+	results = query("/toolfs/rag/query?text=AI+agents&min_score=0.75")
+	if len(results) != 3 {
+		t.Errorf("Expected 3 results above the threshold, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Score < 0.75 {
+			t.Errorf("Result %s scored below the threshold: %v", result.ID, result.Score)
+		}
+	}
+
+	// Filters apply before top_k, so a filtered match outside the
+	// unfiltered top results is still found
+	results = query("/toolfs/rag/query?text=AI+agents&top_k=1&filter.topic=RAG")
+	if len(results) != 1 || results[0].ID != "doc3" {
+		t.Errorf("Expected doc3 despite top_k=1, got %+v", results)
+	}
+
+	if results := query("/toolfs/rag/query?text=AI&filter.topic=Unknown"); len(results) != 0 {
+		t.Errorf("Expected no results for unmatched filter, got %+v", results)
+	}
+
+	for path, want := range map[string]string{
+		"/toolfs/rag/query?text=AI&min_score=high":                "invalid min_score",
+		"/toolfs/rag/query?text=AI&min_score=NaN":                 "invalid min_score",
+		"/toolfs/rag/query?text=AI&filter.=RAG":                   "missing metadata key",
+		"/toolfs/rag/query?text=AI&filter.topic=":                 "missing value",
+		"/toolfs/rag/query?text=AI&filter.topic=a&filter.topic=b": "multiple values",
+	} {
+		if _, err := fs.ReadFile(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ReadFile(%s): expected error containing %q, got %v", path, want, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package toolfs
+
+import (
+	"sort"
+	"strings"
+)
+
+// Default markers wrapped around matched query terms when highlighting
+const (
+	DefaultHighlightPre  = "**"
+	DefaultHighlightPost = "**"
+)
+
+// Span is a byte range [Start, End) within a RAG result's raw content
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// HighlightRAGResult wraps every case-insensitive match of the query terms in
+// the result content with pre and post markers. The unmodified content is kept
+// in RawContent and the match offsets (into RawContent) are reported in
+// Highlights. Empty markers fall back to the defaults.
+func HighlightRAGResult(result RAGResult, query, pre, post string) RAGResult {
+	if pre == "" {
+		pre = DefaultHighlightPre
+	}
+	if post == "" {
+		post = DefaultHighlightPost
+	}
+
+	raw := result.Content
+	spans := matchSpans(raw, strings.Fields(query))
+
+	var b strings.Builder
+	b.Grow(len(raw) + len(spans)*(len(pre)+len(post)))
+	last := 0
+	for _, span := range spans {
+		b.WriteString(raw[last:span.Start])
+		b.WriteString(pre)
+		b.WriteString(raw[span.Start:span.End])
+		b.WriteString(post)
+		last = span.End
+	}
+	b.WriteString(raw[last:])
+
+	result.RawContent = raw
+	result.Content = b.String()
+	result.Highlights = spans
+	return result
+}
+
+// matchSpans returns the sorted, non-overlapping spans of content matching
+// any of the terms, ignoring case
+func matchSpans(content string, terms []string) []Span {
+	var spans []Span
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		for i := 0; i+len(term) <= len(content); i++ {
+			if strings.EqualFold(content[i:i+len(term)], term) {
+				spans = append(spans, Span{Start: i, End: i + len(term)})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return []Span{}
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].Start != spans[j].Start {
+			return spans[i].Start < spans[j].Start
+		}
+		return spans[i].End > spans[j].End
+	})
+
+	// Merge overlapping matches so markers never nest
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		current := &merged[len(merged)-1]
+		if span.Start <= current.End {
+			if span.End > current.End {
+				current.End = span.End
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
@@ -0,0 +1,89 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHighlightRAGResult(t *testing.T) {
+	result := HighlightRAGResult(RAGResult{ID: "doc", Content: "Memory helps agents. AGENTS remember."}, "agents", "", "")
+
+	if result.Content != "Memory helps **agents**. **AGENTS** remember." {
+		t.Errorf("Unexpected highlighted content: %q", result.Content)
+	}
+	if result.RawContent != "Memory helps agents. AGENTS remember." {
+		t.Errorf("Raw content should be preserved, got %q", result.RawContent)
+	}
+	want := []Span{{Start: 13, End: 19}, {Start: 21, End: 27}}
+	if len(result.Highlights) != len(want) {
+		t.Fatalf("Expected %d highlights, got %+v", len(want), result.Highlights)
+	}
+	for i, span := range want {
+		if result.Highlights[i] != span {
+			t.Errorf("Highlight %d: expected %+v, got %+v", i, span, result.Highlights[i])
+		}
+		if !strings.EqualFold(result.RawContent[span.Start:span.End], "agents") {
+			t.Errorf("Highlight %d does not cover the match", i)
+		}
+	}
+
+	// Overlapping terms are merged and custom markers are used
+	result = HighlightRAGResult(RAGResult{Content: "toolfs"}, "tool toolfs", "<em>", "</em>")
+	if result.Content != "<em>toolfs</em>" || len(result.Highlights) != 1 {
+		t.Errorf("Expected merged highlight, got %q %+v", result.Content, result.Highlights)
+	}
+}
+
+func TestRAGQueryHighlight(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+
+	data, err := fs.ReadFile("/toolfs/rag/query?text=ToolFS&top_k=1&highlight=true")
+	if err != nil {
+		t.Fatalf("RAG query failed: %v", err)
+	}
+	var results RAGSearchResults
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	if len(results.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if !strings.Contains(result.Content, "**ToolFS**") {
+		t.Errorf("Expected highlighted term, got %q", result.Content)
+	}
+	if len(result.Highlights) == 0 || result.RawContent[result.Highlights[0].Start:result.Highlights[0].End] != "ToolFS" {
+		t.Errorf("Unexpected highlights: %+v", result.Highlights)
+	}
+
+	// Without highlight the content is untouched
+	data, err = fs.ReadFile("/toolfs/rag/query?text=ToolFS&top_k=1")
+	if err != nil {
+		t.Fatalf("RAG query failed: %v", err)
+	}
+	results = RAGSearchResults{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	result = results.Results[0]
+	if strings.Contains(result.Content, "**") || result.RawContent != "" || result.Highlights != nil {
+		t.Errorf("Expected untouched content, got %+v", result)
+	}
+}
+
+func TestBuiltinRAGSkillHighlight(t *testing.T) {
+	skill := NewBuiltinRAGSkill(NewInMemoryRAGStore())
+
+	input, _ := json.Marshal(SkillRequest{
+		Operation: "search",
+		Data:      map[string]interface{}{"query": "ToolFS", "top_k": 1, "highlight": true, "highlight_pre": "[", "highlight_post": "]"},
+	})
+	output, err := skill.Execute(input)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(string(output), "[ToolFS]") {
+		t.Errorf("Expected custom highlight markers in output: %s", output)
+	}
+}
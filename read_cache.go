@@ -0,0 +1,182 @@
+package toolfs
+
+import (
+	"container/list"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReadCacheStats reports the activity of the read cache
+type ReadCacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`    // Size of the cached contents
+	MaxSize int64 `json:"max_size"` // Limit set by EnableReadCache (0 = disabled)
+}
+
+// readCache is an LRU cache of local file contents keyed by local path
+type readCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	hits     int64
+	misses   int64
+	order    *list.List               // Most recently used at the front
+	entries  map[string]*list.Element // Local path -> element holding a *readCacheEntry
+}
+
+// readCacheEntry is a cached file content along with the file state it was
+// read in
+type readCacheEntry struct {
+	localPath string
+	modTime   time.Time
+	size      int64
+	data      []byte
+}
+
+// EnableReadCache caches the contents of files read from local mounts, up to
+// maxBytes in total, evicting the least recently used files first. A cached
+// content is used as long as the file's modification time and size are
+// unchanged; writes, deletes and moves through ToolFS drop it right away.
+// Files larger than maxBytes are not cached. A maxBytes of 0 or less disables
+// the cache. Enabling it again starts with an empty cache and fresh statistics.
+func (fs *ToolFS) EnableReadCache(maxBytes int64) {
+	var cache *readCache
+	if maxBytes > 0 {
+		cache = &readCache{
+			maxBytes: maxBytes,
+			order:    list.New(),
+			entries:  make(map[string]*list.Element),
+		}
+	}
+	fs.readCacheMu.Lock()
+	fs.readCache = cache
+	fs.readCacheMu.Unlock()
+}
+
+// ReadCacheStats returns the read cache statistics. They are all zero while
+// the cache is disabled.
+func (fs *ToolFS) ReadCacheStats() ReadCacheStats {
+	cache := fs.currentReadCache()
+	if cache == nil {
+		return ReadCacheStats{}
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return ReadCacheStats{
+		Hits:    cache.hits,
+		Misses:  cache.misses,
+		Entries: len(cache.entries),
+		Bytes:   cache.bytes,
+		MaxSize: cache.maxBytes,
+	}
+}
+
+// currentReadCache returns the read cache, or nil when it is disabled
+func (fs *ToolFS) currentReadCache() *readCache {
+	fs.readCacheMu.RLock()
+	defer fs.readCacheMu.RUnlock()
+	return fs.readCache
+}
+
+// readLocalFile reads a file of a local mount through the read cache
+func (fs *ToolFS) readLocalFile(localPath string) ([]byte, error) {
+	cache := fs.currentReadCache()
+	if cache == nil {
+		return os.ReadFile(localPath)
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil || info.IsDir() {
+		// Let ReadFile report the error
+		return os.ReadFile(localPath)
+	}
+	if data, ok := cache.get(localPath, info); ok {
+		return data, nil
+	}
+
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, err
+	}
+	// The file state was taken before reading, so a change during the read
+	// leaves a stale modification time that the next read does not match
+	cache.put(localPath, info, data)
+	return data, nil
+}
+
+// invalidateReadCache drops the cached content of localPath, or of every
+// file when localPath is empty
+func (fs *ToolFS) invalidateReadCache(localPath string) {
+	cache := fs.currentReadCache()
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if localPath == "" {
+		cache.order.Init()
+		cache.entries = make(map[string]*list.Element)
+		cache.bytes = 0
+		return
+	}
+	if elem, exists := cache.entries[localPath]; exists {
+		cache.remove(elem)
+	}
+}
+
+// get returns a copy of the cached content of localPath if it was read while
+// the file was in the state described by info
+func (c *readCache) get(localPath string, info os.FileInfo) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.entries[localPath]
+	if !exists {
+		c.misses++
+		return nil, false
+	}
+	entry := elem.Value.(*readCacheEntry)
+	if !entry.modTime.Equal(info.ModTime()) || entry.size != info.Size() {
+		c.remove(elem)
+		c.misses++
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	c.hits++
+	return append([]byte(nil), entry.data...), true
+}
+
+// put caches a copy of data as the content of localPath in the state
+// described by info, evicting old entries to stay within the size limit
+func (c *readCache) put(localPath string, info os.FileInfo, data []byte) {
+	size := int64(len(data))
+	if size > c.maxBytes || size != info.Size() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, exists := c.entries[localPath]; exists {
+		c.remove(elem)
+	}
+	for c.bytes+size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+	c.entries[localPath] = c.order.PushFront(&readCacheEntry{
+		localPath: localPath,
+		modTime:   info.ModTime(),
+		size:      size,
+		data:      append([]byte(nil), data...),
+	})
+	c.bytes += size
+}
+
+// remove drops a cache entry
+func (c *readCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*readCacheEntry)
+	delete(c.entries, entry.localPath)
+	c.bytes -= entry.size
+}
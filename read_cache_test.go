@@ -0,0 +1,100 @@
+package toolfs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCache(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+
+	// Disabled by default
+	fs.ReadFile("/toolfs/data/test.txt")
+	if stats := fs.ReadCacheStats(); stats != (ReadCacheStats{}) {
+		t.Errorf("Expected empty stats while disabled, got %+v", stats)
+	}
+
+	fs.EnableReadCache(64)
+	for i := 0; i < 3; i++ {
+		data, err := fs.ReadFile("/toolfs/data/test.txt")
+		if err != nil || string(data) != "Hello, ToolFS!" {
+			t.Fatalf("Unexpected read %q, %v", data, err)
+		}
+		data[0] = 'X' // Callers get their own copy
+	}
+	if stats := fs.ReadCacheStats(); stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 || stats.Bytes != 14 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// Writes through ToolFS invalidate the entry
+	fs.WriteFile("/toolfs/data/test.txt", []byte("rewritten"))
+	if data, _ := fs.ReadFile("/toolfs/data/test.txt"); string(data) != "rewritten" {
+		t.Errorf("Expected the new content, got %q", data)
+	}
+
+	// Changes on disk are detected by modification time
+	localPath := filepath.Join(tmpDir, "test.txt")
+	os.WriteFile(localPath, []byte("changed!!"), 0o644)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(localPath, later, later)
+	if data, _ := fs.ReadFile("/toolfs/data/test.txt"); string(data) != "changed!!" {
+		t.Errorf("Expected the changed content, got %q", data)
+	}
+
+	// Deleted files are not served from the cache
+	fs.DeleteFile("/toolfs/data/test.txt")
+	if _, err := fs.ReadFile("/toolfs/data/test.txt"); err == nil {
+		t.Error("Expected an error reading a deleted file")
+	}
+
+	// The least recently used files are evicted to stay within the limit
+	fs.EnableReadCache(40)
+	for _, name := range []string{"a", "b", "c"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte(strings.Repeat(name, 15)), 0o644)
+	}
+	fs.ReadFile("/toolfs/data/a")
+	fs.ReadFile("/toolfs/data/b")
+	fs.ReadFile("/toolfs/data/a")
+	fs.ReadFile("/toolfs/data/c") // Evicts b
+	stats := fs.ReadCacheStats()
+	if stats.Entries != 2 || stats.Bytes != 30 || stats.MaxSize != 40 {
+		t.Errorf("Unexpected stats after eviction %+v", stats)
+	}
+	fs.ReadFile("/toolfs/data/a")
+	fs.ReadFile("/toolfs/data/b")
+	if after := fs.ReadCacheStats(); after.Hits != stats.Hits+1 || after.Misses != stats.Misses+1 {
+		t.Errorf("Expected a hit for a and a miss for b, got %+v then %+v", stats, after)
+	}
+
+	// Files larger than the cache are read but not cached
+	os.WriteFile(filepath.Join(tmpDir, "big"), []byte(strings.Repeat("x", 41)), 0o644)
+	if data, err := fs.ReadFile("/toolfs/data/big"); err != nil || len(data) != 41 {
+		t.Errorf("Unexpected read %d bytes, %v", len(data), err)
+	}
+	if stats := fs.ReadCacheStats(); stats.Bytes > 40 {
+		t.Errorf("Expected the cache to stay within its limit, got %+v", stats)
+	}
+
+	// Virtual paths bypass the cache
+	fs.EnableReadCache(64)
+	fs.WriteFile("/toolfs/memory/note", []byte("note"))
+	fs.ReadFile("/toolfs/memory/note")
+	fs.ReadFile("/toolfs/memory/note")
+	if stats := fs.ReadCacheStats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Expected memory reads to bypass the cache, got %+v", stats)
+	}
+
+	fs.EnableReadCache(0)
+	if data, _ := fs.ReadFile("/toolfs/data/a"); string(data) != strings.Repeat("a", 15) {
+		t.Errorf("Expected reads to work with the cache disabled, got %q", data)
+	}
+}
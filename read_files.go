@@ -0,0 +1,67 @@
+package toolfs
+
+import "sync"
+
+// DefaultReadFilesParallelism is the number of files ReadFiles reads at once
+// unless changed with SetReadFilesParallelism
+const DefaultReadFilesParallelism = 8
+
+// SetReadFilesParallelism sets how many files ReadFiles reads at once. 1
+// reads them sequentially; 0 or less restores DefaultReadFilesParallelism.
+func (fs *ToolFS) SetReadFilesParallelism(n int) {
+	fs.readFilesParallelism = n
+}
+
+// ReadFiles reads many files like ReadFileWithSession, using a bounded pool
+// of workers (see SetReadFilesParallelism). Every file is read and audited
+// separately, so a failure only affects its own path: contents holds the
+// files that were read and errs the paths that failed. Paths listed more
+// than once are read once.
+func (fs *ToolFS) ReadFiles(paths []string, session *Session) (map[string][]byte, map[string]error) {
+	contents := make(map[string][]byte, len(paths))
+	errs := make(map[string]error)
+
+	unique := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			unique = append(unique, path)
+		}
+	}
+
+	workers := fs.readFilesParallelism
+	if workers <= 0 {
+		workers = DefaultReadFilesParallelism
+	}
+	if workers > len(unique) {
+		workers = len(unique)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range queue {
+				data, err := fs.ReadFileWithSession(path, session)
+				mu.Lock()
+				if err != nil {
+					errs[path] = err
+				} else {
+					contents[path] = data
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range unique {
+		queue <- path
+	}
+	close(queue)
+	wg.Wait()
+
+	return contents, errs
+}
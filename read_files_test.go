@@ -0,0 +1,72 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// lockedAuditLogger collects entries logged from several goroutines
+type lockedAuditLogger struct {
+	mu      sync.Mutex
+	entries []AuditLogEntry
+}
+
+func (l *lockedAuditLogger) Log(entry AuditLogEntry) error {
+	l.mu.Lock()
+	l.entries = append(l.entries, entry)
+	l.mu.Unlock()
+	return nil
+}
+
+func TestReadFiles(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	var paths []string
+	for i := 0; i < 30; i++ {
+		name := fmt.Sprintf("file%02d.txt", i)
+		os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0o644)
+		paths = append(paths, "/toolfs/data/"+name)
+	}
+	paths = append(paths, "/toolfs/data/missing.txt", "/toolfs/other/denied.txt", paths[0])
+
+	for _, parallelism := range []int{1, 4, 0} {
+		t.Run(fmt.Sprintf("parallelism=%d", parallelism), func(t *testing.T) {
+			fs := NewToolFS("/toolfs")
+			fs.MountLocal("/data", tmpDir, false)
+			fs.SetReadFilesParallelism(parallelism)
+			session, _ := fs.NewSession("batch", []string{"/toolfs/data"})
+			logger := &lockedAuditLogger{}
+			session.SetAuditLogger(logger)
+
+			contents, errs := fs.ReadFiles(paths, session)
+			if len(contents) != 30 || len(errs) != 2 {
+				t.Fatalf("Expected 30 files and 2 errors, got %d and %v", len(contents), errs)
+			}
+			for _, path := range paths[:30] {
+				if string(contents[path]) != filepath.Base(path) {
+					t.Errorf("Unexpected content for %s: %q", path, contents[path])
+				}
+			}
+			if !errors.Is(errs["/toolfs/data/missing.txt"], os.ErrNotExist) {
+				t.Errorf("Expected os.ErrNotExist, got %v", errs["/toolfs/data/missing.txt"])
+			}
+			if !errors.Is(errs["/toolfs/other/denied.txt"], ErrAccessDenied) {
+				t.Errorf("Expected ErrAccessDenied, got %v", errs["/toolfs/other/denied.txt"])
+			}
+
+			// One audit entry per distinct path
+			if len(logger.entries) != 32 {
+				t.Errorf("Expected 32 audit entries, got %d", len(logger.entries))
+			}
+		})
+	}
+
+	fs := NewToolFS("/toolfs")
+	if contents, errs := fs.ReadFiles(nil, nil); len(contents) != 0 || len(errs) != 0 {
+		t.Error("Expected empty results for no paths")
+	}
+}
@@ -0,0 +1,125 @@
+package toolfs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadLines returns lines start through end (1-based, inclusive) of a text
+// file. An end past the last line is clamped to the end of the file; a start
+// past the last line is an error. Local files are streamed, so only the
+// requested lines are kept in memory. Memory entries are split by content.
+// Line terminators ("\n" or "\r\n") are not included in the returned lines.
+func (fs *ToolFS) ReadLines(path string, start, end int, session *Session) ([]string, error) {
+	if start < 1 || end < 1 {
+		return nil, fmt.Errorf("invalid line range %d-%d: lines start at 1", start, end)
+	}
+	if end < start {
+		return nil, fmt.Errorf("invalid line range %d-%d: end before start", start, end)
+	}
+
+	endSpan := fs.startSpan("ReadLines", path, session)
+	var lines []string
+	err := fs.runAudited(session, "ReadLines", path, func() (int64, int64, error) {
+		var err error
+		lines, err = fs.readLines(path, start, end, session)
+		var bytesRead int64
+		for _, line := range lines {
+			bytesRead += int64(len(line))
+		}
+		return bytesRead, 0, err
+	})
+	endSpan(err)
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// readLines implements ReadLines without tracing or auditing
+func (fs *ToolFS) readLines(path string, start, end int, session *Session) ([]string, error) {
+	localPath, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case mount.LocalPath == "__VIRTUAL_MEMORY__":
+		memPath := strings.TrimSuffix(normalizeVirtualPath(path), "/")
+		if memPath == fs.memoryPath {
+			return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+		}
+		entryID := strings.TrimPrefix(memPath, fs.memoryPath+"/")
+		entry, err := fs.memoryStore.Get(strings.SplitN(entryID, "/", 2)[0])
+		if err != nil {
+			return nil, err
+		}
+		return lineRange(strings.NewReader(entry.Content), start, end)
+
+	case mount.LocalPath == "__VIRTUAL_RAG__" || mount.LocalPath == skillsVirtualMount || strings.HasPrefix(mount.LocalPath, "__SKILL_MOUNT__:"):
+		data, err := fs.readFileWithSession(path, session)
+		if err != nil {
+			return nil, err
+		}
+		return lineRange(strings.NewReader(string(data)), start, end)
+	}
+
+	if hasTrailingSlash(path) {
+		return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+	}
+	if mount.lazy != nil {
+		if err := mount.lazy.materialize(localPath); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+	}
+
+	return lineRange(file, start, end)
+}
+
+// lineRange reads r line by line and returns lines start through end,
+// stopping as soon as end is reached
+func lineRange(r io.Reader, start, end int) ([]string, error) {
+	reader := bufio.NewReader(r)
+	lines := make([]string, 0, end-start+1)
+
+	for lineNo := 1; lineNo <= end; lineNo++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if errors.Is(err, io.EOF) && line == "" {
+			break // No more lines; a trailing newline does not start a new line
+		}
+		if lineNo >= start {
+			line = strings.TrimSuffix(line, "\n")
+			line = strings.TrimSuffix(line, "\r")
+			lines = append(lines, line)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("line %d is past the end of the file", start)
+	}
+	return lines, nil
+}
@@ -0,0 +1,88 @@
+package toolfs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	var content strings.Builder
+	for i := 1; i <= 200; i++ {
+		content.WriteString("line " + strconv.Itoa(i) + "\n")
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "source.go"), []byte(content.String()), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "short.txt"), []byte("first\r\nsecond\nthird"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, true); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+
+	// Middle range keeps line numbers aligned
+	lines, err := fs.ReadLines("/toolfs/data/source.go", 100, 150, nil)
+	if err != nil {
+		t.Fatalf("ReadLines failed: %v", err)
+	}
+	if len(lines) != 51 {
+		t.Fatalf("Expected 51 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if want := "line " + strconv.Itoa(100+i); line != want {
+			t.Fatalf("Line %d: expected %q, got %q", 100+i, want, line)
+		}
+	}
+
+	// End past EOF is clamped; no trailing newline and CRLF are handled
+	lines, err = fs.ReadLines("/toolfs/data/short.txt", 2, 10, nil)
+	if err != nil {
+		t.Fatalf("ReadLines failed: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != "second" || lines[1] != "third" {
+		t.Errorf("Expected clamped range [second third], got %q", lines)
+	}
+	lines, _ = fs.ReadLines("/toolfs/data/short.txt", 1, 1, nil)
+	if len(lines) != 1 || lines[0] != "first" {
+		t.Errorf("Expected CR stripped, got %q", lines)
+	}
+
+	// Start past EOF and invalid ranges are errors
+	if _, err := fs.ReadLines("/toolfs/data/source.go", 201, 210, nil); err == nil {
+		t.Error("Expected error for start past EOF")
+	}
+	if _, err := fs.ReadLines("/toolfs/data/source.go", -1, 5, nil); err == nil {
+		t.Error("Expected error for negative start")
+	}
+	if _, err := fs.ReadLines("/toolfs/data/source.go", 5, 4, nil); err == nil {
+		t.Error("Expected error for end before start")
+	}
+	if _, err := fs.ReadLines("/toolfs/data/subdir", 1, 1, nil); err == nil {
+		t.Error("Expected error for directory")
+	}
+
+	// Memory entries are split by content
+	fs.memoryStore.Set("notes", "a\nb\nc\n", nil)
+	lines, err = fs.ReadLines("/toolfs/memory/notes", 2, 3, nil)
+	if err != nil {
+		t.Fatalf("ReadLines on memory failed: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != "b" || lines[1] != "c" {
+		t.Errorf("Expected [b c], got %q", lines)
+	}
+
+	// Session restrictions apply
+	session, _ := fs.NewSession("lines", []string{"/toolfs/memory"})
+	session.SetAuditLogger(&TestAuditLogger{})
+	if _, err := fs.ReadLines("/toolfs/data/source.go", 1, 2, session); err == nil {
+		t.Error("Expected access denied")
+	}
+}
@@ -0,0 +1,116 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadFileRange returns up to length bytes of a file starting at offset. A
+// range running past the end of the file is clamped, so fewer bytes (none,
+// for an offset at or past the end) may be returned. Local files are read
+// with ReadAt, so only the requested range is loaded. Memory entries are
+// sliced by content; RAG, skill and remote paths are read in full and then
+// sliced.
+// The audit entry records the bytes actually returned.
+func (fs *ToolFS) ReadFileRange(path string, offset, length int64, session *Session) ([]byte, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset %d: must not be negative", offset)
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("invalid length %d: must not be negative", length)
+	}
+
+	end := fs.startSpan("ReadFileRange", path, session)
+	var data []byte
+	err := fs.runAudited(session, "ReadFileRange", path, func() (int64, int64, error) {
+		var err error
+		data, err = fs.readFileRange(path, offset, length, session)
+		return int64(len(data)), 0, err
+	})
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readFileRange implements ReadFileRange without tracing or auditing
+func (fs *ToolFS) readFileRange(path string, offset, length int64, session *Session) ([]byte, error) {
+	localPath, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mountKind(mount) {
+	case "memory":
+		memPath := strings.TrimSuffix(normalizeVirtualPath(path), "/")
+		if memPath == fs.memoryPath {
+			return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+		}
+		entryID := strings.TrimPrefix(memPath, fs.memoryPath+"/")
+		entry, err := fs.memoryStore.Get(strings.SplitN(entryID, "/", 2)[0])
+		if err != nil {
+			return nil, err
+		}
+		return sliceRange([]byte(entry.Content), offset, length), nil
+
+	case "rag", "skills", "skill", "remote":
+		data, err := fs.readFileWithSession(path, session)
+		if err != nil {
+			return nil, err
+		}
+		return sliceRange(data, offset, length), nil
+	}
+
+	if hasTrailingSlash(path) {
+		return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+	}
+	if mount.lazy != nil {
+		if err := mount.lazy.materialize(localPath); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+	}
+	if offset >= info.Size() {
+		return []byte{}, nil
+	}
+	if remaining := info.Size() - offset; length > remaining {
+		length = remaining
+	}
+
+	buf := make([]byte, length)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	// The file may have shrunk since Stat
+	return buf[:n], nil
+}
+
+// sliceRange returns up to length bytes of data starting at offset
+func sliceRange(data []byte, offset, length int64) []byte {
+	size := int64(len(data))
+	if offset >= size {
+		return []byte{}
+	}
+	if length > size-offset {
+		length = size - offset
+	}
+	return data[offset : offset+length]
+}
@@ -0,0 +1,62 @@
+package toolfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadFileRange(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	fs.memoryStore.Set("note", "0123456789", nil)
+
+	session, _ := fs.NewSession("ranger", []string{"/toolfs/data", "/toolfs/memory"})
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+
+	tests := []struct {
+		path           string
+		offset, length int64
+		want           string
+	}{
+		{"/toolfs/data/test.txt", 0, 5, "Hello"},
+		{"/toolfs/data/test.txt", 7, 6, "ToolFS"},
+		{"/toolfs/data/test.txt", 7, 100, "ToolFS!"}, // Clamped at EOF
+		{"/toolfs/data/test.txt", 14, 5, ""},         // At EOF
+		{"/toolfs/data/test.txt", 100, 5, ""},        // Past EOF
+		{"/toolfs/data/test.txt", 3, 0, ""},
+		{"/toolfs/memory/note", 2, 3, "234"},
+		{"/toolfs/memory/note", 8, 10, "89"},
+		{"/toolfs/memory/note", 20, 1, ""},
+	}
+	for _, tt := range tests {
+		logger.Entries = nil
+		data, err := fs.ReadFileRange(tt.path, tt.offset, tt.length, session)
+		if err != nil {
+			t.Errorf("ReadFileRange(%s, %d, %d) failed: %v", tt.path, tt.offset, tt.length, err)
+			continue
+		}
+		if string(data) != tt.want {
+			t.Errorf("ReadFileRange(%s, %d, %d) = %q, want %q", tt.path, tt.offset, tt.length, data, tt.want)
+		}
+		if len(logger.Entries) != 1 || logger.Entries[0].BytesRead != int64(len(tt.want)) {
+			t.Errorf("Expected audit entry with %d bytes, got %+v", len(tt.want), logger.Entries)
+		}
+	}
+
+	if _, err := fs.ReadFileRange("/toolfs/data/test.txt", -1, 5, session); err == nil {
+		t.Error("Expected error for negative offset")
+	}
+	if _, err := fs.ReadFileRange("/toolfs/data/test.txt", 0, -1, session); err == nil {
+		t.Error("Expected error for negative length")
+	}
+	if _, err := fs.ReadFileRange("/toolfs/data/subdir", 0, 5, session); !errors.Is(err, ErrIsDirectory) {
+		t.Errorf("Expected ErrIsDirectory, got %v", err)
+	}
+	if _, err := fs.ReadFileRange("/toolfs/rag/query?text=AI", 0, 5, session); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package toolfs
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// remoteSource serves a mount whose files live outside the local filesystem.
+// Keys are paths relative to the mount point using forward slashes; the mount
+// root is "". Missing keys are reported with errors wrapping os.ErrNotExist.
+// Requests give up when ctx is done.
+type remoteSource interface {
+	// mountType is reported as MountInfo.Type
+	mountType() string
+	get(ctx context.Context, key string) ([]byte, error)
+	// put fails with ErrReadOnly for read-only sources
+	put(ctx context.Context, key string, data []byte) error
+	// list returns the names of the entries in a directory; names of
+	// subdirectories end with "/"
+	list(ctx context.Context, key string) ([]string, error)
+	stat(ctx context.Context, key string) (*FileInfo, error)
+}
+
+// remoteKey converts the relative path resolved for a remote mount into a key
+func remoteKey(relPath string) string {
+	return strings.Trim(normalizeVirtualPath(relPath), "/")
+}
+
+// addRemoteMount registers source at mountPoint, replacing any remote mount
+// already there
+func (fs *ToolFS) addRemoteMount(mountPoint string, source remoteSource, readOnly bool) error {
+	mountPoint, err := fs.canonicalMountPoint(mountPoint)
+	if err != nil {
+		return err
+	}
+
+	fs.remoteMounts[mountPoint] = &Mount{
+		LocalPath: "__REMOTE_MOUNT__",
+		ReadOnly:  readOnly,
+		remote:    source,
+	}
+
+	// Invalidate path resolution cache since mounts changed
+	fs.invalidateLastResolved()
+	fs.pathResolveCache.Range(func(key, value interface{}) bool {
+		path := key.(string)
+		if strings.HasPrefix(path, mountPoint) || strings.HasPrefix(mountPoint, path) {
+			fs.pathResolveCache.Delete(key)
+		}
+		return true
+	})
+	return nil
+}
+
+// remoteMountInfo describes a remote mount
+func remoteMountInfo(mountPoint string, mount *Mount) MountInfo {
+	ops := []string{"read_file", "list_dir", "stat"}
+	if !mount.ReadOnly {
+		ops = []string{"read_file", "write_file", "list_dir", "stat"}
+	}
+	return MountInfo{
+		MountPoint: mountPoint,
+		Type:       mount.remote.mountType(),
+		ReadOnly:   mount.ReadOnly,
+		Operations: ops,
+	}
+}
+
+// walkRemote lists everything below key up to maxDepth levels (0 or less
+// means no limit). Directories that cannot be listed are skipped.
+func walkRemote(ctx context.Context, source remoteSource, key string, maxDepth int) ([]FileInfoEntry, error) {
+	var entries []FileInfoEntry
+	var walk func(dir, rel string, depth int) error
+	walk = func(dir, rel string, depth int) error {
+		names, err := source.list(ctx, dir)
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			isDir := strings.HasSuffix(name, "/")
+			name = strings.TrimSuffix(name, "/")
+			childKey := strings.TrimPrefix(dir+"/"+name, "/")
+			childRel := strings.TrimPrefix(rel+"/"+name, "/")
+
+			entry := FileInfoEntry{RelPath: childRel, IsDir: isDir}
+			if !isDir {
+				if info, err := source.stat(ctx, childKey); err == nil {
+					entry.Size, entry.ModTime = info.Size, info.ModTime
+				}
+			}
+			entries = append(entries, entry)
+			if isDir && (maxDepth <= 0 || depth+1 < maxDepth) {
+				walk(childKey, childRel, depth+1)
+			}
+		}
+		return nil
+	}
+	err := walk(key, "", 0)
+	return entries, err
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -15,12 +16,20 @@ import (
 
 // SandboxConfig configures sandbox behavior for skill execution
 type SandboxConfig struct {
-	CPUTimeout    time.Duration // Maximum CPU time allowed
-	MemoryLimit   int64         // Maximum memory in bytes (0 = no limit)
-	AllowHostFS   bool          // Allow direct host filesystem access (should be false)
-	CaptureStdout bool          // Capture stdout output
-	CaptureStderr bool          // Capture stderr output
-	AuditLog      AuditLogger   // Optional audit logger for skill executions
+	CPUTimeout time.Duration // Maximum CPU time allowed
+
+	// MemoryLimit is the heap growth allowed during execution in bytes (0 =
+	// no limit). It is a best-effort check: Go cannot attribute memory to a
+	// goroutine, so the limit applies to the growth of the process-wide live
+	// heap while the skill runs. Allocations by other goroutines count
+	// against it and garbage collection can hide a skill's own allocations,
+	// so leave some headroom when setting it.
+	MemoryLimit int64
+
+	AllowHostFS   bool        // Allow direct host filesystem access (should be false)
+	CaptureStdout bool        // Capture stdout output
+	CaptureStderr bool        // Capture stderr output
+	AuditLog      AuditLogger // Optional audit logger for skill executions
 }
 
 // DefaultSandboxConfig returns a safe default sandbox configuration
@@ -40,13 +49,22 @@ type SkillExecutionResult struct {
 	Stdout     string                 `json:"stdout"`
 	Stderr     string                 `json:"stderr"`
 	CPUTime    time.Duration          `json:"cpu_time"`
-	MemoryUsed int64                  `json:"memory_used"`
+	MemoryUsed int64                  `json:"memory_used"` // Process-wide heap growth during execution (best effort)
 	Success    bool                   `json:"success"`
 	Error      string                 `json:"error,omitempty"`
 	Violations []string               `json:"violations,omitempty"` // Security violations detected
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// ErrMemoryLimitExceeded is returned when a sandboxed skill allocates more
+// than its SandboxConfig.MemoryLimit
+var ErrMemoryLimitExceeded = errors.New("memory limit exceeded")
+
+// sandboxMemorySampleInterval is how often the heap is sampled while a
+// sandboxed skill with a MemoryLimit runs. Each sample briefly stops the
+// world, so it is kept coarse.
+var sandboxMemorySampleInterval = 100 * time.Millisecond
+
 // WASMSandbox defines the interface for WASM-based skill sandboxing
 type WASMSandbox interface {
 	// Execute runs a skill in the sandbox with resource limits
@@ -64,14 +82,11 @@ type WASMSandbox interface {
 type InMemorySandbox struct {
 	mu           sync.Mutex
 	loadedModule []byte
-	violations   []string
 }
 
 // NewInMemorySandbox creates a new in-memory sandbox
 func NewInMemorySandbox() *InMemorySandbox {
-	return &InMemorySandbox{
-		violations: make([]string, 0),
-	}
+	return &InMemorySandbox{}
 }
 
 // LoadWASMModule loads a WASM module (mock implementation)
@@ -90,14 +105,16 @@ func (s *InMemorySandbox) Close() error {
 	return nil
 }
 
-// Execute runs a skill with sandboxing
+// Execute runs a skill with sandboxing. The skill runs on its own goroutine;
+// when config.CPUTimeout or config.MemoryLimit is exceeded, Execute returns
+// immediately with a failed result. Go cannot stop a goroutine, so the
+// abandoned skill may keep running in the background until it returns, but
+// the original stdout/stderr are restored before Execute returns and the
+// skill no longer affects the sandbox.
 func (s *InMemorySandbox) Execute(executor SkillExecutor, input []byte, config *SandboxConfig, ctx *SkillContext) (*SkillExecutionResult, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Reset violations for this execution
-	s.violations = make([]string, 0)
-
 	// Create context with timeout
 	ctxTimeout := context.Background()
 	if config.CPUTimeout > 0 {
@@ -106,180 +123,272 @@ func (s *InMemorySandbox) Execute(executor SkillExecutor, input []byte, config *
 		defer cancel()
 	}
 
-	// Capture stdout/stderr
-	var stdoutBuf, stderrBuf bytes.Buffer
-	var originalStdout, originalStderr *os.File
-	var stdoutDone, stderrDone chan struct{}
-	var stdoutWriter, stderrWriter *os.File // Save write end for later closing
-
-	if config.CaptureStdout {
-		originalStdout = os.Stdout
-		r, w, _ := os.Pipe()
-		stdoutWriter = w
-		os.Stdout = w
-		stdoutDone = make(chan struct{})
-
-		go func() {
-			io.Copy(&stdoutBuf, r)
-			r.Close()
-			close(stdoutDone)
-		}()
-		defer func() {
-			w.Close()
-			// Don't wait here, wait after goroutine completes
-		}()
-	}
-
-	if config.CaptureStderr {
-		originalStderr = os.Stderr
-		r, w, _ := os.Pipe()
-		stderrWriter = w
-		os.Stderr = w
-		stderrDone = make(chan struct{})
-
-		go func() {
-			io.Copy(&stderrBuf, r)
-			r.Close()
-			close(stderrDone)
-		}()
-		defer func() {
-			w.Close()
-			// Don't wait here, wait after goroutine completes
-		}()
-	}
+	// Capture stdout/stderr; every return path below restores them
+	capture := captureStreams(config.CaptureStdout, config.CaptureStderr)
+	defer capture.restore()
 
-	// Track execution start time and memory
+	// Track execution start time and memory. Memory is measured as the growth
+	// of the process-wide heap (see SandboxConfig.MemoryLimit).
 	startTime := time.Now()
-	var memoryUsed int64
+	baseHeap := heapAlloc()
+	execDone := make(chan struct{})
+	memoryExceeded := make(chan int64, 1)
+	if config.MemoryLimit > 0 {
+		go watchMemory(baseHeap, config.MemoryLimit, execDone, memoryExceeded)
+	}
 
-	// Create a wrapped skill that enforces filesystem restrictions
+	// Create a wrapped skill that enforces filesystem restrictions. Its
+	// violations are kept per execution, so a skill abandoned after a timeout
+	// cannot leak them into a later execution.
+	violations := &violationLog{}
 	restrictedSkill := &RestrictedSkill{
-		executor: executor,
-		config:   config,
-		context:  ctx,
-		sandbox:  s,
+		executor:   executor,
+		config:     config,
+		context:    ctx,
+		violations: violations,
 	}
 
-	// Execute skill with timeout and resource monitoring
-	resultChan := make(chan *SkillExecutionResult, 1)
-
+	// Execute skill with timeout and resource monitoring. The goroutine only
+	// reports back; it must not touch process globals or sandbox state, since
+	// Execute may have returned by the time the skill finishes.
+	outcomeChan := make(chan sandboxOutcome, 1)
 	go func() {
 		output, err := restrictedSkill.Execute(input)
-
-		cpuTime := time.Since(startTime)
-
-		// Restore original stdout/stderr
-		if originalStdout != nil {
-			os.Stdout = originalStdout
-		}
-		if originalStderr != nil {
-			os.Stderr = originalStderr
+		close(execDone)
+		outcomeChan <- sandboxOutcome{
+			output:     output,
+			err:        err,
+			cpuTime:    time.Since(startTime),
+			memoryUsed: heapGrowth(baseHeap),
 		}
+	}()
 
-		// Close write end to trigger copy goroutine completion
-		// Note: Write end needs to be closed after use, but since it's in defer, we need to explicitly close it here
-		// The problem is that write end variable w is in defer scope, cannot access here
-		// So we need to redesign: don't wait in goroutine, wait in main function instead
-
+	// Wait for execution or timeout
+	select {
+	case outcome := <-outcomeChan:
 		result := &SkillExecutionResult{
-			Output:     output,
-			Stdout:     "", // Set empty first, fill later
-			Stderr:     "", // Set empty first, fill later
-			CPUTime:    cpuTime,
-			MemoryUsed: memoryUsed,
-			Success:    err == nil,
-			Violations: make([]string, len(s.violations)),
+			Output:     outcome.output,
+			CPUTime:    outcome.cpuTime,
+			MemoryUsed: outcome.memoryUsed,
+			Success:    outcome.err == nil,
+			Violations: violations.list(),
 			Metadata: map[string]interface{}{
 				"skill_name":    executor.Name(),
 				"skill_version": executor.Version(),
 			},
 		}
+		result.Stdout, result.Stderr = capture.restore()
 
-		copy(result.Violations, s.violations)
-
-		if err != nil {
-			result.Error = err.Error()
+		if outcome.err != nil {
+			result.Error = outcome.err.Error()
 		}
 
 		// Check memory limit
-		if config.MemoryLimit > 0 && memoryUsed > config.MemoryLimit {
+		if config.MemoryLimit > 0 && result.MemoryUsed > config.MemoryLimit {
 			result.Success = false
-			result.Error = fmt.Sprintf("memory limit exceeded: %d > %d bytes", memoryUsed, config.MemoryLimit)
+			result.Error = fmt.Sprintf("memory limit exceeded: %d > %d bytes", result.MemoryUsed, config.MemoryLimit)
 			result.Violations = append(result.Violations, "memory_limit_exceeded")
 		}
 
-		resultChan <- result
-	}()
+		// Check timeout
+		if config.CPUTimeout > 0 && result.CPUTime > config.CPUTimeout {
+			result.Success = false
+			result.Error = fmt.Sprintf("CPU timeout exceeded: %v", result.CPUTime)
+			result.Violations = append(result.Violations, "cpu_timeout_exceeded")
+		}
 
-	// Wait for execution or timeout
-	select {
-	case result := <-resultChan:
-		// Close write end to trigger copy completion
-		if stdoutWriter != nil {
-			stdoutWriter.Close()
+		auditSandboxExecution(config, ctx, executor, input, result)
+		return result, nil
+	case memoryUsed := <-memoryExceeded:
+		result := &SkillExecutionResult{
+			CPUTime:    time.Since(startTime),
+			MemoryUsed: memoryUsed,
+			Success:    false,
+			Error:      fmt.Sprintf("memory limit exceeded: %d > %d bytes", memoryUsed, config.MemoryLimit),
+			Violations: append(violations.list(), "memory_limit_exceeded"),
 		}
-		if stderrWriter != nil {
-			stderrWriter.Close()
+		result.Stdout, result.Stderr = capture.restore()
+		auditSandboxExecution(config, ctx, executor, input, result)
+		return result, ErrMemoryLimitExceeded
+	case <-ctxTimeout.Done():
+		result := &SkillExecutionResult{
+			CPUTime:    time.Since(startTime),
+			Success:    false,
+			Error:      fmt.Sprintf("CPU timeout exceeded: %v", config.CPUTimeout),
+			Violations: append(violations.list(), "cpu_timeout_exceeded"),
 		}
+		result.Stdout, result.Stderr = capture.restore()
+		auditSandboxExecution(config, ctx, executor, input, result)
+		return result, fmt.Errorf("%w: sandbox CPU timeout after %v", ErrSkillTimeout, config.CPUTimeout)
+	}
+}
+
+// sandboxOutcome is what a sandboxed skill's goroutine reports back
+type sandboxOutcome struct {
+	output     []byte
+	err        error
+	cpuTime    time.Duration
+	memoryUsed int64
+}
+
+// violationLog collects the security violations of one sandboxed execution
+type violationLog struct {
+	mu         sync.Mutex
+	violations []string
+}
+
+func (l *violationLog) add(violation string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.violations = append(l.violations, violation)
+}
+
+// list returns a copy of the violations recorded so far
+func (l *violationLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append(make([]string, 0, len(l.violations)), l.violations...)
+}
 
-		// Wait for stdout/stderr copy completion
-		if stdoutDone != nil {
-			<-stdoutDone
+// streamCapture redirects os.Stdout and/or os.Stderr into buffers while a
+// sandboxed skill runs
+type streamCapture struct {
+	once           sync.Once
+	stdout, stderr *capturedStream
+	stdoutText     string
+	stderrText     string
+}
+
+// capturedStream is one redirected stream
+type capturedStream struct {
+	original *os.File
+	writer   *os.File
+	buf      bytes.Buffer
+	done     chan struct{}
+}
+
+// captureStreams redirects the selected standard streams. Streams whose pipe
+// cannot be created are left alone.
+func captureStreams(stdout, stderr bool) *streamCapture {
+	c := &streamCapture{}
+	if stdout {
+		if c.stdout = newCapturedStream(os.Stdout); c.stdout != nil {
+			os.Stdout = c.stdout.writer
 		}
-		if stderrDone != nil {
-			<-stderrDone
+	}
+	if stderr {
+		if c.stderr = newCapturedStream(os.Stderr); c.stderr != nil {
+			os.Stderr = c.stderr.writer
 		}
+	}
+	return c
+}
 
-		// Now safe to read buffers
-		result.Stdout = stdoutBuf.String()
-		result.Stderr = stderrBuf.String()
+func newCapturedStream(original *os.File) *capturedStream {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil
+	}
+	stream := &capturedStream{original: original, writer: w, done: make(chan struct{})}
+	go func() {
+		io.Copy(&stream.buf, r)
+		r.Close()
+		close(stream.done)
+	}()
+	return stream
+}
 
-		// Check timeout
-		if config.CPUTimeout > 0 && result.CPUTime > config.CPUTimeout {
-			result.Success = false
-			result.Error = fmt.Sprintf("CPU timeout exceeded: %v", result.CPUTime)
-			result.Violations = append(result.Violations, "cpu_timeout_exceeded")
+// finish closes the pipe and returns what was written to it
+func (s *capturedStream) finish() string {
+	if s == nil {
+		return ""
+	}
+	s.writer.Close()
+	<-s.done
+	return s.buf.String()
+}
+
+// restore puts the original streams back and returns the captured output.
+// Only the first call restores; later calls return the same output.
+func (c *streamCapture) restore() (stdout, stderr string) {
+	c.once.Do(func() {
+		if c.stdout != nil {
+			os.Stdout = c.stdout.original
 		}
+		if c.stderr != nil {
+			os.Stderr = c.stderr.original
+		}
+		c.stdoutText = c.stdout.finish()
+		c.stderrText = c.stderr.finish()
+	})
+	return c.stdoutText, c.stderrText
+}
+
+// auditSandboxExecution logs a "SkillExecute" entry for result if config has
+// an audit logger
+func auditSandboxExecution(config *SandboxConfig, ctx *SkillContext, executor SkillExecutor, input []byte, result *SkillExecutionResult) {
+	if config.AuditLog == nil {
+		return
+	}
+	config.AuditLog.Log(AuditLogEntry{
+		Timestamp:    time.Now(),
+		SessionID:    getSkillSessionID(ctx),
+		Operation:    "SkillExecute",
+		Path:         fmt.Sprintf("skill:%s", executor.Name()),
+		Success:      result.Success,
+		Error:        result.Error,
+		BytesRead:    int64(len(input)),
+		BytesWritten: int64(len(result.Output)),
+		AccessDenied: len(result.Violations) > 0,
+	})
+}
 
-		// Log audit entry if configured
-		if config.AuditLog != nil {
-			entry := AuditLogEntry{
-				Timestamp:    time.Now(),
-				SessionID:    getSkillSessionID(ctx),
-				Operation:    "SkillExecute",
-				Path:         fmt.Sprintf("skill:%s", executor.Name()),
-				Success:      result.Success,
-				Error:        result.Error,
-				BytesRead:    int64(len(input)),
-				BytesWritten: int64(len(result.Output)),
-				AccessDenied: len(result.Violations) > 0,
+// heapAlloc returns the bytes of heap objects currently allocated by the process
+func heapAlloc() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// heapGrowth returns how much the heap grew since baseHeap, or 0 if it shrank
+func heapGrowth(baseHeap uint64) int64 {
+	if current := heapAlloc(); current > baseHeap {
+		return int64(current - baseHeap)
+	}
+	return 0
+}
+
+// watchMemory samples the heap growth since baseHeap until done is closed,
+// sending the amount on exceeded once it passes limit
+func watchMemory(baseHeap uint64, limit int64, done <-chan struct{}, exceeded chan<- int64) {
+	ticker := time.NewTicker(sandboxMemorySampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if used := heapGrowth(baseHeap); used > limit {
+				exceeded <- used
+				return
 			}
-			config.AuditLog.Log(entry)
 		}
-
-		return result, nil
-	case <-ctxTimeout.Done():
-		return &SkillExecutionResult{
-			Success:    false,
-			Error:      fmt.Sprintf("execution timeout after %v", config.CPUTimeout),
-			Violations: []string{"cpu_timeout"},
-		}, errors.New("execution timeout")
 	}
 }
 
 func getSkillSessionID(ctx *SkillContext) string {
-	if ctx != nil && ctx.session != nil {
-		return ctx.session.ID
+	if ctx != nil {
+		return ctx.capability.SessionID()
 	}
 	return ""
 }
 
 // RestrictedSkill wraps a skill to enforce filesystem restrictions
 type RestrictedSkill struct {
-	executor SkillExecutor
-	config   *SandboxConfig
-	context  *SkillContext
-	sandbox  *InMemorySandbox
+	executor   SkillExecutor
+	config     *SandboxConfig
+	context    *SkillContext
+	violations *violationLog
 }
 
 // Execute runs the skill with filesystem access restrictions
@@ -296,13 +405,13 @@ func (rs *RestrictedSkill) Execute(input []byte) ([]byte, error) {
 
 				// Block absolute paths that don't start with /toolfs
 				if strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "/toolfs") {
-					rs.sandbox.violations = append(rs.sandbox.violations, fmt.Sprintf("blocked_host_fs_access: %s", path))
+					rs.violations.add(fmt.Sprintf("blocked_host_fs_access: %s", path))
 					return nil, fmt.Errorf("access to host filesystem blocked: %s (only ToolFS paths allowed)", path)
 				}
 
 				// Block attempts to escape with ../
 				if strings.Contains(path, "..") {
-					rs.sandbox.violations = append(rs.sandbox.violations, fmt.Sprintf("path_traversal_attempt: %s", path))
+					rs.violations.add(fmt.Sprintf("path_traversal_attempt: %s", path))
 					return nil, fmt.Errorf("path traversal blocked: %s", path)
 				}
 
@@ -315,7 +424,7 @@ func (rs *RestrictedSkill) Execute(input []byte) ([]byte, error) {
 				originalPath := request.Path // Use original path for Windows checks
 				for _, prefix := range blockedPrefixes {
 					if strings.HasPrefix(path, prefix) || strings.HasPrefix(originalPath, prefix) {
-						rs.sandbox.violations = append(rs.sandbox.violations, fmt.Sprintf("blocked_system_path: %s", path))
+						rs.violations.add(fmt.Sprintf("blocked_system_path: %s", path))
 						return nil, fmt.Errorf("access to system path blocked: %s", path)
 					}
 				}
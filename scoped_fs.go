@@ -0,0 +1,63 @@
+package toolfs
+
+// ScopedFS binds a session to a ToolFS so operations do not need an explicit
+// session argument. Every method is equivalent to the corresponding
+// *WithSession call and is subject to the same access checks and auditing.
+type ScopedFS struct {
+	fs      *ToolFS
+	session *Session
+}
+
+// Session returns a ScopedFS bound to the existing session with the given ID
+func (fs *ToolFS) Session(id string) (*ScopedFS, error) {
+	session, err := fs.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	return &ScopedFS{fs: fs, session: session}, nil
+}
+
+// Scope returns a ScopedFS bound to session
+func (fs *ToolFS) Scope(session *Session) *ScopedFS {
+	return &ScopedFS{fs: fs, session: session}
+}
+
+// Session returns the bound session
+func (s *ScopedFS) Session() *Session {
+	return s.session
+}
+
+// ReadFile reads a file as the bound session
+func (s *ScopedFS) ReadFile(path string) ([]byte, error) {
+	return s.fs.ReadFileWithSession(path, s.session)
+}
+
+// WriteFile writes a file as the bound session
+func (s *ScopedFS) WriteFile(path string, data []byte) error {
+	return s.fs.WriteFileWithSession(path, data, s.session)
+}
+
+// ListDir lists a directory as the bound session
+func (s *ScopedFS) ListDir(path string) ([]string, error) {
+	return s.fs.ListDirWithSession(path, s.session)
+}
+
+// Stat returns file metadata as the bound session
+func (s *ScopedFS) Stat(path string) (*FileInfo, error) {
+	return s.fs.StatWithSession(path, s.session)
+}
+
+// ExecuteSkill executes a registered skill as the bound session
+func (s *ScopedFS) ExecuteSkill(name string, input []byte) ([]byte, error) {
+	return s.fs.ExecuteSkill(name, input, s.session)
+}
+
+// ExecuteSkillPipeline executes a skill pipeline as the bound session
+func (s *ScopedFS) ExecuteSkillPipeline(steps []PipelineStep, input []byte) ([]byte, error) {
+	return s.fs.ExecuteSkillPipeline(steps, input, s.session)
+}
+
+// ExecuteCommand validates and runs a command in dir as the bound session
+func (s *ScopedFS) ExecuteCommand(dir, command string, args []string) (*CLIOutput, error) {
+	return s.fs.ExecuteCommandWithSession(dir, command, args, s.session)
+}
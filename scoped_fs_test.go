@@ -0,0 +1,65 @@
+package toolfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScopedFSMatchesWithSession(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+	session, _ := fs.NewSession("scoped", []string{"/toolfs/data"})
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+
+	if _, err := fs.Session("missing"); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+	scoped, err := fs.Session("scoped")
+	if err != nil {
+		t.Fatalf("Session failed: %v", err)
+	}
+	if scoped.Session() != session {
+		t.Error("ScopedFS should be bound to the existing session")
+	}
+
+	paths := []string{"/toolfs/data/test.txt", "/toolfs/data/subdir", "/toolfs/memory/x", "/toolfs/rag/query?text=AI"}
+	for _, path := range paths {
+		_, explicitErr := fs.ReadFileWithSession(path, session)
+		_, scopedErr := scoped.ReadFile(path)
+		if (explicitErr == nil) != (scopedErr == nil) || errors.Is(explicitErr, ErrAccessDenied) != errors.Is(scopedErr, ErrAccessDenied) {
+			t.Errorf("ReadFile %s: explicit %v, scoped %v", path, explicitErr, scopedErr)
+		}
+
+		_, explicitErr = fs.StatWithSession(path, session)
+		_, scopedErr = scoped.Stat(path)
+		if (explicitErr == nil) != (scopedErr == nil) {
+			t.Errorf("Stat %s: explicit %v, scoped %v", path, explicitErr, scopedErr)
+		}
+
+		_, explicitErr = fs.ListDirWithSession(path, session)
+		_, scopedErr = scoped.ListDir(path)
+		if (explicitErr == nil) != (scopedErr == nil) {
+			t.Errorf("ListDir %s: explicit %v, scoped %v", path, explicitErr, scopedErr)
+		}
+	}
+
+	if err := scoped.WriteFile("/toolfs/data/scoped.txt", []byte("ok")); err != nil {
+		t.Errorf("Scoped write in allowed path failed: %v", err)
+	}
+	if err := scoped.WriteFile("/toolfs/memory/x", []byte("no")); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected access denied for scoped write, got %v", err)
+	}
+
+	// Scoped calls are audited under the bound session
+	logged := len(logger.Entries)
+	scoped.ReadFile("/toolfs/data/test.txt")
+	if len(logger.Entries) != logged+1 || logger.Entries[logged].SessionID != "scoped" {
+		t.Errorf("Expected scoped read to be audited for session 'scoped'")
+	}
+}
@@ -1,13 +1,17 @@
 package toolfs
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -45,66 +49,161 @@ type SkillDocumentProvider interface {
 	GetSkillDocument() string
 }
 
+// SkillIntrospector is an optional interface that skills can implement to
+// describe their capabilities. Describe must not have side effects, so it can
+// be called before deciding whether to invoke the skill.
+type SkillIntrospector interface {
+	// Describe returns the operations the skill supports.
+	Describe() SkillCapabilities
+}
+
+// ClosableSkill is an optional interface for skills that hold resources
+// (connections, WASM instances, ...). Close is called once the skill is
+// unloaded from its SkillExecutorManager or its last mount is removed.
+type ClosableSkill interface {
+	Close() error
+}
+
+// ContextSkill is an optional interface for skills that can stop early.
+// Skill mounts call ExecuteContext instead of Execute with the context of
+// context-aware operations such as ReadFileContext.
+type ContextSkill interface {
+	ExecuteContext(ctx context.Context, input []byte) ([]byte, error)
+}
+
+// OperationProvider is an optional interface for skills that handle only
+// some mount operations ("read_file", "write_file", "list_dir",
+// "delete_file", ...). Mounts of such skills reject other operations with
+// ErrUnsupportedOperation without calling the skill, and are writable only
+// when "write_file" is supported.
+type OperationProvider interface {
+	SupportedOperations() []string
+}
+
+// SkillCapabilities describes what a skill can do
+type SkillCapabilities struct {
+	Name        string                 `json:"name"`
+	Version     string                 `json:"version,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Operations  []SkillOperationInfo   `json:"operations,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Source      string                 `json:"source"` // "skill" when reported by Describe, "document" when derived from SKILL.md
+}
+
+// SkillOperationInfo describes a single skill operation
+type SkillOperationInfo struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description,omitempty"`
+	InputSchema  *JSONSchema    `json:"input_schema,omitempty"`
+	OutputSchema *JSONSchema    `json:"output_schema,omitempty"`
+	Examples     []SkillExample `json:"examples,omitempty"`
+}
+
+// SkillExample is a sample request and the response it produces
+type SkillExample struct {
+	Description string                 `json:"description,omitempty"`
+	Input       map[string]interface{} `json:"input,omitempty"`
+	Output      interface{}            `json:"output,omitempty"`
+}
+
 // SkillContext provides access to ToolFS functionality within skills.
+//
+// A context carries only a Capability derived from its session, so a skill
+// can reach files within the session's scope but has no way to obtain the
+// ToolFS instance or swap in a different session.
+//
+// Skills that need to restrict paths should use CheckAccess rather than
+// implementing their own allow lists, so they always apply the same rules as
+// the session their context was created with.
 type SkillContext struct {
-	fs      *ToolFS
-	session *Session
+	capability *Capability
+	skillName  string // Skill the context is bound to (for private state)
+	injected   bool   // skillName was bound by InjectSkill
+	shared     bool   // Injected with several skills, so bound to none
 }
 
 // NewSkillContext creates a new skill context with ToolFS and session access.
 func NewSkillContext(fs *ToolFS, session *Session) *SkillContext {
-	return &SkillContext{
-		fs:      fs,
-		session: session,
-	}
+	return NewSkillContextWithCapability(NewCapability(fs, session))
+}
+
+// NewSkillContextWithCapability creates a skill context from an existing capability.
+func NewSkillContextWithCapability(capability *Capability) *SkillContext {
+	return &SkillContext{capability: capability}
+}
+
+// Capability returns the capability the context was created with.
+func (ctx *SkillContext) Capability() *Capability {
+	return ctx.capability
 }
 
 // ReadFile reads a file from ToolFS using the skill's session.
 func (ctx *SkillContext) ReadFile(path string) ([]byte, error) {
-	if ctx.fs == nil {
-		return nil, errors.New("ToolFS instance not available")
-	}
-	return ctx.fs.ReadFileWithSession(path, ctx.session)
+	return ctx.capability.ReadFile(path)
 }
 
 // WriteFile writes data to a file in ToolFS using the skill's session.
 func (ctx *SkillContext) WriteFile(path string, data []byte) error {
-	if ctx.fs == nil {
-		return errors.New("ToolFS instance not available")
-	}
-	return ctx.fs.WriteFileWithSession(path, data, ctx.session)
+	return ctx.capability.WriteFile(path, data)
 }
 
 // ListDir lists directory contents from ToolFS using the skill's session.
 func (ctx *SkillContext) ListDir(path string) ([]string, error) {
-	if ctx.fs == nil {
-		return nil, errors.New("ToolFS instance not available")
-	}
-	return ctx.fs.ListDirWithSession(path, ctx.session)
+	return ctx.capability.ListDir(path)
 }
 
 // Stat gets file metadata from ToolFS using the skill's session.
 func (ctx *SkillContext) Stat(path string) (*FileInfo, error) {
-	if ctx.fs == nil {
-		return nil, errors.New("ToolFS instance not available")
+	return ctx.capability.Stat(path)
+}
+
+// AllowedPaths returns the skill's session's legacy AllowedPaths list. See
+// ReadPaths and WritePaths for the prefixes that actually apply.
+func (ctx *SkillContext) AllowedPaths() []string {
+	return ctx.capability.AllowedPaths()
+}
+
+// ReadPaths returns the path prefixes the skill's session may read. An empty
+// result means reads are unrestricted.
+func (ctx *SkillContext) ReadPaths() []string {
+	return ctx.capability.ReadPaths()
+}
+
+// WritePaths returns the path prefixes the skill's session may write. An
+// empty result means writes are unrestricted.
+func (ctx *SkillContext) WritePaths() []string {
+	return ctx.capability.WritePaths()
+}
+
+// CheckAccess reports whether the skill's session may perform op ("read",
+// "list" or "write") on path. It applies deny rules and the session's access
+// rules as the session-scoped operations do; other ops are rejected.
+func (ctx *SkillContext) CheckAccess(path, op string) error {
+	switch op {
+	case "read", "list":
+		return ctx.capability.CheckAccess(path, false)
+	case "write":
+		return ctx.capability.CheckAccess(path, true)
 	}
-	return ctx.fs.StatWithSession(path, ctx.session)
+	return fmt.Errorf("unknown access operation '%s'", op)
 }
 
 // SkillRequest represents a request to a skill.
 type SkillRequest struct {
 	Operation string                 `json:"operation"`
-	Path      string                 `json:"path,omitempty"`
+	Path      string                 `json:"path,omitempty"` // Full virtual path; mounted skills also get Data["relative_path"] and Data["query"]
 	Data      map[string]interface{} `json:"data,omitempty"`
 	Options   map[string]interface{} `json:"options,omitempty"`
 }
 
 // SkillResponse represents a response from a skill.
 type SkillResponse struct {
-	Success  bool                   `json:"success"`
-	Result   interface{}            `json:"result,omitempty"`
-	Error    string                 `json:"error,omitempty"`
-	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Success   bool                   `json:"success"`
+	Result    interface{}            `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	ErrorCode string                 `json:"error_code,omitempty"` // Machine-readable failure class (see SkillErrorNotFound etc.)
+	Retryable bool                   `json:"retryable,omitempty"`  // Whether retrying the same request may succeed
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // ManagedSkill wraps a skill with management metadata.
@@ -356,7 +455,7 @@ func (sr *SkillRegistry) UnregisterSkill(name string) error {
 func (sr *SkillRegistry) GetSkill(name string) (*Skill, error) {
 	skill, exists := sr.skills[name]
 	if !exists {
-		return nil, fmt.Errorf("skill '%s' not found", name)
+		return nil, fmt.Errorf("%w: '%s'", ErrSkillNotFound, name)
 	}
 	return skill, nil
 }
@@ -477,6 +576,166 @@ func (sr *SkillRegistry) GetSkillDocument(name string) (*SkillDocument, error) {
 	return sr.docManager.GetDocument(name)
 }
 
+// DescribeSkill returns the capabilities of a skill without executing it.
+// Skills implementing SkillIntrospector describe themselves; for other skills
+// the capabilities are derived from the SKILL.md document. An "operations"
+// front matter entry (a list or a comma separated string) is reported as the
+// operation list.
+func (sr *SkillRegistry) DescribeSkill(name string) (*SkillCapabilities, error) {
+	skill, err := sr.GetSkill(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if introspector, ok := skill.Executor.(SkillIntrospector); ok {
+		caps := introspector.Describe()
+		if caps.Name == "" {
+			caps.Name = skill.Name
+		}
+		if caps.Version == "" {
+			caps.Version = skill.Executor.Version()
+		}
+		if caps.Description == "" {
+			caps.Description = skill.Description
+		}
+		caps.Source = "skill"
+		return &caps, nil
+	}
+
+	caps := &SkillCapabilities{
+		Name:        skill.Name,
+		Description: skill.Description,
+		Metadata:    make(map[string]interface{}),
+		Source:      "document",
+	}
+	if skill.Executor != nil {
+		caps.Version = skill.Executor.Version()
+	}
+
+	doc, err := sr.GetSkillDocument(name)
+	if err != nil {
+		return caps, nil
+	}
+	if caps.Description == "" {
+		caps.Description = doc.Description
+	}
+	for key, value := range doc.Metadata {
+		caps.Metadata[key] = value
+	}
+	if caps.Version == "" {
+		if version, ok := doc.Metadata["version"]; ok && version != nil {
+			caps.Version = fmt.Sprint(version)
+		}
+	}
+	var ops []string
+	switch value := doc.Metadata["operations"].(type) {
+	case string:
+		ops = strings.Split(value, ",")
+	case []interface{}:
+		for _, op := range value {
+			ops = append(ops, fmt.Sprint(op))
+		}
+	}
+	for _, op := range ops {
+		if op = strings.TrimSpace(op); op != "" {
+			caps.Operations = append(caps.Operations, SkillOperationInfo{Name: op})
+		}
+	}
+
+	return caps, nil
+}
+
+// DescribeSkill is a convenience method to describe a skill
+func (fs *ToolFS) DescribeSkill(name string) (*SkillCapabilities, error) {
+	if fs.skillRegistry == nil {
+		return nil, errors.New("skill registry not initialized")
+	}
+	return fs.skillRegistry.DescribeSkill(name)
+}
+
+// RenderSkillCard renders a markdown "skill card" for prompts: the skill's
+// SKILL.md content preceded by live metadata from the registry and executor
+// manager (version, type, path, mount points, sandboxing and timeout).
+// Properties that are unknown for the skill are omitted.
+func (fs *ToolFS) RenderSkillCard(name string) (string, error) {
+	if fs.skillRegistry == nil {
+		return "", errors.New("skill registry not initialized")
+	}
+	skill, err := fs.skillRegistry.GetSkill(name)
+	if err != nil {
+		return "", err
+	}
+	caps, err := fs.skillRegistry.DescribeSkill(name)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", skill.Name)
+	if caps.Description != "" {
+		b.WriteString(caps.Description + "\n\n")
+	}
+
+	property := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "- **%s:** %s\n", key, value)
+		}
+	}
+	property("Version", caps.Version)
+	property("Type", string(skill.Type))
+	property("Path", skill.Path)
+
+	var mounts []string
+	for mountPoint, mount := range fs.skillMounts {
+		if mount.SkillName != name {
+			continue
+		}
+		if mount.ReadOnly {
+			mountPoint += " (read-only)"
+		}
+		mounts = append(mounts, mountPoint)
+	}
+	sort.Strings(mounts)
+	property("Mounted at", strings.Join(mounts, ", "))
+
+	if fs.executorManager != nil {
+		if managed, err := fs.executorManager.GetSkillInfo(name); err == nil {
+			property("Sandboxed", strconv.FormatBool(managed.Sandboxed))
+			if managed.Timeout > 0 {
+				property("Timeout", managed.Timeout.String())
+			}
+		}
+	}
+
+	var ops []string
+	for _, op := range caps.Operations {
+		ops = append(ops, op.Name)
+	}
+	property("Operations", strings.Join(ops, ", "))
+
+	if doc, err := fs.skillRegistry.GetSkillDocument(name); err == nil {
+		if body := strings.TrimSpace(stripFrontMatter(doc.Content)); body != "" {
+			b.WriteString("\n## Document\n\n" + body + "\n")
+		}
+	}
+
+	return b.String(), nil
+}
+
+// stripFrontMatter removes a leading "---" delimited front matter block
+func stripFrontMatter(content string) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return content
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[i+1:], "\n")
+		}
+	}
+	return content
+}
+
 // ExportSkillsJSON exports all skills as JSON
 func (sr *SkillRegistry) ExportSkillsJSON() ([]byte, error) {
 	skills := sr.ListSkills()
@@ -526,11 +785,15 @@ func (fs *ToolFS) RegisterSkillWithSkillDocs(executor SkillExecutor) error {
 }
 
 // RegisterSkill is a convenience method to register a skill with ToolFS
+// Code skills with a Path are mounted there (see RegisterCodeSkill)
 func (fs *ToolFS) RegisterSkill(skill *Skill) error {
 	if fs.skillRegistry == nil {
 		fs.skillRegistry = NewSkillRegistry(fs.skillDocManager)
 	}
-	return fs.skillRegistry.RegisterSkill(skill)
+	if err := fs.skillRegistry.RegisterSkill(skill); err != nil {
+		return err
+	}
+	return fs.autoMountSkill(skill)
 }
 
 // RegisterFilesystemSkill is a convenience method to register a filesystem skill
@@ -541,13 +804,34 @@ func (fs *ToolFS) RegisterFilesystemSkill(basePath string) (*Skill, error) {
 	return fs.skillRegistry.RegisterFilesystemSkill(basePath)
 }
 
-// RegisterCodeSkill is a convenience method to register a skill as a skill
 // RegisterCodeSkill is a convenience method to register a code-based skill with ToolFS
+// The skill is mounted at mountPath, so ReadFile(mountPath + "/run?operation=...")
+// executes it like ExecuteSkill with the query parameters as request data
 func (fs *ToolFS) RegisterCodeSkill(executor SkillExecutor, mountPath string) (*Skill, error) {
 	if fs.skillRegistry == nil {
 		fs.skillRegistry = NewSkillRegistry(fs.skillDocManager)
 	}
-	return fs.skillRegistry.RegisterCodeSkill(executor, mountPath)
+	skill, err := fs.skillRegistry.RegisterCodeSkill(executor, mountPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.autoMountSkill(skill); err != nil {
+		return nil, err
+	}
+	return skill, nil
+}
+
+// autoMountSkill mounts a newly registered code skill at its Path, undoing the
+// registration if the mount fails
+func (fs *ToolFS) autoMountSkill(skill *Skill) error {
+	if skill.Type != SkillTypeCode || skill.Executor == nil || skill.Path == "" {
+		return nil
+	}
+	if err := fs.MountSkill(skill); err != nil {
+		_ = fs.skillRegistry.UnregisterSkill(skill.Name)
+		return fmt.Errorf("failed to mount skill '%s': %w", skill.Name, err)
+	}
+	return nil
 }
 
 // LoadSkillsFromDirectory is a convenience method to load skills from a directory
@@ -596,12 +880,14 @@ func (sr *SkillRegistry) ExecuteSkill(name string, input []byte, session *Sessio
 		if skill.Executor == nil {
 			return nil, fmt.Errorf("skill '%s' has no executor", name)
 		}
+		if err := validateSkillInput(name, skill.Executor, input); err != nil {
+			return nil, err
+		}
 		return skill.Executor.Execute(input)
 
 	case SkillTypeFilesystem:
-		// For filesystem skills, we could execute scripts or return documentation
-		// This is a placeholder - actual implementation depends on requirements
-		return nil, fmt.Errorf("filesystem skill execution not yet implemented for '%s'", name)
+		// Run a script from the skill's scripts/ directory
+		return executeFilesystemSkill(skill, input, session)
 
 	case SkillTypeBuiltin:
 		// Builtin skills would have their own execution logic
@@ -614,10 +900,121 @@ func (sr *SkillRegistry) ExecuteSkill(name string, input []byte, session *Sessio
 
 // ExecuteSkill is a convenience method to execute a skill
 func (fs *ToolFS) ExecuteSkill(name string, input []byte, session *Session) ([]byte, error) {
+	end := fs.startSpan("ExecuteSkill", "", session, "skill", name)
 	if fs.skillRegistry == nil {
-		return nil, errors.New("skill registry not initialized")
+		err := errors.New("skill registry not initialized")
+		end(err)
+		return nil, err
+	}
+	if session != nil {
+		session.touch()
+	}
+	release, err := fs.acquireSkillExec(name)
+	if err != nil {
+		end(err)
+		return nil, err
+	}
+	output, err := fs.skillRegistry.ExecuteSkill(name, input, session)
+	release()
+	end(err)
+	return output, err
+}
+
+// PipelineStep is one skill in a pipeline executed by ExecuteSkillPipeline
+type PipelineStep struct {
+	Skill     string `json:"skill"`               // Registered skill name
+	Operation string `json:"operation,omitempty"` // Operation sent to the skill (steps after the first)
+
+	// Mapping copies fields of the previous result (keys) into the request
+	// data fields of this step (values). Without a mapping, an object result
+	// becomes the request data and any other result is passed as data["input"].
+	Mapping map[string]string `json:"mapping,omitempty"`
+}
+
+// PipelineStepError describes the step that aborted a skill pipeline
+type PipelineStepError struct {
+	Step  int    // Index of the failed step
+	Skill string // Skill executed by the step
+	Err   error
+}
+
+// Error implements the error interface
+func (e *PipelineStepError) Error() string {
+	return fmt.Sprintf("pipeline step %d (%s) failed: %v", e.Step, e.Skill, e.Err)
+}
+
+// Unwrap returns the underlying step error
+func (e *PipelineStepError) Unwrap() error {
+	return e.Err
+}
+
+// ExecuteSkillPipeline runs skills in sequence, feeding each step the
+// SkillResponse.Result of the previous one. input is the request for the first
+// step. It returns the raw output of the last step, or a *PipelineStepError
+// for the first step that fails.
+func (fs *ToolFS) ExecuteSkillPipeline(steps []PipelineStep, input []byte, session *Session) ([]byte, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("pipeline has no steps")
+	}
+
+	var output []byte
+	var previous interface{}
+	for i, step := range steps {
+		request := input
+		if i > 0 {
+			data, err := pipelineStepData(previous, step.Mapping)
+			if err != nil {
+				return nil, &PipelineStepError{Step: i, Skill: step.Skill, Err: err}
+			}
+			request, err = json.Marshal(SkillRequest{Operation: step.Operation, Data: data})
+			if err != nil {
+				return nil, &PipelineStepError{Step: i, Skill: step.Skill, Err: err}
+			}
+		}
+
+		var err error
+		output, err = fs.ExecuteSkill(step.Skill, request, session)
+		if err != nil {
+			return nil, &PipelineStepError{Step: i, Skill: step.Skill, Err: err}
+		}
+
+		var response SkillResponse
+		if err := json.Unmarshal(output, &response); err != nil {
+			return nil, &PipelineStepError{Step: i, Skill: step.Skill, Err: fmt.Errorf("invalid skill response: %w", err)}
+		}
+		if !response.Success {
+			return nil, &PipelineStepError{Step: i, Skill: step.Skill, Err: errors.New(response.Error)}
+		}
+		previous = response.Result
 	}
-	return fs.skillRegistry.ExecuteSkill(name, input, session)
+
+	return output, nil
+}
+
+// pipelineStepData builds the request data for a pipeline step from the
+// previous step's result
+func pipelineStepData(previous interface{}, mapping map[string]string) (map[string]interface{}, error) {
+	result, isObject := previous.(map[string]interface{})
+
+	if len(mapping) == 0 {
+		if isObject {
+			return result, nil
+		}
+		return map[string]interface{}{"input": previous}, nil
+	}
+
+	if !isObject {
+		return nil, fmt.Errorf("cannot map fields from non-object result of type %s", jsonTypeName(previous))
+	}
+	data := make(map[string]interface{}, len(mapping))
+	for from, to := range mapping {
+		value, ok := result[from]
+		if !ok {
+			return nil, fmt.Errorf("previous result has no field '%s'", from)
+		}
+		data[to] = value
+	}
+	return data, nil
 }
 
 // LoadSkill loads a skill from a file and registers it as a skill
@@ -643,10 +1040,19 @@ func (fs *ToolFS) LoadSkill(skillPath, mountPath string, config map[string]inter
 
 // MountSkill mounts a skill to a ToolFS path
 // This integrates skill mounting with the existing mount system
+// Code skills registered through ToolFS are already mounted, which is not an error
 func (fs *ToolFS) MountSkill(skill *Skill) error {
 	if skill.Type == SkillTypeCode && skill.Executor != nil {
 		// Mount code-based skill using existing skill mount mechanism
-		return fs.MountSkillExecutor(skill.Path, skill.Name)
+		path, err := fs.canonicalMountPoint(skill.Path)
+		if err != nil {
+			return err
+		}
+		// Registration already mounted the skill here
+		if existing, ok := fs.skillMounts[path]; ok && existing.SkillName == skill.Name {
+			return nil
+		}
+		return fs.mountSkillExecutor(path, skill.Name, skill.Executor)
 	}
 
 	// For filesystem skills, we could mount the base path
@@ -735,7 +1141,7 @@ func (r *SkillExecutorRegistry) Register(skill SkillExecutor, context *SkillCont
 func (r *SkillExecutorRegistry) Get(name string) (SkillExecutor, error) {
 	skill, exists := r.executors[name]
 	if !exists {
-		return nil, fmt.Errorf("skill '%s' not found", name)
+		return nil, fmt.Errorf("%w: '%s'", ErrSkillNotFound, name)
 	}
 	return skill, nil
 }
@@ -765,39 +1171,53 @@ func (r *SkillExecutorRegistry) Unregister(name string) {
 }
 
 // ExecuteSkill executes a skill with the given input.
+// Failures detected here are returned as responses with ErrorCode set.
 func (r *SkillExecutorRegistry) ExecuteSkill(name string, request *SkillRequest) (*SkillResponse, error) {
 	skill, err := r.Get(name)
 	if err != nil {
-		return &SkillResponse{
-			Success: false,
-			Error:   err.Error(),
-		}, err
+		return skillErrorResponse(err), err
 	}
 
 	input, err := json.Marshal(request)
 	if err != nil {
-		return &SkillResponse{
-			Success: false,
-			Error:   fmt.Sprintf("failed to encode request: %v", err),
-		}, err
+		err = fmt.Errorf("failed to encode request: %w", err)
+		return skillErrorResponse(err), err
+	}
+
+	if err := validateSkillInput(name, skill, input); err != nil {
+		return skillErrorResponse(err), err
 	}
 
-	output, err := skill.Execute(input)
+	output, err := executeRecovered(skill, input)
 	if err != nil {
-		return &SkillResponse{
-			Success: false,
-			Error:   err.Error(),
-		}, err
+		return skillErrorResponse(err), err
 	}
 
+	return decodeSkillResponse(output)
+}
+
+// executeRecovered runs the skill, converting a panic into an error wrapping
+// ErrSkillPanic
+func executeRecovered(skill SkillExecutor, input []byte) (output []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			output, err = nil, fmt.Errorf("%w: %v", ErrSkillPanic, r)
+		}
+	}()
+	return skill.Execute(input)
+}
+
+// decodeSkillResponse parses skill output, classifying undecodable output as
+// SkillErrorInvalidResponse
+func decodeSkillResponse(output []byte) (*SkillResponse, error) {
 	var response SkillResponse
 	if err := json.Unmarshal(output, &response); err != nil {
 		return &SkillResponse{
-			Success: false,
-			Error:   fmt.Sprintf("failed to decode response: %v", err),
+			Success:   false,
+			Error:     fmt.Sprintf("failed to decode response: %v", err),
+			ErrorCode: SkillErrorInvalidResponse,
 		}, err
 	}
-
 	return &response, nil
 }
 
@@ -846,16 +1266,37 @@ type WASMSkillLoader interface {
 	Instantiate(wasmBytes []byte, context *SkillContext) (SkillExecutor, error)
 }
 
+// NativeSkillSymbol is the function a Go plugin loaded by
+// SkillExecutorManager.LoadSkill must export, with the signature of
+// NativeSkillFactory:
+//
+//	func NewSkill(ctx *toolfs.SkillContext) (toolfs.SkillExecutor, error)
+const NativeSkillSymbol = "NewSkill"
+
+// NativeSkillFactory creates the executor of a Go plugin
+type NativeSkillFactory func(*SkillContext) (SkillExecutor, error)
+
 // =============================================
 // Skill Manager (Legacy API)
 // =============================================
 
 // SkillExecutorManager manages skill lifecycle, loading, and execution.
+// Several versions of an executor can be loaded at once: the plain name
+// resolves to the highest (semver) version and "name@version" keys (see
+// SkillVersionKey) select a specific one. Skill mounts keep the version
+// that was highest when they were mounted.
 type SkillExecutorManager struct {
 	registry   *SkillExecutorRegistry
-	executors  map[string]*ManagedSkill
+	executors  map[string]*ManagedSkill            // Name -> highest loaded version
+	versions   map[string]map[string]*ManagedSkill // Name -> version -> executor
 	wasmLoader WASMSkillLoader
 	timeout    time.Duration
+
+	// Per-skill concurrency limits (see SetSkillConcurrencyLimit)
+	limitersMu   sync.Mutex
+	limiters     map[string]*execLimiter
+	policy       ConcurrencyPolicy
+	queueTimeout time.Duration
 }
 
 // NewSkillExecutorManager creates a new SkillExecutorManager with default settings.
@@ -863,6 +1304,7 @@ func NewSkillExecutorManager() *SkillExecutorManager {
 	return &SkillExecutorManager{
 		registry:  NewSkillExecutorRegistry(),
 		executors: make(map[string]*ManagedSkill),
+		versions:  make(map[string]map[string]*ManagedSkill),
 		timeout:   30 * time.Second,
 	}
 }
@@ -877,7 +1319,10 @@ func (pm *SkillExecutorManager) SetWASMLoader(loader WASMSkillLoader) {
 	pm.wasmLoader = loader
 }
 
-// LoadSkill loads a executor from a file path.
+// LoadSkill loads a executor from a file path: a .wasm module through the
+// WASM loader, or a Go plugin (.so) exporting NativeSkillSymbol. Go plugins
+// are only supported on linux and darwin with cgo, run unsandboxed and must
+// be built with the same toolchain and dependency versions as the host.
 func (pm *SkillExecutorManager) LoadSkill(path string, context *SkillContext, config map[string]interface{}) error {
 	if path == "" {
 		return errors.New("executor path cannot be empty")
@@ -889,6 +1334,7 @@ func (pm *SkillExecutorManager) LoadSkill(path string, context *SkillContext, co
 
 	var executor SkillExecutor
 	var wasmBytes []byte
+	sandboxed := true
 
 	if strings.HasSuffix(strings.ToLower(path), ".wasm") {
 		if pm.wasmLoader == nil {
@@ -905,8 +1351,19 @@ func (pm *SkillExecutorManager) LoadSkill(path string, context *SkillContext, co
 		if err != nil {
 			return fmt.Errorf("failed to instantiate WASM executor: %w", err)
 		}
+	} else if strings.HasSuffix(strings.ToLower(path), ".so") {
+		var err error
+		executor, err = loadNativeSkill(path, context)
+		if err != nil {
+			return err
+		}
+		sandboxed = false
 	} else {
-		return errors.New("native Go executor loading not yet implemented, use InjectSkill instead")
+		return fmt.Errorf("unsupported executor file '%s': expected a .wasm or .so file", path)
+	}
+
+	if pm.isLoaded(executor) {
+		return fmt.Errorf("executor '%s' version %s is already loaded", executor.Name(), executor.Version())
 	}
 
 	if config == nil {
@@ -924,20 +1381,20 @@ func (pm *SkillExecutorManager) LoadSkill(path string, context *SkillContext, co
 		LoadedAt:  time.Now(),
 		Config:    config,
 		Timeout:   pm.timeout,
-		Sandboxed: true,
+		Sandboxed: sandboxed,
 	}
 
-	pm.executors[executor.Name()] = managed
-
-	if err := pm.registry.Register(executor, context); err != nil {
-		delete(pm.executors, executor.Name())
+	if err := pm.add(managed); err != nil {
 		return fmt.Errorf("failed to register executor: %w", err)
 	}
 
 	return nil
 }
 
-// InjectSkill injects a executor directly into the ToolFS runtime.
+// InjectSkill injects a executor directly into the ToolFS runtime. Another
+// version of an already loaded executor may be injected alongside it. An
+// unbound context is bound to the executor's private state; a context
+// injected with several skills gets no private state.
 func (pm *SkillExecutorManager) InjectSkill(executor SkillExecutor, context *SkillContext, config map[string]interface{}) error {
 	if executor == nil {
 		return errors.New("executor cannot be nil")
@@ -948,7 +1405,7 @@ func (pm *SkillExecutorManager) InjectSkill(executor SkillExecutor, context *Ski
 		return errors.New("executor name cannot be empty")
 	}
 
-	if _, exists := pm.executors[name]; exists {
+	if pm.isLoaded(executor) {
 		return fmt.Errorf("executor '%s' is already loaded", name)
 	}
 
@@ -956,6 +1413,12 @@ func (pm *SkillExecutorManager) InjectSkill(executor SkillExecutor, context *Ski
 		config = make(map[string]interface{})
 	}
 
+	if context != nil {
+		if err := context.bindInjected(name); err != nil {
+			return err
+		}
+	}
+
 	if err := executor.Init(config); err != nil {
 		return fmt.Errorf("executor initialization failed: %w", err)
 	}
@@ -970,10 +1433,7 @@ func (pm *SkillExecutorManager) InjectSkill(executor SkillExecutor, context *Ski
 		Sandboxed: false,
 	}
 
-	pm.executors[name] = managed
-
-	if err := pm.registry.Register(executor, context); err != nil {
-		delete(pm.executors, name)
+	if err := pm.add(managed); err != nil {
 		return fmt.Errorf("failed to register executor: %w", err)
 	}
 
@@ -991,7 +1451,7 @@ func (pm *SkillExecutorManager) ListSkills() []string {
 
 // GetSkillInfo returns information about a loaded executor.
 func (pm *SkillExecutorManager) GetSkillInfo(name string) (*ManagedSkill, error) {
-	managed, exists := pm.executors[name]
+	managed, exists := pm.lookup(name)
 	if !exists {
 		return nil, fmt.Errorf("executor '%s' not found", name)
 	}
@@ -999,10 +1459,16 @@ func (pm *SkillExecutorManager) GetSkillInfo(name string) (*ManagedSkill, error)
 }
 
 // ExecuteSkill executes a executor with the given input, respecting timeout.
+// Errors wrap ErrSkillNotFound, ErrSkillTimeout, ErrSkillPanic or
+// ErrConcurrencyLimit, or are *SkillValidationError, where applicable.
 func (pm *SkillExecutorManager) ExecuteSkill(name string, input []byte) ([]byte, error) {
-	managed, exists := pm.executors[name]
+	managed, exists := pm.lookup(name)
 	if !exists {
-		return nil, fmt.Errorf("executor '%s' not found", name)
+		return nil, fmt.Errorf("%w: executor '%s'", ErrSkillNotFound, name)
+	}
+
+	if err := validateSkillInput(name, managed.Executor, input); err != nil {
+		return nil, err
 	}
 
 	timeout := managed.Timeout
@@ -1010,10 +1476,17 @@ func (pm *SkillExecutorManager) ExecuteSkill(name string, input []byte) ([]byte,
 		timeout = pm.timeout
 	}
 
+	limiter := pm.limiter(name)
+	if err := limiter.acquire("skill '"+name+"'", pm.policy, pm.queueTimeout); err != nil {
+		return nil, err
+	}
+
 	resultChan := make(chan executeResult, 1)
 
 	go func() {
-		output, err := managed.Executor.Execute(input)
+		// The slot is held until the executor returns, even after a timeout
+		defer limiter.release()
+		output, err := executeRecovered(managed.Executor, input)
 		resultChan <- executeResult{output: output, err: err}
 	}()
 
@@ -1024,31 +1497,65 @@ func (pm *SkillExecutorManager) ExecuteSkill(name string, input []byte) ([]byte,
 		}
 		return result.output, nil
 	case <-time.After(timeout):
-		return nil, fmt.Errorf("executor execution timeout after %v", timeout)
+		return nil, fmt.Errorf("%w: executor execution timeout after %v", ErrSkillTimeout, timeout)
 	}
 }
 
+// ExecuteRequest executes a executor like ExecuteSkill, encoding the request
+// and decoding the response. Failures detected by the manager are returned as
+// responses with ErrorCode and Retryable set.
+func (pm *SkillExecutorManager) ExecuteRequest(name string, request *SkillRequest) (*SkillResponse, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		err = fmt.Errorf("failed to encode request: %w", err)
+		return skillErrorResponse(err), err
+	}
+
+	output, err := pm.ExecuteSkill(name, input)
+	if err != nil {
+		return skillErrorResponse(err), err
+	}
+
+	return decodeSkillResponse(output)
+}
+
 type executeResult struct {
 	output []byte
 	err    error
 }
 
-// UnloadSkill removes a executor from the manager.
+// UnloadSkill removes a executor from the manager, closing it if it
+// implements ClosableSkill. The executor is removed even if Close fails.
+// A plain name unloads every loaded version; "name@version" only that one.
 func (pm *SkillExecutorManager) UnloadSkill(name string) error {
-	_, exists := pm.executors[name]
+	return pm.unload(name)
+}
+
+// CheckHealth calls HealthCheck on a loaded executor if it implements
+// HealthChecker; other executors are reported healthy.
+func (pm *SkillExecutorManager) CheckHealth(name string) error {
+	managed, exists := pm.lookup(name)
 	if !exists {
-		return fmt.Errorf("executor '%s' not found", name)
+		return fmt.Errorf("%w: executor '%s'", ErrSkillNotFound, name)
 	}
+	return checkHealth(managed.Executor)
+}
 
-	pm.registry.Unregister(name)
-	delete(pm.executors, name)
-
+// closeSkill calls Close on skill if it implements ClosableSkill
+func closeSkill(name string, skill SkillExecutor) error {
+	closable, ok := skill.(ClosableSkill)
+	if !ok {
+		return nil
+	}
+	if err := closable.Close(); err != nil {
+		return fmt.Errorf("failed to close skill '%s': %w", name, err)
+	}
 	return nil
 }
 
 // SetSkillTimeout sets a custom timeout for a specific executor.
 func (pm *SkillExecutorManager) SetSkillTimeout(name string, timeout time.Duration) error {
-	managed, exists := pm.executors[name]
+	managed, exists := pm.lookup(name)
 	if !exists {
 		return fmt.Errorf("executor '%s' not found", name)
 	}
@@ -1063,7 +1570,7 @@ func (pm *SkillExecutorManager) SetSkillTimeout(name string, timeout time.Durati
 
 // SetSkillSandboxed sets whether a executor runs in sandbox mode.
 func (pm *SkillExecutorManager) SetSkillSandboxed(name string, sandboxed bool) error {
-	managed, exists := pm.executors[name]
+	managed, exists := pm.lookup(name)
 	if !exists {
 		return fmt.Errorf("executor '%s' not found", name)
 	}
@@ -2,8 +2,10 @@ package toolfs
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -416,3 +418,385 @@ func (p *MockSkill) Execute(input []byte) ([]byte, error) {
 	}
 	return json.Marshal(response)
 }
+
+// ValidatedSkill declares a required query field via a JSON schema
+type ValidatedSkill struct {
+	*JSONSchemaValidator
+	executed bool
+}
+
+func (p *ValidatedSkill) Name() string                             { return "validated-skill" }
+func (p *ValidatedSkill) Version() string                          { return "1.0.0" }
+func (p *ValidatedSkill) Init(config map[string]interface{}) error { return nil }
+
+func (p *ValidatedSkill) Execute(input []byte) ([]byte, error) {
+	p.executed = true
+	return json.Marshal(SkillResponse{Success: true, Result: "ok"})
+}
+
+func TestSkillInputValidation(t *testing.T) {
+	validator, err := ParseJSONSchemaValidator([]byte(`{
+		"search": {
+			"type": "object",
+			"required": ["query"],
+			"properties": {
+				"query": {"type": "string", "minLength": 1},
+				"top_k": {"type": "integer", "minimum": 1}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	skill := &ValidatedSkill{JSONSchemaValidator: validator}
+	registry := NewSkillRegistry(nil)
+	if _, err := registry.RegisterCodeSkill(skill, "/toolfs/skills/validated"); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+
+	// Missing required field is rejected before reaching the skill
+	input, _ := json.Marshal(SkillRequest{Operation: "search", Data: map[string]interface{}{"top_k": 3}})
+	_, err = registry.ExecuteSkill("validated-skill", input, nil)
+	if err == nil {
+		t.Fatal("Expected validation error for missing query")
+	}
+	var validationErr *SkillValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected SkillValidationError, got %T: %v", err, err)
+	}
+	if validationErr.Field != "query" || validationErr.Skill != "validated-skill" || validationErr.Operation != "search" {
+		t.Errorf("Unexpected validation error details: %+v", validationErr)
+	}
+	if !strings.Contains(err.Error(), "required field is missing") {
+		t.Errorf("Expected clear message, got: %v", err)
+	}
+	if skill.executed {
+		t.Error("Skill should not be executed when validation fails")
+	}
+
+	// Wrong type is rejected
+	input, _ = json.Marshal(SkillRequest{Operation: "search", Data: map[string]interface{}{"query": "ai", "top_k": 1.5}})
+	if _, err := registry.ExecuteSkill("validated-skill", input, nil); err == nil {
+		t.Error("Expected validation error for non-integer top_k")
+	}
+
+	// Valid input reaches the skill
+	input, _ = json.Marshal(SkillRequest{Operation: "search", Data: map[string]interface{}{"query": "ai", "top_k": 2}})
+	if _, err := registry.ExecuteSkill("validated-skill", input, nil); err != nil {
+		t.Fatalf("Expected valid input to pass: %v", err)
+	}
+	if !skill.executed {
+		t.Error("Skill should be executed when validation passes")
+	}
+
+	// Operations without a schema are not validated
+	skill.executed = false
+	input, _ = json.Marshal(SkillRequest{Operation: "list"})
+	if _, err := registry.ExecuteSkill("validated-skill", input, nil); err != nil {
+		t.Fatalf("Expected unvalidated operation to pass: %v", err)
+	}
+}
+
+// DescribingSkill reports its operations through SkillIntrospector
+type DescribingSkill struct {
+	executed bool
+}
+
+func (p *DescribingSkill) Name() string                             { return "describing-skill" }
+func (p *DescribingSkill) Version() string                          { return "2.1.0" }
+func (p *DescribingSkill) Init(config map[string]interface{}) error { return nil }
+
+func (p *DescribingSkill) Execute(input []byte) ([]byte, error) {
+	p.executed = true
+	return json.Marshal(SkillResponse{Success: true})
+}
+
+func (p *DescribingSkill) Describe() SkillCapabilities {
+	return SkillCapabilities{
+		Description: "Searches indexed documents",
+		Operations: []SkillOperationInfo{
+			{
+				Name:        "search",
+				Description: "Full text search",
+				InputSchema: &JSONSchema{Type: "object", Required: []string{"query"}},
+				Examples: []SkillExample{
+					{Input: map[string]interface{}{"query": "toolfs"}, Output: []string{"/docs/readme.md"}},
+				},
+			},
+			{Name: "index"},
+		},
+	}
+}
+
+func TestDescribeSkill(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	skill := &DescribingSkill{}
+	if _, err := fs.RegisterCodeSkill(skill, "/toolfs/skills/describing"); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+
+	caps, err := fs.DescribeSkill("describing-skill")
+	if err != nil {
+		t.Fatalf("DescribeSkill failed: %v", err)
+	}
+	if caps.Source != "skill" || caps.Name != "describing-skill" || caps.Version != "2.1.0" {
+		t.Errorf("Unexpected capabilities: %+v", caps)
+	}
+	if len(caps.Operations) != 2 || caps.Operations[0].Name != "search" || caps.Operations[1].Name != "index" {
+		t.Fatalf("Expected search and index operations, got %+v", caps.Operations)
+	}
+	if caps.Operations[0].InputSchema == nil || len(caps.Operations[0].Examples) != 1 {
+		t.Error("Expected input schema and example for search")
+	}
+	if skill.executed {
+		t.Error("DescribeSkill should not execute the skill")
+	}
+
+	// Skills without Describe fall back to their SKILL.md metadata
+	docSkill := &DocSkill{
+		MockSkill: MockSkill{name: "doc-skill", version: "1.0.0"},
+		doc: `---
+name: doc-skill
+description: Converts documents
+operations: convert, preview
+---
+# Doc Skill
+`,
+	}
+	if _, err := fs.RegisterCodeSkill(docSkill, "/toolfs/skills/doc"); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+
+	caps, err = fs.DescribeSkill("doc-skill")
+	if err != nil {
+		t.Fatalf("DescribeSkill failed: %v", err)
+	}
+	if caps.Source != "document" || caps.Description != "Converts documents" || caps.Version != "1.0.0" {
+		t.Errorf("Unexpected fallback capabilities: %+v", caps)
+	}
+	if len(caps.Operations) != 2 || caps.Operations[0].Name != "convert" || caps.Operations[1].Name != "preview" {
+		t.Errorf("Expected operations from front matter, got %+v", caps.Operations)
+	}
+
+	if _, err := fs.DescribeSkill("missing"); err == nil {
+		t.Error("Expected error for unknown skill")
+	}
+}
+
+// DocSkill provides a SKILL.md document but does not implement Describe
+type DocSkill struct {
+	MockSkill
+	doc string
+}
+
+func (p *DocSkill) GetSkillDocument() string { return p.doc }
+
+// TransformSkill applies fn to the "text" request field and returns {"text": ...}
+type TransformSkill struct {
+	name string
+	fn   func(string) string
+}
+
+func (p *TransformSkill) Name() string                             { return p.name }
+func (p *TransformSkill) Version() string                          { return "1.0.0" }
+func (p *TransformSkill) Init(config map[string]interface{}) error { return nil }
+
+func (p *TransformSkill) Execute(input []byte) ([]byte, error) {
+	var request SkillRequest
+	if err := json.Unmarshal(input, &request); err != nil {
+		return nil, err
+	}
+	text, ok := request.Data["text"].(string)
+	if !ok {
+		return json.Marshal(SkillResponse{Success: false, Error: "text is required"})
+	}
+	return json.Marshal(SkillResponse{
+		Success: true,
+		Result:  map[string]interface{}{"text": p.fn(text), "length": len(text)},
+	})
+}
+
+func TestExecuteSkillPipeline(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	upper := &TransformSkill{name: "upper", fn: strings.ToUpper}
+	wrap := &TransformSkill{name: "wrap", fn: func(s string) string { return "[" + s + "]" }}
+	for _, skill := range []*TransformSkill{upper, wrap} {
+		if _, err := fs.RegisterCodeSkill(skill, "/toolfs/skills/"+skill.name); err != nil {
+			t.Fatalf("Failed to register %s: %v", skill.name, err)
+		}
+	}
+
+	input, _ := json.Marshal(SkillRequest{Operation: "transform", Data: map[string]interface{}{"text": "hello"}})
+	output, err := fs.ExecuteSkillPipeline([]PipelineStep{
+		{Skill: "upper"},
+		{Skill: "wrap", Operation: "transform"},
+	}, input, nil)
+	if err != nil {
+		t.Fatalf("Pipeline failed: %v", err)
+	}
+
+	var response SkillResponse
+	if err := json.Unmarshal(output, &response); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	result, _ := response.Result.(map[string]interface{})
+	if result["text"] != "[HELLO]" {
+		t.Errorf("Expected both transforms applied, got %v", response.Result)
+	}
+
+	// Field mapping selects what the next step receives
+	_, err = fs.ExecuteSkillPipeline([]PipelineStep{
+		{Skill: "upper"},
+		{Skill: "wrap", Mapping: map[string]string{"length": "text"}},
+	}, input, nil)
+	var stepErr *PipelineStepError
+	if !errors.As(err, &stepErr) {
+		t.Fatalf("Expected PipelineStepError, got %v", err)
+	}
+	if stepErr.Step != 1 || stepErr.Skill != "wrap" || !strings.Contains(err.Error(), "text is required") {
+		t.Errorf("Unexpected step error: %v", err)
+	}
+
+	// Missing mapped fields and unknown skills abort at the failing step
+	_, err = fs.ExecuteSkillPipeline([]PipelineStep{
+		{Skill: "upper"},
+		{Skill: "wrap", Mapping: map[string]string{"missing": "text"}},
+	}, input, nil)
+	if !errors.As(err, &stepErr) || stepErr.Step != 1 || !strings.Contains(err.Error(), "no field 'missing'") {
+		t.Errorf("Expected mapping error at step 1, got %v", err)
+	}
+	_, err = fs.ExecuteSkillPipeline([]PipelineStep{{Skill: "nope"}}, input, nil)
+	if !errors.As(err, &stepErr) || stepErr.Step != 0 {
+		t.Errorf("Expected error at step 0, got %v", err)
+	}
+}
+
+func TestRenderSkillCard(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	skill := &DocSkill{
+		MockSkill: MockSkill{name: "card-skill", version: "3.2.1"},
+		doc: `---
+name: card-skill
+description: Summarizes reports
+operations: summarize
+---
+# Card Skill
+
+Use summarize to condense a report.
+`,
+	}
+	if _, err := fs.RegisterCodeSkill(skill, "/toolfs/skills/card"); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	pm.InjectSkill(skill, NewSkillContext(fs, nil), nil)
+	pm.SetSkillSandboxed("card-skill", true)
+	if err := fs.MountSkillExecutor("/reports", "card-skill"); err != nil {
+		t.Fatalf("MountSkillExecutor failed: %v", err)
+	}
+
+	card, err := fs.RenderSkillCard("card-skill")
+	if err != nil {
+		t.Fatalf("RenderSkillCard failed: %v", err)
+	}
+	for _, want := range []string{
+		"# card-skill",
+		"Summarizes reports",
+		"Use summarize to condense a report.",
+		"- **Version:** 3.2.1",
+		"- **Type:** code",
+		"- **Path:** /toolfs/skills/card",
+		"- **Mounted at:** /toolfs/reports (read-only)",
+		"- **Sandboxed:** true",
+		"- **Timeout:** 30s",
+		"- **Operations:** summarize",
+	} {
+		if !strings.Contains(card, want) {
+			t.Errorf("Card missing %q:\n%s", want, card)
+		}
+	}
+	if strings.Contains(card, "description: Summarizes reports") {
+		t.Errorf("Card should not include front matter:\n%s", card)
+	}
+
+	if _, err := fs.RenderSkillCard("missing"); err == nil {
+		t.Error("Expected error for unknown skill")
+	}
+}
+
+func TestParseSkillDocumentYAML(t *testing.T) {
+	sdm := NewSkillDocumentManager()
+	doc, err := sdm.parseSkillDocument(`---
+name: "yaml-skill"
+description: |
+  Converts documents: PDF, DOCX
+  and more.
+version: 2
+tags: [convert, "pdf"]
+limits:
+  max_size: 1048576
+  formats:
+    - pdf
+    - docx
+operations:
+  - convert
+  - preview
+---
+# Heading Not Used
+
+Body text.
+`)
+	if err != nil {
+		t.Fatalf("parseSkillDocument failed: %v", err)
+	}
+	if doc.Name != "yaml-skill" {
+		t.Errorf("Expected name from front matter, got %q", doc.Name)
+	}
+	if doc.Description != "Converts documents: PDF, DOCX\nand more." {
+		t.Errorf("Expected multi-line description, got %q", doc.Description)
+	}
+	if doc.Metadata["version"] != 2 {
+		t.Errorf("Expected numeric version, got %#v", doc.Metadata["version"])
+	}
+	tags, ok := doc.Metadata["tags"].([]interface{})
+	if !ok || len(tags) != 2 || tags[0] != "convert" || tags[1] != "pdf" {
+		t.Errorf("Expected tag list, got %#v", doc.Metadata["tags"])
+	}
+	limits, ok := doc.Metadata["limits"].(map[string]interface{})
+	if !ok || limits["max_size"] != 1048576 || len(limits["formats"].([]interface{})) != 2 {
+		t.Errorf("Expected nested map, got %#v", doc.Metadata["limits"])
+	}
+	if _, ok := doc.Metadata["name"]; ok {
+		t.Error("Expected name to be kept out of Metadata")
+	}
+	if !strings.HasPrefix(doc.Content, "# Heading Not Used") {
+		t.Errorf("Expected content after front matter, got %q", doc.Content)
+	}
+
+	// Without a front matter name the first heading is used
+	doc, err = sdm.parseSkillDocument("---\ndescription: no name\n---\n# From Heading\n")
+	if err != nil || doc.Name != "From Heading" {
+		t.Errorf("Expected heading fallback, got %+v, %v", doc, err)
+	}
+
+	// Invalid YAML is reported
+	if _, err := sdm.parseSkillDocument("---\nname: [unclosed\n---\n"); err == nil {
+		t.Error("Expected error for invalid front matter")
+	}
+
+	// Operation lists are reported by DescribeSkill
+	fs := NewToolFS("/toolfs")
+	docSkill := &DocSkill{
+		MockSkill: MockSkill{name: "yaml-ops", version: "1.0.0"},
+		doc:       "---\nname: yaml-ops\noperations:\n  - convert\n  - preview\n---\n",
+	}
+	if _, err := fs.RegisterCodeSkill(docSkill, "/toolfs/skills/yaml-ops"); err != nil {
+		t.Fatalf("Failed to register skill: %v", err)
+	}
+	caps, err := fs.DescribeSkill("yaml-ops")
+	if err != nil || len(caps.Operations) != 2 || caps.Operations[1].Name != "preview" {
+		t.Errorf("Expected operations from YAML list, got %+v, %v", caps, err)
+	}
+}
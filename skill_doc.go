@@ -6,6 +6,8 @@ import (
 	"io/fs"
 	"path/filepath"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 //go:embed skills/*.md skills/*/*.md
@@ -126,7 +128,11 @@ func (sdm *SkillDocumentManager) LoadBuiltinSkillDocs() error {
 	})
 }
 
-// parseSkillDocument parses a SKILL.md document and extracts front matter and content
+// parseSkillDocument parses a SKILL.md document and extracts front matter and content.
+// The front matter is YAML: "name" and "description" fill the matching fields
+// and every other key is kept in Metadata with its YAML type (lists become
+// []interface{}, numbers stay numeric). Without a front matter name, the first
+// "# " heading is used.
 func (sdm *SkillDocumentManager) parseSkillDocument(content string) (*SkillDocument, error) {
 	doc := &SkillDocument{
 		Content:  content,
@@ -137,39 +143,27 @@ func (sdm *SkillDocumentManager) parseSkillDocument(content string) (*SkillDocum
 	lines := strings.Split(content, "\n")
 	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
 		// Extract front matter
-		var frontMatter []string
 		endIdx := -1
 		for i := 1; i < len(lines); i++ {
 			if strings.TrimSpace(lines[i]) == "---" {
 				endIdx = i
 				break
 			}
-			frontMatter = append(frontMatter, lines[i])
 		}
 
 		if endIdx > 0 {
-			// Parse front matter (simple YAML-like parser)
-			for _, line := range frontMatter {
-				line = strings.TrimSpace(line)
-				if line == "" {
-					continue
-				}
-				parts := strings.SplitN(line, ":", 2)
-				if len(parts) == 2 {
-					key := strings.TrimSpace(parts[0])
-					value := strings.Trim(strings.TrimSpace(parts[1]), "\"'")
-
-					switch key {
-					case "name":
-						doc.Name = value
-					case "description":
-						doc.Description = value
-					default:
-						if doc.Metadata == nil {
-							doc.Metadata = make(map[string]interface{})
-						}
-						doc.Metadata[key] = value
-					}
+			var frontMatter map[string]interface{}
+			if err := yaml.Unmarshal([]byte(strings.Join(lines[1:endIdx], "\n")), &frontMatter); err != nil {
+				return nil, fmt.Errorf("invalid front matter: %w", err)
+			}
+			for key, value := range frontMatter {
+				switch key {
+				case "name":
+					doc.Name = strings.TrimSpace(fmt.Sprint(value))
+				case "description":
+					doc.Description = strings.TrimSpace(fmt.Sprint(value))
+				default:
+					doc.Metadata[key] = value
 				}
 			}
 
@@ -0,0 +1,91 @@
+package toolfs
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Relevance weights for a query word found in each skill document field
+const (
+	skillDocNameWeight        = 3
+	skillDocDescriptionWeight = 2
+	skillDocContentWeight     = 1
+)
+
+// SearchDocuments returns the skill documents matching query, most relevant
+// first. Each query word found in a document's name, description or content
+// adds to its score, with name matches weighted highest and content matches
+// lowest; matching is case-insensitive. Documents matching no word are left
+// out, and ties are ordered by name.
+func (sdm *SkillDocumentManager) SearchDocuments(query string) []*SkillDocument {
+	words := skillSearchWords(query)
+	if len(words) == 0 {
+		return []*SkillDocument{}
+	}
+
+	type scoredDoc struct {
+		doc   *SkillDocument
+		score int
+	}
+	var scored []scoredDoc
+	for _, doc := range sdm.documents {
+		name := strings.ToLower(doc.Name)
+		description := strings.ToLower(doc.Description)
+		content := strings.ToLower(doc.Content)
+
+		score := 0
+		for _, word := range words {
+			if strings.Contains(name, word) {
+				score += skillDocNameWeight
+			}
+			if strings.Contains(description, word) {
+				score += skillDocDescriptionWeight
+			}
+			if strings.Contains(content, word) {
+				score += skillDocContentWeight
+			}
+		}
+		if score > 0 {
+			scored = append(scored, scoredDoc{doc: doc, score: score})
+		}
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].doc.Name < scored[j].doc.Name
+	})
+	docs := make([]*SkillDocument, len(scored))
+	for i, s := range scored {
+		docs[i] = s.doc
+	}
+	return docs
+}
+
+// skillSearchWords splits query into distinct lowercase words, treating any
+// character other than a letter, digit, '-' or '_' as a separator
+func skillSearchWords(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	})
+	seen := make(map[string]bool, len(fields))
+	words := fields[:0]
+	for _, field := range fields {
+		if !seen[field] {
+			seen[field] = true
+			words = append(words, field)
+		}
+	}
+	return words
+}
+
+// SearchSkillDocuments returns the registered skill documents matching query,
+// most relevant first (see SkillDocumentManager.SearchDocuments)
+func (fs *ToolFS) SearchSkillDocuments(query string) []*SkillDocument {
+	if fs.skillDocManager == nil {
+		return []*SkillDocument{}
+	}
+	return fs.skillDocManager.SearchDocuments(query)
+}
@@ -0,0 +1,51 @@
+package toolfs
+
+import "testing"
+
+func TestSearchSkillDocuments(t *testing.T) {
+	sdm := NewSkillDocumentManager()
+	docs := map[string]string{
+		"weather/SKILL.md":   "---\nname: weather\ndescription: Look up forecasts. Use this when the user asks about rain or temperature.\n---\n\n# Weather\n",
+		"calendar/SKILL.md":  "---\nname: calendar\ndescription: Manage events. Use this when the user schedules a meeting.\n---\n\n# Calendar\n\nMeetings can be moved if rain is forecast.\n",
+		"translate/SKILL.md": "---\nname: translate\ndescription: Translate text between languages.\n---\n\n# Translate\n",
+	}
+	for path, content := range docs {
+		if err := sdm.RegisterDocument(path, content); err != nil {
+			t.Fatalf("RegisterDocument(%s) failed: %v", path, err)
+		}
+	}
+
+	names := func(results []*SkillDocument) []string {
+		out := make([]string, len(results))
+		for i, doc := range results {
+			out[i] = doc.Name
+		}
+		return out
+	}
+
+	// Description matches outrank content matches
+	results := names(sdm.SearchDocuments("Will it RAIN tomorrow?"))
+	if len(results) != 2 || results[0] != "weather" || results[1] != "calendar" {
+		t.Errorf("Expected [weather calendar], got %v", results)
+	}
+
+	// Name matches outrank description matches
+	results = names(sdm.SearchDocuments("schedule a calendar meeting"))
+	if len(results) == 0 || results[0] != "calendar" {
+		t.Errorf("Expected calendar first, got %v", results)
+	}
+
+	if results := sdm.SearchDocuments("quantum"); len(results) != 0 {
+		t.Errorf("Expected no results, got %v", names(results))
+	}
+	if results := sdm.SearchDocuments("  "); len(results) != 0 {
+		t.Errorf("Expected no results for empty query, got %v", names(results))
+	}
+
+	// Builtin skill documents are searchable through ToolFS
+	fs := NewToolFS("/toolfs")
+	results = names(fs.SearchSkillDocuments("rollback changes to a previous state"))
+	if len(results) == 0 || results[0] != "toolfs-snapshot" {
+		t.Errorf("Expected toolfs-snapshot first, got %v", results)
+	}
+}
@@ -0,0 +1,152 @@
+package toolfs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MountTypeSkills is the MountInfo type of the virtual skill document directory
+const MountTypeSkills = "skills"
+
+// skillsVirtualMount marks paths served from the SkillDocumentManager, like
+// __VIRTUAL_MEMORY__ and __VIRTUAL_RAG__ for the memory and RAG stores
+const skillsVirtualMount = "__VIRTUAL_SKILLS__"
+
+// skillDocFileName is the only file in each skill's virtual directory
+const skillDocFileName = "SKILL.md"
+
+// skillDocPath splits a path below the skills directory into the skill name
+// and the file in the skill's directory; both are empty for the skills
+// directory itself. ok is false for paths outside it or nested deeper than
+// <name>/<file>.
+func (fs *ToolFS) skillDocPath(path string) (name, file string, ok bool) {
+	path = strings.TrimSuffix(normalizeVirtualPath(path), "/")
+	if path == fs.skillsPath {
+		return "", "", true
+	}
+	rel := strings.TrimPrefix(path, fs.skillsPath+"/")
+	if rel == path || rel == "" {
+		return "", "", false
+	}
+	name, file, _ = strings.Cut(rel, "/")
+	if name == "" || strings.Contains(file, "/") {
+		return "", "", false
+	}
+	return name, file, true
+}
+
+// isSkillDocFile reports whether path names a skill's SKILL.md. These paths
+// resolve to the skill documents even below a skill mounted at
+// <skills>/<name>, so every registered skill can be discovered the same way.
+func (fs *ToolFS) isSkillDocFile(path string) bool {
+	_, file, ok := fs.skillDocPath(path)
+	return ok && file == skillDocFileName
+}
+
+// isSkillDocDir reports whether path names the skills directory or a skill's
+// directory. Mounts at these paths take precedence over the documents.
+func (fs *ToolFS) isSkillDocDir(path string) bool {
+	_, file, ok := fs.skillDocPath(path)
+	return ok && file == ""
+}
+
+// skillDocument returns the document of the named skill
+func (fs *ToolFS) skillDocument(name string) (*SkillDocument, error) {
+	doc, err := fs.skillDocManager.GetDocument(name)
+	if err != nil {
+		return nil, notFoundf("skill document not found: %s", name)
+	}
+	return doc, nil
+}
+
+// readSkillDoc returns the raw content of a skill's SKILL.md
+func (fs *ToolFS) readSkillDoc(path string) ([]byte, error) {
+	name, file, _ := fs.skillDocPath(path)
+	if file == "" {
+		return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+	}
+	if hasTrailingSlash(path) {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, path)
+	}
+	doc, err := fs.skillDocument(name)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(doc.Content), nil
+}
+
+// listSkillDocs lists the documented skills, sorted by name, or the SKILL.md
+// in a skill's directory
+func (fs *ToolFS) listSkillDocs(path string) ([]string, error) {
+	name, file, _ := fs.skillDocPath(path)
+	if file != "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, path)
+	}
+	if name != "" {
+		if _, err := fs.skillDocument(name); err != nil {
+			return nil, err
+		}
+		return []string{skillDocFileName}, nil
+	}
+
+	names := make([]string, 0)
+	for _, name := range fs.skillDocManager.ListDocumentNames() {
+		// Names that are not a single path segment cannot be addressed
+		if name != "" && !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// statSkillDoc returns metadata for a path in the skills directory
+func (fs *ToolFS) statSkillDoc(path string, backedBy *MountInfo, wantDir bool) (*FileInfo, error) {
+	name, file, _ := fs.skillDocPath(path)
+	if name == "" {
+		return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true, BackedBy: backedBy}, nil
+	}
+	doc, err := fs.skillDocument(name)
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true, BackedBy: backedBy}, nil
+	}
+	if wantDir {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, path)
+	}
+	return &FileInfo{Size: int64(len(doc.Content)), ModTime: time.Now(), IsDir: false, BackedBy: backedBy}, nil
+}
+
+// skillDocEntry describes name in the skills directory dir
+func (fs *ToolFS) skillDocEntry(dir, name string) FileInfoEntry {
+	entry := FileInfoEntry{RelPath: name, ModTime: time.Now()}
+	if info, err := fs.statSkillDoc(dir+"/"+name, nil, false); err == nil {
+		entry.Size, entry.IsDir = info.Size, info.IsDir
+	}
+	return entry
+}
+
+// listSkillDocsRecursive lists the skills directory with each skill's SKILL.md
+func (fs *ToolFS) listSkillDocsRecursive(path string) ([]FileInfoEntry, error) {
+	dir := strings.TrimSuffix(normalizeVirtualPath(path), "/")
+	names, err := fs.listSkillDocs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []FileInfoEntry
+	for _, name := range names {
+		entry := fs.skillDocEntry(dir, name)
+		entries = append(entries, entry)
+		if entry.IsDir {
+			file := fs.skillDocEntry(dir+"/"+name, skillDocFileName)
+			file.RelPath = name + "/" + skillDocFileName
+			entries = append(entries, file)
+		}
+	}
+	return entries, nil
+}
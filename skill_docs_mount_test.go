@@ -0,0 +1,128 @@
+package toolfs
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// hasEntry reports whether the sorted entries contain name
+func hasEntry(entries []string, name string) bool {
+	i := sort.SearchStrings(entries, name)
+	return i < len(entries) && entries[i] == name
+}
+
+func TestSkillDocsDirectory(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	skill := &DocSkill{
+		MockSkill: MockSkill{name: "data-processor", version: "1.0.0"},
+		doc: `---
+name: data-processor
+description: Processes data
+---
+# Data Processor
+
+Use process to clean a dataset.`,
+	}
+	if err := fs.RegisterSkillWithSkillDocs(skill); err != nil {
+		t.Fatalf("RegisterSkillWithSkillDocs failed: %v", err)
+	}
+
+	entries, err := fs.ListDir("/toolfs/skills")
+	if err != nil {
+		t.Fatalf("ListDir failed: %v", err)
+	}
+	if !hasEntry(entries, "data-processor") || !hasEntry(entries, "memory") {
+		t.Errorf("Expected registered and built-in skills, got %v", entries)
+	}
+
+	entries, err = fs.ListDir("/toolfs/skills/data-processor")
+	if err != nil || len(entries) != 1 || entries[0] != "SKILL.md" {
+		t.Errorf("Expected SKILL.md in skill directory, got %v (%v)", entries, err)
+	}
+
+	data, err := fs.ReadFile("/toolfs/skills/data-processor/SKILL.md")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	doc, _ := fs.skillDocManager.GetDocument("data-processor")
+	if string(data) != doc.Content || !strings.Contains(string(data), "# Data Processor") {
+		t.Errorf("Expected document content, got %q", data)
+	}
+
+	info, err := fs.Stat("/toolfs/skills/data-processor/SKILL.md")
+	if err != nil || info.IsDir || info.Size != int64(len(doc.Content)) {
+		t.Errorf("Unexpected SKILL.md info %+v (%v)", info, err)
+	}
+	if info.BackedBy == nil || info.BackedBy.Type != MountTypeSkills {
+		t.Errorf("Expected skill document mount, got %+v", info.BackedBy)
+	}
+	if info, err := fs.Stat("/toolfs/skills/data-processor"); err != nil || !info.IsDir {
+		t.Errorf("Expected skill directory, got %+v (%v)", info, err)
+	}
+
+	if _, err := fs.ReadFile("/toolfs/skills/data-processor"); !errors.Is(err, ErrIsDirectory) {
+		t.Errorf("Expected ErrIsDirectory, got %v", err)
+	}
+	if _, err := fs.ReadFile("/toolfs/skills/missing/SKILL.md"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := fs.WriteFile("/toolfs/skills/data-processor/SKILL.md", []byte("x")); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestSkillDocsBelowSkillMount(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	skill := &DocSkill{
+		MockSkill: MockSkill{name: "data-processor", version: "1.0.0"},
+		doc:       "# Data Processor\n\nProcesses data.",
+	}
+	if _, err := fs.RegisterCodeSkill(skill, "/toolfs/skills/data-processor"); err != nil {
+		t.Fatalf("RegisterCodeSkill failed: %v", err)
+	}
+
+	// The document stays readable while other paths reach the skill
+	data, err := fs.ReadFile("/toolfs/skills/data-processor/SKILL.md")
+	if err != nil || !strings.Contains(string(data), "Processes data.") {
+		t.Errorf("Expected document content, got %q (%v)", data, err)
+	}
+	data, err = fs.ReadFile("/toolfs/skills/data-processor/run")
+	if err != nil || string(data) != "mock result" {
+		t.Errorf("Expected skill result, got %q (%v)", data, err)
+	}
+
+	entries, err := fs.ListDir("/toolfs/skills")
+	if err != nil || !hasEntry(entries, "data-processor") {
+		t.Errorf("Expected mounted skill in listing, got %v (%v)", entries, err)
+	}
+}
+
+func TestSkillDocsListDirInfo(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+
+	infos, err := fs.ListDirInfo("/toolfs/skills", nil)
+	if err != nil {
+		t.Fatalf("ListDirInfo failed: %v", err)
+	}
+	for _, info := range infos {
+		if !info.IsDir {
+			t.Errorf("Expected skill directories, got %+v", info)
+		}
+	}
+
+	entries, err := fs.ListDirRecursive("/toolfs/skills", 0)
+	if err != nil {
+		t.Fatalf("ListDirRecursive failed: %v", err)
+	}
+	found := false
+	for _, entry := range entries {
+		if entry.Path == "/toolfs/skills/memory/SKILL.md" {
+			found = !entry.IsDir && entry.Size > 0
+		}
+	}
+	if !found {
+		t.Errorf("Expected built-in memory SKILL.md in recursive listing, got %+v", entries)
+	}
+}
@@ -0,0 +1,61 @@
+package toolfs
+
+import "errors"
+
+// Standard SkillResponse.ErrorCode values. Skills may report these or their
+// own codes; ToolFS uses them for failures it detects itself.
+const (
+	SkillErrorNotFound        = "not_found"        // No skill with the requested name
+	SkillErrorInvalidInput    = "invalid_input"    // The request failed input validation
+	SkillErrorTimeout         = "timeout"          // The skill did not finish in time (retryable)
+	SkillErrorPanic           = "panic"            // The skill panicked
+	SkillErrorUnavailable     = "unavailable"      // A concurrency limit was reached (retryable)
+	SkillErrorInvalidResponse = "invalid_response" // The skill output could not be decoded
+	SkillErrorInternal        = "internal"         // Any other failure
+)
+
+// ErrSkillNotFound is wrapped by errors for executions of unknown skills
+var ErrSkillNotFound = errors.New("skill not found")
+
+// ErrSkillTimeout is wrapped by errors for executions that exceeded their timeout
+var ErrSkillTimeout = errors.New("skill execution timed out")
+
+// ErrSkillPanic is wrapped by errors for executions that panicked
+var ErrSkillPanic = errors.New("skill execution panicked")
+
+// ErrUnsupportedOperation is wrapped by errors for mount operations a skill
+// does not declare through OperationProvider
+var ErrUnsupportedOperation = errors.New("operation not supported by skill")
+
+// ClassifySkillError maps an execution error to a standard error code and
+// whether retrying the same request may succeed
+func ClassifySkillError(err error) (code string, retryable bool) {
+	var validationErr *SkillValidationError
+	switch {
+	case err == nil:
+		return "", false
+	case errors.Is(err, ErrSkillNotFound):
+		return SkillErrorNotFound, false
+	case errors.As(err, &validationErr):
+		return SkillErrorInvalidInput, false
+	case errors.Is(err, ErrSkillTimeout):
+		return SkillErrorTimeout, true
+	case errors.Is(err, ErrSkillPanic):
+		return SkillErrorPanic, false
+	case errors.Is(err, ErrConcurrencyLimit):
+		return SkillErrorUnavailable, true
+	default:
+		return SkillErrorInternal, false
+	}
+}
+
+// skillErrorResponse builds a failed SkillResponse classified from err
+func skillErrorResponse(err error) *SkillResponse {
+	code, retryable := ClassifySkillError(err)
+	return &SkillResponse{
+		Success:   false,
+		Error:     err.Error(),
+		ErrorCode: code,
+		Retryable: retryable,
+	}
+}
@@ -0,0 +1,58 @@
+package toolfs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSkillErrorCodes(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	ctx := NewSkillContext(fs, nil)
+
+	validator, err := ParseJSONSchemaValidator([]byte(`{"search": {"type": "object", "required": ["query"]}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+	pm.InjectSkill(&SlowSkill{}, ctx, map[string]interface{}{"delay": float64(200)})
+	pm.InjectSkill(&ValidatedSkill{JSONSchemaValidator: validator}, ctx, nil)
+	pm.InjectSkill(&PanicSkill{}, ctx, nil)
+	pm.SetSkillTimeout("slow-skill", 20*time.Millisecond)
+
+	// Timeouts are retryable
+	response, err := pm.ExecuteRequest("slow-skill", &SkillRequest{Operation: "run"})
+	if !errors.Is(err, ErrSkillTimeout) {
+		t.Errorf("Expected ErrSkillTimeout, got %v", err)
+	}
+	if response.Success || response.ErrorCode != SkillErrorTimeout || !response.Retryable {
+		t.Errorf("Expected retryable timeout response, got %+v", response)
+	}
+
+	// Validation failures are not
+	response, err = pm.ExecuteRequest("validated-skill", &SkillRequest{Operation: "search", Data: map[string]interface{}{}})
+	if err == nil || response.ErrorCode != SkillErrorInvalidInput || response.Retryable {
+		t.Errorf("Expected non-retryable invalid_input response, got %+v (%v)", response, err)
+	}
+
+	response, _ = pm.ExecuteRequest("missing-skill", &SkillRequest{})
+	if response.ErrorCode != SkillErrorNotFound || response.Retryable {
+		t.Errorf("Expected not_found response, got %+v", response)
+	}
+
+	// The registry classifies its failures the same way, and recovers panics
+	registry := fs.GetSkillExecutorRegistry()
+	response, err = registry.ExecuteSkill("panic-skill", &SkillRequest{})
+	if !errors.Is(err, ErrSkillPanic) || response.ErrorCode != SkillErrorPanic {
+		t.Errorf("Expected panic response, got %+v (%v)", response, err)
+	}
+	response, _ = registry.ExecuteSkill("validated-skill", &SkillRequest{Operation: "search", Data: map[string]interface{}{"query": "ok"}})
+	if !response.Success || response.ErrorCode != "" {
+		t.Errorf("Expected success without error code, got %+v", response)
+	}
+
+	if code, retryable := ClassifySkillError(ErrConcurrencyLimit); code != SkillErrorUnavailable || !retryable {
+		t.Errorf("Expected retryable unavailable, got %s, %v", code, retryable)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build (linux || darwin) && cgo
+// +build linux darwin
+// +build cgo
+
+package toolfs
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// loadNativeSkill opens a shared object built with -buildmode=plugin and
+// instantiates the skill returned by its NativeSkillSymbol function
+func loadNativeSkill(path string, context *SkillContext) (SkillExecutor, error) {
+	lib, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open native executor: %w", err)
+	}
+	symbol, err := lib.Lookup(NativeSkillSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("native executor %s does not export %s: %w", path, NativeSkillSymbol, err)
+	}
+
+	var factory NativeSkillFactory
+	switch f := symbol.(type) {
+	case func(*SkillContext) (SkillExecutor, error):
+		factory = f
+	case *func(*SkillContext) (SkillExecutor, error):
+		factory = *f
+	default:
+		return nil, fmt.Errorf("native executor %s: %s has type %T, expected func(*SkillContext) (SkillExecutor, error)", path, NativeSkillSymbol, symbol)
+	}
+	if factory == nil {
+		return nil, fmt.Errorf("native executor %s: %s is nil", path, NativeSkillSymbol)
+	}
+
+	executor, err := factory(context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate native executor: %w", err)
+	}
+	if executor == nil {
+		return nil, fmt.Errorf("native executor %s: %s returned no executor", path, NativeSkillSymbol)
+	}
+	return executor, nil
+}
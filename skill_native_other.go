@@ -0,0 +1,14 @@
+//go:build !((linux || darwin) && cgo)
+// +build !linux,!darwin !cgo
+
+package toolfs
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// loadNativeSkill is unsupported where the plugin package is unavailable
+func loadNativeSkill(path string, context *SkillContext) (SkillExecutor, error) {
+	return nil, fmt.Errorf("cannot load native executor %s: Go plugins are not supported on %s/%s (requires linux or darwin with cgo)", path, runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,128 @@
+//go:build (linux || darwin) && cgo
+// +build linux darwin
+// +build cgo
+
+package toolfs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const nativeSkillSource = `package main
+
+import "github.com/IceWhaleTech/toolfs"
+
+type greeter struct{ greeting string }
+
+func (g *greeter) Name() string    { return "greeter" }
+func (g *greeter) Version() string { return "1.0.0" }
+
+func (g *greeter) Init(config map[string]interface{}) error {
+	if greeting, ok := config["greeting"].(string); ok {
+		g.greeting = greeting
+	}
+	return nil
+}
+
+func (g *greeter) Execute(input []byte) ([]byte, error) {
+	return []byte(g.greeting + ", " + string(input)), nil
+}
+
+func NewSkill(ctx *toolfs.SkillContext) (toolfs.SkillExecutor, error) {
+	return &greeter{greeting: "hello"}, nil
+}
+`
+
+// nativeSkillHost loads the plugin given as its argument. A plugin can only
+// be loaded by a binary built against the same packages, which the test
+// binary is not, so the test runs this host instead.
+const nativeSkillHost = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/IceWhaleTech/toolfs"
+)
+
+func main() {
+	fs := toolfs.NewToolFS("/toolfs")
+	pm := toolfs.NewSkillExecutorManager()
+	ctx := toolfs.NewSkillContext(fs, nil)
+	if err := pm.LoadSkill(os.Args[1], ctx, map[string]interface{}{"greeting": "hi"}); err != nil {
+		fmt.Println("load error:", err)
+		os.Exit(1)
+	}
+	output, err := pm.ExecuteSkill("greeter", []byte("toolfs"))
+	if err != nil {
+		fmt.Println("execute error:", err)
+		os.Exit(1)
+	}
+	info, _ := pm.GetSkillInfo("greeter")
+	fmt.Printf("%s sandboxed=%v\n", output, info.Sandboxed)
+
+	if err := pm.LoadSkill(os.Args[2], ctx, nil); err == nil {
+		fmt.Println("expected error for a plugin without NewSkill")
+		os.Exit(1)
+	}
+}
+`
+
+func TestSkillExecutorManagerLoadNativeSkill(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds Go plugins")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+	repoDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile(filepath.Join(repoDir, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/nativeskill\n\ngo 1.21\n\n" +
+			"require github.com/IceWhaleTech/toolfs v0.0.0\n\n" +
+			"replace github.com/IceWhaleTech/toolfs => " + repoDir + "\n",
+		"go.sum":          string(goSum),
+		"skill/main.go":   nativeSkillSource,
+		"invalid/main.go": "package main\n\nfunc Other() {}\n",
+		"host/main.go":    nativeSkillHost,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(name string, args ...string) string {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %v failed: %v\n%s", name, args, err, output)
+		}
+		return string(output)
+	}
+	run(goTool, "build", "-buildmode=plugin", "-o", "skill.so", "./skill")
+	run(goTool, "build", "-buildmode=plugin", "-o", "invalid.so", "./invalid")
+	run(goTool, "build", "-o", "skillhost", "./host")
+
+	output := run(filepath.Join(dir, "skillhost"), filepath.Join(dir, "skill.so"), filepath.Join(dir, "invalid.so"))
+	if strings.TrimSpace(output) != "hi, toolfs sandboxed=false" {
+		t.Errorf("Unexpected host output %q", output)
+	}
+}
@@ -0,0 +1,52 @@
+package toolfs
+
+import (
+	"net/url"
+	"strings"
+)
+
+// skillRunPath is the path below a skill mount whose reads execute the skill
+// with query-derived input, e.g.
+//
+//	fs.ReadFile("/toolfs/skills/report/run?operation=summarize&id=7")
+//
+// sends the skill the operation and data of
+//
+//	fs.ExecuteSkill("report", []byte(`{"operation":"summarize","data":{"id":"7"}}`), nil)
+//
+// alongside the usual mount path information.
+const skillRunPath = "/run"
+
+// defaultRunOperation is the operation used when a run path has no
+// "operation" query parameter.
+const defaultRunOperation = "run"
+
+// isSkillRun reports whether a skill mount operation reads the run path.
+func isSkillRun(operation, relPath string) bool {
+	relPath, _, _ = strings.Cut(relPath, "?")
+	return operation == "read_file" && relPath == skillRunPath
+}
+
+// applyRunQuery turns request into a run request: the "operation" parameter
+// names the skill operation and every other parameter becomes request data,
+// single values as strings and repeated ones as string slices. Parameters do
+// not override the path information already in request.Data.
+func applyRunQuery(request *SkillRequest, query url.Values) {
+	request.Operation = defaultRunOperation
+	if op := query.Get("operation"); op != "" {
+		request.Operation = op
+	}
+	for key, values := range query {
+		if key == "operation" {
+			continue
+		}
+		if _, reserved := request.Data[key]; reserved {
+			continue
+		}
+		if len(values) == 1 {
+			request.Data[key] = values[0]
+		} else {
+			request.Data[key] = values
+		}
+	}
+}
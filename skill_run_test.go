@@ -0,0 +1,112 @@
+package toolfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegisterCodeSkillMountsRunPath(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	skill := &PathRecordingSkill{}
+	registered, err := fs.RegisterCodeSkill(skill, "/toolfs/skills/recorder")
+	if err != nil {
+		t.Fatalf("RegisterCodeSkill failed: %v", err)
+	}
+
+	// Registration mounted the skill, so mounting it again is a no-op
+	if err := fs.MountSkill(registered); err != nil {
+		t.Errorf("MountSkill after registration failed: %v", err)
+	}
+
+	data, err := fs.ReadFile("/toolfs/skills/recorder/run?operation=search&q=go&tag=a&tag=b")
+	if err != nil {
+		t.Fatalf("ReadFile run failed: %v", err)
+	}
+	if string(data) != "ok" {
+		t.Errorf("Expected skill result, got %q", data)
+	}
+
+	request := skill.requests[len(skill.requests)-1]
+	if request.Operation != "search" {
+		t.Errorf("Expected operation from query, got %q", request.Operation)
+	}
+	if request.Data["q"] != "go" {
+		t.Errorf("Expected single value as string, got %#v", request.Data["q"])
+	}
+	if tags, ok := request.Data["tag"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("Expected repeated value as list, got %#v", request.Data["tag"])
+	}
+	if _, ok := request.Data["operation"]; ok {
+		t.Error("Expected operation to be removed from request data")
+	}
+	if request.Data["relative_path"] != "/run" {
+		t.Errorf("Expected path information to be kept, got %#v", request.Data["relative_path"])
+	}
+
+	// Without an operation parameter the skill is asked to run
+	if _, err := fs.ReadFile("/toolfs/skills/recorder/run"); err != nil {
+		t.Fatalf("ReadFile run failed: %v", err)
+	}
+	if op := skill.requests[len(skill.requests)-1].Operation; op != defaultRunOperation {
+		t.Errorf("Expected default operation, got %q", op)
+	}
+
+	// Other paths keep forwarding plain reads
+	if _, err := fs.ReadFile("/toolfs/skills/recorder/docs?q=go"); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if op := skill.requests[len(skill.requests)-1].Operation; op != "read_file" {
+		t.Errorf("Expected read_file outside the run path, got %q", op)
+	}
+}
+
+func TestSkillRunValidation(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	skill := &DeclaredOpsSkill{name: "declared", ops: []string{"search"}}
+	if _, err := fs.RegisterCodeSkill(skill, "/toolfs/skills/declared"); err != nil {
+		t.Fatalf("RegisterCodeSkill failed: %v", err)
+	}
+
+	// Run reads are checked against the operation they name
+	if _, err := fs.ReadFile("/toolfs/skills/declared/run?operation=search"); err != nil {
+		t.Errorf("Expected declared operation to run, got %v", err)
+	}
+	if _, err := fs.ReadFile("/toolfs/skills/declared/run?operation=delete"); !errors.Is(err, ErrUnsupportedOperation) {
+		t.Errorf("Expected ErrUnsupportedOperation, got %v", err)
+	}
+	if len(skill.requests) != 1 {
+		t.Errorf("Expected only the declared operation to reach the skill, got %d requests", len(skill.requests))
+	}
+}
+
+func TestRegisterCodeSkillMountFailure(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	if _, err := fs.RegisterCodeSkill(&PathRecordingSkill{}, "/toolfs/skills/../recorder"); err == nil {
+		t.Fatal("Expected an invalid mount path to fail")
+	}
+	if _, err := fs.GetSkill("path-recorder"); err == nil {
+		t.Error("Expected failed registration to be undone")
+	}
+}
+
+func TestMountSkillExecutorFallsBackToSkillRegistry(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	skill := &PathRecordingSkill{}
+	if _, err := fs.RegisterCodeSkill(skill, "/toolfs/skills/recorder"); err != nil {
+		t.Fatalf("RegisterCodeSkill failed: %v", err)
+	}
+
+	// No executor registry is set, so the registered code skill is used
+	if err := fs.MountSkillExecutor("/toolfs/recorder", "path-recorder"); err != nil {
+		t.Fatalf("MountSkillExecutor failed: %v", err)
+	}
+	if _, err := fs.ReadFile("/toolfs/recorder/a.txt"); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(skill.requests) != 1 {
+		t.Errorf("Expected request to reach the skill, got %d", len(skill.requests))
+	}
+	if err := fs.MountSkillExecutor("/toolfs/missing", "missing"); err == nil {
+		t.Error("Expected unknown skill to fail")
+	}
+}
@@ -0,0 +1,305 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SkillInputValidator is an optional interface that skills can implement
+// to validate request data before Execute is called.
+type SkillInputValidator interface {
+	// ValidateInput checks the request data for the given operation.
+	ValidateInput(op string, data map[string]interface{}) error
+}
+
+// SkillValidationError describes why a skill request was rejected.
+type SkillValidationError struct {
+	Skill     string `json:"skill,omitempty"`
+	Operation string `json:"operation,omitempty"`
+	Field     string `json:"field,omitempty"` // Dotted path of the offending field
+	Message   string `json:"message"`         // Human readable reason
+}
+
+// Error implements the error interface
+func (e *SkillValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid input")
+	if e.Skill != "" {
+		fmt.Fprintf(&b, " for skill '%s'", e.Skill)
+	}
+	if e.Operation != "" {
+		fmt.Fprintf(&b, " (operation '%s')", e.Operation)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, ": field '%s'", e.Field)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// JSONSchema is the subset of JSON Schema supported by JSONSchemaValidator.
+// It can be decoded directly from a JSON schema document.
+type JSONSchema struct {
+	Type       string                 `json:"type,omitempty"` // "object", "string", "number", "integer", "boolean", "array"
+	Required   []string               `json:"required,omitempty"`
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	Items      *JSONSchema            `json:"items,omitempty"`
+	Enum       []interface{}          `json:"enum,omitempty"`
+	MinLength  *int                   `json:"minLength,omitempty"`
+	MaxLength  *int                   `json:"maxLength,omitempty"`
+	Minimum    *float64               `json:"minimum,omitempty"`
+	Maximum    *float64               `json:"maximum,omitempty"`
+
+	// AdditionalProperties set to false rejects unknown object fields
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+}
+
+// JSONSchemaValidator is the default SkillInputValidator implementation.
+// Schemas are keyed by operation; the "*" key applies to every operation
+// without a dedicated schema.
+type JSONSchemaValidator struct {
+	schemas map[string]*JSONSchema
+}
+
+// NewJSONSchemaValidator creates a validator from per-operation schemas
+func NewJSONSchemaValidator(schemas map[string]*JSONSchema) *JSONSchemaValidator {
+	if schemas == nil {
+		schemas = make(map[string]*JSONSchema)
+	}
+	return &JSONSchemaValidator{schemas: schemas}
+}
+
+// ParseJSONSchemaValidator creates a validator from a JSON document mapping
+// operation names to JSON schemas
+func ParseJSONSchemaValidator(data []byte) (*JSONSchemaValidator, error) {
+	var schemas map[string]*JSONSchema
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON schema: %w", err)
+	}
+	return NewJSONSchemaValidator(schemas), nil
+}
+
+// SetSchema sets the schema used for an operation ("*" for all operations)
+func (v *JSONSchemaValidator) SetSchema(op string, schema *JSONSchema) {
+	v.schemas[op] = schema
+}
+
+// ValidateInput implements SkillInputValidator
+func (v *JSONSchemaValidator) ValidateInput(op string, data map[string]interface{}) error {
+	schema, exists := v.schemas[op]
+	if !exists {
+		schema, exists = v.schemas["*"]
+	}
+	if !exists || schema == nil {
+		return nil
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	if err := schema.validate("", data); err != nil {
+		err.Operation = op
+		return err
+	}
+	return nil
+}
+
+// validateSkillInput runs the executor's input validator, if it has one,
+// against the decoded request
+func validateSkillInput(name string, executor SkillExecutor, input []byte) error {
+	validator, ok := executor.(SkillInputValidator)
+	if !ok {
+		return nil
+	}
+
+	var request SkillRequest
+	if err := json.Unmarshal(input, &request); err != nil {
+		return &SkillValidationError{
+			Skill:   name,
+			Message: fmt.Sprintf("request is not valid JSON: %v", err),
+		}
+	}
+
+	if err := validator.ValidateInput(request.Operation, request.Data); err != nil {
+		var validationErr *SkillValidationError
+		if errors.As(err, &validationErr) {
+			if validationErr.Skill == "" {
+				validationErr.Skill = name
+			}
+			if validationErr.Operation == "" {
+				validationErr.Operation = request.Operation
+			}
+			return validationErr
+		}
+		return &SkillValidationError{
+			Skill:     name,
+			Operation: request.Operation,
+			Message:   err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// validate checks value against the schema, returning the first violation
+func (s *JSONSchema) validate(field string, value interface{}) *SkillValidationError {
+	if s.Type != "" && !jsonTypeMatches(s.Type, value) {
+		return &SkillValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("expected %s, got %s", s.Type, jsonTypeName(value)),
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		matched := false
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &SkillValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("value %v is not one of %v", value, s.Enum),
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &SkillValidationError{
+					Field:   joinSchemaField(field, name),
+					Message: "required field is missing",
+				}
+			}
+		}
+
+		// Iterate in sorted order so the reported violation is deterministic
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			propSchema, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return &SkillValidationError{
+						Field:   joinSchemaField(field, key),
+						Message: "unknown field",
+					}
+				}
+				continue
+			}
+			if err := propSchema.validate(joinSchemaField(field, key), v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", field, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			return &SkillValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("length must be at least %d", *s.MinLength),
+			}
+		}
+		if s.MaxLength != nil && len(v) > *s.MaxLength {
+			return &SkillValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("length must be at most %d", *s.MaxLength),
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return &SkillValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("must be >= %v", *s.Minimum),
+			}
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return &SkillValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("must be <= %v", *s.Maximum),
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonTypeMatches reports whether a decoded JSON value has the given schema type
+func jsonTypeMatches(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		switch value.(type) {
+		case float64, float32, int, int64:
+			return true
+		}
+		return false
+	case "integer":
+		switch n := value.(type) {
+		case int, int64:
+			return true
+		case float64:
+			return n == float64(int64(n))
+		}
+		return false
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+// jsonTypeName returns the JSON type name of a decoded value
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinSchemaField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
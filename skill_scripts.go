@@ -0,0 +1,121 @@
+package toolfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultSkillScriptTimeout limits filesystem skill scripts run without a
+// timeout_ms in their request
+const DefaultSkillScriptTimeout = 30 * time.Second
+
+// SkillScriptRequest is the input for executing a filesystem skill. Script
+// names a file under the skill's scripts/ directory.
+type SkillScriptRequest struct {
+	Script    string   `json:"script"`
+	Args      []string `json:"args,omitempty"`
+	TimeoutMs int      `json:"timeout_ms,omitempty"` // 0 = DefaultSkillScriptTimeout
+}
+
+// SkillScriptResult is the SkillResponse.Result of a filesystem skill script
+type SkillScriptResult struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+// executeFilesystemSkill runs the script named in input from the skill's
+// scripts directory and returns a JSON SkillResponse. The response reports
+// success only for exit code 0; failures to run the script, including
+// timeouts, are returned as errors.
+func executeFilesystemSkill(skill *Skill, input []byte, session *Session) ([]byte, error) {
+	var request SkillScriptRequest
+	if err := json.Unmarshal(input, &request); err != nil {
+		return nil, &SkillValidationError{Skill: skill.Name, Message: "invalid script request: " + err.Error()}
+	}
+	if request.Script == "" {
+		return nil, &SkillValidationError{Skill: skill.Name, Field: "script", Message: "script is required"}
+	}
+
+	scriptPath, err := resolveSkillScript(skill.ScriptsPath, request.Script)
+	if err != nil {
+		return nil, err
+	}
+	if session != nil {
+		if allowed, reason := session.ValidateCommand(request.Script, request.Args); !allowed {
+			return nil, fmt.Errorf("command not allowed: %s", reason)
+		}
+	}
+
+	timeout := DefaultSkillScriptTimeout
+	if request.TimeoutMs > 0 {
+		timeout = time.Duration(request.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, scriptPath, request.Args...)
+	cmd.Dir = skill.BasePath
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%w: script '%s' of skill '%s' exceeded %v", ErrSkillTimeout, request.Script, skill.Name, timeout)
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return nil, fmt.Errorf("failed to run script '%s' of skill '%s': %w", request.Script, skill.Name, runErr)
+	}
+
+	result := SkillScriptResult{
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}
+	response := SkillResponse{Success: result.ExitCode == 0, Result: result}
+	if !response.Success {
+		response.Error = fmt.Sprintf("script '%s' exited with status %d", request.Script, result.ExitCode)
+	}
+	return json.Marshal(response)
+}
+
+// resolveSkillScript returns the path of script within scriptsDir, rejecting
+// names (or symlinks) that lead outside it
+func resolveSkillScript(scriptsDir, script string) (string, error) {
+	if scriptsDir == "" {
+		return "", errors.New("skill has no scripts directory")
+	}
+	root, err := filepath.EvalSymlinks(scriptsDir)
+	if err != nil {
+		return "", fmt.Errorf("scripts directory unavailable: %w", err)
+	}
+
+	scriptPath := filepath.Join(root, filepath.FromSlash(script))
+	resolved, err := filepath.EvalSymlinks(scriptPath)
+	if err != nil {
+		return "", fmt.Errorf("script '%s' not found: %w", script, err)
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("%w: script '%s' is outside the scripts directory", ErrAccessDenied, script)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("script '%s' is not a regular file", script)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,102 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecuteFilesystemSkillScript(t *testing.T) {
+	skillDir := filepath.Join(t.TempDir(), "script-skill")
+	scriptsDir := filepath.Join(skillDir, "scripts")
+	if err := os.MkdirAll(scriptsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skillMd := "---\nname: script-skill\ndescription: Runs scripts\n---\n\n# Script Skill\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skillMd), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	scripts := map[string]string{
+		"echo.sh":  "#!/bin/sh\necho \"args: $*\"\necho oops >&2\n",
+		"fail.sh":  "#!/bin/sh\necho failing\nexit 3\n",
+		"sleep.sh": "#!/bin/sh\nsleep 5\n",
+	}
+	for name, content := range scripts {
+		if err := os.WriteFile(filepath.Join(scriptsDir, name), []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "outside.sh"), []byte("#!/bin/sh\necho outside\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewSkillRegistry(NewSkillDocumentManager())
+	if _, err := registry.RegisterFilesystemSkill(skillDir); err != nil {
+		t.Fatalf("RegisterFilesystemSkill failed: %v", err)
+	}
+
+	run := func(input string) (*SkillResponse, SkillScriptResult, error) {
+		t.Helper()
+		output, err := registry.ExecuteSkill("script-skill", []byte(input), nil)
+		if err != nil {
+			return nil, SkillScriptResult{}, err
+		}
+		var response SkillResponse
+		if err := json.Unmarshal(output, &response); err != nil {
+			t.Fatalf("invalid response %s: %v", output, err)
+		}
+		var result SkillScriptResult
+		data, _ := json.Marshal(response.Result)
+		json.Unmarshal(data, &result)
+		return &response, result, nil
+	}
+
+	response, result, err := run(`{"script": "echo.sh", "args": ["a", "b"]}`)
+	if err != nil {
+		t.Fatalf("echo.sh failed: %v", err)
+	}
+	if !response.Success || result.ExitCode != 0 {
+		t.Errorf("Expected success with exit code 0, got %+v", response)
+	}
+	if result.Stdout != "args: a b\n" || result.Stderr != "oops\n" {
+		t.Errorf("Unexpected output: stdout=%q stderr=%q", result.Stdout, result.Stderr)
+	}
+
+	response, result, err = run(`{"script": "fail.sh"}`)
+	if err != nil {
+		t.Fatalf("fail.sh returned error: %v", err)
+	}
+	if response.Success || result.ExitCode != 3 || result.Stdout != "failing\n" {
+		t.Errorf("Expected failure with exit code 3, got %+v", response)
+	}
+	if !strings.Contains(response.Error, "status 3") {
+		t.Errorf("Expected exit status in error, got %q", response.Error)
+	}
+
+	if _, _, err := run(`{"script": "sleep.sh", "timeout_ms": 100}`); !errors.Is(err, ErrSkillTimeout) {
+		t.Errorf("Expected ErrSkillTimeout, got %v", err)
+	}
+
+	for _, script := range []string{"../outside.sh", "../SKILL.md", "/bin/sh"} {
+		if _, _, err := run(`{"script": "` + script + `"}`); err == nil {
+			t.Errorf("Expected %s to be rejected", script)
+		}
+	}
+	if err := os.Symlink(filepath.Join(skillDir, "outside.sh"), filepath.Join(scriptsDir, "link.sh")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := run(`{"script": "link.sh"}`); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected symlink escape to be denied, got %v", err)
+	}
+
+	var validationErr *SkillValidationError
+	if _, _, err := run(`{"args": ["x"]}`); !errors.As(err, &validationErr) {
+		t.Errorf("Expected validation error for missing script, got %v", err)
+	}
+	if _, _, err := run(`{"script": "missing.sh"}`); err == nil {
+		t.Error("Expected error for missing script")
+	}
+}
@@ -0,0 +1,141 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetSkillStateRoot sets the directory under which skills get private state
+// directories (see SkillContext.StateDir). Skill state is disabled until a
+// root is set.
+func (fs *ToolFS) SetSkillStateRoot(dir string) error {
+	if dir == "" {
+		fs.skillStateRoot = ""
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	fs.skillStateRoot = abs
+	return nil
+}
+
+// NewSkillContextForSkill creates a skill context bound to the named skill,
+// giving it access to that skill's private state directory.
+func NewSkillContextForSkill(fs *ToolFS, session *Session, skillName string) *SkillContext {
+	ctx := NewSkillContext(fs, session)
+	ctx.skillName = skillName
+	return ctx
+}
+
+// SkillName returns the name of the skill the context is bound to, or "" if
+// it is not bound to a skill
+func (ctx *SkillContext) SkillName() string {
+	return ctx.skillName
+}
+
+// StateDir returns the skill's private state directory, or "" if no state
+// root is configured or the context is not bound to a skill. The directory is
+// created on the first SaveState.
+func (ctx *SkillContext) StateDir() string {
+	dir, err := ctx.stateDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// SaveState persists data under key in the skill's state directory, replacing
+// any previous value. Keys are plain file names; they cannot contain path
+// separators or refer outside the state directory.
+func (ctx *SkillContext) SaveState(key string, data []byte) error {
+	path, err := ctx.statePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves partial state
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*")
+	if err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// LoadState returns the data saved under key. The error wraps os.ErrNotExist
+// if nothing has been saved.
+func (ctx *SkillContext) LoadState(key string) ([]byte, error) {
+	path, err := ctx.statePath(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// bindInjected binds a context injected with the named skill to that skill's
+// private state. A context injected with several skills is bound to none of
+// them, so no skill can reach another's state through it, and a context
+// created for another skill with NewSkillContextForSkill is rejected.
+func (ctx *SkillContext) bindInjected(name string) error {
+	switch {
+	case ctx.skillName == name || ctx.shared:
+	case ctx.skillName == "":
+		ctx.skillName, ctx.injected = name, true
+	case ctx.injected:
+		ctx.skillName, ctx.shared = "", true
+	default:
+		return fmt.Errorf("context is bound to skill '%s', cannot inject '%s'", ctx.skillName, name)
+	}
+	return nil
+}
+
+// stateDir returns the skill's state directory
+func (ctx *SkillContext) stateDir() (string, error) {
+	if ctx.shared {
+		return "", errors.New("skill context is shared by several skills; use NewSkillContextForSkill for private state")
+	}
+	if ctx.skillName == "" {
+		return "", errors.New("skill context is not bound to a skill")
+	}
+	if ctx.skillName == "." || ctx.skillName == ".." {
+		return "", fmt.Errorf("invalid skill name for state: '%s'", ctx.skillName)
+	}
+	if ctx.capability == nil || ctx.capability.fs == nil || ctx.capability.fs.skillStateRoot == "" {
+		return "", errors.New("skill state root not configured")
+	}
+	// Escaping keeps names with separators inside the state root
+	return filepath.Join(ctx.capability.fs.skillStateRoot, url.PathEscape(ctx.skillName)), nil
+}
+
+// statePath returns the file for key in the skill's state directory
+func (ctx *SkillContext) statePath(key string) (string, error) {
+	dir, err := ctx.stateDir()
+	if err != nil {
+		return "", err
+	}
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".state-") {
+		return "", fmt.Errorf("invalid state key: '%s'", key)
+	}
+	return filepath.Join(dir, key), nil
+}
@@ -0,0 +1,133 @@
+package toolfs
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// StatefulSkill saves the "text" request field as its "index" state
+type StatefulSkill struct {
+	name    string
+	context *SkillContext
+}
+
+func (s *StatefulSkill) Name() string                             { return s.name }
+func (s *StatefulSkill) Version() string                          { return "1.0.0" }
+func (s *StatefulSkill) Init(config map[string]interface{}) error { return nil }
+
+func (s *StatefulSkill) Execute(input []byte) ([]byte, error) {
+	var request SkillRequest
+	if err := json.Unmarshal(input, &request); err != nil {
+		return nil, err
+	}
+	text, _ := request.Data["text"].(string)
+	if err := s.context.SaveState("index", []byte(text)); err != nil {
+		return nil, err
+	}
+	return json.Marshal(SkillResponse{Success: true})
+}
+
+func TestSkillState(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	stateRoot := t.TempDir()
+	if err := fs.SetSkillStateRoot(stateRoot); err != nil {
+		t.Fatalf("SetSkillStateRoot failed: %v", err)
+	}
+
+	// Injection binds the context to the skill
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	ctx := NewSkillContext(fs, nil)
+	skill := &StatefulSkill{name: "indexer", context: ctx}
+	if err := pm.InjectSkill(skill, ctx, nil); err != nil {
+		t.Fatalf("InjectSkill failed: %v", err)
+	}
+	if ctx.SkillName() != "indexer" || ctx.StateDir() != filepath.Join(stateRoot, "indexer") {
+		t.Errorf("Unexpected binding: name=%q dir=%q", ctx.SkillName(), ctx.StateDir())
+	}
+
+	input, _ := json.Marshal(SkillRequest{Operation: "index", Data: map[string]interface{}{"text": "doc1,doc2"}})
+	if _, err := pm.ExecuteSkill("indexer", input); err != nil {
+		t.Fatalf("ExecuteSkill failed: %v", err)
+	}
+
+	// The state survives a new context for the same skill
+	restarted := NewSkillContextForSkill(fs, nil, "indexer")
+	if data, err := restarted.LoadState("index"); err != nil || string(data) != "doc1,doc2" {
+		t.Errorf("Expected saved state, got %q, %v", data, err)
+	}
+
+	// Other skills cannot read it
+	other := NewSkillContextForSkill(fs, nil, "other")
+	if _, err := other.LoadState("index"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected other skill to have no state, got %v", err)
+	}
+	for _, key := range []string{"../indexer/index", "..", "", `..\indexer\index`} {
+		if _, err := other.LoadState(key); err == nil || errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected invalid key error for %q, got %v", key, err)
+		}
+	}
+
+	// Skill names cannot escape the state root
+	escaping := NewSkillContextForSkill(fs, nil, "../escape")
+	if err := escaping.SaveState("x", []byte("x")); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	if dir := escaping.StateDir(); !strings.HasPrefix(dir, stateRoot+string(filepath.Separator)) {
+		t.Errorf("State directory %q escapes root %q", dir, stateRoot)
+	}
+	if err := NewSkillContextForSkill(fs, nil, "..").SaveState("x", nil); err == nil {
+		t.Error("Expected error for '..' skill name")
+	}
+
+	// Without a root or a skill name there is no state
+	if NewSkillContext(fs, nil).StateDir() != "" {
+		t.Error("Expected no state directory for unbound context")
+	}
+	if err := NewSkillContextForSkill(NewToolFS("/toolfs"), nil, "indexer").SaveState("index", nil); err == nil {
+		t.Error("Expected error without a state root")
+	}
+}
+
+func TestSkillStateSharedContext(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	if err := fs.SetSkillStateRoot(t.TempDir()); err != nil {
+		t.Fatalf("SetSkillStateRoot failed: %v", err)
+	}
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+
+	// The first skill's state exists before a second skill shares its context
+	if err := NewSkillContextForSkill(fs, nil, "indexer").SaveState("index", []byte("secret")); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	ctx := NewSkillContext(fs, nil)
+	if err := pm.InjectSkill(&StatefulSkill{name: "indexer", context: ctx}, ctx, nil); err != nil {
+		t.Fatalf("InjectSkill failed: %v", err)
+	}
+	if err := pm.InjectSkill(&StatefulSkill{name: "intruder", context: ctx}, ctx, nil); err != nil {
+		t.Fatalf("InjectSkill failed: %v", err)
+	}
+
+	// A context shared by two skills gives neither of them private state
+	if data, err := ctx.LoadState("index"); err == nil {
+		t.Errorf("Expected shared context to have no state, got %q", data)
+	}
+	input, _ := json.Marshal(SkillRequest{Operation: "index", Data: map[string]interface{}{"text": "overwrite"}})
+	if _, err := pm.ExecuteSkill("intruder", input); err == nil {
+		t.Error("Expected SaveState through a shared context to fail")
+	}
+	if data, _ := NewSkillContextForSkill(fs, nil, "indexer").LoadState("index"); string(data) != "secret" {
+		t.Errorf("Expected indexer state to be untouched, got %q", data)
+	}
+
+	// A context created for one skill cannot be injected into another
+	bound := NewSkillContextForSkill(fs, nil, "indexer")
+	if err := pm.InjectSkill(&StatefulSkill{name: "other", context: bound}, bound, nil); err == nil {
+		t.Error("Expected context bound to another skill to be rejected")
+	}
+}
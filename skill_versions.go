@@ -0,0 +1,246 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SkillVersionKey returns the key a specific version of a skill is loaded
+// under in a SkillExecutorManager ("name@version"). Manager methods taking a
+// name also accept these keys.
+func SkillVersionKey(name, version string) string {
+	return name + "@" + version
+}
+
+// splitSkillVersionKey splits a "name@version" key; ok is false for plain
+// names
+func splitSkillVersionKey(key string) (name, version string, ok bool) {
+	i := strings.LastIndex(key, "@")
+	if i <= 0 || i == len(key)-1 {
+		return key, "", false
+	}
+	return key[:i], key[i+1:], true
+}
+
+// ExecuteSkillVersion executes a specific version of a loaded executor, like
+// ExecuteSkill does for the highest version.
+func (pm *SkillExecutorManager) ExecuteSkillVersion(name, version string, input []byte) ([]byte, error) {
+	return pm.ExecuteSkill(SkillVersionKey(name, version), input)
+}
+
+// ListSkillVersions returns the loaded versions of the named executor,
+// lowest first
+func (pm *SkillExecutorManager) ListSkillVersions(name string) []string {
+	versions := make([]string, 0, len(pm.versions[name]))
+	for version := range pm.versions[name] {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareSemver(versions[i], versions[j]) < 0
+	})
+	return versions
+}
+
+// lookup finds a loaded executor by plain name (the highest version) or by
+// "name@version" key
+func (pm *SkillExecutorManager) lookup(key string) (*ManagedSkill, bool) {
+	if managed, exists := pm.executors[key]; exists {
+		return managed, true
+	}
+	if name, version, ok := splitSkillVersionKey(key); ok {
+		managed, exists := pm.versions[name][version]
+		return managed, exists
+	}
+	return nil, false
+}
+
+// isLoaded reports whether this version of the executor is already loaded
+func (pm *SkillExecutorManager) isLoaded(executor SkillExecutor) bool {
+	_, exists := pm.versions[executor.Name()][executor.Version()]
+	return exists
+}
+
+// add stores a loaded executor and makes the highest version of its name the
+// one registered and executed by plain name
+func (pm *SkillExecutorManager) add(managed *ManagedSkill) error {
+	name, version := managed.Executor.Name(), managed.Executor.Version()
+	if pm.versions[name] == nil {
+		pm.versions[name] = make(map[string]*ManagedSkill)
+	}
+	pm.versions[name][version] = managed
+
+	if err := pm.promote(name); err != nil {
+		delete(pm.versions[name], version)
+		if len(pm.versions[name]) == 0 {
+			delete(pm.versions, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// promote registers the highest loaded version of name under the plain name
+func (pm *SkillExecutorManager) promote(name string) error {
+	versions := pm.ListSkillVersions(name)
+	if len(versions) == 0 {
+		if _, exists := pm.executors[name]; exists {
+			pm.registry.Unregister(name)
+			delete(pm.executors, name)
+		}
+		return nil
+	}
+
+	highest := pm.versions[name][versions[len(versions)-1]]
+	current, exists := pm.executors[name]
+	if exists && current == highest {
+		return nil
+	}
+	if exists {
+		pm.registry.Unregister(name)
+	}
+	if err := pm.registry.Register(highest.Executor, highest.Context); err != nil {
+		if exists {
+			pm.registry.Register(current.Executor, current.Context)
+		}
+		return err
+	}
+	pm.executors[name] = highest
+	return nil
+}
+
+// unload removes one version of an executor, or every version when key is a
+// plain name, closing the executors that implement ClosableSkill
+func (pm *SkillExecutorManager) unload(key string) error {
+	var removed []*ManagedSkill
+	name, version, versioned := splitSkillVersionKey(key)
+	if _, exists := pm.versions[key]; exists || !versioned {
+		name = key
+		for _, managed := range pm.versions[name] {
+			removed = append(removed, managed)
+		}
+		delete(pm.versions, name)
+	} else if managed, exists := pm.versions[name][version]; exists {
+		removed = append(removed, managed)
+		delete(pm.versions[name], version)
+		if len(pm.versions[name]) == 0 {
+			delete(pm.versions, name)
+		}
+	}
+	if len(removed) == 0 {
+		return fmt.Errorf("executor '%s' not found", key)
+	}
+
+	// Registering a lower version cannot conflict with the one it replaces
+	pm.promote(name)
+
+	var errs []error
+	for _, managed := range removed {
+		if err := closeSkill(key, managed.Executor); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compareSemver compares two semantic versions ("1.2.3", "v1.2.0-beta.1"),
+// returning -1, 0 or 1. Build metadata is ignored, pre-releases sort before
+// their release and versions that do not parse sort before valid ones.
+func compareSemver(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(a, b)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := 0; i < 3; i++ {
+		if va.core[i] != vb.core[i] {
+			if va.core[i] < vb.core[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return comparePrerelease(va.prerelease, vb.prerelease)
+}
+
+type semver struct {
+	core       [3]int
+	prerelease []string
+}
+
+func parseSemver(version string) (semver, bool) {
+	var v semver
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, hasPrerelease := strings.Cut(version, "-")
+	if hasPrerelease {
+		if prerelease == "" {
+			return v, false
+		}
+		v.prerelease = strings.Split(prerelease, ".")
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+// comparePrerelease orders pre-release identifiers as semver does: numeric
+// identifiers numerically and below alphanumeric ones, and a release above
+// any pre-release
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		na, errA := strconv.Atoi(a[i])
+		nb, errB := strconv.Atoi(b[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
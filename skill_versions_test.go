@@ -0,0 +1,108 @@
+package toolfs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// VersionedSkill echoes its version
+type VersionedSkill struct {
+	ClosingSkill
+}
+
+func (s *VersionedSkill) Execute(input []byte) ([]byte, error) {
+	return []byte(s.version), nil
+}
+
+func newVersionedSkill(name, version string) *VersionedSkill {
+	return &VersionedSkill{ClosingSkill{MockSkill: MockSkill{name: name, version: version}}}
+}
+
+func TestSkillVersions(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	pm := NewSkillExecutorManager()
+	ctx := NewSkillContext(fs, nil)
+
+	v1 := newVersionedSkill("convert", "1.9.0")
+	v2 := newVersionedSkill("convert", "1.10.0")
+	beta := newVersionedSkill("convert", "2.0.0-beta.1")
+	for _, skill := range []*VersionedSkill{v1, v2, beta} {
+		if err := pm.InjectSkill(skill, ctx, nil); err != nil {
+			t.Fatalf("InjectSkill %s failed: %v", skill.version, err)
+		}
+	}
+	if err := pm.InjectSkill(newVersionedSkill("convert", "1.9.0"), ctx, nil); err == nil {
+		t.Error("Expected duplicate version to be rejected")
+	}
+
+	versions := pm.ListSkillVersions("convert")
+	if strings.Join(versions, ",") != "1.9.0,1.10.0,2.0.0-beta.1" {
+		t.Errorf("Unexpected versions %v", versions)
+	}
+	if names := pm.ListSkills(); len(names) != 1 || names[0] != "convert" {
+		t.Errorf("Expected one skill name, got %v", names)
+	}
+
+	// The plain name resolves to the highest version
+	if output, err := pm.ExecuteSkill("convert", nil); err != nil || string(output) != "2.0.0-beta.1" {
+		t.Errorf("Expected highest version, got %q, %v", output, err)
+	}
+	if output, err := pm.ExecuteSkillVersion("convert", "1.9.0", nil); err != nil || string(output) != "1.9.0" {
+		t.Errorf("Expected version 1.9.0, got %q, %v", output, err)
+	}
+	if _, err := pm.ExecuteSkillVersion("convert", "3.0.0", nil); !errors.Is(err, ErrSkillNotFound) {
+		t.Errorf("Expected ErrSkillNotFound, got %v", err)
+	}
+	if skill, err := pm.registry.Get("convert"); err != nil || skill != SkillExecutor(beta) {
+		t.Errorf("Expected registry to hold the highest version, got %v, %v", skill, err)
+	}
+
+	// Unloading one version falls back to the next highest
+	if err := pm.UnloadSkill(SkillVersionKey("convert", "2.0.0-beta.1")); err != nil || beta.closed != 1 {
+		t.Fatalf("Unload of one version failed: %v (closed %d)", err, beta.closed)
+	}
+	if output, _ := pm.ExecuteSkill("convert", nil); string(output) != "1.10.0" {
+		t.Errorf("Expected fallback to 1.10.0, got %q", output)
+	}
+	if skill, _ := pm.registry.Get("convert"); skill != SkillExecutor(v2) {
+		t.Errorf("Expected registry to hold 1.10.0, got %v", skill)
+	}
+
+	// The plain name unloads every version
+	if err := pm.UnloadSkill("convert"); err != nil || v1.closed != 1 || v2.closed != 1 {
+		t.Errorf("Expected all versions to be unloaded and closed, got %v", err)
+	}
+	if len(pm.ListSkillVersions("convert")) != 0 || len(pm.ListSkills()) != 0 {
+		t.Error("Expected no versions left")
+	}
+	if _, err := pm.registry.Get("convert"); err == nil {
+		t.Error("Expected skill to be unregistered")
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.2.0", "1.10.0", -1},
+		{"v2.0.0", "1.9.9", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha.2", "1.0.0-alpha.10", -1},
+		{"1.0.0-alpha.1", "1.0.0-beta", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0+build.5", "1.0.0", 0},
+		{"1.2", "1.2.0", 0},
+		{"latest", "0.0.1", -1},
+	}
+	for _, tt := range tests {
+		if got := compareSemver(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareSemver(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := compareSemver(tt.b, tt.a); got != -tt.want {
+			t.Errorf("compareSemver(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // Result represents a structured result from a skill API operation
@@ -18,9 +19,18 @@ type Result struct {
 	Success   bool        `json:"success"`              // Operation success status
 	Error     string      `json:"error,omitempty"`      // Error message if failed
 	CLIOutput *CLIOutput  `json:"cli_output,omitempty"` // CLI command output if applicable
+	Stat      *FileStat   `json:"stat,omitempty"`       // File metadata for stat operations
+	Skipped   bool        `json:"skipped,omitempty"`    // The step's condition was not met (see Operation.Condition)
 	Skill    *SkillInfo `json:"skill,omitempty"`     // Skill information if applicable
 }
 
+// FileStat is the file metadata returned by the "stat" chain operation
+type FileStat struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	IsDir   bool      `json:"is_dir"`
+}
+
 // SkillInfo contains information about a skill execution
 type SkillInfo struct {
 	Name    string      `json:"name"`
@@ -201,6 +211,13 @@ func tryReadFile(fs *ToolFS, path string, session *Session) *Result {
 
 // ExecuteCLI safely executes a CLI command and captures stdout/stderr
 func ExecuteCLI(command string, args []string, session *Session, fs *ToolFS) (*Result, error) {
+	return ExecuteCLIWithOptions(command, args, session, fs, CLIOptions{})
+}
+
+// ExecuteCLIWithOptions executes a CLI command like ExecuteCLI. With
+// opts.RestrictToSessionMounts the command only sees the session's local
+// mounts; see CLIOptions for platform limitations.
+func ExecuteCLIWithOptions(command string, args []string, session *Session, fs *ToolFS, opts CLIOptions) (*Result, error) {
 	if session != nil {
 		// Validate command if session has a validator
 		allowed, reason := session.ValidateCommand(command, args)
@@ -222,6 +239,23 @@ func ExecuteCLI(command string, args []string, session *Session, fs *ToolFS) (*R
 	// Execute the command
 	cmd := exec.Command(command, args...)
 
+	var metadata interface{}
+	if opts.RestrictToSessionMounts {
+		if fs == nil {
+			err := errors.New("restricting a command to session mounts requires a ToolFS")
+			return &Result{Type: "cli", Source: command, Success: false, Error: err.Error()}, err
+		}
+		view, err := fs.newCLIView(session)
+		if err == nil {
+			defer view.Close()
+			err = view.apply(cmd)
+		}
+		if err != nil {
+			return &Result{Type: "cli", Source: command, Success: false, Error: err.Error()}, err
+		}
+		metadata = map[string]interface{}{"view": view.mode}
+	}
+
 	var stdout, stderr strings.Builder
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -247,10 +281,11 @@ func ExecuteCLI(command string, args []string, session *Session, fs *ToolFS) (*R
 	}
 
 	result := &Result{
-		Type:    "cli",
-		Source:  fullCommand,
-		Content: stdout.String(),
-		Success: exitCode == 0,
+		Type:     "cli",
+		Source:   fullCommand,
+		Content:  stdout.String(),
+		Metadata: metadata,
+		Success:  exitCode == 0,
 		CLIOutput: &CLIOutput{
 			Stdout:   stdout.String(),
 			Stderr:   stderr.String(),
@@ -275,112 +310,268 @@ func ExecuteCLI(command string, args []string, session *Session, fs *ToolFS) (*R
 	return result, nil
 }
 
-// ChainOperations executes multiple ToolFS operations in sequence
+// ChainOperations executes multiple ToolFS operations in sequence. Steps
+// with a Condition run depending on the preceding step; steps whose condition
+// is not met are not executed and produce a skipped Result.
 func ChainOperations(fs *ToolFS, operations []Operation, session *Session) ([]*Result, error) {
 	var results []*Result
 
-	for _, op := range operations {
-		var result *Result
-		var err error
-
-		switch op.Type {
-		case "read_file":
-			result = tryReadFile(fs, op.Path, session)
-		case "write_file":
-			if session != nil {
-				err = fs.WriteFileWithSession(op.Path, []byte(op.Content), session)
-			} else {
-				err = fs.WriteFile(op.Path, []byte(op.Content))
+	for i, op := range operations {
+		if result := checkCondition(op, results, i); result != nil {
+			results = append(results, result)
+			continue
+		}
+		result, _ := runOperation(fs, op, session)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Step conditions for Operation.Condition
+const (
+	ConditionAlways    = "always"
+	ConditionOnSuccess = "on_success"
+	ConditionOnFailure = "on_failure"
+)
+
+// checkCondition evaluates the condition of step i against the result of the
+// preceding step. It returns nil if the step should run, or the Result to
+// record instead: a skipped result, or an error for unknown conditions.
+// A preceding step succeeded if its result has Success set; it failed if it
+// reported an error or found nothing (a nil result, e.g. an empty memory
+// search). A skipped step counts as neither, so conditional steps after it
+// are skipped too. The first step always runs.
+func checkCondition(op Operation, results []*Result, i int) *Result {
+	var met bool
+	switch op.Condition {
+	case "", ConditionAlways:
+		met = true
+	case ConditionOnSuccess:
+		met = i == 0 || (results[i-1] != nil && results[i-1].Success)
+	case ConditionOnFailure:
+		met = i == 0 || results[i-1] == nil || (!results[i-1].Success && !results[i-1].Skipped)
+	default:
+		return &Result{
+			Type:    "error",
+			Success: false,
+			Error:   fmt.Sprintf("unknown step condition: %s", op.Condition),
+		}
+	}
+	if met {
+		return nil
+	}
+	return &Result{
+		Type:    "skipped",
+		Source:  op.Type,
+		Skipped: true,
+	}
+}
+
+// runOperation executes a single chain step. Failures are reported in the
+// result; the result is nil for searches without matches.
+func runOperation(fs *ToolFS, op Operation, session *Session) (*Result, error) {
+	var result *Result
+	var err error
+
+	switch op.Type {
+	case "read_file":
+		result = tryReadFile(fs, op.Path, session)
+	case "write_file":
+		if session != nil {
+			err = fs.WriteFileWithSession(op.Path, []byte(op.Content), session)
+		} else {
+			err = fs.WriteFile(op.Path, []byte(op.Content))
+		}
+		if err != nil {
+			result = &Result{
+				Type:    "file",
+				Source:  op.Path,
+				Success: false,
+				Error:   err.Error(),
 			}
-			if err != nil {
-				result = &Result{
-					Type:    "file",
-					Source:  op.Path,
-					Success: false,
-					Error:   err.Error(),
-				}
-			} else {
-				result = &Result{
-					Type:    "file",
-					Source:  op.Path,
-					Content: op.Content,
-					Success: true,
-				}
+		} else {
+			result = &Result{
+				Type:    "file",
+				Source:  op.Path,
+				Content: op.Content,
+				Success: true,
 			}
-		case "list_dir":
-			var entries []string
-			if session != nil {
-				entries, err = fs.ListDirWithSession(op.Path, session)
-			} else {
-				entries, err = fs.ListDir(op.Path)
+		}
+	case "delete_file":
+		if session != nil {
+			err = fs.DeleteFileWithSession(op.Path, session)
+		} else {
+			err = fs.DeleteFile(op.Path)
+		}
+		if err != nil {
+			result = &Result{
+				Type:    "file",
+				Source:  op.Path,
+				Success: false,
+				Error:   err.Error(),
 			}
-			if err != nil {
-				result = &Result{
-					Type:    "file",
-					Source:  op.Path,
-					Success: false,
-					Error:   err.Error(),
-				}
-			} else {
-				result = &Result{
-					Type:    "file",
-					Source:  op.Path,
-					Content: strings.Join(entries, "\n"),
-					Success: true,
-				}
+		} else {
+			result = &Result{
+				Type:    "file",
+				Source:  op.Path,
+				Success: true,
 			}
-		case "search_memory":
-			var entries []MemoryEntry
-			entries, err = searchMemory(fs, op.Query, session)
-			if err != nil {
-				result = &Result{
-					Type:    "memory",
-					Success: false,
-					Error:   err.Error(),
-				}
-			} else if len(entries) > 0 {
-				result = &Result{
-					Type:     "memory",
-					Source:   entries[0].ID,
-					Content:  entries[0].Content,
-					Metadata: entries[0].Metadata,
-					Success:  true,
-				}
+		}
+	case "stat":
+		var info *FileInfo
+		if session != nil {
+			info, err = fs.StatWithSession(op.Path, session)
+		} else {
+			info, err = fs.Stat(op.Path)
+		}
+		if err != nil {
+			result = &Result{
+				Type:    "file",
+				Source:  op.Path,
+				Success: false,
+				Error:   err.Error(),
 			}
-		case "search_rag":
-			var ragResults *RAGSearchResults
-			ragResults, err = searchRAG(fs, op.Query, op.TopK, session)
-			if err != nil {
-				result = &Result{
-					Type:    "rag",
-					Success: false,
-					Error:   err.Error(),
-				}
-			} else if len(ragResults.Results) > 0 {
-				result = &Result{
-					Type:     "rag",
-					Source:   ragResults.Results[0].ID,
-					Content:  ragResults.Results[0].Content,
-					Metadata: ragResults.Results[0].Metadata,
-					Success:  true,
-				}
+		} else {
+			result = &Result{
+				Type:    "file",
+				Source:  op.Path,
+				Stat:    &FileStat{Size: info.Size, ModTime: info.ModTime, IsDir: info.IsDir},
+				Success: true,
 			}
-		case "execute_cli":
-			result, err = ExecuteCLI(op.Command, op.Args, session, fs)
-		case "execute_code_skill":
-			result, err = ExecuteCodeSkill(fs, op.SkillName, op.SkillPath, op.Query, op.SkillData, session)
-		default:
+		}
+	case "list_dir":
+		var entries []string
+		if session != nil {
+			entries, err = fs.ListDirWithSession(op.Path, session)
+		} else {
+			entries, err = fs.ListDir(op.Path)
+		}
+		if err != nil {
+			result = &Result{
+				Type:    "file",
+				Source:  op.Path,
+				Success: false,
+				Error:   err.Error(),
+			}
+		} else {
+			result = &Result{
+				Type:    "file",
+				Source:  op.Path,
+				Content: strings.Join(entries, "\n"),
+				Success: true,
+			}
+		}
+	case "search_memory":
+		var entries []MemoryEntry
+		entries, err = searchMemory(fs, op.Query, session)
+		if err != nil {
+			result = &Result{
+				Type:    "memory",
+				Success: false,
+				Error:   err.Error(),
+			}
+		} else if len(entries) > 0 {
+			result = &Result{
+				Type:     "memory",
+				Source:   entries[0].ID,
+				Content:  entries[0].Content,
+				Metadata: entries[0].Metadata,
+				Success:  true,
+			}
+		}
+	case "search_rag":
+		var ragResults *RAGSearchResults
+		ragResults, err = searchRAG(fs, op.Query, op.TopK, session)
+		if err != nil {
 			result = &Result{
-				Type:    "error",
+				Type:    "rag",
 				Success: false,
-				Error:   fmt.Sprintf("unknown operation type: %s", op.Type),
+				Error:   err.Error(),
+			}
+		} else if len(ragResults.Results) > 0 {
+			result = &Result{
+				Type:     "rag",
+				Source:   ragResults.Results[0].ID,
+				Content:  ragResults.Results[0].Content,
+				Metadata: ragResults.Results[0].Metadata,
+				Success:  true,
 			}
 		}
+	case "execute_cli":
+		result, err = ExecuteCLI(op.Command, op.Args, session, fs)
+	case "execute_code_skill":
+		result, err = ExecuteCodeSkill(fs, op.SkillName, op.SkillPath, op.Query, op.SkillData, session)
+	default:
+		result = &Result{
+			Type:    "error",
+			Success: false,
+			Error:   fmt.Sprintf("unknown operation type: %s", op.Type),
+		}
+	}
 
-		results = append(results, result)
+	return result, err
+}
+
+// ChainResult holds the per-step results of a chain of operations
+type ChainResult struct {
+	Operations []Operation
+	Results    []*Result
+}
+
+// ChainStepError describes a failed step in a chain
+type ChainStepError struct {
+	Step      int    // Index of the failed operation
+	Operation string // Operation type
+	Message   string // Error reported by the step
+}
+
+// Error implements the error interface
+func (e *ChainStepError) Error() string {
+	return fmt.Sprintf("chain step %d (%s) failed: %s", e.Step, e.Operation, e.Message)
+}
+
+// RunChain executes operations like ChainOperations and wraps the results
+// so step failures can be checked without inspecting every Result
+func RunChain(fs *ToolFS, operations []Operation, session *Session) (*ChainResult, error) {
+	results, err := ChainOperations(fs, operations, session)
+	return &ChainResult{Operations: operations, Results: results}, err
+}
+
+// HasErrors reports whether any step failed
+func (c *ChainResult) HasErrors() bool {
+	return len(c.FailedSteps()) > 0
+}
+
+// FailedSteps returns the indexes of the steps that failed. Skipped steps
+// are not failures.
+func (c *ChainResult) FailedSteps() []int {
+	var failed []int
+	for i, result := range c.Results {
+		if result != nil && !result.Success && !result.Skipped {
+			failed = append(failed, i)
+		}
 	}
+	return failed
+}
 
-	return results, nil
+// FirstError returns a *ChainStepError for the first failed step, or nil
+func (c *ChainResult) FirstError() error {
+	failed := c.FailedSteps()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	step := failed[0]
+	opType := ""
+	if step < len(c.Operations) {
+		opType = c.Operations[step].Type
+	}
+	return &ChainStepError{
+		Step:      step,
+		Operation: opType,
+		Message:   c.Results[step].Error,
+	}
 }
 
 // ExecuteCodeSkill executes a skill through ToolFS mount or SkillManager
@@ -688,7 +879,8 @@ func mergeResults(results []*Result, query string, memErr, ragErr, skillErr erro
 
 // Operation represents a single operation in a chain
 type Operation struct {
-	Type       string                 `json:"type"` // "read_file", "write_file", "list_dir", "search_memory", "search_rag", "execute_cli", "execute_code_skill"
+	Type       string                 `json:"type"` // "read_file", "write_file", "delete_file", "stat", "list_dir", "search_memory", "search_rag", "execute_cli", "execute_code_skill"
+	Condition  string                 `json:"condition,omitempty"` // "always" (default), "on_success" or "on_failure"
 	Path       string                 `json:"path,omitempty"`
 	Content    string                 `json:"content,omitempty"`
 	Query      string                 `json:"query,omitempty"`
@@ -0,0 +1,149 @@
+package toolfs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CommitChanges materializes the current state of every path changed since
+// the snapshot was created into the snapshot, so rolling back to it restores
+// the edits instead of the state it was created with.
+//
+// Snapshots are otherwise immutable: TrackChange only records which paths
+// changed, and creating later snapshots, rolling back or rolling forward never
+// alters a snapshot's files. CommitChanges is the one explicit exception. It
+// only rewrites the named snapshot's own files; snapshots created on top of
+// it keep resolving unchanged files through it, so they see the committed
+// state for files they did not capture themselves. Memory captured with
+// SnapshotOptions.IncludeMemory is not updated.
+func (fs *ToolFS) CommitChanges(name string) error {
+	snapshot, exists := fs.snapshots[name]
+	if !exists {
+		return notFoundf("snapshot '%s' does not exist", name)
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, change := range snapshot.Changes {
+		path := strings.TrimSuffix(normalizeVirtualPath(change.Path), "/")
+		if !seen[path] && snapshot.inScope(path) {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var baseFiles map[string]*FileSnapshot
+	if base, exists := fs.snapshots[snapshot.BaseSnapshot]; exists {
+		baseFiles = fs.snapshotFiles(base)
+	} else {
+		baseFiles = make(map[string]*FileSnapshot)
+	}
+
+	for _, path := range paths {
+		mountPoint, mount := fs.localMountFor(path)
+		if mount == nil || mount.ReadOnly {
+			continue // Only writable local mounts are captured
+		}
+		localPath := filepath.Join(mount.LocalPath, filepath.FromSlash(strings.TrimPrefix(path, mountPoint)))
+
+		info, err := os.Lstat(localPath)
+		if os.IsNotExist(err) {
+			fs.commitDeletion(snapshot, baseFiles, path)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if err := fs.commitFile(snapshot, baseFiles, path, localPath, info); err != nil {
+				return err
+			}
+			continue
+		}
+		err = filepath.Walk(localPath, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(localPath, file)
+			if err != nil {
+				return err
+			}
+			virtualPath := path
+			if relPath != "." {
+				virtualPath = normalizeVirtualPath(path + "/" + relPath)
+			}
+			return fs.commitFile(snapshot, baseFiles, virtualPath, file, info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	snapshot.updateMetadata()
+	return nil
+}
+
+// commitFile captures the current state of a file or directory into snapshot
+func (fs *ToolFS) commitFile(snapshot *Snapshot, baseFiles map[string]*FileSnapshot, virtualPath, localPath string, info os.FileInfo) error {
+	var content []byte
+	if !info.IsDir() {
+		var err error
+		if content, err = os.ReadFile(localPath); err != nil {
+			return err
+		}
+	}
+	operation := "created"
+	if _, inBase := baseFiles[virtualPath]; inBase {
+		operation = "modified"
+	}
+	content, compressed := fs.snapshotContent(content)
+	snapshot.Files[virtualPath] = &FileSnapshot{
+		Path:       virtualPath,
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		IsDir:      info.IsDir(),
+		Mode:       info.Mode().Perm(),
+		Operation:  operation,
+		Content:    content,
+		Compressed: compressed,
+	}
+	return nil
+}
+
+// commitDeletion records that path, and anything the snapshot holds below
+// it, no longer exists
+func (fs *ToolFS) commitDeletion(snapshot *Snapshot, baseFiles map[string]*FileSnapshot, path string) {
+	for virtualPath, fileSnap := range fs.snapshotFiles(snapshot) {
+		if !isSubPath(virtualPath, path) {
+			continue
+		}
+		if _, inBase := baseFiles[virtualPath]; !inBase {
+			// Captured by this snapshot only, so there is nothing to mask
+			delete(snapshot.Files, virtualPath)
+			continue
+		}
+		snapshot.Files[virtualPath] = &FileSnapshot{
+			Path:      virtualPath,
+			IsDir:     fileSnap.IsDir,
+			Operation: "deleted",
+		}
+	}
+}
+
+// updateMetadata recomputes the file count and size of the files captured by
+// the snapshot itself
+func (s *Snapshot) updateMetadata() {
+	var totalSize int64
+	fileCount := 0
+	for _, fileSnap := range s.Files {
+		if !fileSnap.IsDir && fileSnap.Operation != "deleted" {
+			fileCount++
+			totalSize += fileSnap.Size
+		}
+	}
+	s.Metadata.FileCount = fileCount
+	s.Metadata.Size = totalSize
+}
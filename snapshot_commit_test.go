@@ -0,0 +1,74 @@
+package toolfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommitChanges(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+
+	fs.CreateSnapshot("base")
+	fs.CreateSnapshot("work")
+
+	fs.WriteFile("/toolfs/data/test.txt", []byte("edited after snapshot"))
+	fs.WriteFile("/toolfs/data/new.txt", []byte("new file"))
+	fs.WriteFile("/toolfs/data/scratch.txt", []byte("temporary"))
+	fs.DeleteFile("/toolfs/data/scratch.txt")
+	fs.DeleteFile("/toolfs/data/subdir/subfile.txt")
+
+	// Without committing, the snapshot keeps its original state
+	if file := fs.snapshots["work"].Files["/toolfs/data/test.txt"]; file != nil {
+		t.Fatalf("Expected the snapshot to be untouched by writes, got %+v", file)
+	}
+
+	if err := fs.CommitChanges("work"); err != nil {
+		t.Fatalf("CommitChanges failed: %v", err)
+	}
+	work := fs.snapshots["work"]
+	if file := work.Files["/toolfs/data/test.txt"]; file == nil || file.Operation != "modified" || string(file.Content) != "edited after snapshot" {
+		t.Errorf("Expected the edit to be committed, got %+v", file)
+	}
+	if file := work.Files["/toolfs/data/new.txt"]; file == nil || file.Operation != "created" {
+		t.Errorf("Expected the new file to be committed, got %+v", file)
+	}
+	if _, exists := work.Files["/toolfs/data/scratch.txt"]; exists {
+		t.Error("Expected a file created and deleted after the snapshot to be left out")
+	}
+	if file := work.Files["/toolfs/data/subdir/subfile.txt"]; file == nil || file.Operation != "deleted" {
+		t.Errorf("Expected the deletion to be committed, got %+v", file)
+	}
+	if work.Metadata.FileCount != 2 || work.Metadata.Size != int64(len("edited after snapshot")+len("new file")) {
+		t.Errorf("Unexpected metadata %+v", work.Metadata)
+	}
+
+	// Rolling back restores the committed state
+	fs.WriteFile("/toolfs/data/test.txt", []byte("edited again"))
+	if err := fs.RollbackSnapshot("work"); err != nil {
+		t.Fatalf("RollbackSnapshot failed: %v", err)
+	}
+	if data, _ := fs.ReadFile("/toolfs/data/test.txt"); string(data) != "edited after snapshot" {
+		t.Errorf("Expected the committed content, got %q", data)
+	}
+
+	// Earlier snapshots are not affected
+	if err := fs.RollbackSnapshot("base"); err != nil {
+		t.Fatalf("RollbackSnapshot failed: %v", err)
+	}
+	if data, _ := fs.ReadFile("/toolfs/data/test.txt"); string(data) != "Hello, ToolFS!" {
+		t.Errorf("Expected the base content, got %q", data)
+	}
+	if data, _ := fs.ReadFile("/toolfs/data/subdir/subfile.txt"); string(data) != "Subdirectory file" {
+		t.Errorf("Expected the base to restore the deleted file, got %q", data)
+	}
+
+	if err := fs.CommitChanges("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package toolfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// SetSnapshotCompression turns gzip compression of file contents captured by
+// new snapshots on or off. Contents of at least minBytes are compressed when
+// that makes them smaller; Size and the snapshot metadata keep reporting the
+// uncompressed sizes. Existing snapshots are not affected.
+func (fs *ToolFS) SetSnapshotCompression(enabled bool, minBytes int) {
+	if minBytes < 0 {
+		minBytes = 0
+	}
+	fs.snapshotCompress = enabled
+	fs.snapshotCompressMin = minBytes
+}
+
+// snapshotContent prepares the content of a file for a snapshot, compressing
+// it when enabled. It reports whether the returned content is compressed.
+func (fs *ToolFS) snapshotContent(content []byte) ([]byte, bool) {
+	if !fs.snapshotCompress || len(content) == 0 || len(content) < fs.snapshotCompressMin {
+		return content, false
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return content, false
+	}
+	if err := zw.Close(); err != nil || buf.Len() >= len(content) {
+		return content, false
+	}
+	return buf.Bytes(), true
+}
+
+// data returns the uncompressed content of the file
+func (f *FileSnapshot) data() ([]byte, error) {
+	if !f.Compressed {
+		return f.Content, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(f.Content))
+	if err != nil {
+		return nil, fmt.Errorf("corrupt snapshot content of '%s': %w", f.Path, err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt snapshot content of '%s': %w", f.Path, err)
+	}
+	return data, nil
+}
+
+// sameContent reports whether two captured files hold the same content
+func sameContent(a, b *FileSnapshot) (bool, error) {
+	if a.Size != b.Size {
+		return false, nil
+	}
+	if a == b || (a.Compressed == b.Compressed && bytes.Equal(a.Content, b.Content)) {
+		return true, nil
+	}
+	dataA, err := a.data()
+	if err != nil {
+		return false, err
+	}
+	dataB, err := b.data()
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(dataA, dataB), nil
+}
@@ -0,0 +1,90 @@
+package toolfs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotCompression(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	large := []byte(strings.Repeat("compressible snapshot line\n", 400))
+	os.WriteFile(filepath.Join(tmpDir, "large.txt"), large, 0o644)
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+	fs.SetSnapshotCompression(true, 1024)
+
+	if err := fs.CreateSnapshot("compressed"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	snapshot := fs.snapshots["compressed"]
+
+	file := snapshot.Files["/toolfs/data/large.txt"]
+	if !file.Compressed || len(file.Content) >= len(large) || file.Size != int64(len(large)) {
+		t.Errorf("Expected compressed content with the logical size, got %d bytes, size %d, compressed %v", len(file.Content), file.Size, file.Compressed)
+	}
+	if small := snapshot.Files["/toolfs/data/test.txt"]; small.Compressed || string(small.Content) != "Hello, ToolFS!" {
+		t.Errorf("Expected content below the threshold to be stored as is, got %+v", small)
+	}
+	wantSize := int64(len(large) + len("Hello, ToolFS!") + len("Subdirectory file"))
+	if snapshot.Metadata.Size != wantSize {
+		t.Errorf("Expected metadata size %d, got %d", wantSize, snapshot.Metadata.Size)
+	}
+
+	// Unchanged compressed files are recognized when creating the next snapshot
+	if err := fs.CreateSnapshot("next"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	if _, captured := fs.snapshots["next"].Files["/toolfs/data/large.txt"]; captured {
+		t.Error("Expected the unchanged file to come from the base snapshot")
+	}
+
+	// Rollback restores the original bytes
+	fs.WriteFile("/toolfs/data/large.txt", []byte("overwritten"))
+	if err := fs.RollbackSnapshot("compressed"); err != nil {
+		t.Fatalf("RollbackSnapshot failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "large.txt")); !bytes.Equal(data, large) {
+		t.Errorf("Expected the restored content to match, got %d bytes", len(data))
+	}
+
+	// Diff compares uncompressed content, including against uncompressed captures
+	compressedContent, _ := fs.snapshotContent(large)
+	packed := &FileSnapshot{Path: "/large.txt", Content: compressedContent, Size: int64(len(large)), Compressed: true}
+	if same, err := sameContent(packed, &FileSnapshot{Content: large, Size: int64(len(large))}); err != nil || !same {
+		t.Errorf("Expected compressed and plain captures to match, got %v, %v", same, err)
+	}
+	diffs, err := fs.DiffSnapshots("compressed", "next")
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	for _, diff := range diffs {
+		if diff.Status != SnapshotFileUnchanged {
+			t.Errorf("Expected no changes, got %+v", diff)
+		}
+	}
+
+	fs.WriteFile("/toolfs/data/large.txt", append(large[:len(large)-1:len(large)-1], '!'))
+	fs.SetSnapshotCompression(false, 0)
+	fs.CreateSnapshot("edited")
+	if edited := fs.snapshots["edited"].Files["/toolfs/data/large.txt"]; edited == nil || edited.Compressed {
+		t.Fatalf("Expected an uncompressed capture, got %+v", edited)
+	}
+	diffs, _ = fs.DiffSnapshots("compressed", "edited")
+	found := false
+	for _, diff := range diffs {
+		if diff.Path == "/toolfs/data/large.txt" {
+			found = diff.Status == SnapshotFileModified
+		}
+	}
+	if !found {
+		t.Errorf("Expected the same-size edit to be reported, got %+v", diffs)
+	}
+}
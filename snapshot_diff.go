@@ -0,0 +1,68 @@
+package toolfs
+
+import "sort"
+
+// Snapshot diff statuses
+const (
+	SnapshotFileAdded     = "added"
+	SnapshotFileRemoved   = "removed"
+	SnapshotFileModified  = "modified"
+	SnapshotFileUnchanged = "unchanged"
+)
+
+// SnapshotFileDiff describes how one file differs between two snapshots
+type SnapshotFileDiff struct {
+	Path    string `json:"path"`
+	Status  string `json:"status"`             // One of the SnapshotFile* statuses
+	OldSize int64  `json:"old_size,omitempty"` // Size in the first snapshot (removed, modified, unchanged)
+	NewSize int64  `json:"new_size,omitempty"` // Size in the second snapshot (added, modified, unchanged)
+}
+
+// DiffSnapshots compares the files of snapshots a and b, each resolved
+// through its base chain as RollbackSnapshot does. Directories are not
+// reported. Entries are sorted by path.
+func (fs *ToolFS) DiffSnapshots(a, b string) ([]SnapshotFileDiff, error) {
+	snapA, exists := fs.snapshots[a]
+	if !exists {
+		return nil, notFoundf("snapshot '%s' does not exist", a)
+	}
+	snapB, exists := fs.snapshots[b]
+	if !exists {
+		return nil, notFoundf("snapshot '%s' does not exist", b)
+	}
+
+	oldFiles := fs.snapshotFiles(snapA)
+	newFiles := fs.snapshotFiles(snapB)
+
+	var diffs []SnapshotFileDiff
+	for path, oldFile := range oldFiles {
+		if oldFile.IsDir {
+			continue
+		}
+		newFile, ok := newFiles[path]
+		if !ok || newFile.IsDir {
+			diffs = append(diffs, SnapshotFileDiff{Path: path, Status: SnapshotFileRemoved, OldSize: oldFile.Size})
+			continue
+		}
+		same, err := sameContent(oldFile, newFile)
+		if err != nil {
+			return nil, err
+		}
+		status := SnapshotFileUnchanged
+		if !same {
+			status = SnapshotFileModified
+		}
+		diffs = append(diffs, SnapshotFileDiff{Path: path, Status: status, OldSize: oldFile.Size, NewSize: newFile.Size})
+	}
+	for path, newFile := range newFiles {
+		if newFile.IsDir {
+			continue
+		}
+		if oldFile, ok := oldFiles[path]; !ok || oldFile.IsDir {
+			diffs = append(diffs, SnapshotFileDiff{Path: path, Status: SnapshotFileAdded, NewSize: newFile.Size})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
@@ -0,0 +1,74 @@
+package toolfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "gone.txt"), []byte("bye"), 0o644)
+
+	if err := fs.CreateSnapshot("before"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	// Modify, add and remove files
+	if err := fs.WriteFile("/toolfs/data/test.txt", []byte("Hello, changed ToolFS!")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := fs.WriteFile("/toolfs/data/new.txt", []byte("new")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := fs.DeleteFile("/toolfs/data/gone.txt"); err != nil {
+		t.Fatalf("DeleteFile failed: %v", err)
+	}
+	if err := fs.CreateSnapshot("after"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	diffs, err := fs.DiffSnapshots("before", "after")
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	want := []SnapshotFileDiff{
+		{Path: "/toolfs/data/gone.txt", Status: SnapshotFileRemoved, OldSize: 3},
+		{Path: "/toolfs/data/new.txt", Status: SnapshotFileAdded, NewSize: 3},
+		{Path: "/toolfs/data/subdir/subfile.txt", Status: SnapshotFileUnchanged, OldSize: 17, NewSize: 17},
+		{Path: "/toolfs/data/test.txt", Status: SnapshotFileModified, OldSize: 14, NewSize: 22},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("Expected %d diffs, got %+v", len(want), diffs)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Errorf("Diff %d: expected %+v, got %+v", i, want[i], diffs[i])
+		}
+	}
+
+	// Reversing the arguments reverses added and removed
+	diffs, _ = fs.DiffSnapshots("after", "before")
+	if diffs[0].Status != SnapshotFileAdded || diffs[1].Status != SnapshotFileRemoved {
+		t.Errorf("Expected reversed statuses, got %+v", diffs)
+	}
+
+	// Rolling back to "after" does not bring back the deleted file
+	os.WriteFile(filepath.Join(tmpDir, "test.txt"), []byte("x"), 0o644)
+	if err := fs.RollbackSnapshot("after"); err != nil {
+		t.Fatalf("RollbackSnapshot failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "gone.txt")); !os.IsNotExist(err) {
+		t.Error("Expected deleted file to stay deleted after rollback")
+	}
+
+	if _, err := fs.DiffSnapshots("before", "missing"); err == nil {
+		t.Error("Expected error for unknown snapshot")
+	}
+}
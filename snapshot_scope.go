@@ -0,0 +1,73 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CreateSnapshotScoped creates a snapshot of only the files below the given
+// virtual path prefixes, which must lie on writable local mounts. Files are
+// still captured copy-on-write against the current snapshot, and rolling the
+// snapshot back leaves files outside the prefixes alone.
+func (fs *ToolFS) CreateSnapshotScoped(name string, paths []string) error {
+	if len(paths) == 0 {
+		return errors.New("scoped snapshot needs at least one path")
+	}
+	return fs.CreateSnapshotWithOptions(name, SnapshotOptions{Paths: paths})
+}
+
+// snapshotRoots resolves the path prefixes of a scoped snapshot to the local
+// directories to walk, keyed by virtual path. Prefixes below another prefix
+// are dropped. Without paths it returns nil for both.
+func (fs *ToolFS) snapshotRoots(paths []string) ([]string, map[string]string, error) {
+	if len(paths) == 0 {
+		return nil, nil, nil
+	}
+
+	scope := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSuffix(normalizeVirtualPath(path), "/")
+		if path == "" {
+			return nil, nil, errors.New("snapshot path cannot be empty")
+		}
+		scope = append(scope, path)
+	}
+	sort.Strings(scope)
+
+	roots := make(map[string]string, len(scope))
+	var kept []string
+	for _, path := range scope {
+		if pathWithinAny(path, kept) {
+			continue
+		}
+		mountPoint, mount := fs.localMountFor(path)
+		if mount == nil {
+			return nil, nil, notFoundf("no local mount for snapshot path '%s'", path)
+		}
+		if mount.ReadOnly {
+			return nil, nil, fmt.Errorf("%w: cannot snapshot '%s'", ErrReadOnly, path)
+		}
+		roots[path] = filepath.Join(mount.LocalPath, filepath.FromSlash(strings.TrimPrefix(path, mountPoint)))
+		kept = append(kept, path)
+	}
+	return kept, roots, nil
+}
+
+// inScope reports whether path is captured by the snapshot. Snapshots
+// without a scope capture every path.
+func (s *Snapshot) inScope(path string) bool {
+	return len(s.Scope) == 0 || pathWithinAny(path, s.Scope)
+}
+
+// pathWithinAny reports whether virtual path lies within any of prefixes
+func pathWithinAny(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if isSubPath(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
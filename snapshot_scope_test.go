@@ -0,0 +1,86 @@
+package toolfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateSnapshotScoped(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	if err := fs.MountLocal("/data", tmpDir, false); err != nil {
+		t.Fatalf("MountLocal failed: %v", err)
+	}
+
+	if err := fs.CreateSnapshot("full"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	fs.WriteFile("/toolfs/data/subdir/subfile.txt", []byte("changed in scope"))
+	fs.WriteFile("/toolfs/data/subdir/added.txt", []byte("added"))
+	if err := fs.CreateSnapshotScoped("work", []string{"/toolfs/data/subdir", "/toolfs/data/subdir/added.txt"}); err != nil {
+		t.Fatalf("CreateSnapshotScoped failed: %v", err)
+	}
+
+	snapshot := fs.snapshots["work"]
+	if snapshot.BaseSnapshot != "full" || len(snapshot.Scope) != 1 || snapshot.Scope[0] != "/toolfs/data/subdir" {
+		t.Errorf("Unexpected base %q and scope %v", snapshot.BaseSnapshot, snapshot.Scope)
+	}
+	for path := range snapshot.Files {
+		if !isSubPath(path, "/toolfs/data/subdir") {
+			t.Errorf("Expected only files within the scope, got %s", path)
+		}
+	}
+	if snapshot.Files["/toolfs/data/subdir/subfile.txt"] == nil || snapshot.Files["/toolfs/data/subdir/added.txt"] == nil {
+		t.Errorf("Expected the changed and added files to be captured, got %v", snapshot.Files)
+	}
+
+	// Rolling back only touches files within the scope
+	fs.WriteFile("/toolfs/data/subdir/subfile.txt", []byte("changed again"))
+	fs.WriteFile("/toolfs/data/test.txt", []byte("outside scope"))
+	if err := fs.RollbackSnapshot("work"); err != nil {
+		t.Fatalf("RollbackSnapshot failed: %v", err)
+	}
+	if data, _ := fs.ReadFile("/toolfs/data/subdir/subfile.txt"); string(data) != "changed in scope" {
+		t.Errorf("Expected the file within the scope to be restored, got %q", data)
+	}
+	if data, _ := fs.ReadFile("/toolfs/data/test.txt"); string(data) != "outside scope" {
+		t.Errorf("Expected the file outside the scope to be left alone, got %q", data)
+	}
+
+	// Deletions within the scope are captured against the base chain
+	fs.DeleteFile("/toolfs/data/subdir/added.txt")
+	if err := fs.CreateSnapshotScoped("deleted", []string{"/toolfs/data/subdir/added.txt"}); err != nil {
+		t.Fatalf("CreateSnapshotScoped failed: %v", err)
+	}
+	if file := fs.snapshots["deleted"].Files["/toolfs/data/subdir/added.txt"]; file == nil || file.Operation != "deleted" {
+		t.Errorf("Expected the deletion to be recorded, got %+v", file)
+	}
+
+	// A full rollback still restores everything
+	if err := fs.RollbackSnapshot("full"); err != nil {
+		t.Fatalf("RollbackSnapshot failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "test.txt")); string(data) != "Hello, ToolFS!" {
+		t.Errorf("Expected the full rollback to restore test.txt, got %q", data)
+	}
+
+	if err := fs.CreateSnapshotScoped("none", nil); err == nil {
+		t.Error("Expected an error without paths")
+	}
+	if err := fs.CreateSnapshotScoped("memory", []string{"/toolfs/memory"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a virtual path, got %v", err)
+	}
+	readOnly := t.TempDir()
+	fs.MountLocal("/ro", readOnly, true)
+	if err := fs.CreateSnapshotScoped("ro", []string{"/toolfs/ro"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	if _, exists := fs.snapshots["ro"]; exists {
+		t.Error("Expected failed snapshots not to be stored")
+	}
+}
@@ -0,0 +1,138 @@
+package toolfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrStreamingUnsupported is wrapped by OpenReader errors for paths that
+// cannot be streamed, such as skill mounts
+var ErrStreamingUnsupported = errors.New("streaming not supported")
+
+// OpenReader opens path for streaming reads. Files on local mounts are read
+// directly from disk; virtual paths (memory entries, RAG queries, the health
+// file) and files on remote mounts are read in full and served from memory. Skill mounts are not
+// supported. With a session, access is checked when the reader is opened and
+// one "OpenReader" audit entry recording the total bytes read is logged when
+// it is closed (or immediately, if opening fails). The caller must close the
+// reader.
+func (fs *ToolFS) OpenReader(path string, session *Session) (io.ReadCloser, error) {
+	end := fs.startSpan("OpenReader", path, session)
+	reader, err := fs.openReaderAudited(path, session)
+	end(err)
+	return reader, err
+}
+
+// openReaderAudited applies access control and auditing to openReader
+func (fs *ToolFS) openReaderAudited(path string, session *Session) (io.ReadCloser, error) {
+	if session == nil {
+		if err := fs.checkDenied(path); err != nil {
+			return nil, err
+		}
+		reader, err := fs.openReader(path)
+		fs.checkMountError(path, err)
+		return reader, err
+	}
+
+	start := time.Now()
+	err := fs.authorize(session, "OpenReader", path)
+	var reader io.ReadCloser
+	if err == nil {
+		reader, err = fs.openReader(path)
+		fs.checkMountError(path, err)
+	}
+	if err != nil {
+		fs.auditOp(session, "OpenReader", path, err, 0, 0, start, nil, nil)
+		return nil, err
+	}
+	return &auditedReader{ReadCloser: reader, fs: fs, session: session, path: path, start: start}, nil
+}
+
+// openReader opens path without access control or auditing
+func (fs *ToolFS) openReader(path string) (io.ReadCloser, error) {
+	if normalizeVirtualPath(path) == fs.healthPath() {
+		data, err := fs.readHealth()
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	localPath, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mountKind(mount) {
+	case "skill":
+		return nil, fmt.Errorf("%w: '%s' is on a skill mount", ErrStreamingUnsupported, path)
+	case "memory", "rag", "skills", "remote":
+		data, err := fs.readFileWithSession(path, nil)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	if hasTrailingSlash(path) {
+		return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+	}
+	if mount.lazy != nil {
+		if err := mount.lazy.materialize(localPath); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := file.Stat(); err == nil && info.IsDir() {
+		file.Close()
+		return nil, fmt.Errorf("%w: %s", ErrIsDirectory, path)
+	}
+	return file, nil
+}
+
+// auditedReader counts the bytes read through it and logs the audit entry
+// for its OpenReader call when closed
+type auditedReader struct {
+	io.ReadCloser
+	fs      *ToolFS
+	session *Session
+	path    string
+	start   time.Time
+
+	mu        sync.Mutex
+	bytesRead int64
+	closed    bool
+}
+
+func (r *auditedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.mu.Lock()
+	r.bytesRead += int64(n)
+	r.mu.Unlock()
+	return n, err
+}
+
+// Close closes the underlying reader and logs the audit entry. Later calls
+// return an error and log nothing.
+func (r *auditedReader) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return os.ErrClosed
+	}
+	r.closed = true
+	bytesRead := r.bytesRead
+	r.mu.Unlock()
+
+	err := r.ReadCloser.Close()
+	r.fs.auditOp(r.session, "OpenReader", r.path, err, bytesRead, 0, r.start, nil, nil)
+	return err
+}
@@ -0,0 +1,96 @@
+package toolfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenReader(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	large := bytes.Repeat([]byte("0123456789"), 100000)
+	if err := os.WriteFile(filepath.Join(tmpDir, "large.bin"), large, 0o644); err != nil {
+		t.Fatalf("Failed to write large file: %v", err)
+	}
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+	fs.memoryStore.Set("note", "remember this", nil)
+
+	session, _ := fs.NewSession("streamer", []string{"/toolfs/data", "/toolfs/memory"})
+	logger := &TestAuditLogger{}
+	session.SetAuditLogger(logger)
+
+	reader, err := fs.OpenReader("/toolfs/data/large.bin", session)
+	if err != nil {
+		t.Fatalf("OpenReader failed: %v", err)
+	}
+	if _, ok := reader.(*auditedReader).ReadCloser.(*os.File); !ok {
+		t.Error("Expected local files to be streamed from disk")
+	}
+	if len(logger.Entries) != 0 {
+		t.Errorf("Expected no audit entry before Close, got %+v", logger.Entries)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil || !bytes.Equal(data, large) {
+		t.Fatalf("Expected streamed content, got %d bytes, %v", len(data), err)
+	}
+	if err := reader.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if reader.Close() == nil {
+		t.Error("Expected second Close to fail")
+	}
+	if len(logger.Entries) != 1 || logger.Entries[0].Operation != "OpenReader" || logger.Entries[0].BytesRead != int64(len(large)) {
+		t.Errorf("Expected one OpenReader entry with the total bytes, got %+v", logger.Entries)
+	}
+
+	// Partial reads record only what was read
+	logger.Entries = nil
+	reader, _ = fs.OpenReader("/toolfs/data/large.bin", session)
+	io.ReadFull(reader, make([]byte, 100))
+	reader.Close()
+	if logger.Entries[0].BytesRead != 100 {
+		t.Errorf("Expected 100 bytes recorded, got %d", logger.Entries[0].BytesRead)
+	}
+
+	// Virtual paths are served from memory
+	reader, err = fs.OpenReader("/toolfs/memory/note", session)
+	if err != nil {
+		t.Fatalf("OpenReader on memory failed: %v", err)
+	}
+	data, _ = io.ReadAll(reader)
+	reader.Close()
+	if !strings.Contains(string(data), "remember this") {
+		t.Errorf("Expected memory entry, got %s", data)
+	}
+
+	// Access control is applied and failures are audited immediately
+	logger.Entries = nil
+	if _, err := fs.OpenReader("/toolfs/rag/query?text=x", session); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied, got %v", err)
+	}
+	if len(logger.Entries) != 1 || !logger.Entries[0].AccessDenied {
+		t.Errorf("Expected denied audit entry, got %+v", logger.Entries)
+	}
+	if _, err := fs.OpenReader("/toolfs/data/subdir", nil); !errors.Is(err, ErrIsDirectory) {
+		t.Errorf("Expected ErrIsDirectory, got %v", err)
+	}
+
+	// Skill mounts cannot be streamed
+	pm := NewSkillExecutorManager()
+	fs.SetSkillExecutorManager(pm)
+	pm.InjectSkill(&MockSkill{name: "mock-stream", version: "1.0.0"}, NewSkillContext(fs, nil), nil)
+	if err := fs.MountSkillExecutor("/stream", "mock-stream"); err != nil {
+		t.Fatalf("MountSkillExecutor failed: %v", err)
+	}
+	if _, err := fs.OpenReader("/toolfs/stream/file", nil); !errors.Is(err, ErrStreamingUnsupported) {
+		t.Errorf("Expected ErrStreamingUnsupported, got %v", err)
+	}
+}
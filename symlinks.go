@@ -0,0 +1,65 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymlinkHops bounds symlink resolution in confineToMount, like the
+// kernel's limit on nested links
+const maxSymlinkHops = 255
+
+// confineToMount verifies that localPath, with its symlinks resolved, lies
+// within root. Paths that do not exist yet are checked through their nearest
+// existing ancestor, so files created through a link are covered too.
+func confineToMount(localPath, root string) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		// A missing mount directory is reported by the operation itself
+		return nil
+	}
+	resolved, err := resolveSymlinks(localPath)
+	if err != nil {
+		return err
+	}
+	if resolved != realRoot && !strings.HasPrefix(resolved, realRoot+string(filepath.Separator)) {
+		return &PathEscapeError{Symlink: true}
+	}
+	return nil
+}
+
+// resolveSymlinks is filepath.EvalSymlinks for paths whose final components
+// may not exist. Dangling links are followed to where a write would create
+// their target.
+func resolveSymlinks(path string) (string, error) {
+	var rest []string // Missing components below path, innermost last
+	for hops := 0; hops < maxSymlinkHops; hops++ {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		if target, linkErr := os.Readlink(path); linkErr == nil {
+			// A dangling link: continue from its target
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			path = target
+			continue
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, rest...)...), nil
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+	return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+}
@@ -1,29 +1,121 @@
 package toolfs
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	mathrand "math/rand"
 	"net/url"
 	"os"
+	"os/exec"
+	pathpkg "path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
 // FileInfo represents file metadata
 type FileInfo struct {
-	Size    int64
-	ModTime time.Time
-	IsDir   bool
+	Name     string // Entry name, set by ListDirInfo
+	Size     int64
+	ModTime  time.Time
+	IsDir    bool
+	BackedBy *MountInfo // Mount that backs this path (local, memory, rag or skill)
 }
 
+// Errors returned when a path's trailing slash does not match its target.
+// A trailing slash signals that the caller expects a directory.
+var (
+	ErrIsDirectory  = errors.New("is a directory")
+	ErrNotDirectory = errors.New("not a directory")
+)
+
+// ErrAccessDenied is wrapped by errors returned when a session is not allowed
+// to access a path
+var ErrAccessDenied = errors.New("access denied")
+
+// ErrPathEscape is wrapped, along with ErrAccessDenied, by the
+// *PathEscapeError returned for paths that lead outside their mount
+var ErrPathEscape = errors.New("path escapes mount")
+
+// ErrNotFound is wrapped by errors for memory entries, mounts, skill mounts
+// and snapshots that do not exist. It is os.ErrNotExist, so errors for
+// missing local files and objects match it too.
+var ErrNotFound = os.ErrNotExist
+
+// notFoundError is an error message wrapping ErrNotFound
+type notFoundError string
+
+func (e notFoundError) Error() string { return string(e) }
+
+func (e notFoundError) Unwrap() error { return ErrNotFound }
+
+// notFoundf formats an error wrapping ErrNotFound without adding its text
+func notFoundf(format string, args ...interface{}) error {
+	return notFoundError(fmt.Sprintf(format, args...))
+}
+
+// ErrTooManySessions is wrapped by NewSession errors when the session limit
+// set with SetMaxSessions is reached
+var ErrTooManySessions = errors.New("too many sessions")
+
+// ErrSessionExpired is wrapped by errors returned for operations on a session
+// past its expiry time
+var ErrSessionExpired = errors.New("session expired")
+
+// Errors returned by Move. ErrReadOnly is wrapped when the source or
+// destination is read-only (and by WriteFile, DeleteFile and Mkdir for
+// read-only paths);
+// ErrCrossDevice when the paths are served by different backends, or by a
+// backend that cannot rename (RAG, skill mounts).
+var (
+	ErrReadOnly    = errors.New("read-only path")
+	ErrCrossDevice = errors.New("cannot move across backends")
+)
+
 // Mount represents a mounted directory with its permissions
 type Mount struct {
-	LocalPath string
-	ReadOnly  bool
+	LocalPath      string
+	ReadOnly       bool
+	FollowSymlinks bool // Allow symlinks that lead outside LocalPath (see LocalMountOptions)
+
+	lazy   *lazySource  // Set for lazy mounts, which materialize files on access
+	remote remoteSource // Set for mounts served from outside the local filesystem (e.g. HTTP, object stores)
+
+	missing error // Set when the local directory was found missing (degraded)
+}
+
+// Mount types reported by MountInfo
+const (
+	MountTypeLocal  = "local"
+	MountTypeMemory = "memory"
+	MountTypeRAG    = "rag"
+	MountTypeSkill  = "skill"
+	MountTypeLazy   = "lazy"
+)
+
+// MountInfo describes a mount point and what backs it
+type MountInfo struct {
+	MountPoint string   `json:"mount_point"`
+	Type       string   `json:"type"`                 // "local", "lazy", "http", "object", "memory", "rag" or "skill"
+	LocalPath  string   `json:"local_path,omitempty"` // Only for local mounts
+	SkillName  string   `json:"skill_name,omitempty"` // Only for skill mounts
+	ReadOnly   bool     `json:"read_only"`
+	Operations []string `json:"operations"`         // Operations allowed on the mount
+	Degraded   bool     `json:"degraded,omitempty"` // Local directory found missing (see SetMissingMountPolicy)
+	Error      string   `json:"error,omitempty"`    // Why the mount is degraded
+
+	FollowSymlinks bool `json:"follow_symlinks,omitempty"` // Only for local mounts (see LocalMountOptions)
 }
 
 // MemoryEntry represents a memory entry with content and metadata
@@ -41,6 +133,11 @@ type RAGResult struct {
 	Content  string                 `json:"content"`
 	Score    float64                `json:"score"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Set when highlighting is requested (?highlight=true): RawContent keeps
+	// the original content and Highlights lists the match offsets within it
+	RawContent string `json:"raw_content,omitempty"`
+	Highlights []Span `json:"highlights,omitempty"`
 }
 
 // RAGSearchResults represents RAG search results
@@ -48,13 +145,27 @@ type RAGSearchResults struct {
 	Query   string      `json:"query"`
 	TopK    int         `json:"top_k"`
 	Results []RAGResult `json:"results"`
+
+	// Set when paging is requested (?offset=&limit=): Total counts the
+	// results on all pages
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+	Total  int `json:"total,omitempty"`
 }
 
+// Formats for reading memory entries: /toolfs/memory/<id> returns the
+// content as text, /toolfs/memory/<id>?format=json the whole MemoryEntry
+const (
+	MemoryFormatText = "text"
+	MemoryFormatJSON = "json"
+)
+
 // MemoryStore defines the interface for memory storage
 type MemoryStore interface {
 	Get(id string) (*MemoryEntry, error)
 	Set(id string, content string, metadata map[string]interface{}) error
 	List() ([]string, error)
+	Delete(id string) error // Fails for unknown IDs
 }
 
 // RAGStore defines the interface for RAG storage and search
@@ -64,15 +175,22 @@ type RAGStore interface {
 
 // AuditLogEntry represents a single audit log entry
 type AuditLogEntry struct {
-	Timestamp    time.Time `json:"timestamp"`
-	SessionID    string    `json:"session_id"`
-	Operation    string    `json:"operation"` // "ReadFile", "WriteFile", "ListDir", "Stat"
-	Path         string    `json:"path"`
-	Success      bool      `json:"success"`
-	Error        string    `json:"error,omitempty"`
-	BytesRead    int64     `json:"bytes_read,omitempty"`
-	BytesWritten int64     `json:"bytes_written,omitempty"`
-	AccessDenied bool      `json:"access_denied,omitempty"`
+	Timestamp     time.Time     `json:"timestamp"`
+	SessionID     string        `json:"session_id"`
+	Operation     string        `json:"operation"` // "ReadFile", "WriteFile", "ListDir", "Stat", "SessionCreate", ...
+	Path          string        `json:"path"`
+	Success       bool          `json:"success"`
+	Error         string        `json:"error,omitempty"`
+	BytesRead     int64         `json:"bytes_read,omitempty"`
+	BytesWritten  int64         `json:"bytes_written,omitempty"`
+	AccessDenied  bool          `json:"access_denied,omitempty"`
+	Expired       bool          `json:"expired,omitempty"`        // The operation was rejected because the session expired
+	QuotaExceeded bool          `json:"quota_exceeded,omitempty"` // The operation was rejected by the session's quota
+	DeniedBy      string        `json:"denied_by,omitempty"`      // Deny rule or pattern that caused an access denial
+	Duration      time.Duration `json:"duration_ns,omitempty"`    // Time spent in the operation
+	ContentHash   string        `json:"content_hash,omitempty"`   // SHA-256 of the content read or written (see SetAuditContentHash)
+
+	Metadata map[string]interface{} `json:"metadata,omitempty"` // Extra event details (e.g. allowed_paths)
 }
 
 // AuditLogger defines the interface for audit logging
@@ -93,22 +211,66 @@ func (l *StdoutAuditLogger) Log(entry AuditLogEntry) error {
 	return nil
 }
 
+// SampleConfig controls which audit entries are logged. Each entry is kept
+// with probability ReadSampleRate (0 drops all, 1 keeps all); failures and
+// writes can be exempted from sampling so they are always logged. A
+// session's first operation, marked first_use, is never sampled out.
+type SampleConfig struct {
+	ReadSampleRate    float64
+	AlwaysLogFailures bool
+	AlwaysLogWrites   bool
+}
+
+// Tracer creates spans around ToolFS operations for distributed tracing.
+// StartSpan returns a derived context and a function that ends the span,
+// recording the operation's error (nil on success).
+type Tracer interface {
+	StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, func(err error))
+}
+
+// noopTracer is the default tracer and records nothing
+type noopTracer struct{}
+
+// StartSpan implements Tracer
+func (noopTracer) StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, func(err error)) {
+	return ctx, func(error) {}
+}
+
 // Session represents an isolated LLM session with access restrictions
 type Session struct {
 	ID               string
 	CreatedAt        time.Time
-	AllowedPaths     []string // List of allowed path prefixes
+	AllowedPaths     []string // List of allowed path prefixes (for reads and writes without ReadPaths/WritePaths)
+	ReadPaths        []string // Path prefixes that may be read; empty falls back to AllowedPaths
+	WritePaths       []string // Path prefixes that may be written; empty falls back to AllowedPaths
+	DeniedPaths      []string // Path prefixes or globs that may not be accessed; checked before any allow list
 	AuditLogger      AuditLogger
 	CommandValidator CommandValidator // Optional command validator
+	ExpiresAt        time.Time        // Optional expiry; zero means the session never expires
+
+	// LastAccessedAt is when the session last performed an operation (its
+	// creation time until then). It is updated concurrently with operations;
+	// use LastAccess to read it while operations may be running.
+	LastAccessedAt time.Time
+
+	firstUse sync.Once  // Marks the first operation of the session
+	accessMu sync.Mutex // Protects LastAccessedAt
+
+	// Resource limits and consumption (see SetQuota)
+	quotaMu sync.Mutex
+	quota   Quota
+	usage   Quota
 }
 
 // NewSession creates a new session with the given ID and allowed paths
 func NewSession(id string, allowedPaths []string) *Session {
+	now := time.Now()
 	return &Session{
-		ID:           id,
-		CreatedAt:    time.Now(),
-		AllowedPaths: allowedPaths,
-		AuditLogger:  &StdoutAuditLogger{},
+		ID:             id,
+		CreatedAt:      now,
+		AllowedPaths:   allowedPaths,
+		AuditLogger:    &StdoutAuditLogger{},
+		LastAccessedAt: now,
 	}
 }
 
@@ -117,6 +279,24 @@ func (s *Session) SetAuditLogger(logger AuditLogger) {
 	s.AuditLogger = logger
 }
 
+// SetReadPaths sets the path prefixes the session may read, overriding
+// AllowedPaths for reads. Nil or empty restores the AllowedPaths fallback.
+func (s *Session) SetReadPaths(paths []string) {
+	s.ReadPaths = paths
+}
+
+// SetWritePaths sets the path prefixes the session may write, overriding
+// AllowedPaths for writes. Nil or empty restores the AllowedPaths fallback.
+func (s *Session) SetWritePaths(paths []string) {
+	s.WritePaths = paths
+}
+
+// SetDeniedPaths sets path prefixes or glob patterns the session may not
+// access. They override ReadPaths, WritePaths and AllowedPaths.
+func (s *Session) SetDeniedPaths(paths []string) {
+	s.DeniedPaths = paths
+}
+
 // SetCommandValidator sets a command validator for the session
 func (s *Session) SetCommandValidator(validator CommandValidator) {
 	s.CommandValidator = validator
@@ -130,42 +310,210 @@ func (s *Session) ValidateCommand(command string, args []string) (bool, string)
 	return s.CommandValidator.IsCommandAllowed(command, args)
 }
 
-// IsPathAllowed checks if a path is allowed for this session
+// Expired reports whether the session has passed its expiry time
+func (s *Session) Expired() bool {
+	return !s.ExpiresAt.IsZero() && !time.Now().Before(s.ExpiresAt)
+}
+
+// SetTTL makes the session expire ttl after its creation time. Zero or less
+// removes the expiry.
+func (s *Session) SetTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		s.ExpiresAt = time.Time{}
+		return
+	}
+	s.ExpiresAt = s.CreatedAt.Add(ttl)
+}
+
+// checkExpired returns an error wrapping ErrSessionExpired once the session
+// has expired
+func (s *Session) checkExpired() error {
+	if s.Expired() {
+		return fmt.Errorf("%w: session '%s' expired at %s", ErrSessionExpired, s.ID, s.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// touch records that the session performed an operation
+func (s *Session) touch() {
+	s.accessMu.Lock()
+	s.LastAccessedAt = time.Now()
+	s.accessMu.Unlock()
+}
+
+// LastAccess returns LastAccessedAt
+func (s *Session) LastAccess() time.Time {
+	s.accessMu.Lock()
+	defer s.accessMu.Unlock()
+	return s.LastAccessedAt
+}
+
+// IdleDuration returns how long the session has been idle
+func (s *Session) IdleDuration() time.Duration {
+	return time.Since(s.LastAccess())
+}
+
+// IsPathAllowed reports whether the session may read or write path
 func (s *Session) IsPathAllowed(path string) bool {
-	if len(s.AllowedPaths) == 0 {
+	return s.IsReadAllowed(path) || s.IsWriteAllowed(path)
+}
+
+// IsReadAllowed reports whether the session may read path. ReadPaths is used
+// when set, AllowedPaths otherwise; DeniedPaths overrides both.
+func (s *Session) IsReadAllowed(path string) bool {
+	return s.deniedRule(path) == "" && pathMatchesPrefixes(path, s.readPaths())
+}
+
+// IsWriteAllowed reports whether the session may write path. WritePaths is
+// used when set, AllowedPaths otherwise; DeniedPaths overrides both.
+func (s *Session) IsWriteAllowed(path string) bool {
+	return s.deniedRule(path) == "" && pathMatchesPrefixes(path, s.writePaths())
+}
+
+// checkAccess returns an error wrapping ErrAccessDenied if the session may not
+// read (or, if write is set, write) path. Denials by DeniedPaths are returned
+// as *PathDeniedError.
+func (s *Session) checkAccess(path string, write bool) error {
+	if rule := s.deniedRule(path); rule != "" {
+		return &PathDeniedError{Path: normalizeVirtualPath(path), Rule: rule, SessionID: s.ID}
+	}
+	if write && !pathMatchesPrefixes(path, s.writePaths()) {
+		return fmt.Errorf("%w: writing path '%s' is not allowed for session '%s'", ErrAccessDenied, path, s.ID)
+	}
+	if !write && !pathMatchesPrefixes(path, s.readPaths()) {
+		return fmt.Errorf("%w: path '%s' is not allowed for session '%s'", ErrAccessDenied, path, s.ID)
+	}
+	return nil
+}
+
+// deniedRule returns the first DeniedPaths entry matching path, or ""
+func (s *Session) deniedRule(path string) string {
+	path = normalizeVirtualPath(path)
+	for _, rule := range s.DeniedPaths {
+		if denyRuleMatches(normalizeVirtualPath(rule), path) {
+			return rule
+		}
+	}
+	return ""
+}
+
+// readPaths returns the prefixes governing reads
+func (s *Session) readPaths() []string {
+	if len(s.ReadPaths) > 0 {
+		return s.ReadPaths
+	}
+	return s.AllowedPaths
+}
+
+// writePaths returns the prefixes governing writes
+func (s *Session) writePaths() []string {
+	if len(s.WritePaths) > 0 {
+		return s.WritePaths
+	}
+	return s.AllowedPaths
+}
+
+// pathMatchesPrefixes reports whether path starts with one of prefixes, or
+// matches (or lies below a path matching) one of them that is a glob pattern
+// such as "/toolfs/data/**". No prefixes means no restriction.
+func pathMatchesPrefixes(path string, prefixes []string) bool {
+	if len(prefixes) == 0 {
 		return true // No restrictions if no paths specified
 	}
 
 	path = normalizeVirtualPath(path)
-	for _, allowed := range s.AllowedPaths {
+	for _, allowed := range prefixes {
 		allowed = normalizeVirtualPath(allowed)
-		if strings.HasPrefix(path, allowed) {
+		if isGlobPattern(allowed) {
+			if globMatchesPathOrAncestor(allowed, path) {
+				return true
+			}
+		} else if strings.HasPrefix(path, allowed) {
 			return true
 		}
 	}
 	return false
 }
 
-// logAudit logs an audit entry for this session
+// isWriteOp reports whether an audited operation modifies its path
+func isWriteOp(op string) bool {
+	switch op {
+	case "WriteFile", "DeleteFile", "Move", "MoveTo", "CopyTo", "ApplyPatch", "Mkdir", "SetMemory", "DeleteMemory", "ExecuteCommand":
+		return true
+	}
+	return false
+}
+
+// logAudit counts an operation towards the session's usage and logs an audit
+// entry for it
 func (s *Session) logAudit(operation, path string, success bool, err error, bytesRead, bytesWritten int64) {
+	s.addUsage(err, bytesRead, bytesWritten)
+	s.recordAudit(operation, path, success, err, bytesRead, bytesWritten, 0, "", nil)
+}
+
+// logEvent logs a session lifecycle event (SessionCreate or SessionDelete)
+// with the given metadata
+func (s *Session) logEvent(operation string, metadata map[string]interface{}) {
+	if s.AuditLogger == nil {
+		return
+	}
+	s.AuditLogger.Log(AuditLogEntry{
+		Timestamp: time.Now(),
+		SessionID: s.ID,
+		Operation: operation,
+		Success:   true,
+		Metadata:  metadata,
+	})
+}
+
+// firstUseMetadata returns metadata marking the session's first operation,
+// or nil for every later operation
+func (s *Session) firstUseMetadata() map[string]interface{} {
+	var metadata map[string]interface{}
+	s.firstUse.Do(func() {
+		metadata = map[string]interface{}{
+			"first_use":     true,
+			"allowed_paths": s.allowedPathsCopy(),
+		}
+	})
+	return metadata
+}
+
+// allowedPathsCopy returns a copy of the allowed paths for audit metadata
+func (s *Session) allowedPathsCopy() []string {
+	return append([]string{}, s.AllowedPaths...)
+}
+
+// recordAudit logs an audit entry including the operation's duration and
+// optional metadata
+func (s *Session) recordAudit(operation, path string, success bool, err error, bytesRead, bytesWritten int64, duration time.Duration, contentHash string, metadata map[string]interface{}) {
 	if s.AuditLogger == nil {
 		return
 	}
 
 	entry := AuditLogEntry{
-		Timestamp:    time.Now(),
-		SessionID:    s.ID,
-		Operation:    operation,
-		Path:         path,
-		Success:      success,
-		BytesRead:    bytesRead,
-		BytesWritten: bytesWritten,
-		AccessDenied: !success && err != nil && strings.Contains(err.Error(), "access denied"),
+		Timestamp:     time.Now(),
+		SessionID:     s.ID,
+		Operation:     operation,
+		Path:          path,
+		Success:       success,
+		BytesRead:     bytesRead,
+		BytesWritten:  bytesWritten,
+		AccessDenied:  !success && errors.Is(err, ErrAccessDenied),
+		Expired:       !success && errors.Is(err, ErrSessionExpired),
+		QuotaExceeded: !success && errors.Is(err, ErrQuotaExceeded),
+		Duration:      duration,
+		ContentHash:   contentHash,
+		Metadata:      metadata,
 	}
 
 	if err != nil {
 		entry.Error = err.Error()
 	}
+	var deniedErr *PathDeniedError
+	if errors.As(err, &deniedErr) {
+		entry.DeniedBy = deniedErr.Rule
+	}
 
 	s.AuditLogger.Log(entry)
 }
@@ -180,12 +528,17 @@ type SnapshotMetadata struct {
 
 // FileSnapshot represents a snapshot of a single file
 type FileSnapshot struct {
-	Path      string    `json:"path"`
-	Content   []byte    `json:"content"`
-	Size      int64     `json:"size"`
-	ModTime   time.Time `json:"mod_time"`
-	IsDir     bool      `json:"is_dir"`
-	Operation string    `json:"operation"` // "created", "modified", "deleted", "unchanged"
+	Path      string      `json:"path"`
+	Content   []byte      `json:"content"`
+	Size      int64       `json:"size"` // Uncompressed size
+	ModTime   time.Time   `json:"mod_time"`
+	IsDir     bool        `json:"is_dir"`
+	Mode      os.FileMode `json:"mode,omitempty"` // Permission bits, restored on rollback
+	Operation string      `json:"operation"`      // "created", "modified", "deleted", "unchanged"
+
+	// Compressed is set when Content holds the gzip-compressed content
+	// (see SetSnapshotCompression)
+	Compressed bool `json:"compressed,omitempty"`
 }
 
 // Snapshot represents a complete filesystem snapshot
@@ -194,6 +547,27 @@ type Snapshot struct {
 	Files        map[string]*FileSnapshot `json:"files"`                   // Path -> FileSnapshot
 	Changes      []ChangeRecord           `json:"changes"`                 // Tracked changes
 	BaseSnapshot string                   `json:"base_snapshot,omitempty"` // For copy-on-write
+
+	// Memory holds every memory entry when the snapshot was created with
+	// SnapshotOptions.IncludeMemory (IncludesMemory is then set)
+	Memory         []MemoryEntry `json:"memory,omitempty"`
+	IncludesMemory bool          `json:"includes_memory,omitempty"`
+
+	// Scope holds the virtual path prefixes captured by a scoped snapshot
+	// (see SnapshotOptions.Paths); empty for snapshots of every mount
+	Scope []string `json:"scope,omitempty"`
+}
+
+// SnapshotOptions configures CreateSnapshotWithOptions
+type SnapshotOptions struct {
+	// IncludeMemory captures all memory entries, which RollbackSnapshot then
+	// restores. Memory is captured in full rather than copy-on-write.
+	IncludeMemory bool
+
+	// Paths limits the snapshot to these virtual path prefixes on writable
+	// local mounts instead of walking every mount. Rolling the snapshot back
+	// only restores files within them.
+	Paths []string
 }
 
 // ChangeRecord tracks a change made after snapshot creation
@@ -214,15 +588,92 @@ type SandboxBackend interface {
 
 // SkillMount represents a skill mounted to a path
 type SkillMount struct {
-	SkillName string
-	Skill     SkillExecutor
-	ReadOnly  bool // Whether the skill mount is read-only
+	SkillName      string
+	Skill          SkillExecutor
+	ReadOnly       bool           // Whether the skill mount is read-only
+	ResponseFormat ResponseFormat // How the skill result is returned (Auto by default)
+
+	// Operations declared by a skill implementing OperationProvider (nil
+	// forwards every operation to the skill)
+	operations map[string]bool
+
+	// In-flight execution tracking for graceful unmount
+	mu       sync.Mutex
+	inflight int
+	draining bool
+	idle     chan struct{} // Closed when inflight drops to zero while draining
+}
+
+// ErrUnmountTimeout is returned (wrapped) when a skill mount is removed
+// before its in-flight executions finished
+var ErrUnmountTimeout = errors.New("timed out waiting for in-flight skill executions")
+
+// defaultUnmountTimeout bounds how long UnmountSkillExecutor waits for
+// in-flight executions
+const defaultUnmountTimeout = 30 * time.Second
+
+// acquire registers an execution against the mount. It fails once the mount
+// is being unmounted.
+func (m *SkillMount) acquire() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.draining {
+		return false
+	}
+	m.inflight++
+	return true
+}
+
+// release marks an execution started with acquire as finished
+func (m *SkillMount) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inflight--
+	if m.inflight == 0 && m.idle != nil {
+		close(m.idle)
+		m.idle = nil
+	}
+}
+
+// drain stops new executions and returns a channel closed once all
+// in-flight executions have finished
+func (m *SkillMount) drain() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.draining = true
+	if m.inflight == 0 {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	if m.idle == nil {
+		m.idle = make(chan struct{})
+	}
+	return m.idle
+}
+
+// supports reports whether the mounted skill handles operation
+func (m *SkillMount) supports(operation string) bool {
+	return m.operations == nil || m.operations[operation]
 }
 
+// ResponseFormat controls how a skill mount returns SkillResponse.Result
+type ResponseFormat string
+
+const (
+	// ResponseFormatAuto returns string results verbatim and anything else as JSON
+	ResponseFormatAuto ResponseFormat = "auto"
+	// ResponseFormatRaw returns string results verbatim and rejects other results
+	ResponseFormatRaw ResponseFormat = "raw"
+	// ResponseFormatJSON always returns the result encoded as JSON
+	ResponseFormatJSON ResponseFormat = "json"
+)
+
 // ToolFS represents the filesystem instance
 type ToolFS struct {
 	rootPath         string
 	mounts           map[string]*Mount
+	remoteMounts     map[string]*Mount      // Path -> mount served by a remoteSource
 	skillMounts      map[string]*SkillMount // Path -> SkillMount (formerly SkillMount)
 	memoryStore      MemoryStore
 	ragStore         RAGStore
@@ -230,6 +681,12 @@ type ToolFS struct {
 	snapshots        map[string]*Snapshot
 	currentSnapshot  string                 // Currently active snapshot (if any)
 	sandboxBackend   SandboxBackend         // Optional sandbox integration
+	restoreOps       restoreFileOps         // File operations used by rollback (nil = os)
+	defaultValidator CommandValidator       // Command validator applied to new sessions
+	commandTimeout   time.Duration          // Limit for ExecuteCommandWithSession (0 = default)
+	tracer           Tracer                 // Tracer for operation spans (no-op by default)
+	auditSampling    *SampleConfig          // Audit sampling (nil logs every operation)
+	auditLogger      AuditLogger            // Default audit logger for new sessions
 	executorManager  *SkillExecutorManager  // Optional skill manager
 	executorRegistry *SkillExecutorRegistry // Optional direct skill registry
 	skillDocManager  *SkillDocumentManager  // Skill document manager
@@ -237,14 +694,60 @@ type ToolFS struct {
 	builtinSkills    *BuiltinSkills         // Built-in skills (Memory, RAG)
 
 	// Performance optimizations: cached paths
-	memoryPath         string   // Cached memory path: rootPath + "/memory"
-	ragPath            string   // Cached RAG path: rootPath + "/rag"
-	pathNormalizeCache sync.Map // Cache for path normalization results
-	pathResolveCache   sync.Map // Cache for path resolution results (path -> *resolveCacheEntry)
+	memoryPath           string        // Cached memory path: rootPath + "/memory"
+	ragPath              string        // Cached RAG path: rootPath + "/rag"
+	skillsPath           string        // Cached skill document path: rootPath + "/skills"
+	pathNormalizeCache   sync.Map      // Cache for path normalization results
+	pathResolveCache     sync.Map      // Cache for path resolution results (path -> *resolveCacheEntry)
+	pathCacheDisabled    bool          // Resolve every path from the mount tables (see SetPathCacheEnabled)
+	unmountTimeout       time.Duration // Wait for in-flight skill executions on unmount (0 = default)
+	maxSessions          int           // Maximum number of live sessions (0 = unlimited)
+	sessionIdleTimeout   time.Duration // Idle time after which sessions are reaped (0 = never)
+	denyRules            []string      // Global deny rules (see SetDenyRules)
+	skillStateRoot       string        // Root of per-skill state directories ("" = disabled)
+	readFilesParallelism int           // Workers used by ReadFiles (0 = default)
+
+	// Missing local mount handling (see SetMissingMountPolicy). mountErrMu
+	// guards the policy, the callback and Mount.missing; like the other mount
+	// maps, fs.mounts itself is not guarded.
+	mountErrMu         sync.Mutex
+	missingMountPolicy MissingMountPolicy
+	onMountError       func(mountPoint string, err error)
+	degradedMounts     atomic.Int32 // Mounts with Mount.missing set
+
+	// ReadFileAuto decompression
+	decompressors       map[string]Decompressor // Extra decompressors by extension
+	maxDecompressedSize int64                   // Output limit (0 = DefaultMaxDecompressedSize)
+
+	// Audit content hashing (see SetAuditContentHash)
+	auditWriteHash bool
+	auditReadHash  bool
+
+	// Global skill execution limit (see SetMaxConcurrentSkillExecs)
+	skillExecLimiter      *execLimiter
+	skillExecPolicy       ConcurrencyPolicy
+	skillExecQueueTimeout time.Duration
+
+	// Local file content cache (see EnableReadCache)
+	readCacheMu sync.RWMutex
+	readCache   *readCache
+
+	// Snapshot content compression (see SetSnapshotCompression)
+	snapshotCompress    bool
+	snapshotCompressMin int
+
+	// Operation hooks (see AddHook)
+	hooksMu sync.RWMutex
+	hooks   []OperationHook
+
+	// Single-entry fast cache for repeated resolution of the same path,
+	// checked before pathResolveCache. Hits are lock-free.
+	lastResolved atomic.Pointer[resolveCacheEntry]
 }
 
 // resolveCacheEntry represents a cached path resolution result
 type resolveCacheEntry struct {
+	path      string
 	localPath string
 	mount     *Mount
 	mu        sync.RWMutex
@@ -254,20 +757,24 @@ type resolveCacheEntry struct {
 func NewToolFS(rootPath string) *ToolFS {
 	rootPath = normalizeVirtualPath(rootPath)
 	fs := &ToolFS{
-		rootPath:        rootPath,
-		mounts:          make(map[string]*Mount),
-		skillMounts:     make(map[string]*SkillMount),
-		memoryStore:     NewInMemoryStore(),
-		ragStore:        NewInMemoryRAGStore(),
-		sessions:        make(map[string]*Session),
-		snapshots:       make(map[string]*Snapshot),
-		currentSnapshot: "",
-		skillDocManager: NewSkillDocumentManager(),
+		rootPath:         rootPath,
+		mounts:           make(map[string]*Mount),
+		remoteMounts:     make(map[string]*Mount),
+		skillMounts:      make(map[string]*SkillMount),
+		memoryStore:      NewInMemoryStore(),
+		ragStore:         NewInMemoryRAGStore(),
+		sessions:         make(map[string]*Session),
+		snapshots:        make(map[string]*Snapshot),
+		currentSnapshot:  "",
+		skillDocManager:  NewSkillDocumentManager(),
+		tracer:           noopTracer{},
+		skillExecLimiter: newExecLimiter(0),
 	}
 
 	// Pre-compute and cache virtual paths for performance
 	fs.memoryPath = normalizeVirtualPath(rootPath + "/memory")
 	fs.ragPath = normalizeVirtualPath(rootPath + "/rag")
+	fs.skillsPath = normalizeVirtualPath(rootPath + "/skills")
 
 	// Load built-in skill documents from filesystem
 	_ = fs.skillDocManager.LoadBuiltinSkillDocs()
@@ -297,19 +804,116 @@ func (fs *ToolFS) SetSkillExecutorManager(manager *SkillExecutorManager) {
 	}
 }
 
+// SetTracer sets the tracer used to emit spans for operations.
+// Passing nil restores the default no-op tracer.
+func (fs *ToolFS) SetTracer(tracer Tracer) {
+	if tracer == nil {
+		tracer = noopTracer{}
+	}
+	fs.tracer = tracer
+}
+
+// SetAuditSampling enables sampling of session audit entries, e.g. to log
+// only 1% of successful reads. SampleConfig{ReadSampleRate: 1} logs every
+// operation again.
+func (fs *ToolFS) SetAuditSampling(config SampleConfig) {
+	fs.auditSampling = &config
+}
+
+// SetAuditContentHash records the SHA-256 of the data written by WriteFile in
+// the ContentHash field of its audit entry, so later tampering with the file
+// can be detected. It is off by default to avoid the hashing overhead.
+func (fs *ToolFS) SetAuditContentHash(enabled bool) {
+	fs.auditWriteHash = enabled
+}
+
+// SetAuditReadContentHash records the SHA-256 of the data returned by
+// ReadFile in its audit entry. It is off by default.
+func (fs *ToolFS) SetAuditReadContentHash(enabled bool) {
+	fs.auditReadHash = enabled
+}
+
+// auditContentHash returns the hex SHA-256 of content when hashing is enabled
+// for op, or ""
+func (fs *ToolFS) auditContentHash(op string, content []byte) string {
+	if content == nil || !(op == "WriteFile" && fs.auditWriteHash || op == "ReadFile" && fs.auditReadHash) {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// shouldAudit reports whether an audit entry for op should be logged
+func (fs *ToolFS) shouldAudit(op string, err error) bool {
+	config := fs.auditSampling
+	if config == nil {
+		return true
+	}
+	if err != nil && config.AlwaysLogFailures {
+		return true
+	}
+	if (op == "WriteFile" || op == "Move" || op == "MoveTo" || op == "CopyTo" || op == "DeleteFile" || op == "Mkdir") && config.AlwaysLogWrites {
+		return true
+	}
+	if config.ReadSampleRate >= 1 {
+		return true
+	}
+	return config.ReadSampleRate > 0 && mathrand.Float64() < config.ReadSampleRate
+}
+
+// startSpan starts a span for an operation on path
+func (fs *ToolFS) startSpan(name, path string, session *Session, extra ...string) func(err error) {
+	tracer := fs.tracer
+	if tracer == nil {
+		return func(error) {}
+	}
+
+	attrs := map[string]string{"path": path}
+	if session != nil {
+		attrs["session_id"] = session.ID
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		attrs[extra[i]] = extra[i+1]
+	}
+
+	_, end := tracer.StartSpan(context.Background(), name, attrs)
+	return end
+}
+
 // SetSandboxBackend sets an optional sandbox backend for snapshot integration
 func (fs *ToolFS) SetSandboxBackend(backend SandboxBackend) {
 	fs.sandboxBackend = backend
 }
 
+// SetDefaultCommandValidator sets the command validator applied to sessions
+// created by NewSession. Sessions can still override it with SetCommandValidator.
+// Existing sessions are not affected.
+func (fs *ToolFS) SetDefaultCommandValidator(validator CommandValidator) {
+	fs.defaultValidator = validator
+}
+
 // NewSession creates a new session and registers it with the ToolFS instance
 func (fs *ToolFS) NewSession(sessionID string, allowedPaths []string) (*Session, error) {
 	if _, exists := fs.sessions[sessionID]; exists {
 		return nil, errors.New("session already exists")
 	}
 
+	if fs.maxSessions > 0 && len(fs.sessions) >= fs.maxSessions {
+		fs.ReapSessions()
+		if len(fs.sessions) >= fs.maxSessions {
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManySessions, fs.maxSessions)
+		}
+	}
+
 	session := NewSession(sessionID, allowedPaths)
+	if fs.defaultValidator != nil {
+		session.CommandValidator = fs.defaultValidator
+	}
+	if fs.auditLogger != nil {
+		session.AuditLogger = fs.auditLogger
+	}
 	fs.sessions[sessionID] = session
+	session.logEvent("SessionCreate", map[string]interface{}{"allowed_paths": session.allowedPathsCopy()})
 	return session, nil
 }
 
@@ -324,7 +928,67 @@ func (fs *ToolFS) GetSession(sessionID string) (*Session, error) {
 
 // DeleteSession removes a session
 func (fs *ToolFS) DeleteSession(sessionID string) {
+	session, exists := fs.sessions[sessionID]
+	if !exists {
+		return
+	}
 	delete(fs.sessions, sessionID)
+	session.logEvent("SessionDelete", map[string]interface{}{"allowed_paths": session.allowedPathsCopy()})
+}
+
+// SetMaxSessions limits the number of live sessions. When the limit is
+// reached, NewSession removes expired and idle sessions (see ReapSessions) and
+// returns an error wrapping ErrTooManySessions if none could be removed. Zero
+// or less means unlimited.
+func (fs *ToolFS) SetMaxSessions(n int) {
+	fs.maxSessions = n
+}
+
+// SessionCount returns the number of sessions, including expired ones not
+// yet removed
+func (fs *ToolFS) SessionCount() int {
+	return len(fs.sessions)
+}
+
+// SetSessionIdleTimeout sets how long a session may go without operations
+// before ReapSessions removes it. Zero or less disables idle reaping.
+func (fs *ToolFS) SetSessionIdleTimeout(timeout time.Duration) {
+	fs.sessionIdleTimeout = timeout
+}
+
+// ReapSessions deletes every expired session and, if an idle timeout is set,
+// every session idle for longer than it. It returns the number of sessions
+// removed.
+func (fs *ToolFS) ReapSessions() int {
+	removed := 0
+	for id, session := range fs.sessions {
+		idle := fs.sessionIdleTimeout > 0 && session.IdleDuration() > fs.sessionIdleTimeout
+		if session.Expired() || idle {
+			fs.DeleteSession(id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// PurgeExpiredSessions deletes every expired session and returns the number
+// removed. Unlike ReapSessions it ignores the idle timeout.
+func (fs *ToolFS) PurgeExpiredSessions() int {
+	removed := 0
+	for id, session := range fs.sessions {
+		if session.Expired() {
+			fs.DeleteSession(id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// SetAuditLogger sets the audit logger used by sessions created afterwards
+// with fs.NewSession, including their SessionCreate events. Sessions can still
+// override it with Session.SetAuditLogger.
+func (fs *ToolFS) SetAuditLogger(logger AuditLogger) {
+	fs.auditLogger = logger
 }
 
 // SetMemoryStore sets the memory store for the ToolFS instance
@@ -342,6 +1006,12 @@ func (fs *ToolFS) GetSkillDocumentManager() *SkillDocumentManager {
 	return fs.skillDocManager
 }
 
+// hasTrailingSlash reports whether path ends with a separator, meaning the
+// caller refers to a directory
+func hasTrailingSlash(path string) bool {
+	return len(path) > 1 && (path[len(path)-1] == '/' || path[len(path)-1] == '\\')
+}
+
 // normalizeVirtualPath normalizes a virtual path to use forward slashes
 // Optimized: uses builder to reduce allocations and handles common cases efficiently
 func normalizeVirtualPath(path string) string {
@@ -402,20 +1072,73 @@ func normalizeVirtualPath(path string) string {
 	return result
 }
 
-// MountLocal mounts a local directory at the specified mount point
-// mountPoint is the path within the ToolFS root (e.g., "/data")
-// localPath is the actual local filesystem path
-// readOnly determines if the mount is read-only
-func (fs *ToolFS) MountLocal(mountPoint string, localPath string, readOnly bool) error {
+// rootedMountPoint normalizes a mount point and places it under the ToolFS root
+func (fs *ToolFS) rootedMountPoint(mountPoint string) string {
 	// Normalize mount point to use forward slashes
 	mountPoint = normalizeVirtualPath(mountPoint)
 
-	if !strings.HasPrefix(mountPoint, fs.rootPath) {
+	if mountPoint != fs.rootPath && !strings.HasPrefix(mountPoint, fs.rootPath+"/") {
 		// Join with root path, ensuring forward slashes
 		if !strings.HasPrefix(mountPoint, "/") {
 			mountPoint = "/" + mountPoint
 		}
-		mountPoint = normalizeVirtualPath(fs.rootPath + mountPoint)
+		mountPoint = fs.rootPath + mountPoint
+	}
+	return normalizeVirtualPath(pathpkg.Clean(mountPoint))
+}
+
+// canonicalMountPoint validates a mount point and returns its rooted, cleaned
+// form. Paths containing ".." segments are rejected rather than resolved, and
+// the result must lie strictly below the ToolFS root.
+func (fs *ToolFS) canonicalMountPoint(mountPoint string) (string, error) {
+	if mountPoint == "" {
+		return "", errors.New("mount path cannot be empty")
+	}
+	for _, segment := range strings.Split(normalizeVirtualPath(mountPoint), "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("invalid mount path '%s': path traversal is not allowed", mountPoint)
+		}
+	}
+
+	rooted := fs.rootedMountPoint(mountPoint)
+	if !strings.HasPrefix(rooted, fs.rootPath+"/") {
+		return "", fmt.Errorf("invalid mount path '%s': must be below the ToolFS root '%s'", mountPoint, fs.rootPath)
+	}
+	return rooted, nil
+}
+
+// MountLocal mounts a local directory at the specified mount point
+// mountPoint is the path within the ToolFS root (e.g., "/data")
+// localPath is the actual local filesystem path
+// readOnly determines if the mount is read-only
+// Symlinks leading outside localPath are not followed (see MountLocalWithOptions)
+func (fs *ToolFS) MountLocal(mountPoint string, localPath string, readOnly bool) error {
+	return fs.MountLocalWithOptions(mountPoint, localPath, LocalMountOptions{ReadOnly: readOnly})
+}
+
+// LocalMountOptions configures MountLocalWithOptions
+type LocalMountOptions struct {
+	ReadOnly bool
+
+	// FollowSymlinks allows symlinks inside the mount to lead anywhere on the
+	// host. By default paths are rejected with ErrAccessDenied ("symlink
+	// escapes mount") when their symlinks resolve outside the local directory.
+	FollowSymlinks bool
+}
+
+// MountLocalWithOptions mounts a local directory like MountLocal, with
+// additional options
+func (fs *ToolFS) MountLocalWithOptions(mountPoint string, localPath string, opts LocalMountOptions) error {
+	mountPoint, err := fs.canonicalMountPoint(mountPoint)
+	if err != nil {
+		return err
+	}
+
+	// Store the absolute path so later changes to the working directory
+	// do not change what the mount refers to
+	localPath, err = filepath.Abs(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve local path: %w", err)
 	}
 
 	// Verify local path exists
@@ -427,12 +1150,18 @@ func (fs *ToolFS) MountLocal(mountPoint string, localPath string, readOnly bool)
 		return errors.New("local path must be a directory")
 	}
 
+	// Remounting replaces the mount, along with any degraded state
+	if old, exists := fs.mounts[mountPoint]; exists {
+		fs.clearMountError(old)
+	}
 	fs.mounts[mountPoint] = &Mount{
-		LocalPath: localPath,
-		ReadOnly:  readOnly,
+		LocalPath:      localPath,
+		ReadOnly:       opts.ReadOnly,
+		FollowSymlinks: opts.FollowSymlinks,
 	}
 
 	// Invalidate path resolution cache since mounts changed
+	fs.invalidateLastResolved()
 	fs.pathResolveCache.Range(func(key, value interface{}) bool {
 		path := key.(string)
 		// Remove cache entries that might be affected by this mount
@@ -445,56 +1174,219 @@ func (fs *ToolFS) MountLocal(mountPoint string, localPath string, readOnly bool)
 	return nil
 }
 
-// isVirtualPath checks if the path is a virtual path (memory or rag)
-// Optimized: uses pre-computed cached paths
-func (fs *ToolFS) isVirtualPath(path string) (bool, string) {
-	path = normalizeVirtualPath(path)
+// UnmountLocal removes the local mount at mountPoint, including lazy mounts.
+// Files on disk are left untouched; cached path resolutions below the mount
+// point are discarded.
+func (fs *ToolFS) UnmountLocal(mountPoint string) error {
+	mountPoint = fs.rootedMountPoint(mountPoint)
 
-	if strings.HasPrefix(path, fs.memoryPath) {
-		return true, "memory"
-	}
-	if strings.HasPrefix(path, fs.ragPath) {
-		return true, "rag"
+	mount, exists := fs.mounts[mountPoint]
+	if !exists {
+		return fmt.Errorf("no local directory mounted at path '%s'", mountPoint)
 	}
-	return false, ""
+	fs.clearMountError(mount)
+	fs.removeLocalMount(mountPoint)
+	return nil
 }
 
-// isSkillMount checks if the path is mounted to a skill
-func (fs *ToolFS) isSkillMount(path string) (*SkillMount, string) {
-	path = normalizeVirtualPath(path)
-
-	// Find the longest matching skill mount point
-	var bestMount *SkillMount
-	var bestMountPoint string
-	var relPath string
+// ListMounts returns information about every mount point, sorted by path.
+// The built-in memory, RAG and skill document paths are included alongside
+// local and skill mounts.
+func (fs *ToolFS) ListMounts() []MountInfo {
+	mounts := make([]MountInfo, 0, len(fs.mounts)+len(fs.remoteMounts)+len(fs.skillMounts)+3)
 
-	for mountPoint, skillMount := range fs.skillMounts {
-		mountPoint = normalizeVirtualPath(mountPoint)
-		if strings.HasPrefix(path, mountPoint) {
-			if len(mountPoint) > len(bestMountPoint) {
-				bestMountPoint = mountPoint
-				bestMount = skillMount
-				relPath = strings.TrimPrefix(path, mountPoint)
-				relPath = strings.TrimPrefix(relPath, "/")
-				if relPath == "" {
-					relPath = "/"
-				} else {
-					relPath = "/" + relPath
-				}
-			}
-		}
+	mounts = append(mounts, fs.virtualMountInfo(MountTypeMemory), fs.virtualMountInfo(MountTypeRAG), fs.virtualMountInfo(MountTypeSkills))
+	fs.mountErrMu.Lock()
+	for mountPoint, mount := range fs.mounts {
+		mounts = append(mounts, localMountInfo(mountPoint, mount))
 	}
-
-	if bestMount != nil {
-		return bestMount, relPath
+	fs.mountErrMu.Unlock()
+	for mountPoint, mount := range fs.remoteMounts {
+		mounts = append(mounts, remoteMountInfo(mountPoint, mount))
+	}
+	for mountPoint, skillMount := range fs.skillMounts {
+		mounts = append(mounts, skillMountInfo(mountPoint, skillMount))
 	}
 
-	return nil, ""
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].MountPoint < mounts[j].MountPoint })
+	return mounts
 }
 
-// MountSkillExecutor mounts a skill to a ToolFS path.
+// mountInfoForPath describes the mount that a resolved path belongs to
+func (fs *ToolFS) mountInfoForPath(path string, mount *Mount) *MountInfo {
+	if mount == nil {
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(mount.LocalPath, "__SKILL_MOUNT__:"):
+		mountPoint, skillMount := fs.findSkillMount(path)
+		if skillMount == nil {
+			return nil
+		}
+		info := skillMountInfo(mountPoint, skillMount)
+		return &info
+	case mount.LocalPath == "__VIRTUAL_MEMORY__":
+		info := fs.virtualMountInfo(MountTypeMemory)
+		return &info
+	case mount.LocalPath == "__VIRTUAL_RAG__":
+		info := fs.virtualMountInfo(MountTypeRAG)
+		return &info
+	case mount.LocalPath == skillsVirtualMount:
+		info := fs.virtualMountInfo(MountTypeSkills)
+		return &info
+	case mount.remote != nil:
+		for mountPoint, m := range fs.remoteMounts {
+			if m == mount {
+				info := remoteMountInfo(mountPoint, m)
+				return &info
+			}
+		}
+		return nil
+	}
+
+	for mountPoint, m := range fs.mounts {
+		if m == mount {
+			info := localMountInfo(mountPoint, m)
+			return &info
+		}
+	}
+	return nil
+}
+
+// findSkillMount returns the longest skill mount point containing path
+func (fs *ToolFS) findSkillMount(path string) (string, *SkillMount) {
+	path = normalizeVirtualPath(path)
+
+	var bestMountPoint string
+	var bestMount *SkillMount
+	for mountPoint, skillMount := range fs.skillMounts {
+		if strings.HasPrefix(path, mountPoint) && len(mountPoint) > len(bestMountPoint) {
+			bestMountPoint = mountPoint
+			bestMount = skillMount
+		}
+	}
+	return bestMountPoint, bestMount
+}
+
+func (fs *ToolFS) virtualMountInfo(mountType string) MountInfo {
+	switch mountType {
+	case MountTypeRAG:
+		return MountInfo{
+			MountPoint: fs.ragPath,
+			Type:       MountTypeRAG,
+			ReadOnly:   true,
+			Operations: []string{"read_file", "list_dir", "stat"},
+		}
+	case MountTypeSkills:
+		return MountInfo{
+			MountPoint: fs.skillsPath,
+			Type:       MountTypeSkills,
+			ReadOnly:   true,
+			Operations: []string{"read_file", "list_dir", "stat"},
+		}
+	}
+	return MountInfo{
+		MountPoint: fs.memoryPath,
+		Type:       MountTypeMemory,
+		Operations: []string{"read_file", "write_file", "list_dir", "stat"},
+	}
+}
+
+func localMountInfo(mountPoint string, mount *Mount) MountInfo {
+	ops := []string{"read_file", "list_dir", "stat"}
+	if !mount.ReadOnly {
+		ops = []string{"read_file", "write_file", "list_dir", "stat"}
+	}
+	mountType := MountTypeLocal
+	if mount.lazy != nil {
+		mountType = MountTypeLazy
+	}
+	info := MountInfo{
+		MountPoint: mountPoint,
+		Type:       mountType,
+		LocalPath:  mount.LocalPath,
+		ReadOnly:   mount.ReadOnly,
+		Operations: ops,
+
+		FollowSymlinks: mount.FollowSymlinks,
+	}
+	if mount.missing != nil {
+		info.Degraded = true
+		info.Error = mount.missing.Error()
+	}
+	return info
+}
+
+func skillMountInfo(mountPoint string, skillMount *SkillMount) MountInfo {
+	var ops []string
+	for _, op := range []string{"read_file", "write_file", "list_dir"} {
+		if skillMount.supports(op) && (op != "write_file" || !skillMount.ReadOnly) {
+			ops = append(ops, op)
+		}
+	}
+	ops = append(ops, "stat") // Answered by ToolFS
+	return MountInfo{
+		MountPoint: mountPoint,
+		Type:       MountTypeSkill,
+		SkillName:  skillMount.SkillName,
+		ReadOnly:   skillMount.ReadOnly,
+		Operations: ops,
+	}
+}
+
+// isVirtualPath checks if the path is a virtual path (memory or rag)
+// Optimized: uses pre-computed cached paths
+func (fs *ToolFS) isVirtualPath(path string) (bool, string) {
+	path = normalizeVirtualPath(path)
+
+	if strings.HasPrefix(path, fs.memoryPath) {
+		return true, "memory"
+	}
+	if strings.HasPrefix(path, fs.ragPath) {
+		return true, "rag"
+	}
+	return false, ""
+}
+
+// isSkillMount checks if the path is mounted to a skill
+func (fs *ToolFS) isSkillMount(path string) (*SkillMount, string) {
+	path = normalizeVirtualPath(path)
+
+	// Find the longest matching skill mount point
+	var bestMount *SkillMount
+	var bestMountPoint string
+	var relPath string
+
+	for mountPoint, skillMount := range fs.skillMounts {
+		mountPoint = normalizeVirtualPath(mountPoint)
+		if strings.HasPrefix(path, mountPoint) {
+			if len(mountPoint) > len(bestMountPoint) {
+				bestMountPoint = mountPoint
+				bestMount = skillMount
+				relPath = strings.TrimPrefix(path, mountPoint)
+				relPath = strings.TrimPrefix(relPath, "/")
+				if relPath == "" {
+					relPath = "/"
+				} else {
+					relPath = "/" + relPath
+				}
+			}
+		}
+	}
+
+	if bestMount != nil {
+		return bestMount, relPath
+	}
+
+	return nil, ""
+}
+
+// MountSkillExecutor mounts a skill to a ToolFS path.
 // When operations are performed on paths under the mount point,
 // they are forwarded to the skill's Execute method.
+// The skill is looked up in the executor registry first and then among the
+// code skills registered with RegisterCodeSkill.
 //
 // Example:
 //
@@ -508,42 +1400,59 @@ func (fs *ToolFS) MountSkillExecutor(path string, skillName string) error {
 		return errors.New("skill name cannot be empty")
 	}
 
-	// Normalize path
-	path = normalizeVirtualPath(path)
-
-	if !strings.HasPrefix(path, fs.rootPath) {
-		if !strings.HasPrefix(path, "/") {
-			path = "/" + path
-		}
-		path = normalizeVirtualPath(fs.rootPath + path)
+	path, err := fs.canonicalMountPoint(path)
+	if err != nil {
+		return err
 	}
 
-	// Check if skill exists in skill manager or registry
+	// Check if skill exists in skill manager or registry, falling back to
+	// code skills registered through RegisterCodeSkill
 	var skill SkillExecutor
 	registry := fs.GetSkillExecutorRegistry()
 	if registry != nil {
-		var err error
 		skill, err = registry.Get(skillName)
-		if err != nil {
-			return fmt.Errorf("skill '%s' not found in registry: %w", skillName, err)
+	}
+	if skill == nil {
+		if registered, _ := fs.GetSkill(skillName); registered != nil && registered.Executor != nil {
+			skill, err = registered.Executor, nil
 		}
-	} else {
+	}
+	if err != nil {
+		return fmt.Errorf("skill '%s' not found in registry: %w", skillName, err)
+	}
+	if skill == nil {
 		return errors.New("skill registry not set, use AddSkillExecutorRegistry() or SetSkillExecutorManager() first")
 	}
 
+	return fs.mountSkillExecutor(path, skillName, skill)
+}
+
+// mountSkillExecutor mounts skill at the canonical mount point path.
+func (fs *ToolFS) mountSkillExecutor(path string, skillName string, skill SkillExecutor) error {
 	// Check if path is already mounted
 	if _, exists := fs.skillMounts[path]; exists {
 		return fmt.Errorf("path '%s' is already mounted to a skill", path)
 	}
 
 	// Create skill mount
-	fs.skillMounts[path] = &SkillMount{
-		SkillName: skillName,
-		Skill:     skill,
-		ReadOnly:  true, // Skills are read-only by default for safety
+	skillMount := &SkillMount{
+		SkillName:      skillName,
+		Skill:          skill,
+		ReadOnly:       true, // Skills are read-only by default for safety
+		ResponseFormat: ResponseFormatAuto,
+	}
+	if provider, ok := skill.(OperationProvider); ok {
+		skillMount.operations = make(map[string]bool)
+		for _, op := range provider.SupportedOperations() {
+			skillMount.operations[op] = true
+		}
+		// Skills declaring writes get writable mounts
+		skillMount.ReadOnly = !skillMount.supports("write_file")
 	}
+	fs.skillMounts[path] = skillMount
 
 	// Invalidate path resolution cache since skill mounts changed
+	fs.invalidateLastResolved()
 	fs.pathResolveCache.Range(func(key, value interface{}) bool {
 		pathKey := key.(string)
 		// Remove cache entries that might be affected by this mount
@@ -557,23 +1466,39 @@ func (fs *ToolFS) MountSkillExecutor(path string, skillName string) error {
 }
 
 // UnmountSkillExecutor removes a skill mount from a path.
+// It waits for executions already running against the mount to finish,
+// rejecting new ones meanwhile. If they do not finish within the unmount
+// timeout (see SetUnmountTimeout) the mount is removed anyway and an error
+// wrapping ErrUnmountTimeout is returned.
+// When the last mount of a skill implementing ClosableSkill is removed after
+// its executions finished, the skill is closed.
 func (fs *ToolFS) UnmountSkillExecutor(path string) error {
-	path = normalizeVirtualPath(path)
+	path = fs.rootedMountPoint(path)
 
-	if !strings.HasPrefix(path, fs.rootPath) {
-		if !strings.HasPrefix(path, "/") {
-			path = "/" + path
-		}
-		path = normalizeVirtualPath(fs.rootPath + path)
+	skillMount, exists := fs.skillMounts[path]
+	if !exists {
+		return fmt.Errorf("no skill mounted at path '%s'", path)
 	}
 
-	if _, exists := fs.skillMounts[path]; !exists {
-		return fmt.Errorf("no skill mounted at path '%s'", path)
+	// Wait for in-flight executions before removing the mount; new
+	// executions are rejected while draining
+	timeout := fs.unmountTimeout
+	if timeout <= 0 {
+		timeout = defaultUnmountTimeout
+	}
+	var drainErr error
+	timer := time.NewTimer(timeout)
+	select {
+	case <-skillMount.drain():
+	case <-timer.C:
+		drainErr = fmt.Errorf("%w: skill mount at '%s' removed after %s", ErrUnmountTimeout, path, timeout)
 	}
+	timer.Stop()
 
 	delete(fs.skillMounts, path)
 
 	// Invalidate path resolution cache since skill mounts changed
+	fs.invalidateLastResolved()
 	fs.pathResolveCache.Range(func(key, value interface{}) bool {
 		pathKey := key.(string)
 		// Remove cache entries that might be affected by this unmount
@@ -583,11 +1508,109 @@ func (fs *ToolFS) UnmountSkillExecutor(path string) error {
 		return true
 	})
 
+	if drainErr != nil {
+		// Executions may still be using the skill, so it is left open
+		return drainErr
+	}
+	for _, other := range fs.skillMounts {
+		if other.SkillName == skillMount.SkillName {
+			return nil
+		}
+	}
+	return closeSkill(skillMount.SkillName, skillMount.Skill)
+}
+
+// SetUnmountTimeout sets how long UnmountSkillExecutor waits for in-flight
+// executions on the mount before removing it anyway (30 seconds by default)
+func (fs *ToolFS) SetUnmountTimeout(timeout time.Duration) {
+	fs.unmountTimeout = timeout
+}
+
+// SetSkillMountResponseFormat sets how the skill mounted at path returns its
+// results: verbatim strings (Raw), always JSON (JSON) or auto-detected (Auto).
+func (fs *ToolFS) SetSkillMountResponseFormat(path string, format ResponseFormat) error {
+	switch format {
+	case ResponseFormatAuto, ResponseFormatRaw, ResponseFormatJSON:
+	default:
+		return fmt.Errorf("unknown response format: %s", format)
+	}
+
+	skillMount, exists := fs.skillMounts[fs.rootedMountPoint(path)]
+	if !exists {
+		return fmt.Errorf("no skill mounted at '%s'", path)
+	}
+	skillMount.ResponseFormat = format
 	return nil
 }
 
 // executeSkillMount executes a skill for a given path and operation.
-func (fs *ToolFS) executeSkillMount(skillMount *SkillMount, path, relPath, operation string, inputData []byte, session *Session) ([]byte, error) {
+func (fs *ToolFS) executeSkillMount(ctx context.Context, skillMount *SkillMount, path, relPath, operation string, inputData []byte, session *Session) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Run reads are checked against the operation named in their query
+	if !isSkillRun(operation, relPath) && !skillMount.supports(operation) {
+		return nil, fmt.Errorf("%w: skill '%s' does not support %s", ErrUnsupportedOperation, skillMount.SkillName, operation)
+	}
+	if !skillMount.acquire() {
+		return nil, fmt.Errorf("skill mount for '%s' is being unmounted", skillMount.SkillName)
+	}
+
+	release, err := fs.acquireSkillExec(skillMount.SkillName)
+	if err != nil {
+		skillMount.release()
+		return nil, err
+	}
+
+	end := fs.startSpan("SkillMount", path, session, "skill", skillMount.SkillName, "operation", operation)
+	run := func() ([]byte, error) {
+		defer skillMount.release()
+		defer release()
+		return fs.runSkillMount(ctx, skillMount, path, relPath, operation, inputData, session)
+	}
+
+	var output []byte
+	if ctx.Done() == nil {
+		output, err = run()
+	} else {
+		// The skill keeps its execution slot and holds off unmounting until
+		// it returns, even when the caller gives up on it
+		resultChan := make(chan executeResult, 1)
+		go func() {
+			output, err := run()
+			resultChan <- executeResult{output: output, err: err}
+		}()
+		select {
+		case result := <-resultChan:
+			output, err = result.output, result.err
+		case <-ctx.Done():
+			err = fmt.Errorf("skill '%s' %s abandoned: %w", skillMount.SkillName, operation, ctx.Err())
+		}
+	}
+	end(err)
+	return output, err
+}
+
+// runSkillMount implements executeSkillMount without tracing.
+//
+// The request sent to the skill always carries the same path information
+// regardless of the entry point (ReadFile, WriteFile or ListDir):
+//   - Path and Data["full_path"] hold the normalized full virtual path
+//     (e.g. "/toolfs/rag/query")
+//   - Data["relative_path"] holds the path relative to the mount point,
+//     always starting with "/" ("/" for the mount point itself), without
+//     any query string
+//   - Data["query"] holds the parameters of a "?<query>" suffix parsed into
+//     a map[string][]string, and is absent for paths without a query;
+//     Path and Data["full_path"] keep the raw query
+func (fs *ToolFS) runSkillMount(ctx context.Context, skillMount *SkillMount, path, relPath, operation string, inputData []byte, session *Session) ([]byte, error) {
+	path = normalizeVirtualPath(path)
+	run := isSkillRun(operation, relPath)
+	relPath, _, _ = strings.Cut(relPath, "?")
+	if relPath == "" {
+		relPath = "/"
+	}
+
 	// Create skill request
 	request := SkillRequest{
 		Operation: operation,
@@ -598,6 +1621,23 @@ func (fs *ToolFS) executeSkillMount(skillMount *SkillMount, path, relPath, opera
 		},
 	}
 
+	// Parse the query once so skills do not each re-parse the raw path
+	var query url.Values
+	if _, rawQuery, hasQuery := strings.Cut(path, "?"); hasQuery && rawQuery != "" {
+		var err error
+		query, err = url.ParseQuery(rawQuery)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query in skill path '%s': %w", path, err)
+		}
+		request.Data["query"] = map[string][]string(query)
+	}
+	if run {
+		applyRunQuery(&request, query)
+		if !skillMount.supports(request.Operation) {
+			return nil, fmt.Errorf("%w: skill '%s' does not support %s", ErrUnsupportedOperation, skillMount.SkillName, request.Operation)
+		}
+	}
+
 	// Add input data if provided
 	if inputData != nil {
 		request.Data["input"] = string(inputData)
@@ -613,6 +1653,12 @@ func (fs *ToolFS) executeSkillMount(skillMount *SkillMount, path, relPath, opera
 	if err != nil {
 		return nil, fmt.Errorf("failed to create skill request: %w", err)
 	}
+	// Run reads stand in for ExecuteSkill, so they get the same input checks
+	if run {
+		if err := validateSkillInput(skillMount.SkillName, skillMount.Skill, requestBytes); err != nil {
+			return nil, err
+		}
+	}
 
 	// Execute skill with error recovery
 	var output []byte
@@ -622,12 +1668,16 @@ func (fs *ToolFS) executeSkillMount(skillMount *SkillMount, path, relPath, opera
 		defer func() {
 			if r := recover(); r != nil {
 				// Skill execution panicked - convert to error but don't crash
-				execErr = fmt.Errorf("skill execution panicked: %v", r)
+				execErr = fmt.Errorf("%w: %v", ErrSkillPanic, r)
 			}
 		}()
 
-		// Execute skill
-		output, execErr = skillMount.Skill.Execute(requestBytes)
+		// Execute skill, passing the context to skills that accept one
+		if contextSkill, ok := skillMount.Skill.(ContextSkill); ok {
+			output, execErr = contextSkill.ExecuteContext(ctx, requestBytes)
+		} else {
+			output, execErr = skillMount.Skill.Execute(requestBytes)
+		}
 	}()
 
 	if execErr != nil {
@@ -644,12 +1694,23 @@ func (fs *ToolFS) executeSkillMount(skillMount *SkillMount, path, relPath, opera
 		return nil, fmt.Errorf("skill returned error: %s", response.Error)
 	}
 
-	// Extract result
-	if resultStr, ok := response.Result.(string); ok {
+	// Extract result according to the mount's response format
+	resultStr, isString := response.Result.(string)
+	switch skillMount.ResponseFormat {
+	case ResponseFormatRaw:
+		if !isString {
+			return nil, fmt.Errorf("skill '%s' returned a non-string result for a raw mount", skillMount.SkillName)
+		}
 		return []byte(resultStr), nil
+	case ResponseFormatJSON:
+		// Always marshal below
+	default:
+		if isString {
+			return []byte(resultStr), nil
+		}
 	}
 
-	// Try to marshal result to JSON if it's not a string
+	// Marshal the result to JSON
 	resultBytes, err := json.Marshal(response.Result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal skill result: %w", err)
@@ -658,12 +1719,43 @@ func (fs *ToolFS) executeSkillMount(skillMount *SkillMount, path, relPath, opera
 	return resultBytes, nil
 }
 
-// resolvePath resolves a ToolFS path to a local filesystem path
-// Optimized: uses result caching to avoid repeated resolution
+// resolvePath resolves a ToolFS path to a local filesystem path.
+// Local paths whose symlinks lead outside their mount are rejected unless the
+// mount follows symlinks; this is checked on every call since links can
+// change on disk.
 func (fs *ToolFS) resolvePath(path string) (string, *Mount, error) {
+	localPath, mount, err := fs.resolveMapped(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if !mount.FollowSymlinks && mountKind(mount) == "local" {
+		if err := confineToMount(localPath, mount.LocalPath); err != nil {
+			var escapeErr *PathEscapeError
+			if errors.As(err, &escapeErr) {
+				escapeErr.Path = normalizeVirtualPath(path)
+				return "", nil, escapeErr
+			}
+			return "", nil, fmt.Errorf("%w: %s", err, normalizeVirtualPath(path))
+		}
+	}
+	return localPath, mount, nil
+}
+
+// resolveMapped maps a ToolFS path to its mount and local path
+// Optimized: uses result caching to avoid repeated resolution
+func (fs *ToolFS) resolveMapped(path string) (string, *Mount, error) {
 	// Normalize the virtual path to use forward slashes
 	path = normalizeVirtualPath(path)
 
+	if fs.pathCacheDisabled {
+		return fs.resolveUncached(path)
+	}
+
+	// Fast path: the same path as the previous resolution
+	if last := fs.lastResolved.Load(); last != nil && last.path == path {
+		return last.localPath, last.mount, nil
+	}
+
 	// Try to get from cache first
 	// Note: Cache is invalidated when mounts change (MountLocal/UnmountSkillExecutor)
 	if cached, ok := fs.pathResolveCache.Load(path); ok {
@@ -675,16 +1767,42 @@ func (fs *ToolFS) resolvePath(path string) (string, *Mount, error) {
 
 		// Return cached result
 		// Cache is invalidated when mounts change, so this is safe
+		fs.lastResolved.Store(entry)
 		return localPath, mount, nil
 	}
 
 	// Not in cache, resolve the path
+	localPath, mount, err := fs.resolveUncached(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Cache the result (only for successful resolutions)
+	entry := &resolveCacheEntry{
+		path:      path,
+		localPath: localPath,
+		mount:     mount,
+	}
+	fs.pathResolveCache.Store(path, entry)
+	fs.lastResolved.Store(entry)
+
+	return localPath, mount, nil
+}
+
+// resolveUncached resolves a normalized path against the current mounts
+// without consulting or updating the resolution caches
+func (fs *ToolFS) resolveUncached(path string) (string, *Mount, error) {
 	var localPath string
 	var mount *Mount
 	var err error
 
-	// Check if this is a skill mount first (highest priority)
-	if skillMount, relPath := fs.isSkillMount(path); skillMount != nil {
+	// Skill documents are checked first so that a skill mounted below the
+	// skills directory keeps its SKILL.md
+	if fs.isSkillDocFile(path) {
+		localPath = ""
+		mount = &Mount{LocalPath: skillsVirtualMount, ReadOnly: true}
+	} else if skillMount, relPath := fs.isSkillMount(path); skillMount != nil {
+		// Check if this is a skill mount (highest priority after skill documents)
 		// Return special marker for skill mount
 		localPath = relPath
 		mount = &Mount{LocalPath: "__SKILL_MOUNT__:" + skillMount.SkillName, ReadOnly: skillMount.ReadOnly}
@@ -720,24 +1838,193 @@ func (fs *ToolFS) resolvePath(path string) (string, *Mount, error) {
 			}
 		}
 
+		for mountPoint, m := range fs.remoteMounts {
+			if isSubPath(path, mountPoint) && len(mountPoint) > len(bestMountPoint) {
+				bestMountPoint = mountPoint
+				bestMount = m
+				bestLocalPath = remoteKey(strings.TrimPrefix(path, mountPoint))
+			}
+		}
+
 		if bestMount == nil {
-			err = errors.New("path not found in any mount")
+			// Unmounted skill directories fall back to the skill documents
+			if fs.isSkillDocDir(path) {
+				return "", &Mount{LocalPath: skillsVirtualMount, ReadOnly: true}, nil
+			}
+			err = notFoundf("path not found in any mount")
 			return "", nil, err
 		}
 
+		// Joining cleans ".." segments, which must not climb out of the mount
+		if bestMount.remote != nil {
+			for _, segment := range strings.Split(bestLocalPath, "/") {
+				if segment == ".." {
+					return "", nil, &PathEscapeError{Path: path}
+				}
+			}
+		} else if rel, relErr := filepath.Rel(bestMount.LocalPath, bestLocalPath); relErr != nil ||
+			rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", nil, &PathEscapeError{Path: path}
+		}
+
 		localPath = bestLocalPath
 		mount = bestMount
 	}
 
-	// Cache the result (only for successful resolutions)
-	// Note: err is nil at this point if we reached here
-	entry := &resolveCacheEntry{
-		localPath: localPath,
-		mount:     mount,
+	return localPath, mount, nil
+}
+
+// SetPathCacheEnabled turns the path resolution cache on or off (it is on by
+// default). The cache makes repeated access to the same paths cheap, but each
+// mount change has to invalidate it; when mounts change on nearly every request
+// (e.g. per-tenant remapping) the cache is pure overhead, and disabling it
+// resolves every path from the current mounts. Path normalization is not
+// affected. Disabling the cache also clears it.
+func (fs *ToolFS) SetPathCacheEnabled(enabled bool) {
+	fs.pathCacheDisabled = !enabled
+	if !enabled {
+		fs.invalidateLastResolved()
+		fs.pathResolveCache.Range(func(key, value interface{}) bool {
+			fs.pathResolveCache.Delete(key)
+			return true
+		})
 	}
-	fs.pathResolveCache.Store(path, entry)
+}
 
-	return localPath, mount, nil
+// invalidateLastResolved clears the single-entry resolution cache
+func (fs *ToolFS) invalidateLastResolved() {
+	fs.lastResolved.Store(nil)
+}
+
+// runAudited runs a session-scoped operation and records exactly one audit
+// entry for it. Session expiry, global deny rules and the session's path
+// restrictions (write paths for write operations, read paths otherwise) are
+// checked before fn runs; deny rules also apply without a session.
+// fn reports the bytes it read and wrote; both are logged as zero on failure.
+func (fs *ToolFS) runAudited(session *Session, op, path string, fn func() (bytesRead, bytesWritten int64, err error)) error {
+	return fs.runAuditedContent(session, op, path, func() ([]byte, int64, int64, error) {
+		bytesRead, bytesWritten, err := fn()
+		return nil, bytesRead, bytesWritten, err
+	})
+}
+
+// runAuditedContent is runAudited for operations that also return the content
+// read or written, which is hashed into the audit entry when enabled
+func (fs *ToolFS) runAuditedContent(session *Session, op, path string, fn func() (content []byte, bytesRead, bytesWritten int64, err error)) error {
+	return fs.runAuditedAt(session, op, path, func() (string, []byte, int64, int64, error) {
+		content, bytesRead, bytesWritten, err := fn()
+		return path, content, bytesRead, bytesWritten, err
+	})
+}
+
+// runAuditedAt is runAuditedContent for operations that hooks may redirect:
+// fn also reports the path it operated on, which the audit entry records
+// along with the requested path when they differ
+func (fs *ToolFS) runAuditedAt(session *Session, op, path string, fn func() (at string, content []byte, bytesRead, bytesWritten int64, err error)) error {
+	if session == nil {
+		if err := fs.checkDenied(path); err != nil {
+			return err
+		}
+		at, _, _, _, err := fn()
+		fs.checkMountError(at, err)
+		return err
+	}
+
+	start := time.Now()
+	at := path
+	var content []byte
+	var bytesRead, bytesWritten int64
+	err := fs.authorize(session, op, path)
+	if err == nil {
+		at, content, bytesRead, bytesWritten, err = fn()
+		fs.checkMountError(at, err)
+	}
+	if err != nil {
+		content, bytesRead, bytesWritten = nil, 0, 0
+	}
+
+	var metadata map[string]interface{}
+	if at != path {
+		metadata = map[string]interface{}{"requested_path": path}
+	}
+	fs.auditOp(session, op, at, err, bytesRead, bytesWritten, start, content, metadata)
+	return err
+}
+
+// authorize checks that session may perform op on path: the session must not
+// have expired, path must not match a global deny rule, the session's path
+// restrictions must allow it and its quota must not be used up. Sessions that
+// are not expired are marked as accessed.
+func (fs *ToolFS) authorize(session *Session, op, path string) error {
+	if err := session.checkExpired(); err != nil {
+		// Expired sessions are not marked as accessed
+		return err
+	}
+	session.touch()
+	// Global deny rules take precedence over the session's allowed paths
+	if err := fs.checkDenied(path); err != nil {
+		return err
+	}
+	if err := session.checkAccess(path, isWriteOp(op)); err != nil {
+		return err
+	}
+	return session.checkQuota(op)
+}
+
+// runAuditedTarget runs the part of a two-path operation such as Copy or
+// Move that involves its second path, inside the runAudited call for the
+// first path, and records a separate audit entry for it. The second path is
+// checked against the deny rules and the session's read or write paths; the
+// operation counts once towards the session's usage and quota, through the
+// first path's entry, while the bytes fn reports are added here.
+func (fs *ToolFS) runAuditedTarget(session *Session, op, path string, fn func() (bytesRead, bytesWritten int64, err error)) error {
+	err := fs.checkDenied(path)
+	if err == nil && session != nil {
+		err = session.checkAccess(path, isWriteOp(op))
+	}
+	if err != nil && session == nil {
+		return err
+	}
+
+	start := time.Now()
+	var bytesRead, bytesWritten int64
+	if err == nil {
+		bytesRead, bytesWritten, err = fn()
+		fs.checkMountError(path, err)
+	}
+	if session == nil {
+		return err
+	}
+	if err != nil {
+		bytesRead, bytesWritten = 0, 0
+	}
+	session.addBytes(bytesRead, bytesWritten)
+	fs.recordOp(session, op, path, err, bytesRead, bytesWritten, start, nil, nil)
+	return err
+}
+
+// auditOp counts an operation that started at start towards the session's
+// usage and records its audit entry with optional metadata, subject to audit
+// sampling
+func (fs *ToolFS) auditOp(session *Session, op, path string, err error, bytesRead, bytesWritten int64, start time.Time, content []byte, metadata map[string]interface{}) {
+	session.addUsage(err, bytesRead, bytesWritten)
+	fs.recordOp(session, op, path, err, bytesRead, bytesWritten, start, content, metadata)
+}
+
+// recordOp records the audit entry of auditOp without counting the operation
+func (fs *ToolFS) recordOp(session *Session, op, path string, err error, bytesRead, bytesWritten int64, start time.Time, content []byte, metadata map[string]interface{}) {
+	// First use is tracked on every operation and always logged, so sampling
+	// can neither drop it nor move the marker to a later entry
+	firstUse := session.firstUseMetadata()
+	if firstUse != nil || fs.shouldAudit(op, err) {
+		for key, value := range firstUse {
+			if metadata == nil {
+				metadata = make(map[string]interface{}, len(firstUse))
+			}
+			metadata[key] = value
+		}
+		session.recordAudit(op, path, err == nil, err, bytesRead, bytesWritten, time.Since(start), fs.auditContentHash(op, content), metadata)
+	}
 }
 
 // ReadFile reads a file from the ToolFS
@@ -747,18 +2034,46 @@ func (fs *ToolFS) ReadFile(path string) ([]byte, error) {
 
 // ReadFileWithSession reads a file from the ToolFS with session-based access control
 func (fs *ToolFS) ReadFileWithSession(path string, session *Session) ([]byte, error) {
-	// Check access control
-	if session != nil && !session.IsPathAllowed(path) {
-		err := fmt.Errorf("access denied: path '%s' is not allowed for session '%s'", path, session.ID)
-		session.logAudit("ReadFile", path, false, err, 0, 0)
+	return fs.ReadFileContext(context.Background(), path, session)
+}
+
+// ReadFileContext reads a file like ReadFileWithSession, giving up when ctx
+// is done: skill mounts receive ctx if they implement ContextSkill and are
+// otherwise abandoned, HTTP mounts cancel their request, and the operation
+// returns an error wrapping ctx.Err()
+func (fs *ToolFS) ReadFileContext(ctx context.Context, path string, session *Session) ([]byte, error) {
+	end := fs.startSpan("ReadFile", path, session)
+	var result []byte
+	err := fs.runAuditedAt(session, "ReadFile", path, func() (string, []byte, int64, int64, error) {
+		hooked, at, err := fs.runHooked("ReadFile", path, session, func(path string) (interface{}, error) {
+			return fs.readFileContext(ctx, path, session)
+		})
+		var ok bool
+		if result, ok = hooked.([]byte); !ok && hooked != nil {
+			err = hookResultError("ReadFile", hooked)
+		}
+		return at, result, int64(len(result)), 0, err
+	})
+	end(err)
+	return result, err
+}
+
+// readFileWithSession implements ReadFileWithSession without tracing or auditing
+func (fs *ToolFS) readFileWithSession(path string, session *Session) ([]byte, error) {
+	return fs.readFileContext(context.Background(), path, session)
+}
+
+// readFileContext implements ReadFileContext without tracing or auditing
+func (fs *ToolFS) readFileContext(ctx context.Context, path string, session *Session) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	if normalizeVirtualPath(path) == fs.healthPath() {
+		return fs.readHealth()
+	}
 
 	localPath, mount, err := fs.resolvePath(path)
 	if err != nil {
-		if session != nil {
-			session.logAudit("ReadFile", path, false, err, 0, 0)
-		}
 		return nil, err
 	}
 
@@ -781,59 +2096,79 @@ func (fs *ToolFS) ReadFileWithSession(path string, session *Session) ([]byte, er
 
 		if skillMount != nil {
 			// Execute skill with error recovery
-			data, err = fs.executeSkillMount(skillMount, path, localPath, "read_file", nil, session)
+			data, err = fs.executeSkillMount(ctx, skillMount, path, localPath, "read_file", nil, session)
 			if err != nil {
 				// Return error but don't crash
 				return nil, err
 			}
 		} else {
-			return nil, fmt.Errorf("skill mount not found for path: %s", path)
+			return nil, notFoundf("skill mount not found for path: %s", path)
 		}
 	} else if mount.LocalPath == "__VIRTUAL_MEMORY__" {
 		data, err = fs.readMemory(path)
 	} else if mount.LocalPath == "__VIRTUAL_RAG__" {
 		data, err = fs.readRAG(path)
-	} else {
-		data, err = os.ReadFile(localPath)
-	}
-
-	// Log audit entry
-	if session != nil {
-		bytesRead := int64(0)
-		if err == nil {
-			bytesRead = int64(len(data))
+	} else if mount.LocalPath == skillsVirtualMount {
+		data, err = fs.readSkillDoc(path)
+	} else if mount.remote != nil {
+		data, err = mount.remote.get(ctx, localPath)
+	} else if hasTrailingSlash(path) {
+		// A trailing slash names a directory, which cannot be read as a file
+		if info, statErr := os.Stat(localPath); statErr == nil && !info.IsDir() {
+			err = fmt.Errorf("%w: %s", ErrNotDirectory, path)
+		} else {
+			err = fmt.Errorf("%w: %s", ErrIsDirectory, path)
 		}
-		session.logAudit("ReadFile", path, err == nil, err, bytesRead, 0)
+	} else if mount.lazy != nil {
+		if err = mount.lazy.materialize(localPath); err == nil {
+			data, err = fs.readLocalFile(localPath)
+		}
+	} else {
+		data, err = fs.readLocalFile(localPath)
 	}
 
 	return data, err
 }
 
-// readMemory reads a memory entry
+// readMemory reads a memory entry. The bare path returns the entry's content
+// as plain text; "?format=json" returns the whole MemoryEntry as JSON,
+// including its timestamps and metadata.
 // Optimized: uses pre-computed cached memoryPath
 func (fs *ToolFS) readMemory(path string) ([]byte, error) {
 	path = normalizeVirtualPath(path)
 	memoryPathWithSlash := fs.memoryPath + "/"
 
-	// Extract memory entry ID
-	relPath := strings.TrimPrefix(path, memoryPathWithSlash)
+	// Extract memory entry ID and the optional "?format=" parameter
+	relPath, rawQuery, _ := strings.Cut(strings.TrimPrefix(path, memoryPathWithSlash), "?")
 	parts := strings.Split(relPath, "/")
 	if len(parts) == 0 || parts[0] == "" {
 		// Listing memory directory - return empty for now
 		return nil, errors.New("cannot read memory directory directly, use ListDir")
 	}
 
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, errors.New("invalid memory read query format")
+	}
+	format := params.Get("format")
+	if format != "" && format != MemoryFormatJSON && format != MemoryFormatText {
+		return nil, fmt.Errorf("unsupported memory read format '%s', expected %s or %s", format, MemoryFormatText, MemoryFormatJSON)
+	}
+
 	entryID := parts[0]
 	entry, err := fs.memoryStore.Get(entryID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Return JSON representation for consistent API behavior
-	return json.Marshal(entry)
+	if format == MemoryFormatJSON {
+		return json.Marshal(entry)
+	}
+	return []byte(entry.Content), nil
 }
 
-// readRAG performs a RAG search
+// readRAG performs a RAG search. Queries accept text (or q), top_k,
+// highlight, min_score, filter.<key>=<value>, offset and limit parameters.
 // Optimized: uses pre-computed cached ragPath
 func (fs *ToolFS) readRAG(path string) ([]byte, error) {
 	path = normalizeVirtualPath(path)
@@ -868,7 +2203,17 @@ func (fs *ToolFS) readRAG(path string) ([]byte, error) {
 			query = decodedQuery
 		}
 
+		offset, limit, paged, err := parseRAGPage(queryURL)
+		if err != nil {
+			return nil, err
+		}
+
+		// top_k caps the results paged through; with a limit it defaults to
+		// the end of the requested page
 		topK := 5 // default
+		if paged && limit > 0 {
+			topK = offset + limit
+		}
 		if topKStr := queryURL.Get("top_k"); topKStr != "" {
 			var err error
 			topK, err = strconv.Atoi(topKStr)
@@ -877,21 +2222,56 @@ func (fs *ToolFS) readRAG(path string) ([]byte, error) {
 			}
 		}
 
-		results, err := fs.ragStore.Search(query, topK)
+		filter, err := parseRAGQueryFilter(queryURL)
+		if err != nil {
+			return nil, err
+		}
+
+		results, err := fs.ragStore.Search(query, fs.ragSearchLimit(topK, filter))
 		if err != nil {
 			return nil, err
 		}
+		results = topRAGResults(filter.Apply(results), topK)
+
+		total := len(results)
+		if paged {
+			sortRAGResults(results)
+			start, end := pageBounds(total, offset, limit)
+			results = results[start:end]
+		}
+
+		if highlight, _ := strconv.ParseBool(queryURL.Get("highlight")); highlight {
+			pre, post := queryURL.Get("highlight_pre"), queryURL.Get("highlight_post")
+			for i := range results {
+				results[i] = HighlightRAGResult(results[i], query, pre, post)
+			}
+		}
 
 		searchResults := RAGSearchResults{
 			Query:   query,
 			TopK:    topK,
 			Results: results,
 		}
+		if paged {
+			searchResults.Offset = offset
+			searchResults.Limit = limit
+			searchResults.Total = total
+		}
 
 		return json.Marshal(searchResults)
 	}
 
-	return nil, errors.New("invalid RAG path, use /toolfs/rag/query?text=...&top_k=...")
+	// Document listing: "documents" optionally followed by "?<params>"
+	if relPath == "documents" || strings.HasPrefix(relPath, "documents?") {
+		_, rawQuery, _ := strings.Cut(relPath, "?")
+		page, err := fs.listRAGDocuments(rawQuery)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(page)
+	}
+
+	return nil, errors.New("invalid RAG path, use /toolfs/rag/query?text=...&top_k=...[&highlight=true][&min_score=...][&filter.<key>=<value>][&offset=...&limit=...] or /toolfs/rag/documents?offset=...&limit=...")
 }
 
 // WriteFile writes data to a file in the ToolFS
@@ -901,21 +2281,72 @@ func (fs *ToolFS) WriteFile(path string, data []byte) error {
 
 // WriteFileWithSession writes data to a file in the ToolFS with session-based access control
 func (fs *ToolFS) WriteFileWithSession(path string, data []byte, session *Session) error {
-	// Check access control
-	if session != nil && !session.IsPathAllowed(path) {
-		err := fmt.Errorf("access denied: path '%s' is not allowed for session '%s'", path, session.ID)
-		session.logAudit("WriteFile", path, false, err, 0, 0)
-		return err
+	_, err := fs.WriteFileResult(path, data, session)
+	return err
+}
+
+// WriteResult describes the effect of a write
+type WriteResult struct {
+	Created      bool   // The file did not exist before the write
+	BytesWritten int64  // Number of bytes written
+	PreviousSize int64  // Size before the write (0 when created)
+	ID           string // Memory entry ID for memory writes, including IDs generated for writes to the memory directory
+}
+
+// WriteFileResult writes data like WriteFileWithSession and reports whether
+// the write created the file or replaced existing content. Existence is
+// determined before writing. Skill mounts cannot report existence, so writes
+// through them always report Created as false.
+func (fs *ToolFS) WriteFileResult(path string, data []byte, session *Session) (*WriteResult, error) {
+	return fs.writeFileResult(context.Background(), path, data, session)
+}
+
+// WriteFileContext writes data like WriteFileWithSession, giving up when ctx
+// is done like ReadFileContext. A write abandoned after it was handed to a
+// skill may still take effect.
+func (fs *ToolFS) WriteFileContext(ctx context.Context, path string, data []byte, session *Session) error {
+	_, err := fs.writeFileResult(ctx, path, data, session)
+	return err
+}
+
+// writeFileResult implements WriteFileResult and WriteFileContext
+func (fs *ToolFS) writeFileResult(ctx context.Context, path string, data []byte, session *Session) (*WriteResult, error) {
+	end := fs.startSpan("WriteFile", path, session)
+	var result *WriteResult
+	err := fs.runAuditedAt(session, "WriteFile", path, func() (string, []byte, int64, int64, error) {
+		hooked, at, err := fs.runHooked("WriteFile", path, session, func(path string) (interface{}, error) {
+			return fs.writeFileContext(ctx, path, data, session)
+		})
+		var ok bool
+		if result, ok = hooked.(*WriteResult); err == nil && (!ok || result == nil) {
+			err = hookResultError("WriteFile", hooked)
+		}
+		return at, data, 0, int64(len(data)), err
+	})
+	end(err)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
+// writeFileWithSession implements WriteFileResult without tracing or auditing
+func (fs *ToolFS) writeFileWithSession(path string, data []byte, session *Session) (*WriteResult, error) {
+	return fs.writeFileContext(context.Background(), path, data, session)
+}
+
+// writeFileContext implements WriteFileContext without tracing or auditing
+func (fs *ToolFS) writeFileContext(ctx context.Context, path string, data []byte, session *Session) (*WriteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	localPath, mount, err := fs.resolvePath(path)
 	if err != nil {
-		if session != nil {
-			session.logAudit("WriteFile", path, false, err, 0, 0)
-		}
-		return err
+		return nil, err
 	}
 
+	result := &WriteResult{BytesWritten: int64(len(data))}
+
 	// Handle skill mounts
 	if strings.HasPrefix(mount.LocalPath, "__SKILL_MOUNT__:") {
 		skillName := strings.TrimPrefix(mount.LocalPath, "__SKILL_MOUNT__:")
@@ -932,53 +2363,44 @@ func (fs *ToolFS) WriteFileWithSession(path string, data []byte, session *Sessio
 
 		if skillMount != nil {
 			if skillMount.ReadOnly {
-				err := errors.New("cannot write to read-only skill mount")
-				if session != nil {
-					session.logAudit("WriteFile", path, false, err, 0, 0)
-				}
-				return err
+				return nil, fmt.Errorf("%w: cannot write to read-only skill mount", ErrReadOnly)
 			}
 			// Execute skill for write_file operation
-			_, err = fs.executeSkillMount(skillMount, path, localPath, "write_file", data, session)
+			_, err = fs.executeSkillMount(ctx, skillMount, path, localPath, "write_file", data, session)
 			if err != nil {
 				// Return error but don't crash
-				if session != nil {
-					session.logAudit("WriteFile", path, false, err, 0, 0)
-				}
-				return err
+				return nil, err
 			}
 		} else {
-			err = fmt.Errorf("skill mount not found for path: %s", path)
+			err = notFoundf("skill mount not found for path: %s", path)
 		}
 	} else if mount.ReadOnly {
-		err := errors.New("cannot write to read-only mount")
-		if session != nil {
-			session.logAudit("WriteFile", path, false, err, 0, 0)
-		}
-		return err
+		return nil, fmt.Errorf("%w: cannot write to read-only mount", ErrReadOnly)
 	} else if mount.LocalPath == "__VIRTUAL_MEMORY__" {
-		err = fs.writeMemory(path, data)
+		result.Created, result.PreviousSize = fs.memoryEntryState(path)
+		result.ID, err = fs.writeMemory(path, data)
 	} else if mount.LocalPath == "__VIRTUAL_RAG__" {
-		err = errors.New("cannot write to RAG store")
+		err = fmt.Errorf("%w: cannot write to RAG store", ErrReadOnly)
+	} else if mount.remote != nil {
+		if info, statErr := mount.remote.stat(ctx, localPath); statErr == nil {
+			result.PreviousSize = info.Size
+		} else {
+			result.Created = true
+		}
+		err = mount.remote.put(ctx, localPath, data)
 	} else {
 		// Create parent directory if it doesn't exist
 		parentDir := filepath.Dir(localPath)
 		if err := os.MkdirAll(parentDir, 0o755); err != nil {
-			if session != nil {
-				session.logAudit("WriteFile", path, false, err, 0, 0)
-			}
-			return err
+			return nil, err
 		}
-		err = os.WriteFile(localPath, data, 0o644)
-	}
-
-	// Log audit entry
-	if session != nil {
-		bytesWritten := int64(0)
-		if err == nil {
-			bytesWritten = int64(len(data))
+		if info, statErr := os.Stat(localPath); statErr == nil {
+			result.PreviousSize = info.Size()
+		} else {
+			result.Created = true
 		}
-		session.logAudit("WriteFile", path, err == nil, err, 0, bytesWritten)
+		err = os.WriteFile(localPath, data, 0o644)
+		fs.invalidateReadCache(localPath)
 	}
 
 	// Track change for snapshot
@@ -987,38 +2409,305 @@ func (fs *ToolFS) WriteFileWithSession(path string, data []byte, session *Sessio
 		if session != nil {
 			sessionID = session.ID
 		}
-		// Optimization: Check file existence before write to avoid extra Stat call
-		// We can use os.Stat on localPath before writing to determine create vs modify
+		// Existence was checked before the write
 		operation := "write"
-		if localPath != "" && mount != nil && mount.LocalPath != "__VIRTUAL_MEMORY__" && mount.LocalPath != "__VIRTUAL_RAG__" {
-			// Check if file existed before write (optimize: only for local filesystem)
-			if _, statErr := os.Stat(localPath); statErr != nil {
-				operation = "create"
-			}
+		if result.Created {
+			operation = "create"
 		}
-		// Note: For virtual paths, we default to "write" since existence check
-		// would require calling the virtual store, which may be expensive
 		fs.TrackChange(path, operation, sessionID)
 	}
 
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteFile removes a file from the ToolFS
+func (fs *ToolFS) DeleteFile(path string) error {
+	return fs.DeleteFileWithSession(path, nil)
+}
+
+// DeleteFileWithSession removes a file with session-based access control.
+// Local files are removed with os.Remove (directories must be empty), memory
+// entries are deleted from the memory store and writable skill mounts receive
+// a "delete_file" request. Deletes through read-only mounts, including the RAG
+// store, fail with ErrReadOnly.
+func (fs *ToolFS) DeleteFileWithSession(path string, session *Session) error {
+	end := fs.startSpan("DeleteFile", path, session)
+	err := fs.runAuditedAt(session, "DeleteFile", path, func() (string, []byte, int64, int64, error) {
+		_, at, err := fs.runHooked("DeleteFile", path, session, func(path string) (interface{}, error) {
+			return nil, fs.deleteFile(path, session)
+		})
+		return at, nil, 0, 0, err
+	})
+	end(err)
 	return err
 }
 
+// deleteFile implements DeleteFileWithSession without tracing or auditing
+func (fs *ToolFS) deleteFile(path string, session *Session) error {
+	localPath, mount, err := fs.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if mount.ReadOnly {
+		return fmt.Errorf("%w: cannot delete '%s'", ErrReadOnly, path)
+	}
+
+	switch mountKind(mount) {
+	case "skill":
+		skillMount, _ := fs.isSkillMount(path)
+		if skillMount == nil {
+			return notFoundf("skill mount not found for path: %s", path)
+		}
+		_, err = fs.executeSkillMount(context.Background(), skillMount, path, localPath, "delete_file", nil, session)
+	case "memory":
+		var id string
+		if id, err = fs.memoryEntryID(path); err != nil {
+			return err
+		}
+		err = fs.memoryStore.Delete(id)
+	case "remote":
+		err = fmt.Errorf("cannot delete '%s': %s mounts do not support deletion", path, mount.remote.mountType())
+	default:
+		err = os.Remove(localPath)
+		fs.invalidateReadCache(localPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	fs.pathResolveCache.Delete(normalizeVirtualPath(path))
+	fs.invalidateLastResolved()
+
+	sessionID := ""
+	if session != nil {
+		sessionID = session.ID
+	}
+	fs.TrackChange(path, "delete", sessionID)
+	return nil
+}
+
+// Move renames src to dst. Files and directories can be moved within and
+// between writable local mounts (with os.Rename where possible), and memory
+// entries can be re-keyed within the memory directory. Files moved between
+// backends (e.g. memory to a local mount) or through skill mounts are copied
+// as by Copy and the source is deleted once the copy succeeds. Moves
+// involving read-only mounts fail with ErrReadOnly; moves of directories
+// between backends and moves out of the RAG store fail with ErrCrossDevice.
+// An existing file at dst is replaced.
+func (fs *ToolFS) Move(src, dst string) error {
+	return fs.MoveWithSession(src, dst, nil)
+}
+
+// MoveWithSession moves src to dst with session-based access control. The
+// session must be allowed to write both paths. Two audit entries are
+// recorded, "Move" for src and "MoveTo" for dst, but the move counts as one
+// operation towards the session's quota.
+func (fs *ToolFS) MoveWithSession(src, dst string, session *Session) error {
+	end := fs.startSpan("Move", src, session, "dst", dst)
+	err := fs.runAudited(session, "Move", src, func() (int64, int64, error) {
+		return 0, 0, fs.runAuditedTarget(session, "MoveTo", dst, func() (int64, int64, error) {
+			return 0, 0, fs.move(src, dst, session)
+		})
+	})
+	end(err)
+	return err
+}
+
+// move implements MoveWithSession without tracing or auditing
+func (fs *ToolFS) move(src, dst string, session *Session) error {
+	srcLocal, srcMount, err := fs.resolvePath(src)
+	if err != nil {
+		return err
+	}
+	dstLocal, dstMount, err := fs.resolvePath(dst)
+	if err != nil {
+		return err
+	}
+
+	srcKind, dstKind := mountKind(srcMount), mountKind(dstMount)
+	if srcMount.ReadOnly || dstMount.ReadOnly {
+		return fmt.Errorf("%w: cannot move '%s' to '%s'", ErrReadOnly, src, dst)
+	}
+	if srcKind == "rag" {
+		return fmt.Errorf("%w: cannot move '%s' to '%s'", ErrCrossDevice, src, dst)
+	}
+	if srcKind != dstKind || srcKind == "skill" || srcKind == "remote" {
+		return fs.moveByCopy(src, dst, session)
+	}
+
+	var created bool
+	if srcKind == "memory" {
+		created, err = fs.moveMemory(src, dst)
+	} else {
+		created, err = moveLocal(srcLocal, dstLocal)
+		fs.invalidateReadCache(srcLocal)
+		fs.invalidateReadCache(dstLocal)
+	}
+	if err != nil {
+		return err
+	}
+
+	sessionID := ""
+	if session != nil {
+		sessionID = session.ID
+	}
+	operation := "write"
+	if created {
+		operation = "create"
+	}
+	fs.TrackChange(src, "delete", sessionID)
+	fs.TrackChange(dst, operation, sessionID)
+	return nil
+}
+
+// moveByCopy moves a file between backends by copying it and then deleting
+// the source
+func (fs *ToolFS) moveByCopy(src, dst string, session *Session) error {
+	if info, err := fs.statWithSession(src, session); err == nil && info.IsDir {
+		return fmt.Errorf("%w: cannot move directory '%s' to '%s'", ErrCrossDevice, src, dst)
+	}
+	if _, err := fs.copyFile(src, dst, session); err != nil {
+		return err
+	}
+	return fs.deleteFile(src, session)
+}
+
+// mountKind classifies a resolved mount as "local", "remote", "memory", "rag",
+// "skills" (skill documents) or "skill"
+func mountKind(m *Mount) string {
+	switch {
+	case m.remote != nil:
+		return "remote"
+	case strings.HasPrefix(m.LocalPath, "__SKILL_MOUNT__:"):
+		return "skill"
+	case m.LocalPath == "__VIRTUAL_MEMORY__":
+		return "memory"
+	case m.LocalPath == "__VIRTUAL_RAG__":
+		return "rag"
+	case m.LocalPath == skillsVirtualMount:
+		return "skills"
+	default:
+		return "local"
+	}
+}
+
+// moveMemory re-keys a memory entry, keeping its content and metadata.
+// It reports whether dst did not exist before the move.
+func (fs *ToolFS) moveMemory(src, dst string) (bool, error) {
+	srcID, err := fs.memoryEntryID(src)
+	if err != nil {
+		return false, err
+	}
+	dstID, err := fs.memoryEntryID(dst)
+	if err != nil {
+		return false, err
+	}
+
+	entry, err := fs.memoryStore.Get(srcID)
+	if err != nil {
+		return false, err
+	}
+	if srcID == dstID {
+		return false, nil
+	}
+
+	created, _ := fs.memoryEntryState(dst)
+	if err := fs.memoryStore.Set(dstID, entry.Content, entry.Metadata); err != nil {
+		return false, err
+	}
+	if err := fs.memoryStore.Delete(srcID); err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// memoryEntryID extracts the entry ID from a memory path
+func (fs *ToolFS) memoryEntryID(path string) (string, error) {
+	memPath := strings.TrimSuffix(normalizeVirtualPath(path), "/")
+	relPath := strings.TrimPrefix(memPath, fs.memoryPath+"/")
+	if memPath == fs.memoryPath || relPath == "" || strings.Contains(relPath, "/") {
+		return "", errors.New("invalid memory path, expected /toolfs/memory/<id>")
+	}
+	return relPath, nil
+}
+
+// moveLocal renames a local file or directory, copying files when the
+// rename crosses filesystems. It reports whether dst did not exist before.
+func moveLocal(srcLocal, dstLocal string) (bool, error) {
+	info, err := os.Stat(srcLocal)
+	if err != nil {
+		return false, err
+	}
+	_, statErr := os.Stat(dstLocal)
+	created := statErr != nil
+
+	if err := os.MkdirAll(filepath.Dir(dstLocal), 0o755); err != nil {
+		return false, err
+	}
+	err = os.Rename(srcLocal, dstLocal)
+	if err != nil && errors.Is(err, syscall.EXDEV) && !info.IsDir() {
+		// Different mounts may live on different filesystems
+		var data []byte
+		if data, err = os.ReadFile(srcLocal); err == nil {
+			if err = os.WriteFile(dstLocal, data, info.Mode().Perm()); err == nil {
+				err = os.Remove(srcLocal)
+			}
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
 // writeMemory writes to a memory entry
 // Optimized: uses pre-computed cached memoryPath
-func (fs *ToolFS) writeMemory(path string, data []byte) error {
+func (fs *ToolFS) writeMemory(path string, data []byte) (string, error) {
 	path = normalizeVirtualPath(path)
 	memoryPathWithSlash := fs.memoryPath + "/"
 
 	// Extract memory entry ID
+	// Writing to the memory directory itself stores the entry under a new ID
+	if strings.TrimSuffix(path, "/") == fs.memoryPath {
+		content, metadata := parseMemoryData(data)
+		return fs.RememberMemory(content, metadata)
+	}
+
 	relPath := strings.TrimPrefix(path, memoryPathWithSlash)
 	parts := strings.Split(relPath, "/")
 	if len(parts) == 0 || parts[0] == "" {
-		return errors.New("invalid memory path, expected /toolfs/memory/<id>")
+		return "", errors.New("invalid memory path, expected /toolfs/memory/<id>")
 	}
 
 	entryID := parts[0]
+	content, metadata := parseMemoryData(data)
+	if err := fs.memoryStore.Set(entryID, content, metadata); err != nil {
+		return "", err
+	}
+	return entryID, nil
+}
 
+// memoryEntryState reports whether a write to a memory path creates a new
+// entry and the size of the entry it replaces
+func (fs *ToolFS) memoryEntryState(path string) (bool, int64) {
+	memPath := strings.TrimSuffix(normalizeVirtualPath(path), "/")
+	if memPath == fs.memoryPath {
+		return true, 0 // Writes to the directory always create a new entry
+	}
+	entryID := strings.SplitN(strings.TrimPrefix(memPath, fs.memoryPath+"/"), "/", 2)[0]
+	entry, err := fs.memoryStore.Get(entryID)
+	if err != nil {
+		return true, 0
+	}
+	return false, int64(len(entry.Content))
+}
+
+// parseMemoryData extracts content and metadata from data written to a memory
+// path. JSON in MemoryEntry form carries metadata; anything else is plain text.
+func parseMemoryData(data []byte) (string, map[string]interface{}) {
 	// Try to parse as JSON first (for metadata)
 	var entry MemoryEntry
 	if err := json.Unmarshal(data, &entry); err == nil {
@@ -1027,11 +2716,41 @@ func (fs *ToolFS) writeMemory(path string, data []byte) error {
 		if metadata == nil {
 			metadata = make(map[string]interface{})
 		}
-		return fs.memoryStore.Set(entryID, entry.Content, metadata)
+		return entry.Content, metadata
 	}
 
 	// Plain text content
-	return fs.memoryStore.Set(entryID, string(data), nil)
+	return string(data), nil
+}
+
+// RememberMemory stores a new memory entry under a generated unique ID and
+// returns the ID. Writing to the memory directory itself
+// (WriteFile("/toolfs/memory/", data)) does the same.
+func (fs *ToolFS) RememberMemory(content string, metadata map[string]interface{}) (string, error) {
+	if fs.memoryStore == nil {
+		return "", errors.New("memory store not available")
+	}
+
+	for attempt := 0; attempt < 8; attempt++ {
+		id := generateMemoryID()
+		if _, err := fs.memoryStore.Get(id); err == nil {
+			continue // Extremely unlikely collision, try again
+		}
+		if err := fs.memoryStore.Set(id, content, metadata); err != nil {
+			return "", err
+		}
+		return id, nil
+	}
+	return "", errors.New("failed to generate unique memory ID")
+}
+
+// generateMemoryID returns a time-ordered ID with a random suffix
+func generateMemoryID() string {
+	var suffix [4]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return fmt.Sprintf("mem-%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("mem-%d-%s", time.Now().UnixNano(), hex.EncodeToString(suffix[:]))
 }
 
 // ListDir lists the contents of a directory
@@ -1041,18 +2760,40 @@ func (fs *ToolFS) ListDir(path string) ([]string, error) {
 
 // ListDirWithSession lists the contents of a directory with session-based access control
 func (fs *ToolFS) ListDirWithSession(path string, session *Session) ([]string, error) {
-	// Check access control
-	if session != nil && !session.IsPathAllowed(path) {
-		err := fmt.Errorf("access denied: path '%s' is not allowed for session '%s'", path, session.ID)
-		session.logAudit("ListDir", path, false, err, 0, 0)
+	return fs.ListDirContext(context.Background(), path, session)
+}
+
+// ListDirContext lists a directory like ListDirWithSession, giving up when
+// ctx is done like ReadFileContext
+func (fs *ToolFS) ListDirContext(ctx context.Context, path string, session *Session) ([]string, error) {
+	end := fs.startSpan("ListDir", path, session)
+	var result []string
+	err := fs.runAuditedAt(session, "ListDir", path, func() (string, []byte, int64, int64, error) {
+		hooked, at, err := fs.runHooked("ListDir", path, session, func(path string) (interface{}, error) {
+			return fs.listDirContext(ctx, path, session)
+		})
+		var ok bool
+		if result, ok = hooked.([]string); !ok && hooked != nil {
+			err = hookResultError("ListDir", hooked)
+		}
+		return at, nil, 0, 0, err
+	})
+	end(err)
+	return result, err
+}
+
+// listDirWithSession implements ListDirWithSession without tracing or auditing
+func (fs *ToolFS) listDirWithSession(path string, session *Session) ([]string, error) {
+	return fs.listDirContext(context.Background(), path, session)
+}
+
+// listDirContext implements ListDirContext without tracing or auditing
+func (fs *ToolFS) listDirContext(ctx context.Context, path string, session *Session) ([]string, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-
 	localPath, mount, err := fs.resolvePath(path)
 	if err != nil {
-		if session != nil {
-			session.logAudit("ListDir", path, false, err, 0, 0)
-		}
 		return nil, err
 	}
 
@@ -1074,7 +2815,7 @@ func (fs *ToolFS) ListDirWithSession(path string, session *Session) ([]string, e
 
 		if skillMount != nil {
 			// Execute skill for list_dir operation
-			data, execErr := fs.executeSkillMount(skillMount, path, localPath, "list_dir", nil, session)
+			data, execErr := fs.executeSkillMount(ctx, skillMount, path, localPath, "list_dir", nil, session)
 			if execErr != nil {
 				err = execErr
 			} else {
@@ -1106,7 +2847,7 @@ func (fs *ToolFS) ListDirWithSession(path string, session *Session) ([]string, e
 				}
 			}
 		} else {
-			err = fmt.Errorf("skill mount not found for path: %s", path)
+			err = notFoundf("skill mount not found for path: %s", path)
 		}
 	} else if mount.LocalPath == "__VIRTUAL_MEMORY__" {
 		path = normalizeVirtualPath(path)
@@ -1117,8 +2858,18 @@ func (fs *ToolFS) ListDirWithSession(path string, session *Session) ([]string, e
 			err = errors.New("cannot list RAG directory")
 		}
 	} else if mount.LocalPath == "__VIRTUAL_RAG__" {
-		// RAG is read-only and doesn't support listing
+		// RAG is read-only; it exposes the query file and, when the store
+		// supports browsing, the document listing
 		entries = []string{"query"}
+		if _, ok := fs.ragStore.(RAGDocumentLister); ok {
+			entries = append(entries, "documents")
+		}
+	} else if mount.LocalPath == skillsVirtualMount {
+		entries, err = fs.listSkillDocs(path)
+	} else if mount.remote != nil {
+		entries, err = mount.remote.list(ctx, localPath)
+	} else if lazyEntries, ok, listErr := mount.lazy.list(localPath); ok {
+		entries, err = lazyEntries, listErr
 	} else {
 		dirEntries, readErr := os.ReadDir(localPath)
 		if readErr != nil {
@@ -1126,16 +2877,14 @@ func (fs *ToolFS) ListDirWithSession(path string, session *Session) ([]string, e
 		} else {
 			entries = make([]string, 0, len(dirEntries))
 			for _, entry := range dirEntries {
+				if mount.lazy != nil && entry.Name() == lazyManifestName {
+					continue
+				}
 				entries = append(entries, entry.Name())
 			}
 		}
 	}
 
-	// Log audit entry
-	if session != nil {
-		session.logAudit("ListDir", path, err == nil, err, 0, 0)
-	}
-
 	return entries, err
 }
 
@@ -1146,21 +2895,46 @@ func (fs *ToolFS) Stat(path string) (*FileInfo, error) {
 
 // StatWithSession returns file metadata for the given path with session-based access control
 func (fs *ToolFS) StatWithSession(path string, session *Session) (*FileInfo, error) {
-	// Check access control
-	if session != nil && !session.IsPathAllowed(path) {
-		err := fmt.Errorf("access denied: path '%s' is not allowed for session '%s'", path, session.ID)
-		session.logAudit("Stat", path, false, err, 0, 0)
+	return fs.StatContext(context.Background(), path, session)
+}
+
+// StatContext returns file metadata like StatWithSession, giving up when
+// ctx is done like ReadFileContext
+func (fs *ToolFS) StatContext(ctx context.Context, path string, session *Session) (*FileInfo, error) {
+	end := fs.startSpan("Stat", path, session)
+	var result *FileInfo
+	err := fs.runAuditedAt(session, "Stat", path, func() (string, []byte, int64, int64, error) {
+		hooked, at, err := fs.runHooked("Stat", path, session, func(path string) (interface{}, error) {
+			return fs.statContext(ctx, path, session)
+		})
+		var ok bool
+		if result, ok = hooked.(*FileInfo); err == nil && (!ok || result == nil) {
+			err = hookResultError("Stat", hooked)
+		}
+		return at, nil, 0, 0, err
+	})
+	end(err)
+	return result, err
+}
+
+// statWithSession implements StatWithSession without tracing or auditing
+func (fs *ToolFS) statWithSession(path string, session *Session) (*FileInfo, error) {
+	return fs.statContext(context.Background(), path, session)
+}
+
+// statContext implements StatContext without tracing or auditing
+func (fs *ToolFS) statContext(ctx context.Context, path string, session *Session) (*FileInfo, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-
 	localPath, mount, err := fs.resolvePath(path)
 	if err != nil {
-		if session != nil {
-			session.logAudit("Stat", path, false, err, 0, 0)
-		}
 		return nil, err
 	}
 
+	backedBy := fs.mountInfoForPath(path, mount)
+	wantDir := hasTrailingSlash(path)
+
 	// Handle virtual paths (memory, rag, skills)
 	if mount != nil {
 		if mount.LocalPath == "__VIRTUAL_MEMORY__" {
@@ -1169,7 +2943,7 @@ func (fs *ToolFS) StatWithSession(path string, session *Session) (*FileInfo, err
 			// Optimized: uses pre-computed cached memoryPath
 			if path == fs.memoryPath {
 				// Root memory directory
-				return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true}, nil
+				return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true, BackedBy: backedBy}, nil
 			}
 			// Check if entry exists
 			relPath := strings.TrimPrefix(path, fs.memoryPath+"/")
@@ -1179,49 +2953,74 @@ func (fs *ToolFS) StatWithSession(path string, session *Session) (*FileInfo, err
 				if err != nil {
 					return nil, err
 				}
+				if wantDir {
+					return nil, fmt.Errorf("%w: %s", ErrNotDirectory, path)
+				}
 				// Return actual content size (plain text, not JSON)
 				contentSize := int64(len(entry.Content))
-				return &FileInfo{Size: contentSize, ModTime: entry.UpdatedAt, IsDir: false}, nil
+				return &FileInfo{Size: contentSize, ModTime: entry.UpdatedAt, IsDir: false, BackedBy: backedBy}, nil
 			}
-			return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true}, nil
+			return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true, BackedBy: backedBy}, nil
 		}
 		if mount.LocalPath == "__VIRTUAL_RAG__" {
 			// RAG is always a directory at root, query is a virtual file
 			path = normalizeVirtualPath(path)
 			// Optimized: uses pre-computed cached ragPath
 			if path == fs.ragPath {
-				return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true}, nil
+				return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true, BackedBy: backedBy}, nil
+			}
+			// Query and document listing files are virtual
+			if strings.HasPrefix(path, fs.ragPath+"/query") || strings.HasPrefix(path, fs.ragPath+"/documents") {
+				if wantDir {
+					return nil, fmt.Errorf("%w: %s", ErrNotDirectory, path)
+				}
+				return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: false, BackedBy: backedBy}, nil
+			}
+			return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true, BackedBy: backedBy}, nil
+		}
+		if mount.LocalPath == skillsVirtualMount {
+			return fs.statSkillDoc(path, backedBy, wantDir)
+		}
+		if mount.remote != nil {
+			info, err := mount.remote.stat(ctx, localPath)
+			if err != nil {
+				return nil, err
 			}
-			// Query files are virtual
-			if strings.HasPrefix(path, fs.ragPath+"/query") {
-				return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: false}, nil
+			if wantDir && !info.IsDir {
+				return nil, fmt.Errorf("%w: %s", ErrNotDirectory, path)
 			}
-			return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true}, nil
+			info.BackedBy = backedBy
+			return info, nil
 		}
 		if strings.HasPrefix(mount.LocalPath, "__SKILL_MOUNT__:") {
 			// Skill mounts - treat as directory for now
 			// In a real implementation, skills should provide stat info
-			return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true}, nil
+			return &FileInfo{Size: 0, ModTime: time.Now(), IsDir: true, BackedBy: backedBy}, nil
+		}
+	}
+
+	if mount != nil && mount.lazy != nil {
+		if _, statErr := os.Stat(localPath); statErr != nil {
+			if err := mount.lazy.materialize(localPath); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	info, err := os.Stat(localPath)
 	if err != nil {
-		if session != nil {
-			session.logAudit("Stat", path, false, err, 0, 0)
-		}
 		return nil, err
 	}
 
-	result := &FileInfo{
-		Size:    info.Size(),
-		ModTime: info.ModTime(),
-		IsDir:   info.IsDir(),
+	if wantDir && !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, path)
 	}
 
-	// Log audit entry
-	if session != nil {
-		session.logAudit("Stat", path, true, nil, 0, 0)
+	result := &FileInfo{
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		IsDir:    info.IsDir(),
+		BackedBy: backedBy,
 	}
 
 	return result, nil
@@ -1232,8 +3031,9 @@ func (fs *ToolFS) StatWithSession(path string, session *Session) (*FileInfo, err
 type InMemoryStore struct {
 	mu             sync.RWMutex // Protects entries map
 	entries        map[string]*MemoryEntry
-	listCache      []string // Cache for List() results
-	listCacheValid bool     // Whether listCache is still valid
+	listCache      []string     // Cache for List() results
+	listCacheValid bool         // Whether listCache is still valid
+	dedup          *memoryDedup // Content index, set by EnableDedup
 }
 
 // NewInMemoryStore creates a new in-memory memory store
@@ -1253,7 +3053,7 @@ func (s *InMemoryStore) Get(id string) (*MemoryEntry, error) {
 	s.mu.RUnlock()
 
 	if !exists {
-		return nil, errors.New("memory entry not found")
+		return nil, notFoundf("memory entry not found")
 	}
 	return entry, nil
 }
@@ -1264,6 +3064,9 @@ func (s *InMemoryStore) Set(id string, content string, metadata map[string]inter
 	now := time.Now()
 	s.mu.Lock()
 	entry, exists := s.entries[id]
+	if s.dedup != nil {
+		content = s.dedup.intern(id, content)
+	}
 
 	if exists {
 		// Update existing entry
@@ -1326,9 +3129,33 @@ func (s *InMemoryStore) List() ([]string, error) {
 	return ids, nil
 }
 
-// InMemoryRAGStore is a simple in-memory implementation of RAGStore
+// Delete removes a memory entry
+func (s *InMemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entries[id]; !exists {
+		return notFoundf("memory entry not found")
+	}
+	delete(s.entries, id)
+	if s.dedup != nil {
+		s.dedup.release(id)
+	}
+	s.listCacheValid = false
+	return nil
+}
+
+// InMemoryRAGStore is a simple in-memory implementation of RAGStore.
+// Search scores documents by keyword overlap unless an embedding function is
+// set with SetEmbeddingFunc.
 type InMemoryRAGStore struct {
-	documents []RAGDocument
+	mu         sync.RWMutex
+	documents  []RAGDocument
+	embed      EmbeddingFunc
+	embeddings [][]float32 // Document embeddings by index (set with embed)
+
+	hybrid       bool    // Set by SetHybridWeight
+	hybridWeight float64 // Weight of the vector score in hybrid scoring
 }
 
 // RAGDocument represents a document in the RAG store
@@ -1354,24 +3181,24 @@ func NewInMemoryRAGStore() *InMemoryRAGStore {
 
 // Search performs a simple keyword-based search (simulating semantic search)
 func (s *InMemoryRAGStore) Search(query string, topK int) ([]RAGResult, error) {
-	queryLower := strings.ToLower(query)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.embed != nil {
+		if s.hybrid {
+			return s.searchHybrid(query, topK)
+		}
+		return s.searchEmbeddings(query, topK)
+	}
+
+	queryWords := strings.Fields(strings.ToLower(query))
 	var results []RAGResult
 
 	for _, doc := range s.documents {
 		// Simple keyword matching (in a real implementation, this would be semantic)
-		contentLower := strings.ToLower(doc.Content)
-		score := 0.0
-
-		queryWords := strings.Fields(queryLower)
-		for _, word := range queryWords {
-			if strings.Contains(contentLower, word) {
-				score += 1.0
-			}
-		}
+		score := keywordScore(queryWords, doc.Content)
 
 		if score > 0 {
-			// Normalize score (simple heuristic)
-			score = score / float64(len(queryWords))
 			results = append(results, RAGResult{
 				ID:       doc.ID,
 				Content:  doc.Content,
@@ -1402,8 +3229,31 @@ func (s *InMemoryRAGStore) Search(query string, topK int) ([]RAGResult, error) {
 	return results, nil
 }
 
+// keywordScore returns the fraction of the lower-cased query words that
+// occur in content
+func keywordScore(queryWords []string, content string) float64 {
+	if len(queryWords) == 0 {
+		return 0
+	}
+	contentLower := strings.ToLower(content)
+	score := 0.0
+	for _, word := range queryWords {
+		if strings.Contains(contentLower, word) {
+			score += 1.0
+		}
+	}
+	// Normalize score (simple heuristic)
+	return score / float64(len(queryWords))
+}
+
 // CreateSnapshot creates a snapshot of the current filesystem state
 func (fs *ToolFS) CreateSnapshot(name string) error {
+	return fs.CreateSnapshotWithOptions(name, SnapshotOptions{})
+}
+
+// CreateSnapshotWithOptions creates a snapshot of the current filesystem
+// state, optionally including the memory store
+func (fs *ToolFS) CreateSnapshotWithOptions(name string, opts SnapshotOptions) error {
 	if name == "" {
 		return errors.New("snapshot name cannot be empty")
 	}
@@ -1412,6 +3262,11 @@ func (fs *ToolFS) CreateSnapshot(name string) error {
 		return fmt.Errorf("snapshot '%s' already exists", name)
 	}
 
+	scope, roots, err := fs.snapshotRoots(opts.Paths)
+	if err != nil {
+		return err
+	}
+
 	// If sandbox backend is available, use it
 	if fs.sandboxBackend != nil {
 		if err := fs.sandboxBackend.CreateSnapshot(name); err != nil {
@@ -1426,6 +3281,7 @@ func (fs *ToolFS) CreateSnapshot(name string) error {
 		},
 		Files:   make(map[string]*FileSnapshot),
 		Changes: []ChangeRecord{},
+		Scope:   scope,
 	}
 
 	// Use copy-on-write: reference base snapshot if one exists
@@ -1439,30 +3295,40 @@ func (fs *ToolFS) CreateSnapshot(name string) error {
 	}
 
 	// Snapshot all mounted filesystems (copy-on-write)
-	var totalSize int64
-	fileCount := 0
-
-	for mountPoint, mount := range fs.mounts {
-		if mount.ReadOnly {
-			continue // Skip read-only mounts for snapshots
+	if roots == nil {
+		roots = make(map[string]string, len(fs.mounts))
+		for mountPoint, mount := range fs.mounts {
+			if !mount.ReadOnly { // Skip read-only mounts for snapshots
+				roots[mountPoint] = mount.LocalPath
+			}
 		}
-
-		err := fs.snapshotDirectory(mountPoint, mount.LocalPath, snapshot)
+	}
+	for virtualPath, localPath := range roots {
+		if len(scope) > 0 {
+			// A scoped path that does not exist has nothing to capture;
+			// files of the base chain below it are recorded as deleted
+			if _, err := os.Lstat(localPath); os.IsNotExist(err) {
+				continue
+			}
+		}
+		err := fs.snapshotDirectory(virtualPath, localPath, snapshot)
 		if err != nil {
-			return fmt.Errorf("failed to snapshot directory %s: %w", mountPoint, err)
+			return fmt.Errorf("failed to snapshot directory %s: %w", virtualPath, err)
 		}
 	}
+	fs.snapshotDeletions(snapshot)
 
-	// Count files and calculate size
-	for _, fileSnap := range snapshot.Files {
-		if !fileSnap.IsDir {
-			fileCount++
-			totalSize += fileSnap.Size
+	if opts.IncludeMemory {
+		memory, err := fs.snapshotMemory()
+		if err != nil {
+			return fmt.Errorf("failed to snapshot memory: %w", err)
 		}
+		snapshot.Memory = memory
+		snapshot.IncludesMemory = true
 	}
 
-	snapshot.Metadata.FileCount = fileCount
-	snapshot.Metadata.Size = totalSize
+	// Count files and calculate size
+	snapshot.updateMetadata()
 
 	fs.snapshots[name] = snapshot
 	fs.currentSnapshot = name
@@ -1470,6 +3336,77 @@ func (fs *ToolFS) CreateSnapshot(name string) error {
 	return nil
 }
 
+// snapshotMemory copies every entry of the memory store, sorted by ID
+func (fs *ToolFS) snapshotMemory() ([]MemoryEntry, error) {
+	ids, err := fs.memoryStore.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+
+	memory := make([]MemoryEntry, 0, len(ids))
+	for _, id := range ids {
+		entry, err := fs.memoryStore.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		memory = append(memory, *entry)
+	}
+	return memory, nil
+}
+
+// restoreMemory makes the memory store hold exactly the entries in memory.
+// Restored entries keep their content and metadata; timestamps are set by
+// the store.
+func (fs *ToolFS) restoreMemory(memory []MemoryEntry) error {
+	keep := make(map[string]bool, len(memory))
+	for _, entry := range memory {
+		keep[entry.ID] = true
+		if err := fs.memoryStore.Set(entry.ID, entry.Content, entry.Metadata); err != nil {
+			return fmt.Errorf("failed to restore memory entry '%s': %w", entry.ID, err)
+		}
+	}
+
+	ids, err := fs.memoryStore.List()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !keep[id] {
+			if err := fs.memoryStore.Delete(id); err != nil {
+				return fmt.Errorf("failed to remove memory entry '%s': %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// snapshotDeletions records files of the base snapshot chain that no longer
+// exist on disk as deleted, so they drop out of the snapshot's file set
+func (fs *ToolFS) snapshotDeletions(snapshot *Snapshot) {
+	base, exists := fs.snapshots[snapshot.BaseSnapshot]
+	if !exists {
+		return
+	}
+	for virtualPath, fileSnap := range fs.snapshotFiles(base) {
+		if _, captured := snapshot.Files[virtualPath]; captured || !snapshot.inScope(virtualPath) {
+			continue
+		}
+		mountPoint, mount := fs.localMountFor(virtualPath)
+		if mount == nil || mount.ReadOnly {
+			continue
+		}
+		localPath := filepath.Join(mount.LocalPath, filepath.FromSlash(strings.TrimPrefix(virtualPath, mountPoint)))
+		if _, err := os.Lstat(localPath); os.IsNotExist(err) {
+			snapshot.Files[virtualPath] = &FileSnapshot{
+				Path:      virtualPath,
+				IsDir:     fileSnap.IsDir,
+				Operation: "deleted",
+			}
+		}
+	}
+}
+
 // snapshotDirectory recursively snapshots a directory (copy-on-write)
 func (fs *ToolFS) snapshotDirectory(mountPoint, localPath string, snapshot *Snapshot) error {
 	return filepath.Walk(localPath, func(path string, info os.FileInfo, err error) error {
@@ -1483,7 +3420,10 @@ func (fs *ToolFS) snapshotDirectory(mountPoint, localPath string, snapshot *Snap
 			return err
 		}
 
-		virtualPath := normalizeVirtualPath(mountPoint + "/" + relPath)
+		virtualPath := mountPoint
+		if relPath != "." {
+			virtualPath = normalizeVirtualPath(mountPoint + "/" + relPath)
+		}
 
 		// Check if file exists in base snapshot chain and if it was modified
 		existsInBase := false
@@ -1511,8 +3451,9 @@ func (fs *ToolFS) snapshotDirectory(mountPoint, localPath string, snapshot *Snap
 								currentContent, readErr := os.ReadFile(path)
 								if readErr == nil {
 									// If base snapshot has content, compare it
-									if len(baseFileSnap.Content) > 0 {
-										if string(currentContent) != string(baseFileSnap.Content) {
+									baseContent, baseErr := baseFileSnap.data()
+									if baseErr == nil && len(baseContent) > 0 {
+										if string(currentContent) != string(baseContent) {
 											modified = true
 										} else if timeModified || sizeModified {
 											// Content is same but time/size differ - file was rewritten but content is same
@@ -1550,13 +3491,16 @@ func (fs *ToolFS) snapshotDirectory(mountPoint, localPath string, snapshot *Snap
 						return err
 					}
 				}
+				content, compressed := fs.snapshotContent(content)
 				fileSnap := &FileSnapshot{
-					Path:      virtualPath,
-					Size:      info.Size(),
-					ModTime:   info.ModTime(),
-					IsDir:     info.IsDir(),
-					Operation: "modified",
-					Content:   content,
+					Path:       virtualPath,
+					Size:       info.Size(),
+					ModTime:    info.ModTime(),
+					IsDir:      info.IsDir(),
+					Mode:       info.Mode().Perm(),
+					Operation:  "modified",
+					Content:    content,
+					Compressed: compressed,
 				}
 				snapshot.Files[virtualPath] = fileSnap
 				return nil
@@ -1581,13 +3525,16 @@ func (fs *ToolFS) snapshotDirectory(mountPoint, localPath string, snapshot *Snap
 					return err
 				}
 			}
+			content, compressed := fs.snapshotContent(content)
 			fileSnap := &FileSnapshot{
-				Path:      virtualPath,
-				Size:      info.Size(),
-				ModTime:   info.ModTime(),
-				IsDir:     info.IsDir(),
-				Operation: "created",
-				Content:   content,
+				Path:       virtualPath,
+				Size:       info.Size(),
+				ModTime:    info.ModTime(),
+				IsDir:      info.IsDir(),
+				Mode:       info.Mode().Perm(),
+				Operation:  "created",
+				Content:    content,
+				Compressed: compressed,
 			}
 			snapshot.Files[virtualPath] = fileSnap
 		}
@@ -1596,11 +3543,12 @@ func (fs *ToolFS) snapshotDirectory(mountPoint, localPath string, snapshot *Snap
 	})
 }
 
-// RollbackSnapshot restores the filesystem to a previous snapshot state
+// RollbackSnapshot restores the filesystem to a previous snapshot state. For
+// snapshots that include memory, the memory store is restored as well.
 func (fs *ToolFS) RollbackSnapshot(name string) error {
 	snapshot, exists := fs.snapshots[name]
 	if !exists {
-		return fmt.Errorf("snapshot '%s' does not exist", name)
+		return notFoundf("snapshot '%s' does not exist", name)
 	}
 
 	// If sandbox backend is available, use it
@@ -1614,37 +3562,13 @@ func (fs *ToolFS) RollbackSnapshot(name string) error {
 	// Important: We're restoring to this snapshot, so this becomes the current snapshot
 	// but we don't update the snapshot's content - it's immutable
 	// Build complete file list from snapshot and its base chain
-	filesToRestore := make(map[string]*FileSnapshot)
-
-	// Recursively collect files from snapshot and its base chain
-	// IMPORTANT: We must recurse to base first, then add current snapshot files
-	// This ensures current snapshot files override base snapshot files
-	var collectFiles func(snap *Snapshot)
-	collectFiles = func(snap *Snapshot) {
-		// First, recurse to base snapshot if exists (collect base files first)
-		if snap.BaseSnapshot != "" {
-			if baseSnap, exists := fs.snapshots[snap.BaseSnapshot]; exists {
-				collectFiles(baseSnap)
-			}
-		}
-
-		// Then add all files from this snapshot (overwrites base files if modified)
-		// This ensures current snapshot takes precedence over base snapshots
-		for path, fileSnap := range snap.Files {
-			// Skip deleted files - don't restore them
-			if fileSnap.Operation != "deleted" {
-				filesToRestore[path] = fileSnap
-			}
-		}
-	}
-
-	// Collect all files, with current snapshot taking precedence
-	collectFiles(snapshot)
+	filesToRestore := fs.snapshotFiles(snapshot)
 
-	// Restore each file
+	// Resolve every file to restore to its local path
+	var restores []restoreItem
 	for virtualPath, fileSnap := range filesToRestore {
-		if fileSnap.IsDir {
-			continue // Skip directories
+		if fileSnap.IsDir || !snapshot.inScope(virtualPath) {
+			continue // Skip directories and files outside a scoped snapshot
 		}
 
 		// Resolve to local path
@@ -1670,49 +3594,264 @@ func (fs *ToolFS) RollbackSnapshot(name string) error {
 			localPath = filepath.Join(mount.LocalPath, relPath)
 		}
 
-		// Create parent directory if needed
-		parentDir := filepath.Dir(localPath)
-		if err := os.MkdirAll(parentDir, 0o755); err != nil {
-			return fmt.Errorf("failed to create parent directory: %w", err)
-		}
-
-		// Write file content
-		if err := os.WriteFile(localPath, fileSnap.Content, 0o644); err != nil {
-			return fmt.Errorf("failed to restore file %s: %w", virtualPath, err)
-		}
-
-		// Restore modification time if possible
-		os.Chtimes(localPath, fileSnap.ModTime, fileSnap.ModTime)
+		restores = append(restores, restoreItem{
+			virtualPath: virtualPath,
+			localPath:   localPath,
+			snap:        fileSnap,
+		})
 	}
 
 	// Handle deleted files (compare with current state)
+	var deletions []restoreItem
 	if fs.currentSnapshot != "" {
 		currentSnap, exists := fs.snapshots[fs.currentSnapshot]
 		if exists {
 			// Find files that exist in current but not in target snapshot
 			for path := range currentSnap.Files {
-				if _, exists := snapshot.Files[path]; !exists {
+				if _, exists := snapshot.Files[path]; !exists && snapshot.inScope(path) {
 					// File should be deleted
 					localPath, _, err := fs.resolvePath(path)
 					if err == nil {
-						os.Remove(localPath)
+						deletions = append(deletions, restoreItem{virtualPath: path, localPath: localPath})
 					}
 				}
 			}
 		}
 	}
 
+	// Apply all changes atomically: either every file is restored or none is
+	err := fs.applyRestore(restores, deletions)
+	fs.invalidateReadCache("")
+	if err != nil {
+		return err
+	}
+	if snapshot.IncludesMemory {
+		if err := fs.restoreMemory(snapshot.Memory); err != nil {
+			return err
+		}
+	}
+
 	// Record rollback operation (but don't modify the snapshot's content)
 	fs.currentSnapshot = name
 
 	return nil
 }
 
+// restoreItem is a single file change applied during a rollback
+type restoreItem struct {
+	virtualPath string
+	localPath   string
+	snap        *FileSnapshot // nil for deletions
+	tempPath    string        // Staged content, renamed over localPath on commit
+	preImage    []byte        // Content of localPath before the rollback
+	existed     bool          // Whether localPath existed before the rollback
+	preMode     os.FileMode
+}
+
+// restoreFileOps abstracts the file operations used by rollback so that
+// failures can be injected in tests
+type restoreFileOps interface {
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	ReadFile(name string) ([]byte, error)
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+	Chmod(name string, mode os.FileMode) error
+}
+
+// osRestoreFileOps implements restoreFileOps on top of the os package
+type osRestoreFileOps struct{}
+
+func (osRestoreFileOps) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (osRestoreFileOps) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+func (osRestoreFileOps) Rename(oldpath, newpath string) error { return os.Rename(oldpath, newpath) }
+
+func (osRestoreFileOps) Remove(name string) error { return os.Remove(name) }
+
+func (osRestoreFileOps) Chmod(name string, mode os.FileMode) error { return os.Chmod(name, mode) }
+
+// applyRestore stages restored content in temp files, captures pre-images of
+// the files it is about to replace or delete, and then commits everything with
+// renames. If any step fails, already committed changes are undone from the
+// pre-images so the filesystem is left as it was before the rollback.
+func (fs *ToolFS) applyRestore(restores, deletions []restoreItem) error {
+	ops := fs.restoreOps
+	if ops == nil {
+		ops = osRestoreFileOps{}
+	}
+
+	// Apply in a stable order so failures are reproducible
+	sort.Slice(restores, func(i, j int) bool { return restores[i].virtualPath < restores[j].virtualPath })
+	sort.Slice(deletions, func(i, j int) bool { return deletions[i].virtualPath < deletions[j].virtualPath })
+
+	cleanupTemps := func() {
+		for i := range restores {
+			if restores[i].tempPath != "" {
+				ops.Remove(restores[i].tempPath)
+				restores[i].tempPath = ""
+			}
+		}
+	}
+
+	// Stage: write every restored file next to its destination
+	for i := range restores {
+		item := &restores[i]
+		parentDir := filepath.Dir(item.localPath)
+		if err := os.MkdirAll(parentDir, 0o755); err != nil {
+			cleanupTemps()
+			return fmt.Errorf("failed to create parent directory: %w", err)
+		}
+
+		content, err := item.snap.data()
+		if err != nil {
+			cleanupTemps()
+			return err
+		}
+		tempPath := filepath.Join(parentDir, fmt.Sprintf(".%s.toolfs-restore-%d", filepath.Base(item.localPath), time.Now().UnixNano()))
+		mode := restoreMode(item)
+		if err := ops.WriteFile(tempPath, content, mode); err != nil {
+			ops.Remove(tempPath)
+			cleanupTemps()
+			return fmt.Errorf("failed to stage file %s: %w", item.virtualPath, err)
+		}
+		item.tempPath = tempPath
+		// The staged file is renamed over the target, so it must carry the
+		// target's mode; WriteFile's mode is subject to the umask
+		if err := ops.Chmod(tempPath, mode); err != nil {
+			cleanupTemps()
+			return fmt.Errorf("failed to stage file %s: %w", item.virtualPath, err)
+		}
+	}
+
+	// Capture pre-images so committed changes can be undone
+	capture := func(item *restoreItem) error {
+		info, err := os.Stat(item.localPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil // Directories are left in place
+		}
+		content, err := ops.ReadFile(item.localPath)
+		if err != nil {
+			return err
+		}
+		item.existed = true
+		item.preImage = content
+		item.preMode = info.Mode().Perm()
+		return nil
+	}
+	for i := range restores {
+		if err := capture(&restores[i]); err != nil {
+			cleanupTemps()
+			return fmt.Errorf("failed to capture pre-image of %s: %w", restores[i].virtualPath, err)
+		}
+	}
+	for i := range deletions {
+		if err := capture(&deletions[i]); err != nil {
+			cleanupTemps()
+			return fmt.Errorf("failed to capture pre-image of %s: %w", deletions[i].virtualPath, err)
+		}
+	}
+
+	// Commit: rename staged files into place and remove deleted files
+	var committed []*restoreItem
+	undo := func() {
+		for i := len(committed) - 1; i >= 0; i-- {
+			item := committed[i]
+			if item.existed {
+				ops.WriteFile(item.localPath, item.preImage, item.preMode)
+			} else {
+				ops.Remove(item.localPath)
+			}
+		}
+		cleanupTemps()
+	}
+
+	for i := range restores {
+		item := &restores[i]
+		if err := ops.Rename(item.tempPath, item.localPath); err != nil {
+			undo()
+			return fmt.Errorf("failed to restore file %s: %w", item.virtualPath, err)
+		}
+		item.tempPath = ""
+		committed = append(committed, item)
+	}
+	for i := range deletions {
+		item := &deletions[i]
+		if !item.existed {
+			continue
+		}
+		if err := ops.Remove(item.localPath); err != nil {
+			undo()
+			return fmt.Errorf("failed to remove file %s: %w", item.virtualPath, err)
+		}
+		committed = append(committed, item)
+	}
+
+	// Restore modification times if possible
+	for _, item := range restores {
+		os.Chtimes(item.localPath, item.snap.ModTime, item.snap.ModTime)
+	}
+
+	return nil
+}
+
+// restoreMode returns the permission bits for a restored file: those recorded
+// in the snapshot, else those of the file being replaced, else 0644
+func restoreMode(item *restoreItem) os.FileMode {
+	if item.snap.Mode != 0 {
+		return item.snap.Mode
+	}
+	if info, err := os.Stat(item.localPath); err == nil && !info.IsDir() {
+		return info.Mode().Perm()
+	}
+	return 0o644
+}
+
+// snapshotFiles returns the effective file set of snap: the files of its base
+// chain, overridden (or removed, if deleted) by those of each later snapshot
+func (fs *ToolFS) snapshotFiles(snap *Snapshot) map[string]*FileSnapshot {
+	files := make(map[string]*FileSnapshot)
+
+	// Recursively collect files from snapshot and its base chain
+	// IMPORTANT: We must recurse to base first, then add current snapshot files
+	// This ensures current snapshot files override base snapshot files
+	var collectFiles func(snap *Snapshot)
+	collectFiles = func(snap *Snapshot) {
+		// First, recurse to base snapshot if exists (collect base files first)
+		if snap.BaseSnapshot != "" {
+			if baseSnap, exists := fs.snapshots[snap.BaseSnapshot]; exists {
+				collectFiles(baseSnap)
+			}
+		}
+
+		// Then add all files from this snapshot (overwrites base files if modified)
+		for path, fileSnap := range snap.Files {
+			// Deleted files drop out of the set - don't restore them
+			if fileSnap.Operation == "deleted" {
+				delete(files, path)
+			} else {
+				files[path] = fileSnap
+			}
+		}
+	}
+
+	collectFiles(snap)
+	return files
+}
+
 // GetSnapshot retrieves snapshot metadata
 func (fs *ToolFS) GetSnapshot(name string) (*SnapshotMetadata, error) {
 	snapshot, exists := fs.snapshots[name]
 	if !exists {
-		return nil, fmt.Errorf("snapshot '%s' does not exist", name)
+		return nil, notFoundf("snapshot '%s' does not exist", name)
 	}
 
 	return &snapshot.Metadata, nil
@@ -1742,7 +3881,7 @@ func (fs *ToolFS) DeleteSnapshot(name string) error {
 	}
 
 	if _, exists := fs.snapshots[name]; !exists {
-		return fmt.Errorf("snapshot '%s' does not exist", name)
+		return notFoundf("snapshot '%s' does not exist", name)
 	}
 
 	if fs.sandboxBackend != nil {
@@ -1755,7 +3894,8 @@ func (fs *ToolFS) DeleteSnapshot(name string) error {
 	return nil
 }
 
-// TrackChange records a change for the current snapshot
+// TrackChange records a change for the current snapshot. The snapshot's
+// files are not updated; see CommitChanges.
 func (fs *ToolFS) TrackChange(path, operation, sessionID string) {
 	if fs.currentSnapshot == "" {
 		return // No active snapshot to track
@@ -1779,14 +3919,15 @@ func (fs *ToolFS) TrackChange(path, operation, sessionID string) {
 
 	// Note: We intentionally do NOT update snapshot.Files here
 	// Snapshots are immutable once created. To capture modified state,
-	// create a new snapshot which will capture the current filesystem state.
+	// create a new snapshot which will capture the current filesystem state,
+	// or materialize the tracked changes explicitly with CommitChanges.
 }
 
 // GetSnapshotChanges returns all changes tracked for a snapshot
 func (fs *ToolFS) GetSnapshotChanges(name string) ([]ChangeRecord, error) {
 	snapshot, exists := fs.snapshots[name]
 	if !exists {
-		return nil, fmt.Errorf("snapshot '%s' does not exist", name)
+		return nil, notFoundf("snapshot '%s' does not exist", name)
 	}
 
 	return snapshot.Changes, nil
@@ -1797,9 +3938,26 @@ type CommandValidator interface {
 	IsCommandAllowed(command string, args []string) (bool, string)
 }
 
+// FilterMode selects how a DangerousCommandFilter decides which commands run
+type FilterMode int
+
+const (
+	FilterModeDenylist  FilterMode = iota // Allow everything except blocked commands and patterns (default)
+	FilterModeAllowlist                   // Allow only explicitly listed commands
+)
+
 // DangerousCommandFilter is a default implementation that blocks dangerous commands
 type DangerousCommandFilter struct {
+	mode            FilterMode
 	blockedCommands map[string]bool
+	allowedCommands map[string]bool
+	rules           []commandRule
+}
+
+// commandRule is a custom pattern added with AddRule
+type commandRule struct {
+	pattern *regexp.Regexp
+	reason  string
 }
 
 // NewDangerousCommandFilter creates a new command filter with default blocked commands
@@ -1828,13 +3986,73 @@ func NewDangerousCommandFilter() *DangerousCommandFilter {
 	}
 	return &DangerousCommandFilter{
 		blockedCommands: blocked,
+		allowedCommands: make(map[string]bool),
+	}
+}
+
+// NewAllowlistCommandFilter creates a command filter in allowlist mode that
+// permits exactly the given commands. Commands are matched by name,
+// case-insensitively; a path such as /usr/bin/git does not match "git".
+func NewAllowlistCommandFilter(allowed []string) *DangerousCommandFilter {
+	f := NewDangerousCommandFilter()
+	f.mode = FilterModeAllowlist
+	f.AllowCommands(allowed...)
+	return f
+}
+
+// SetMode switches the filter between denylist and allowlist mode. The
+// blocked and allowed command lists are kept, so the mode can be switched back.
+func (f *DangerousCommandFilter) SetMode(mode FilterMode) {
+	f.mode = mode
+}
+
+// Mode returns the filter's current mode
+func (f *DangerousCommandFilter) Mode() FilterMode {
+	return f.mode
+}
+
+// AllowCommands adds commands to the list consulted in allowlist mode
+func (f *DangerousCommandFilter) AllowCommands(commands ...string) {
+	for _, command := range commands {
+		f.allowedCommands[strings.ToLower(strings.TrimSpace(command))] = true
 	}
 }
 
-// IsCommandAllowed checks if a command is allowed
+// AddRule blocks commands whose full text (the command and its arguments
+// joined by spaces) matches pattern. reason describes the rule and is included
+// in the rejection reason. Rules are checked in the order added, after the
+// built-in denylist or allowlist.
+func (f *DangerousCommandFilter) AddRule(pattern *regexp.Regexp, reason string) {
+	f.rules = append(f.rules, commandRule{pattern: pattern, reason: reason})
+}
+
+// IsCommandAllowed checks if a command is allowed. In allowlist mode only the
+// command name is checked against the list, then custom rules are applied.
 func (f *DangerousCommandFilter) IsCommandAllowed(command string, args []string) (bool, string) {
+	if allowed, reason := f.checkStatic(command, args); !allowed {
+		return false, reason
+	}
+
+	fullCmd := strings.Join(append([]string{command}, args...), " ")
+	for _, rule := range f.rules {
+		if rule.pattern.MatchString(fullCmd) {
+			return false, fmt.Sprintf("command blocked by rule '%s' (%s)", rule.reason, rule.pattern)
+		}
+	}
+	return true, ""
+}
+
+// checkStatic applies the allowlist or the built-in denylist
+func (f *DangerousCommandFilter) checkStatic(command string, args []string) (bool, string) {
 	cmdLower := strings.ToLower(strings.TrimSpace(command))
 
+	if f.mode == FilterModeAllowlist {
+		if !f.allowedCommands[cmdLower] {
+			return false, fmt.Sprintf("command '%s' is not in the allowlist", command)
+		}
+		return true, ""
+	}
+
 	// Check if the command itself is blocked
 	if f.blockedCommands[cmdLower] {
 		return false, fmt.Sprintf("command '%s' is blocked", command)
@@ -1868,29 +4086,99 @@ func (f *DangerousCommandFilter) IsCommandAllowed(command string, args []string)
 	return true, ""
 }
 
-// ExecuteCommand validates and optionally executes a command (validation only for now)
-func (fs *ToolFS) ExecuteCommandWithSession(command string, args []string, session *Session) error {
+// defaultCommandTimeout bounds how long ExecuteCommandWithSession lets a
+// command run
+const defaultCommandTimeout = 30 * time.Second
+
+// SetCommandTimeout sets how long ExecuteCommandWithSession lets a command run
+// before killing it (30 seconds by default)
+func (fs *ToolFS) SetCommandTimeout(timeout time.Duration) {
+	fs.commandTimeout = timeout
+}
+
+// ExecuteCommandWithSession validates a command against the session's command
+// validator, or a default DangerousCommandFilter if the session has none, and
+// runs it with dir as the working directory. Commands can change files, so dir
+// must be a directory in a writable local mount the session may write to.
+// Stdout, stderr and the exit code are captured; a non-zero exit code is not
+// an error. Commands running longer than the command timeout (see
+// SetCommandTimeout) are killed and fail with an error wrapping
+// context.DeadlineExceeded.
+func (fs *ToolFS) ExecuteCommandWithSession(dir, command string, args []string, session *Session) (*CLIOutput, error) {
 	if session == nil {
-		return errors.New("session required for command execution")
+		return nil, errors.New("session required for command execution")
 	}
 
-	allowed, reason := session.ValidateCommand(command, args)
-	if !allowed {
-		err := fmt.Errorf("command not allowed: %s", reason)
-		if session.AuditLogger != nil {
-			session.logAudit("ExecuteCommand", command+" "+strings.Join(args, " "), false, err, 0, 0)
-		}
-		return err
+	start := time.Now()
+	fullCommand := strings.Join(append([]string{command}, args...), " ")
+	output, err := fs.executeCommand(dir, command, args, session)
+	var bytesRead int64
+	if output != nil {
+		bytesRead = int64(len(output.Stdout))
 	}
+	fs.auditOp(session, "ExecuteCommand", fullCommand, err, bytesRead, 0, start, nil, nil)
+	return output, err
+}
 
-	// Log successful validation
-	if session.AuditLogger != nil {
-		session.logAudit("ExecuteCommand", command+" "+strings.Join(args, " "), true, nil, 0, 0)
+// executeCommand implements ExecuteCommandWithSession without auditing
+func (fs *ToolFS) executeCommand(dir, command string, args []string, session *Session) (*CLIOutput, error) {
+	validator := session.CommandValidator
+	if validator == nil {
+		validator = NewDangerousCommandFilter()
+	}
+	if allowed, reason := validator.IsCommandAllowed(command, args); !allowed {
+		return nil, fmt.Errorf("command not allowed: %s", reason)
 	}
 
-	// In a real implementation, you would execute the command here
-	// For now, we just validate
-	return nil
+	dir = normalizeVirtualPath(dir)
+	if err := fs.authorize(session, "ExecuteCommand", dir); err != nil {
+		return nil, err
+	}
+	localPath, mount, err := fs.resolvePath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if mountKind(mount) != "local" {
+		return nil, fmt.Errorf("working directory must be in a local mount: %s", dir)
+	}
+	if mount.ReadOnly {
+		return nil, fmt.Errorf("%w: cannot run commands in read-only mount: %s", ErrReadOnly, dir)
+	}
+	if info, err := os.Stat(localPath); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, dir)
+	}
+
+	timeout := fs.commandTimeout
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr strings.Builder
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = localPath
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait on output pipes held open by children of a killed command
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("command '%s' timed out after %s: %w", command, timeout, ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("failed to run command '%s': %w", command, err)
+	}
+	return &CLIOutput{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Command:  strings.Join(append([]string{command}, args...), " "),
+	}, nil
 }
 
 // GetSkillDocument retrieves a skill document by skill name or path key
@@ -0,0 +1,167 @@
+package toolfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FSEventOp is the kind of change reported by WatchMount
+type FSEventOp string
+
+const (
+	FSEventCreate FSEventOp = "create"
+	FSEventWrite  FSEventOp = "write"
+	FSEventRemove FSEventOp = "remove"
+	FSEventRename FSEventOp = "rename" // Reported for the old path; the new path gets a create event
+)
+
+// FSEvent is a change on disk below a watched mount
+type FSEvent struct {
+	Op   FSEventOp `json:"op"`
+	Path string    `json:"path"` // Virtual path of the changed file or directory
+}
+
+// WatchMount watches the local directory of the mount at mountPoint,
+// including its subdirectories, and calls handler for every file created,
+// written, removed or renamed below it. Cached path resolutions of changed
+// paths are discarded before handler runs. Handlers run one at a time on the
+// watcher's goroutine. A mount can have several watchers; stop closes this
+// one and waits for its goroutine to exit, so handler is not called after
+// stop returns.
+func (fs *ToolFS) WatchMount(mountPoint string, handler func(event FSEvent)) (stop func(), err error) {
+	if handler == nil {
+		return nil, errors.New("watch handler cannot be nil")
+	}
+	mountPoint = fs.rootedMountPoint(mountPoint)
+
+	mount, exists := fs.mounts[mountPoint]
+	if !exists {
+		return nil, fmt.Errorf("no local directory mounted at path '%s'", mountPoint)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	w := &mountWatcher{
+		fs:         fs,
+		watcher:    watcher,
+		mountPoint: mountPoint,
+		localPath:  mount.LocalPath,
+		handler:    handler,
+		done:       make(chan struct{}),
+	}
+	if err := w.addTree(mount.LocalPath); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch '%s': %w", mountPoint, err)
+	}
+
+	go w.run()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			watcher.Close()
+			<-w.done
+		})
+	}, nil
+}
+
+// mountWatcher translates fsnotify events for one WatchMount call
+type mountWatcher struct {
+	fs         *ToolFS
+	watcher    *fsnotify.Watcher
+	mountPoint string
+	localPath  string
+	handler    func(event FSEvent)
+	done       chan struct{} // Closed when run returns
+}
+
+// addTree watches dir and every directory below it; fsnotify watches are
+// not recursive
+func (w *mountWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			// Directories removed while walking are skipped
+			if path != dir && errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		return w.watcher.Add(path)
+	})
+}
+
+func (w *mountWatcher) run() {
+	defer close(w.done)
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		case _, ok := <-w.watcher.Errors:
+			// Errors (e.g. queue overflows) are dropped; handlers only see
+			// the events that were delivered
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (w *mountWatcher) handle(event fsnotify.Event) {
+	var op FSEventOp
+	switch {
+	case event.Has(fsnotify.Create):
+		op = FSEventCreate
+		// Watch new directories, including any created inside them before
+		// the watch was added
+		if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+			w.addTree(event.Name)
+		}
+	case event.Has(fsnotify.Write):
+		op = FSEventWrite
+	case event.Has(fsnotify.Remove):
+		op = FSEventRemove
+	case event.Has(fsnotify.Rename):
+		op = FSEventRename
+	default:
+		return
+	}
+
+	rel, err := filepath.Rel(w.localPath, event.Name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return
+	}
+	path := w.mountPoint
+	if rel != "." {
+		path = normalizeVirtualPath(w.mountPoint + "/" + filepath.ToSlash(rel))
+	}
+
+	w.fs.invalidateResolvedPath(path)
+	w.handler(FSEvent{Op: op, Path: path})
+}
+
+// invalidateResolvedPath discards cached resolutions of path and of
+// everything below it
+func (fs *ToolFS) invalidateResolvedPath(path string) {
+	fs.invalidateLastResolved()
+	fs.pathResolveCache.Range(func(key, value interface{}) bool {
+		cached := key.(string)
+		if cached == path || strings.HasPrefix(cached, path+"/") {
+			fs.pathResolveCache.Delete(key)
+		}
+		return true
+	})
+}
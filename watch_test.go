@@ -0,0 +1,96 @@
+package toolfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForEvent returns the first event on events for path with op
+func waitForEvent(t *testing.T, events <-chan FSEvent, op FSEventOp, path string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Op == op && event.Path == path {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %s event on %s", op, path)
+		}
+	}
+}
+
+func TestWatchMount(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	fs := NewToolFS("/toolfs")
+	fs.MountLocal("/data", tmpDir, false)
+
+	first := make(chan FSEvent, 100)
+	second := make(chan FSEvent, 100)
+	stopFirst, err := fs.WatchMount("/data", func(event FSEvent) { first <- event })
+	if err != nil {
+		t.Fatalf("WatchMount failed: %v", err)
+	}
+	stopSecond, err := fs.WatchMount("/toolfs/data", func(event FSEvent) { second <- event })
+	if err != nil {
+		t.Fatalf("WatchMount failed: %v", err)
+	}
+	defer stopSecond()
+
+	// Changes on disk are reported to every watcher with virtual paths
+	os.WriteFile(filepath.Join(tmpDir, "new.txt"), []byte("new"), 0o644)
+	waitForEvent(t, first, FSEventCreate, "/toolfs/data/new.txt")
+	waitForEvent(t, second, FSEventCreate, "/toolfs/data/new.txt")
+
+	// Resolved paths are dropped from the cache when they change
+	fs.ReadFile("/toolfs/data/test.txt")
+	if _, cached := fs.pathResolveCache.Load("/toolfs/data/test.txt"); !cached {
+		t.Fatal("Expected resolution to be cached")
+	}
+	os.WriteFile(filepath.Join(tmpDir, "test.txt"), []byte("changed"), 0o644)
+	waitForEvent(t, first, FSEventWrite, "/toolfs/data/test.txt")
+	if _, cached := fs.pathResolveCache.Load("/toolfs/data/test.txt"); cached {
+		t.Error("Expected cached resolution to be invalidated")
+	}
+
+	// Existing and new subdirectories are watched
+	os.Remove(filepath.Join(tmpDir, "subdir", "subfile.txt"))
+	waitForEvent(t, first, FSEventRemove, "/toolfs/data/subdir/subfile.txt")
+	os.Mkdir(filepath.Join(tmpDir, "nested"), 0o755)
+	waitForEvent(t, first, FSEventCreate, "/toolfs/data/nested")
+	os.WriteFile(filepath.Join(tmpDir, "nested", "deep.txt"), []byte("deep"), 0o644)
+	waitForEvent(t, first, FSEventCreate, "/toolfs/data/nested/deep.txt")
+
+	os.Rename(filepath.Join(tmpDir, "new.txt"), filepath.Join(tmpDir, "renamed.txt"))
+	waitForEvent(t, first, FSEventRename, "/toolfs/data/new.txt")
+	waitForEvent(t, first, FSEventCreate, "/toolfs/data/renamed.txt")
+
+	// A stopped watcher receives nothing, the others keep working
+	stopFirst()
+	stopFirst()
+	for len(first) > 0 {
+		<-first
+	}
+	os.WriteFile(filepath.Join(tmpDir, "after.txt"), []byte("after"), 0o644)
+	waitForEvent(t, second, FSEventCreate, "/toolfs/data/after.txt")
+	if len(first) != 0 {
+		t.Errorf("Expected no events after stop, got %v", <-first)
+	}
+}
+
+func TestWatchMountErrors(t *testing.T) {
+	fs := NewToolFS("/toolfs")
+	if _, err := fs.WatchMount("/missing", func(FSEvent) {}); err == nil {
+		t.Error("Expected error for unknown mount")
+	}
+
+	fs.MountLocal("/data", t.TempDir(), false)
+	if _, err := fs.WatchMount("/data", nil); err == nil {
+		t.Error("Expected error for nil handler")
+	}
+}